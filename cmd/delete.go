@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdDeleteUse   = "delete <id>..."
+	cmdDeleteShort = "Delete files of your account"
+	cmdDeleteLong  = "Delete files by passing the file urls or file ids, requires your API Key with -k"
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   cmdDeleteUse,
+	Short: cmdDeleteShort,
+	Long:  cmdDeleteLong,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  app.RunDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().StringP("api-key", "k", "", "Auth key for authentication")
+	deleteCmd.Flags().IntP("concurrency", "c", 4, "Files deleted at the same time")
+}
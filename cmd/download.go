@@ -25,4 +25,8 @@ func init() {
 	downloadCmd.Flags().StringP("path", "p", "", "Path where the files are stored")
 	downloadCmd.Flags().StringP("api-key", "k", "", "Auth key for authentication")
 	downloadCmd.Flags().BoolP("verbose", "v", true, "Show more information after an upload (Anonymous, ID, URL)")
+	downloadCmd.Flags().Bool("resume", false, "Continue partially downloaded files and check their hash")
+	downloadCmd.Flags().Int("segments", 0, "Download large files in this many parallel segments")
+	downloadCmd.Flags().String("manifest", "", "Restore the directory tree of an upload manifest into --path")
+	downloadCmd.Flags().String("key", "", "Passphrase or age identity to decrypt files uploaded with --encrypt")
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdFSUse   = "fs"
+	cmdFSShort = "Manage the filesystem of your account"
+	cmdFSLong  = "Create, upload, list, rename, move and delete files and directories in the filesystem of your account, requires your API Key with -k"
+)
+
+// fsCmd represents the filesystem command
+var fsCmd = &cobra.Command{
+	Use:   cmdFSUse,
+	Short: cmdFSShort,
+	Long:  cmdFSLong,
+}
+
+// fsRenameCmd represents the filesystem rename command
+var fsRenameCmd = &cobra.Command{
+	Use:   "rename <path> <target>",
+	Short: "Rename a file or directory, e.g. /me/cat.jpg /me/kitty.jpg",
+	Args:  cobra.ExactArgs(2),
+	RunE:  app.RunFSRename,
+}
+
+// fsMoveCmd represents the filesystem move command
+var fsMoveCmd = &cobra.Command{
+	Use:   "move <path>... <directory>",
+	Short: "Move files or directories into a directory, e.g. /me/cat.jpg /me/archive",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  app.RunFSMove,
+}
+
+// fsMkdirCmd represents the filesystem mkdir command
+var fsMkdirCmd = &cobra.Command{
+	Use:   "mkdir <path>...",
+	Short: "Create directories, a directory at the root is a bucket, e.g. /backups",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  app.RunFSMkdir,
+}
+
+// fsPutCmd represents the filesystem put command
+var fsPutCmd = &cobra.Command{
+	Use:   "put <file> <path>",
+	Short: "Upload a file to a path, e.g. cat.jpg /me/photos/cat.jpg",
+	Args:  cobra.ExactArgs(2),
+	RunE:  app.RunFSPut,
+}
+
+// fsLsCmd represents the filesystem ls command
+var fsLsCmd = &cobra.Command{
+	Use:   "ls <path>",
+	Short: "List the files and directories of a directory, e.g. /me/photos",
+	Args:  cobra.ExactArgs(1),
+	RunE:  app.RunFSList,
+}
+
+// fsRmCmd represents the filesystem rm command
+var fsRmCmd = &cobra.Command{
+	Use:   "rm <path>...",
+	Short: "Delete files or directories, e.g. /me/photos/cat.jpg",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  app.RunFSDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(fsCmd)
+	fsCmd.AddCommand(fsRenameCmd, fsMoveCmd, fsMkdirCmd, fsPutCmd, fsLsCmd, fsRmCmd)
+	fsMkdirCmd.Flags().BoolP("parents", "p", false, "Create the missing parent directories")
+	fsPutCmd.Flags().BoolP("parents", "p", false, "Create the missing parent directories of the path")
+	fsRmCmd.Flags().BoolP("recursive", "r", false, "Delete directories with everything in them")
+	fsCmd.PersistentFlags().StringP("api-key", "k", "", "Auth key for authentication")
+	fsCmd.PersistentFlags().BoolP("verbose", "v", true, "Show more information after a change (old and new path)")
+}
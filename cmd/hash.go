@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdHashUse   = "hash <file>..."
+	cmdHashShort = "Print the SHA-256 hash of files"
+	cmdHashLong  = "Print the SHA-256 hash of files, the hash the duplicate check and pixeldrain compare, or the hash of another --algorithm"
+)
+
+// hashCmd represents the hash command
+var hashCmd = &cobra.Command{
+	Use:   cmdHashUse,
+	Short: cmdHashShort,
+	Long:  cmdHashLong,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  app.RunHash,
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+	hashCmd.Flags().String("algorithm", "sha256", "Hash algorithm: sha256, blake3 or md5")
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdInfoUse   = "info <id>..."
+	cmdInfoShort = "Show the information of files"
+	cmdInfoLong  = "Show name, size, type, upload date and views of files by passing the file urls or file ids"
+)
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   cmdInfoUse,
+	Short: cmdInfoShort,
+	Long:  cmdInfoLong,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  app.RunInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().StringP("api-key", "k", "", "Auth key for authentication")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdListUse   = "list"
+	cmdListShort = "Create and show lists"
+	cmdListLong  = "Create lists of uploaded files and show their files, lists of your account need your API Key with -k"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   cmdListUse,
+	Short: cmdListShort,
+	Long:  cmdListLong,
+}
+
+// listCreateCmd represents the list create command
+var listCreateCmd = &cobra.Command{
+	Use:   "create <title> <id>...",
+	Short: "Create a list of files, e.g. holiday abc123 def456",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  app.RunListCreate,
+}
+
+// listGetCmd represents the list get command
+var listGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Show the files of a list",
+	Args:  cobra.ExactArgs(1),
+	RunE:  app.RunListGet,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.AddCommand(listCreateCmd, listGetCmd)
+	listCmd.PersistentFlags().StringP("api-key", "k", "", "Auth key for authentication")
+}
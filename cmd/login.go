@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdLoginUse   = "login"
+	cmdLoginShort = "Store your API Key in the keyring of the OS"
+	cmdLoginLong  = "Store your API Key in the keyring of the OS, the other commands use it if no key is given with -k or PD_API_KEY.\n" +
+		"The key is asked for without echoing it, or read from stdin, e.g. pass show pixeldrain | go-pd login"
+)
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   cmdLoginUse,
+	Short: cmdLoginShort,
+	Long:  cmdLoginLong,
+	Args:  cobra.NoArgs,
+	RunE:  app.RunLogin,
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+}
@@ -1,20 +1,27 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/itsDarianNgo/go-pd/internal/app"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
 	"github.com/spf13/cobra"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "go-pd",
-	Short: "go-pd is a CLI tool to manage your pixeldrain.com account via cli.",
+	Use:     "go-pd",
+	Version: pd.Version,
+	Short:   "go-pd is a CLI tool to manage your pixeldrain.com account via cli.",
 	Long: `
 go-pd is a CLI tool to manage your pixeldrain.com account via cli.
 
 @Copyright by Manuel Reschke
 	`,
+	SilenceErrors: true,
+	// the config file sets the flags which are not given
+	PersistentPreRunE: app.LoadConfig,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -25,7 +32,10 @@ go-pd is a CLI tool to manage your pixeldrain.com account via cli.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		// a summary for users instead of the raw error, the exit code tells scripts what failed
+		summary := pd.DefaultPresenter.Error(err)
+		fmt.Fprintln(os.Stderr, summary)
+		os.Exit(summary.ExitCode)
 	}
 }
 
@@ -34,7 +44,9 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.go-pd.yaml)")
+	rootCmd.PersistentFlags().String("config", "", "config file (default is "+pd.DefaultConfigPath()+")")
+
+	rootCmd.PersistentFlags().Bool("json", false, "Print the results as JSON")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -9,7 +9,7 @@ import (
 const (
 	cmdUploadUse   = "upload"
 	cmdUploadShort = "With that command you can upload files"
-	cmdUploadLong  = "Upload files by passing the -f flag for your file and your API Key with -k"
+	cmdUploadLong  = "Upload files, directories (-r), URLs or stdin (- with --name) and pass your API Key with -k"
 )
 
 // uploadCmd represents the upload command
@@ -24,4 +24,20 @@ func init() {
 	rootCmd.AddCommand(uploadCmd)
 	uploadCmd.Flags().StringP("api-key", "k", "", "Auth key for authentication")
 	uploadCmd.Flags().BoolP("verbose", "v", true, "Show more information after an upload (Anonymous, ID, URL)")
+	uploadCmd.Flags().Bool("delete-after", false, "Delete the local file after a successful upload")
+	uploadCmd.Flags().String("archive-dir", "", "Move the local file into this directory after a successful upload")
+	uploadCmd.Flags().BoolP("recursive", "r", false, "Upload the files of directories and their subdirectories")
+	uploadCmd.Flags().IntP("concurrency", "c", 1, "Files uploaded at the same time")
+	uploadCmd.Flags().String("encrypt", "", "Encrypt the files with this passphrase or these comma separated age recipients before the upload")
+	uploadCmd.Flags().String("name", "", "File name of the upload from stdin (-)")
+	uploadCmd.Flags().String("archive", "", "Upload each directory as one archive file (tar or zip) instead of file by file")
+	uploadCmd.Flags().String("compress", "", "Compress the files before the upload (gzip)")
+	uploadCmd.Flags().Bool("list", false, "Create a list of all files of each uploaded directory, titled after the directory")
+	uploadCmd.Flags().String("paths", "", "Keep the paths of files in subdirectories in their file name (name) or list description (description)")
+	uploadCmd.Flags().String("manifest", "", "Write a JSON manifest of the uploaded directory (path, ID, hash, size, mtime) to this file")
+	uploadCmd.Flags().String("index", "", "Write a markdown index of the uploaded directory to this file, HTML for .html")
+	uploadCmd.Flags().String("hash-cache", "", "Remember the hashes of checked files here, unchanged files (same size and mtime) are not hashed again")
+	uploadCmd.Flags().String("hash-algorithm", "sha256", "Hash algorithm of the duplicate check: sha256, blake3 or md5")
+	uploadCmd.Flags().Bool("force-hash", false, "Hash every file for the duplicate check, even if the --hash-cache knows it unchanged")
+	uploadCmd.Flags().String("webhook", "", "POST a JSON payload (name, ID, URL, size, hash) to this URL after every upload")
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"github.com/itsDarianNgo/go-pd/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdUserUse   = "user"
+	cmdUserShort = "Show the files and the quota of your account"
+	cmdUserLong  = "Show the files, the storage space, the transfer cap, the activity and the charges of your account, requires your API Key with -k"
+)
+
+// userCmd represents the user command
+var userCmd = &cobra.Command{
+	Use:   cmdUserUse,
+	Short: cmdUserShort,
+	Long:  cmdUserLong,
+}
+
+// userFilesCmd represents the user files command
+var userFilesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "List the files of your account",
+	Args:  cobra.NoArgs,
+	RunE:  app.RunUserFiles,
+}
+
+// userQuotaCmd represents the user quota command
+var userQuotaCmd = &cobra.Command{
+	Use:   "quota [file]...",
+	Short: "Show the storage space and transfer left, with files check if they can be uploaded",
+	RunE:  app.RunUserQuota,
+}
+
+// userActivityCmd represents the user activity command
+var userActivityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Show the file events of your account, e.g. expired files",
+	Args:  cobra.NoArgs,
+	RunE:  app.RunUserActivity,
+}
+
+// userTransactionsCmd represents the user transactions command
+var userTransactionsCmd = &cobra.Command{
+	Use:   "transactions",
+	Short: "Show the storage and bandwidth charges of your account",
+	Args:  cobra.NoArgs,
+	RunE:  app.RunUserTransactions,
+}
+
+func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userFilesCmd, userQuotaCmd, userActivityCmd, userTransactionsCmd)
+	userActivityCmd.Flags().Int("page", 0, "Page of the events, 0 is the newest page")
+	userCmd.PersistentFlags().StringP("api-key", "k", "", "Auth key for authentication")
+}
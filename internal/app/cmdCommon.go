@@ -0,0 +1,49 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/spf13/cobra"
+)
+
+// flagAuth returns the auth of the api-key flag, the PD_API_KEY environment variable, the OS keyring or the config
+// file, in this order. Commands of an account require a key.
+func flagAuth(cmd *cobra.Command, required bool) (pd.Auth, error) {
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	auth, err := pd.NewAuth(pd.FromKey(apiKey), pd.FromEnv(pd.EnvAPIKey), pd.FromKeyring(pd.SystemKeyring{}),
+		pd.FromKey(config.APIKey))
+	if err != nil {
+		return pd.Auth{}, err
+	}
+	if required && !auth.IsAuthAvailable() {
+		return pd.Auth{}, errors.New("please add a valid API-Key to your request")
+	}
+
+	return auth, nil
+}
+
+// jsonOutput checks the json flag of the root command
+func jsonOutput(cmd *cobra.Command) bool {
+	enabled, _ := cmd.Flags().GetBool("json")
+	return enabled
+}
+
+// printJSON writes the value indented to stdout
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// concurrency returns the concurrency flag, at least 1
+func concurrency(cmd *cobra.Command) int {
+	n, _ := cmd.Flags().GetInt("concurrency")
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
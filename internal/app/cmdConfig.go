@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config is the optional config file of the CLI, its values are the defaults of the flags
+type Config struct {
+	APIKey      string `yaml:"api_key"`
+	Concurrency int    `yaml:"concurrency"`
+	LogPath     string `yaml:"log_path"` // upload log, upload_logs.csv in the working directory if empty
+	WebhookURL  string `yaml:"webhook_url"`
+}
+
+// config is loaded before every command by LoadConfig
+var config Config
+
+// LoadConfig reads the file of the config flag or the default config file and sets the flags of the command
+// which were not given, the API key is the last choice of flagAuth. A missing default file is no error, a missing file given with --config is.
+func LoadConfig(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("config")
+	explicit := path != ""
+	if !explicit {
+		path = pd.DefaultConfigPath()
+	}
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	if config.Concurrency > 0 {
+		setDefault(cmd, "concurrency", strconv.Itoa(config.Concurrency))
+	}
+	if config.WebhookURL != "" {
+		setDefault(cmd, "webhook", config.WebhookURL)
+	}
+
+	return nil
+}
+
+// setDefault sets the flag of the command if it has the flag and it was not given
+func setDefault(cmd *cobra.Command, name, value string) {
+	if flag := cmd.Flags().Lookup(name); flag != nil && !flag.Changed {
+		_ = flag.Value.Set(value)
+	}
+}
+
+// newClient returns the client of a command with the options of the config file
+func newClient() *pd.PixelDrainClient {
+	if config.LogPath == "" {
+		return pd.New(nil, nil)
+	}
+
+	// the defaults of pd.New(nil, nil) with the upload log of the config
+	return pd.New(&pd.ClientOptions{
+		EnableCookies:     true,
+		EnableInsecureTLS: true,
+		Timeout:           1 * time.Hour,
+		UploadLogPath:     config.LogPath,
+	}, nil)
+}
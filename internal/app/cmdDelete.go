@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+func RunDelete(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(args))
+	for i, file := range args {
+		ids[i] = filepath.Base(file) // an URL or an ID
+	}
+
+	c := newClient()
+	rsp, err := c.DeleteMany(&pd.RequestDeleteMany{
+		IDs:         ids,
+		Auth:        auth,
+		Concurrency: concurrency(cmd),
+	})
+	if rsp == nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		type result struct {
+			ID         string `json:"id"`
+			StatusCode int    `json:"status_code"`
+			Error      string `json:"error,omitempty"`
+		}
+		results := make([]result, len(rsp.Results))
+		for i, r := range rsp.Results {
+			results[i] = result{ID: r.ID, StatusCode: r.StatusCode}
+			if r.Err != nil {
+				results[i].Error = r.Err.Error()
+			}
+		}
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range rsp.Results {
+			if r.Err != nil {
+				fmt.Printf("Failed! ID: %s | %v\n", r.ID, r.Err)
+			} else {
+				fmt.Printf("Deleted: %s\n", r.ID)
+			}
+		}
+	}
+
+	return err
+}
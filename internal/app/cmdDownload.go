@@ -11,6 +11,9 @@ import (
 )
 
 func RunDownload(cmd *cobra.Command, args []string) error {
+	if manifest, _ := cmd.Flags().GetString("manifest"); manifest != "" {
+		return runRestore(cmd, manifest)
+	}
 	if len(args) == 0 {
 		return errors.New("please add a pixeldrain URL or file id to your download request")
 	}
@@ -23,10 +26,13 @@ func RunDownload(cmd *cobra.Command, args []string) error {
 		path, _ = os.Getwd()
 	}
 
-	apiKey, err := cmd.Flags().GetString("api-key")
+	auth, err := flagAuth(cmd, false)
 	if err != nil {
-		return errors.New("please add a valid API-Key to your request")
+		return err
 	}
+	resume, _ := cmd.Flags().GetBool("resume")
+	segments, _ := cmd.Flags().GetInt("segments")
+	key, _ := cmd.Flags().GetString("key")
 
 	// file is here an url or an ID to a file
 	for _, file := range args {
@@ -36,13 +42,11 @@ func RunDownload(cmd *cobra.Command, args []string) error {
 		}
 
 		req01 := &pd.RequestFileInfo{
-			ID: fileID,
-		}
-		if apiKey != "" {
-			req01.Auth.APIKey = apiKey
+			ID:   fileID,
+			Auth: auth,
 		}
 
-		c := pd.New(nil, nil)
+		c := newClient()
 		rsp, err := c.GetFileInfo(req01)
 		if err != nil {
 			return err
@@ -51,9 +55,10 @@ func RunDownload(cmd *cobra.Command, args []string) error {
 		req := &pd.RequestDownload{
 			ID:         fileID,
 			PathToSave: filepath.FromSlash(path + "/" + rsp.Name),
-		}
-		if apiKey != "" {
-			req.Auth.APIKey = apiKey
+			Resume:     resume,
+			Segments:   segments,
+			Key:        key,
+			Auth:       auth,
 		}
 
 		rspDL, err := c.Download(req)
@@ -77,3 +82,53 @@ func RunDownload(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runRestore downloads the tree of an upload manifest into --path
+func runRestore(cmd *cobra.Command, manifest string) error {
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
+		path, _ = os.Getwd()
+	}
+	auth, err := flagAuth(cmd, false)
+	if err != nil {
+		return err
+	}
+	key, _ := cmd.Flags().GetString("key")
+
+	rsp, err := newClient().RestoreFromManifest(&pd.RequestRestoreManifest{
+		ManifestPath:    manifest,
+		Directory:       path,
+		Auth:            auth,
+		Key:             key,
+		ContinueOnError: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		if err := printJSON(rsp); err != nil {
+			return err
+		}
+	} else {
+		for _, file := range rsp.Files {
+			detail := file.URL
+			if file.Error != "" {
+				detail = file.Error
+			}
+			fmt.Printf("%s | %s | %s\n", file.Path, file.Status, detail)
+		}
+	}
+
+	failed := 0
+	for _, file := range rsp.Files {
+		if file.Status == pd.BatchFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be restored", failed)
+	}
+
+	return nil
+}
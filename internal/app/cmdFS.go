@@ -0,0 +1,164 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/spf13/cobra"
+)
+
+func RunFSRename(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	rsp, err := c.FS().Rename(&pd.RequestFSRename{
+		Path:   args[0],
+		Target: args[1],
+		Auth:   auth,
+	})
+	if err != nil {
+		return err
+	}
+
+	printFSChange(cmd, args[0], rsp.Path)
+
+	return nil
+}
+
+func RunFSMove(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	targetDir := args[len(args)-1]
+	for _, path := range args[:len(args)-1] {
+		rsp, err := c.FS().Move(&pd.RequestFSMove{
+			Path:      path,
+			TargetDir: targetDir,
+			Auth:      auth,
+		})
+		if err != nil {
+			return err
+		}
+
+		printFSChange(cmd, path, rsp.Path)
+	}
+
+	return nil
+}
+
+func RunFSMkdir(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+	parents, _ := cmd.Flags().GetBool("parents")
+
+	c := newClient()
+	for _, path := range args {
+		rsp, err := c.FS().Mkdir(&pd.RequestFSMkdir{
+			Path:    path,
+			Parents: parents,
+			Auth:    auth,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(rsp.Path)
+	}
+
+	return nil
+}
+
+func RunFSPut(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+	parents, _ := cmd.Flags().GetBool("parents")
+
+	c := newClient()
+	_, err = c.FS().Upload(&pd.RequestFSUpload{
+		Path:       args[1],
+		PathToFile: args[0],
+		Parents:    parents,
+		Auth:       auth,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(args[1])
+
+	return nil
+}
+
+func RunFSList(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	rsp, err := c.FS().List(&pd.RequestFSList{Path: args[0], Auth: auth})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp.Children)
+	}
+
+	for _, node := range rsp.Children {
+		if node.IsDir() {
+			fmt.Printf("%s/\n", node.Name)
+			continue
+		}
+		fmt.Printf("%s | %s | %s\n", node.Name, utils.FormatFileSize(node.FileSize), node.FileType)
+	}
+
+	return nil
+}
+
+func RunFSDelete(cmd *cobra.Command, args []string) error {
+	auth, err := fsAuth(cmd)
+	if err != nil {
+		return err
+	}
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	c := newClient()
+	for _, path := range args {
+		_, err := c.FS().Delete(&pd.RequestFSDelete{
+			Path:      path,
+			Recursive: recursive,
+			Auth:      auth,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+	}
+
+	return nil
+}
+
+// fsAuth the filesystem belongs to an account, the API key is required
+func fsAuth(cmd *cobra.Command) (pd.Auth, error) {
+	return flagAuth(cmd, true)
+}
+
+func printFSChange(cmd *cobra.Command, from, to string) {
+	if cmd.Flags().Changed("verbose") {
+		fmt.Printf("Successful! Moved: %s | To: %s\n", from, to)
+	} else {
+		fmt.Println(to)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/spf13/cobra"
+)
+
+func RunHash(cmd *cobra.Command, args []string) error {
+	type fileHash struct {
+		Path string `json:"path"`
+		Hash string `json:"hash"`
+	}
+
+	algorithm, _ := cmd.Flags().GetString("algorithm")
+
+	hashes := make([]fileHash, 0, len(args))
+	for _, file := range args {
+		hash, err := utils.CalculateFileHashWith(file, utils.HashAlgorithm(algorithm))
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, fileHash{Path: file, Hash: hash})
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(hashes)
+	}
+	// the format of sha256sum and md5sum, without the algorithm prefix
+	for _, h := range hashes {
+		_, sum := utils.ParseHash(h.Hash)
+		fmt.Printf("%s  %s\n", sum, h.Path)
+	}
+
+	return nil
+}
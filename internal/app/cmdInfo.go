@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/spf13/cobra"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+func RunInfo(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(args))
+	for i, file := range args {
+		ids[i] = filepath.Base(file) // an URL or an ID
+	}
+
+	// one request for all files, they are printed in the order of the arguments
+	c := newClient()
+	found, err := c.GetFileInfoMany(&pd.RequestFileInfoMany{IDs: ids, Auth: auth})
+	if err != nil {
+		return err
+	}
+	infos := make([]*pd.ResponseFileInfo, 0, len(ids))
+	for _, id := range ids {
+		info, ok := found[id]
+		if !ok {
+			return &pd.APIError{StatusCode: http.StatusNotFound, Value: "not_found", Message: fmt.Sprintf("file %s not found", id)}
+		}
+		infos = append(infos, info)
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(infos)
+	}
+
+	for _, info := range infos {
+		status := ""
+		if !info.IsAvailable() {
+			status = " | unavailable: " + info.Availability
+			if info.AbuseType != "" {
+				status += " (" + info.AbuseType + ")"
+			}
+		}
+		fmt.Printf("%s | %s | %s | %s | uploaded %s | %d views%s\n", info.ID, info.Name, utils.FormatFileSize(info.Size),
+			info.MimeType, info.DateUpload.Format(time.RFC3339), info.Views, status)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/spf13/cobra"
+	"path/filepath"
+)
+
+func RunListCreate(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	files := make([]pd.ListFile, 0, len(args)-1)
+	for _, file := range args[1:] {
+		files = append(files, pd.ListFile{ID: filepath.Base(file)})
+	}
+
+	c := newClient()
+	rsp, err := c.CreateList(&pd.RequestCreateList{
+		Title:     args[0],
+		Anonymous: auth.APIKey == "",
+		Files:     files,
+		Auth:      auth,
+	})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &pd.APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp)
+	}
+	fmt.Println(rsp.GetListURL())
+
+	return nil
+}
+
+func RunListGet(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	rsp, err := c.GetList(&pd.RequestGetList{ID: filepath.Base(args[0]), Auth: auth})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &pd.APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp)
+	}
+	fmt.Printf("%s | %s | %d files\n", rsp.ID, rsp.Title, len(rsp.Files))
+	for _, file := range rsp.Files {
+		fmt.Printf("%s | %s | %s\n", file.ID, file.Name, utils.FormatFileSize(file.Size))
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func RunLogin(cmd *cobra.Command, args []string) error {
+	key, err := readAPIKey(os.Stdin, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("please enter your API Key or pipe it into go-pd login")
+	}
+
+	if err := pd.SetAPIKey(key); err != nil {
+		return err
+	}
+
+	fmt.Println("API Key stored in the keyring")
+	return nil
+}
+
+// readAPIKey asks for the key without echoing it on a terminal, otherwise it reads the first line of the input.
+// The key is never an argument, so it doesn't end up in the shell history or the process list.
+func readAPIKey(in *os.File, prompt io.Writer) (string, error) {
+	fd := int(in.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(prompt, "API Key: ")
+		key, err := term.ReadPassword(fd)
+		fmt.Fprintln(prompt)
+		return strings.TrimSpace(string(key)), err
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// batchProgress draws a progress bar per file of a directory upload and counts the uploaded bytes for the summary
+type batchProgress struct {
+	out   io.Writer // nil draws no bars, e.g. if stderr is no terminal
+	start time.Time
+
+	mu    sync.Mutex
+	bytes int64
+}
+
+func newBatchProgress(bars bool) *batchProgress {
+	p := &batchProgress{start: time.Now()}
+	if bars && isTerminal(os.Stderr) {
+		p.out = os.Stderr
+	}
+
+	return p
+}
+
+// update is the Progress of RequestUploadDirectory, the files of a directory are uploaded one after another
+func (b *batchProgress) update(filePath string, p utils.Progress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if p.Done {
+		b.bytes += p.Transferred
+	}
+	if b.out == nil {
+		return
+	}
+
+	filled, percent := 0, "    ?%"
+	if p.Percent() >= 0 {
+		filled, percent = int(p.Percent()/100*progressBarWidth), fmt.Sprintf("%5.1f%%", p.Percent())
+	}
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+
+	fmt.Fprintf(b.out, "\r[%s] %s %10s/s  %s", bar, percent, utils.FormatFileSize(int64(p.BytesPerSec)), filepath.Base(filePath))
+	if p.Done {
+		fmt.Fprintln(b.out)
+	}
+}
+
+// printSummary writes the table of the uploaded, skipped and failed files of the directories
+func (b *batchProgress) printSummary(out io.Writer, directories []*pd.ResponseUploadDirectory) {
+	counts := map[pd.BatchFileStatus]int{}
+	for _, rsp := range directories {
+		for _, file := range rsp.Files {
+			counts[file.Status]++
+		}
+	}
+	skipped := counts[pd.BatchSkippedEmpty] + counts[pd.BatchSkippedSpecial] + counts[pd.BatchSkippedUnreadable]
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Uploaded\t%d\t\n", counts[pd.BatchCompleted])
+	fmt.Fprintf(w, "Skipped duplicates\t%d\t\n", counts[pd.BatchSkippedDuplicate])
+	if skipped > 0 {
+		fmt.Fprintf(w, "Skipped other\t%d\t\n", skipped)
+	}
+	fmt.Fprintf(w, "Failed\t%d\t\n", counts[pd.BatchFailed])
+	fmt.Fprintf(w, "Total bytes\t%s\t\n", utils.FormatFileSize(b.bytes))
+	fmt.Fprintf(w, "Elapsed\t%s\t\n", time.Since(b.start).Round(time.Millisecond))
+	_ = w.Flush()
+}
+
+// isTerminal checks if the file is a character device, a pipe or a file gets no progress bars
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const hashFilePath = "hashes.csv" // Define the hash file path
@@ -16,36 +18,207 @@ func RunUpload(cmd *cobra.Command, args []string) error {
 		return errors.New("please add a file to your upload request")
 	}
 
-	apiKey, err := cmd.Flags().GetString("api-key")
+	auth, err := flagAuth(cmd, false)
 	if err != nil {
-		return errors.New("please add a valid API-Key to your upload request")
+		return err
 	}
 
+	deleteAfter, _ := cmd.Flags().GetBool("delete-after")
+	archiveDir, _ := cmd.Flags().GetString("archive-dir")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	encrypt, _ := cmd.Flags().GetString("encrypt")
+	compress, _ := cmd.Flags().GetString("compress")
+	archive, _ := cmd.Flags().GetString("archive")
+	webhook, _ := cmd.Flags().GetString("webhook")
+	index, _ := cmd.Flags().GetString("index")
+	createList, _ := cmd.Flags().GetBool("list")
+	paths, _ := cmd.Flags().GetString("paths")
+	manifest, _ := cmd.Flags().GetString("manifest")
+	hashCache, _ := cmd.Flags().GetString("hash-cache")
+	forceHash, _ := cmd.Flags().GetBool("force-hash")
+	hashAlgorithm, _ := cmd.Flags().GetString("hash-algorithm")
+
+	// zstd is not built in, it would fail for every file
+	if compress != "" && pd.Compression(compress) != pd.CompressGzip {
+		return fmt.Errorf("unknown --compress %q, only gzip is supported", compress)
+	}
+
+	preservePaths := pd.PathNone
+	switch paths {
+	case "":
+	case "name":
+		preservePaths = pd.PathInFileName
+	case "description":
+		preservePaths = pd.PathInDescription
+	default:
+		return fmt.Errorf("unknown --paths %q, use name or description", paths)
+	}
+
+	var files, dirs, archives, urls []string
+	stdin := false
 	for _, file := range args {
+		if file == "-" {
+			stdin = true
+			continue
+		}
+		if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+			urls = append(urls, file)
+			continue
+		}
+
 		// check if file exist
-		if _, err := os.Stat(filepath.FromSlash(file)); errors.Is(err, os.ErrNotExist) {
+		info, err := os.Stat(filepath.FromSlash(file))
+		if errors.Is(err, os.ErrNotExist) {
 			return errors.New("one of the given files does not exist")
 		}
+		if err != nil {
+			return err
+		}
 
+		if info.IsDir() && archive != "" {
+			archives = append(archives, file)
+			continue
+		}
+		if info.IsDir() {
+			if !recursive {
+				return fmt.Errorf("%s is a directory, upload it with --recursive", file)
+			}
+			dirs = append(dirs, file)
+			continue
+		}
+		files = append(files, file)
+	}
+
+	c := newClient()
+	if webhook != "" {
+		c.Webhook = &pd.Webhook{URL: webhook}
+		defer c.Webhook.Wait()
+	}
+	if hashCache != "" {
+		c.HashCache = utils.NewFileHashCache(hashCache)
+	}
+	c.HashAlgorithm = utils.HashAlgorithm(hashAlgorithm)
+
+	// the files are uploaded in parallel, the results are printed in the order of the arguments
+	responses := make([]*pd.ResponseUpload, len(files))
+	errs := make([]error, len(files))
+	pd.Parallel(len(files), concurrency(cmd), func(i int) {
 		req := &pd.RequestUpload{
-			PathToFile: file,
-			Anonymous:  true,
+			PathToFile:        files[i],
+			Anonymous:         !auth.IsAuthAvailable(),
+			Auth:              auth,
+			DeleteAfterUpload: deleteAfter,
+			ArchiveDir:        archiveDir,
+			EncryptWith:       encrypt,
+			Compress:          pd.Compression(compress),
+			ForceHash:         forceHash,
+		}
+		responses[i], errs[i] = c.UploadPOST(req, hashFilePath) // Pass hashFilePath as an argument
+	})
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// a pipe, e.g. cat backup.tar | go-pd upload - --name backup.tar
+	if stdin {
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			return errors.New("please add a file name for the upload from stdin with --name")
+		}
+		rsp, err := c.UploadStream(&pd.RequestUploadStream{
+			Reader:    os.Stdin,
+			FileName:  name,
+			Anonymous: !auth.IsAuthAvailable(),
+			Auth:      auth,
+		})
+		if err != nil {
+			return err
+		}
+		responses = append(responses, rsp)
+	}
+
+	// a file of another server is streamed into pixeldrain without a local copy
+	for _, sourceURL := range urls {
+		rsp, err := c.UploadFromURL(&pd.RequestUploadFromURL{
+			SourceURL: sourceURL,
+			Anonymous: !auth.IsAuthAvailable(),
+			Auth:      auth,
+		})
+		if err != nil {
+			return err
+		}
+		responses = append(responses, rsp)
+	}
+
+	// a directory packed into one archive is printed like an uploaded file
+	for _, dir := range archives {
+		rsp, err := c.UploadDirectoryAsArchive(&pd.RequestUploadArchive{
+			Directory: dir,
+			Format:    pd.ArchiveFormat(archive),
+			Anonymous: !auth.IsAuthAvailable(),
+			Auth:      auth,
+		})
+		if err != nil {
+			return err
 		}
+		responses = append(responses, rsp)
+	}
 
-		if apiKey != "" {
-			req.Anonymous = false
-			req.Auth.APIKey = apiKey
+	// bars and the summary are for people, JSON is for scripts
+	progress := newBatchProgress(!jsonOutput(cmd))
+	directories := make([]*pd.ResponseUploadDirectory, len(dirs))
+	for i, dir := range dirs {
+		rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{
+			Directory:       dir,
+			Auth:            auth,
+			HashFilePath:    hashFilePath,
+			ContinueOnError: true,
+			Progress:        progress.update,
+			EncryptWith:     encrypt,
+			Compress:        pd.Compression(compress),
+			IndexPath:       perDirectoryPath(index, dir, len(dirs)),
+			ManifestPath:    perDirectoryPath(manifest, dir, len(dirs)),
+			CreateList:      createList,
+			PreservePaths:   preservePaths,
+			ForceHash:       forceHash,
+		})
+		if err != nil {
+			return err
 		}
+		directories[i] = rsp
+	}
 
-		c := pd.New(nil, nil)
-		rsp, err := c.UploadPOST(req, hashFilePath) // Pass hashFilePath as an argument
+	if jsonOutput(cmd) {
+		err := printJSON(struct {
+			Files       []*pd.ResponseUpload          `json:"files"`
+			Directories []*pd.ResponseUploadDirectory `json:"directories,omitempty"`
+		}{responses, directories})
 		if err != nil {
 			return err
 		}
+	} else {
+		printUploads(cmd, !auth.IsAuthAvailable(), responses, directories)
+		if len(directories) > 0 {
+			progress.printSummary(os.Stdout, directories)
+		}
+	}
 
+	failed := 0
+	for _, rsp := range directories {
+		failed += len(rsp.Failed())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be uploaded", failed)
+	}
+
+	return nil
+}
+
+func printUploads(cmd *cobra.Command, anonymous bool, responses []*pd.ResponseUpload, directories []*pd.ResponseUploadDirectory) {
+	for _, rsp := range responses {
 		msg := ""
 		if cmd.Flags().Changed("verbose") {
-			msg = fmt.Sprintf("Successful! Anonymous upload: %v | ID: %s | URL: %s", req.Anonymous, rsp.ID, rsp.GetFileURL())
+			msg = fmt.Sprintf("Successful! Anonymous upload: %v | ID: %s | URL: %s", anonymous, rsp.ID, rsp.GetFileURL())
 		} else {
 			msg = fmt.Sprintf("%s", rsp.GetFileURL())
 		}
@@ -53,5 +226,29 @@ func RunUpload(cmd *cobra.Command, args []string) error {
 		fmt.Println(msg)
 	}
 
-	return nil
+	for _, rsp := range directories {
+		for _, file := range rsp.Files {
+			detail := file.URL
+			if file.Error != "" {
+				detail = file.Error
+			} else if file.Duplicate != nil {
+				detail = file.Duplicate.URL
+			}
+			fmt.Printf("%s | %s | %s\n", file.Path, file.Status, detail)
+		}
+		if rsp.ListURL != "" {
+			fmt.Printf("%s | list | %s\n", rsp.Directory, rsp.ListURL)
+		}
+	}
+}
+
+// perDirectoryPath is the index or manifest file of the directory, with several directories the name of each is
+// added before the extension
+func perDirectoryPath(path, dir string, dirs int) string {
+	if path == "" || dirs == 1 {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + filepath.Base(filepath.Clean(dir)) + ext
 }
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"fmt"
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+func RunUserFiles(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	rsp, err := c.GetUserFiles(&pd.RequestGetUserFiles{Auth: auth})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &pd.APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp.Files)
+	}
+	for _, file := range rsp.Files {
+		fmt.Printf("%s | %s | %s\n", file.ID, file.Name, utils.FormatFileSize(file.Size))
+	}
+
+	return nil
+}
+
+func RunUserQuota(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	user, err := c.GetUser(&pd.RequestGetUser{Auth: auth})
+	if err != nil {
+		return err
+	}
+	if !user.Success {
+		return &pd.APIError{StatusCode: user.StatusCode, Value: user.Value, Message: user.Message}
+	}
+
+	// the files are checked as one batch, together they have to fit the storage space and the transfer cap
+	var size int64
+	for _, file := range args {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err := user.CheckUpload(info.Size()); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		size += info.Size()
+	}
+	if err := user.CheckUpload(size); err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(struct {
+			StorageUsed       int64 `json:"storage_used"`
+			StorageLimit      int64 `json:"storage_limit"`
+			RemainingStorage  int64 `json:"remaining_storage"`
+			TransferUsed      int64 `json:"transfer_used"`
+			TransferLimit     int64 `json:"transfer_limit"`
+			RemainingTransfer int64 `json:"remaining_transfer"`
+			FileSizeLimit     int64 `json:"file_size_limit"`
+		}{user.StorageSpaceUsed, user.StorageLimit(), user.RemainingStorage(), user.MonthlyTransferUsed,
+			user.TransferLimit(), user.RemainingTransfer(), user.Subscription.FileSizeLimit})
+	}
+
+	fmt.Printf("plan: %s\n", user.Subscription.Name)
+	fmt.Printf("storage: %s used of %s | %s left\n", utils.FormatFileSize(user.StorageSpaceUsed),
+		formatQuota(user.StorageLimit()), formatQuota(user.RemainingStorage()))
+	fmt.Printf("transfer: %s used of %s | %s left\n", utils.FormatFileSize(user.MonthlyTransferUsed),
+		formatQuota(user.TransferLimit()), formatQuota(user.RemainingTransfer()))
+	fmt.Printf("file size limit: %s\n", formatQuota(user.Subscription.FileSizeLimit))
+	if len(args) > 0 {
+		fmt.Printf("%d files of %s can be uploaded\n", len(args), utils.FormatFileSize(size))
+	}
+
+	return nil
+}
+
+// formatQuota the size of a limit, "unlimited" if the account doesn't have it
+func formatQuota(size int64) string {
+	if size <= 0 {
+		return "unlimited"
+	}
+
+	return utils.FormatFileSize(size)
+}
+
+func RunUserActivity(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, true)
+	if err != nil {
+		return err
+	}
+	page, _ := cmd.Flags().GetInt("page")
+
+	c := newClient()
+	rsp, err := c.GetUserActivity(&pd.RequestGetUserActivity{Page: page, Auth: auth})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp.Events)
+	}
+	for _, event := range rsp.Events {
+		fmt.Printf("%s | %s | %s | %s\n", event.Time.Format(time.RFC3339), event.Event, event.FileID, event.FileName)
+	}
+
+	return nil
+}
+
+func RunUserTransactions(cmd *cobra.Command, args []string) error {
+	auth, err := flagAuth(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	c := newClient()
+	rsp, err := c.GetUserTransactions(&pd.RequestGetUserTransactions{Auth: auth})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		return printJSON(rsp.Transactions)
+	}
+	for _, t := range rsp.Transactions {
+		fmt.Printf("%s | storage %s: %s | bandwidth %s: %s | balance %s\n", t.Time.Format("2006-01-02"),
+			utils.FormatFileSize(t.StorageUsed), formatEuro(t.StorageCharge),
+			utils.FormatFileSize(t.BandwidthUsed), formatEuro(t.BandwidthCharge), formatEuro(t.NewBalance))
+	}
+	total := rsp.Total()
+	fmt.Printf("total charged: %s\n", formatEuro(total.Charged()))
+
+	return nil
+}
+
+// formatEuro an amount in micro euro, e.g. "€1.25"
+func formatEuro(microEur int64) string {
+	return fmt.Sprintf("€%.2f", float64(microEur)/1e6)
+}
@@ -0,0 +1,91 @@
+package pd
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrFileChangedDuringUpload the local file was modified while it was uploaded and is kept
+const ErrFileChangedDuringUpload = "file changed during upload, keeping the local file"
+
+// finishLocalFile deletes the uploaded file or moves it into the archive directory.
+// The upload is only treated as verified if the file has not been modified since it was sent.
+func finishLocalFile(r *RequestUpload, before os.FileInfo, uploadRsp *ResponseUpload) error {
+	if !r.DeleteAfterUpload && r.ArchiveDir == "" {
+		return nil
+	}
+
+	if !uploadRsp.Success || uploadRsp.ID == "" {
+		return nil
+	}
+
+	after, err := os.Stat(r.PathToFile)
+	if err != nil {
+		return err
+	}
+	if after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) || after.Size() != uploadRsp.Size {
+		return errors.New(ErrFileChangedDuringUpload)
+	}
+
+	if r.ArchiveDir == "" {
+		log.Printf("Deleting uploaded file: %s", r.PathToFile)
+		return os.Remove(r.PathToFile)
+	}
+
+	if err := os.MkdirAll(r.ArchiveDir, 0755); err != nil {
+		return err
+	}
+
+	// an archived file of the same name may be the only local copy of an earlier upload, the time prefix keeps it
+	target := filepath.Join(r.ArchiveDir, filepath.Base(r.PathToFile))
+	if _, err := os.Lstat(target); err == nil {
+		target = filepath.Join(r.ArchiveDir, time.Now().Format("20060102T150405.000000000")+"-"+filepath.Base(r.PathToFile))
+	}
+	log.Printf("Moving uploaded file %s to %s", r.PathToFile, target)
+
+	return moveFile(r.PathToFile, target)
+}
+
+// moveFile moves the file to the target, an existing target fails instead of being replaced like by a rename
+func moveFile(src, dst string) error {
+	err := os.Link(src, dst)
+	if err != nil && !os.IsExist(err) {
+		// hard links do not work across file systems, copy instead
+		err = copyFile(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+// copyFile copies the file content and permissions to the target path, which must not exist
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,44 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+func init() {
+	RegisterEncoder(TransformAge, newAgeWriter)
+	RegisterDecoder(TransformAge, newAgeReader)
+}
+
+// newAgeWriter encrypts to the age recipients of the key, several recipients are separated by commas
+func newAgeWriter(dst io.Writer, key string) (io.WriteCloser, error) {
+	recipients, err := age.ParseRecipients(strings.NewReader(strings.ReplaceAll(key, ",", "\n")))
+	if err != nil {
+		return nil, err
+	}
+
+	return age.Encrypt(dst, recipients...)
+}
+
+// newAgeReader decrypts with the age identities of the key, e.g. the content of an identity file
+func newAgeReader(src io.Reader, key string) (io.Reader, error) {
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+	identities, err := age.ParseIdentities(strings.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	return r, err
+}
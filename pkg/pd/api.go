@@ -0,0 +1,109 @@
+package pd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// APISpec pins the pixeldrain API the client talks to, the base URL and the endpoint paths.
+// Use a custom spec to stay on a known API behaviour or to talk to a compatible server.
+type APISpec struct {
+	Version    string // name of the pinned API behaviour
+	URL        string // base URL of the API without trailing slash
+	File       string // file endpoints, e.g. "/file"
+	List       string // list endpoints, e.g. "/list"
+	User       string // user endpoints, e.g. "/user"
+	Filesystem string // filesystem endpoints, e.g. "/filesystem"
+}
+
+// DefaultAPISpec the pixeldrain API behaviour this package is built and tested against
+var DefaultAPISpec = APISpec{
+	Version:    "2024-01",
+	URL:        APIURL,
+	File:       "/file",
+	List:       "/list",
+	User:       "/user",
+	Filesystem: "/filesystem",
+}
+
+// RequestCapabilities the optional endpoints are probed with the given IDs, probes without an ID are skipped
+type RequestCapabilities struct {
+	FileID string // existing file, probes GET /file/{id},{id}/info
+	ListID string // existing list, probes the first byte of GET /list/{id}/zip
+	Auth   Auth
+}
+
+// Capabilities the optional endpoints the server supports
+type Capabilities struct {
+	Filesystem   bool `json:"filesystem"`
+	ZipDownload  bool `json:"zip_download"`
+	BulkFileInfo bool `json:"bulk_file_info"`
+}
+
+// Capabilities detects which optional endpoints the server supports, so higher level features can degrade gracefully.
+// An endpoint is unsupported if the server answers with 404, 405 or 501. A ListID of no existing list fails.
+func (pd *PixelDrainClient) Capabilities(ctx context.Context, r *RequestCapabilities) (*Capabilities, error) {
+	caps := &Capabilities{}
+
+	var err error
+	caps.Filesystem, err = pd.probe(ctx, pd.header(r.Auth), pd.API.URL+pd.API.Filesystem)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.ListID != "" {
+		// only the first byte is asked for, the server doesn't have to zip the whole list
+		header := pd.header(r.Auth)
+		header.Set("Range", "bytes=0-0")
+		caps.ZipDownload, err = pd.probe(ctx, header, fmt.Sprintf(pd.API.URL+pd.API.List+"/%s/zip", r.ListID))
+		if err != nil {
+			return nil, err
+		}
+
+		// a missing list is no missing endpoint
+		if !caps.ZipDownload {
+			list, err := pd.GetList(&RequestGetList{ID: r.ListID, Auth: r.Auth})
+			if err != nil {
+				return nil, err
+			}
+			if list.StatusCode != http.StatusOK {
+				return nil, &APIError{StatusCode: list.StatusCode, Value: list.Value, Message: list.Message}
+			}
+		}
+	}
+
+	if r.FileID != "" {
+		caps.BulkFileInfo, err = pd.probe(ctx, pd.header(r.Auth), fmt.Sprintf(pd.API.URL+pd.API.File+"/%s,%s/info", r.FileID, r.FileID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return caps, nil
+}
+
+// probe sends a GET request and reports if the endpoint exists, the body is not read
+func (pd *PixelDrainClient) probe(ctx context.Context, header http.Header, url string) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rsp, err := pd.request(ctx, http.MethodGet, url, header, nil)
+	if err != nil {
+		return false, err
+	}
+	_ = rsp.Body.Close()
+
+	if pd.Debug {
+		log.Printf("Probe %s: %d", url, rsp.StatusCode)
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
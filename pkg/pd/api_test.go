@@ -0,0 +1,92 @@
+package pd_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_Capabilities probes a server without filesystem support
+func TestPD_Capabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/filesystem"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/zip"):
+			// the probe doesn't make the server zip the whole list
+			assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+			w.WriteHeader(http.StatusPartialContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	caps, err := c.Capabilities(context.Background(), &pd.RequestCapabilities{
+		FileID: "K1dA8U5W",
+		ListID: "123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, caps.Filesystem)
+	assert.True(t, caps.ZipDownload)
+	assert.False(t, caps.BulkFileInfo)
+}
+
+// TestPD_Capabilities_MissingList fails for a list which doesn't exist instead of reporting no zip support
+func TestPD_Capabilities_MissingList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/filesystem":
+			w.WriteHeader(http.StatusOK)
+		case "/list/old":
+			_, _ = w.Write([]byte(`{"success": true, "id": "old", "files": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success": false, "value": "not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	caps, err := c.Capabilities(context.Background(), &pd.RequestCapabilities{ListID: "old"})
+	assert.NoError(t, err)
+	assert.False(t, caps.ZipDownload)
+
+	_, err = c.Capabilities(context.Background(), &pd.RequestCapabilities{ListID: "missing"})
+	var apiErr *pd.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+// TestPD_APISpec checks that the pinned API URL is used for default endpoints
+func TestPD_APISpec(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "K1dA8U5W", rsp.ID)
+}
@@ -0,0 +1,171 @@
+package pd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// ArchiveFormat of UploadDirectoryAsArchive
+type ArchiveFormat string
+
+const (
+	ArchiveTar ArchiveFormat = "tar"
+	ArchiveZip ArchiveFormat = "zip" // entries can be extracted on pixeldrain with ExtractFromRemoteZip
+)
+
+// UploadDirectoryAsArchive packs the directory into one tar or zip file while it is uploaded, nothing is written to
+// disk or buffered in memory. A directory with thousands of small files is sent with one request instead of one per
+// file. Files which are not regular files or directories, e.g. symlinks and pipes, are skipped.
+func (pd *PixelDrainClient) UploadDirectoryAsArchive(r *RequestUploadArchive) (*ResponseUpload, error) {
+	if r.Directory == "" {
+		return nil, errors.New(ErrMissingDirectory)
+	}
+	info, err := os.Stat(r.Directory)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", r.Directory)
+	}
+
+	if r.Format == "" {
+		r.Format = ArchiveTar
+	}
+	var write func(w io.Writer, dir string) error
+	switch r.Format {
+	case ArchiveTar:
+		write = writeTar
+	case ArchiveZip:
+		write = writeZip
+	default:
+		return nil, fmt.Errorf("unknown archive format %q", r.Format)
+	}
+
+	fileName := r.FileName
+	if fileName == "" {
+		abs, err := filepath.Abs(r.Directory)
+		if err != nil {
+			return nil, err
+		}
+		fileName = filepath.Base(abs) + "." + string(r.Format)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(write(pw, r.Directory))
+	}()
+	defer pr.Close()
+
+	log.Printf("Uploading directory %s as %s", r.Directory, fileName)
+	return pd.UploadPUT(&RequestUpload{
+		File:      pr,
+		FileName:  fileName,
+		Anonymous: r.Anonymous,
+		Auth:      r.Auth,
+		URL:       r.URL,
+		Progress:  r.Progress,
+	})
+}
+
+// walkArchive calls fn with the slash separated path relative to the directory of every directory and regular file
+func walkArchive(dir string, fn func(path, name string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			log.Printf("Not archiving %s, it is not a regular file", path)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		return fn(path, filepath.ToSlash(rel), info)
+	})
+}
+
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := walkArchive(dir, func(path, name string, info fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		return archiveFile(tw, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func writeZip(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	err := walkArchive(dir, func(path, name string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		return archiveFile(entry, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// archiveFile copies the content of the file into the archive entry
+func archiveFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,72 @@
+package pd_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_UploadDirectoryAsArchive sends the files of the directory as one tar or zip file
+func TestPD_UploadDirectoryAsArchive(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "photos")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "2023", "empty"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cat.jpg"), []byte("cat"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "2023", "dog.jpg"), []byte("dog"), 0o644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	want := map[string]string{"2023/": "", "2023/dog.jpg": "dog", "2023/empty/": "", "cat.jpg": "cat"}
+
+	rsp, err := c.UploadDirectoryAsArchive(&pd.RequestUploadArchive{Directory: dir, Anonymous: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "encrypted-id", rsp.ID)
+
+	entries := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(stored()))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(tr)
+		entries[header.Name] = string(content)
+	}
+	assert.Equal(t, want, entries)
+
+	_, err = c.UploadDirectoryAsArchive(&pd.RequestUploadArchive{Directory: dir, Format: pd.ArchiveZip, FileName: "photos.zip"})
+	assert.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(stored()), int64(len(stored())))
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		assert.Equal(t, want[f.Name], string(content))
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"2023/", "2023/dog.jpg", "2023/empty/", "cat.jpg"}, names)
+
+	_, err = c.UploadDirectoryAsArchive(&pd.RequestUploadArchive{Directory: filepath.Join(dir, "cat.jpg")})
+	assert.Error(t, err)
+	_, err = c.UploadDirectoryAsArchive(&pd.RequestUploadArchive{})
+	assert.EqualError(t, err, pd.ErrMissingDirectory)
+}
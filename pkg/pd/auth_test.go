@@ -0,0 +1,67 @@
+package pd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// authServer answers every request with the API key of its basic auth as ID, "anonymous" without one
+func authServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		id := "anonymous"
+		if _, key, ok := r.BasicAuth(); ok {
+			id = key
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"success": true, "id": %q}`, id)
+	}))
+}
+
+func TestPD_Auth_PerRequest(t *testing.T) {
+	server := authServer()
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	hashes := filepath.Join(t.TempDir(), "hashes.csv")
+	upload := func(auth pd.Auth, anonymous bool, content string) string {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			Source:     bytes.NewReader([]byte(content)),
+			SourceSize: int64(len(content)),
+			FileName:   "auth.txt",
+			Anonymous:  anonymous,
+			Auth:       auth,
+			URL:        server.URL + "/file",
+		}, hashes)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		return rsp.ID
+	}
+
+	assert.Equal(t, "key-1", upload(pd.Auth{APIKey: "key-1"}, false, "first"))
+	// the credentials of the earlier upload must not be sent again
+	assert.Equal(t, "anonymous", upload(pd.Auth{}, false, "second"))
+	assert.Equal(t, "anonymous", upload(pd.Auth{APIKey: "key-1"}, true, "third"))
+	assert.Empty(t, c.Client.Header["Authorization"])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			assert.Equal(t, key, upload(pd.Auth{APIKey: key}, false, key))
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,128 @@
+package pd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// BandwidthPolicy decides what happens with a transfer which would exceed the budget
+type BandwidthPolicy int
+
+const (
+	BandwidthRefuse BandwidthPolicy = iota // return a *BudgetExceededError
+	BandwidthDefer                         // wait until the day or month of the exceeded limit is over
+)
+
+// BandwidthBudget counts the transferred bytes in the ledger and limits them per day and month, 0 means no limit.
+// It protects metered connections and the bandwidth caps of the account.
+type BandwidthBudget struct {
+	Ledger          *utils.BandwidthLedger
+	DailyUpload     int64
+	DailyDownload   int64
+	MonthlyUpload   int64
+	MonthlyDownload int64
+	Policy          BandwidthPolicy
+}
+
+// BudgetExceededError is returned if a transfer would exceed the bandwidth budget
+type BudgetExceededError struct {
+	Direction string    // utils.Upload or utils.Download
+	Period    string    // "day" or "month"
+	Used      int64     // bytes transferred in the period
+	Limit     int64     // budget of the period
+	Size      int64     // size of the refused transfer
+	ResetAt   time.Time // start of the next period
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s of %s exceeds the %s budget: %s of %s used, resets at %s", e.Direction,
+		utils.FormatFileSize(e.Size), e.Period, utils.FormatFileSize(e.Used), utils.FormatFileSize(e.Limit), e.ResetAt.Format(time.RFC3339))
+}
+
+// exceeded checks the transfer of size bytes against the limits of the direction
+func (b *BandwidthBudget) exceeded(direction string, size int64, now time.Time) (*BudgetExceededError, error) {
+	usage, err := b.Ledger.Usage(now)
+	if err != nil {
+		return nil, err
+	}
+
+	dayLimit, monthLimit := b.DailyUpload, b.MonthlyUpload
+	dayUsed, monthUsed := usage.DayUpload, usage.MonthUpload
+	if direction == utils.Download {
+		dayLimit, monthLimit = b.DailyDownload, b.MonthlyDownload
+		dayUsed, monthUsed = usage.DayDownload, usage.MonthDownload
+	}
+
+	// the month is checked first, its reset is later than the one of the day
+	year, month, day := now.Date()
+	if monthLimit > 0 && monthUsed+size > monthLimit {
+		return &BudgetExceededError{direction, "month", monthUsed, monthLimit, size, time.Date(year, month+1, 1, 0, 0, 0, 0, now.Location())}, nil
+	}
+	if dayLimit > 0 && dayUsed+size > dayLimit {
+		return &BudgetExceededError{direction, "day", dayUsed, dayLimit, size, time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())}, nil
+	}
+
+	return nil, nil
+}
+
+// reserveBandwidth refuses or defers a transfer of size bytes which would exceed the budget
+func (pd *PixelDrainClient) reserveBandwidth(ctx context.Context, direction string, size int64) error {
+	if pd.Bandwidth == nil || pd.Bandwidth.Ledger == nil {
+		return nil
+	}
+	if size < 0 {
+		// unknown size, only refused if the budget is already used up
+		size = 0
+	}
+
+	for {
+		exceeded, err := pd.Bandwidth.exceeded(direction, size, time.Now())
+		if err != nil || exceeded == nil {
+			return err
+		}
+		if pd.Bandwidth.Policy != BandwidthDefer {
+			return exceeded
+		}
+
+		log.Printf("Deferring %s until %s: %v", direction, exceeded.ResetAt.Format(time.RFC3339), exceeded)
+		timer := time.NewTimer(time.Until(exceeded.ResetAt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// recordBandwidth counts the transferred bytes in the Meter and the ledger, a failed write of the ledger is only logged
+func (pd *PixelDrainClient) recordBandwidth(direction string, n int64) {
+	if pd.Meter != nil && n > 0 {
+		metric := MetricDownloadBytes
+		if direction == utils.Upload {
+			metric = MetricUploadBytes
+		}
+		pd.Meter.Add(metric, n)
+	}
+
+	if pd.Bandwidth == nil || pd.Bandwidth.Ledger == nil || n <= 0 {
+		return
+	}
+
+	if err := pd.Bandwidth.Ledger.Add(direction, n, time.Now()); err != nil {
+		log.Printf("Error recording %s bandwidth: %v", direction, err)
+	}
+}
+
+// BandwidthUsage returns the bytes transferred today and this month, zero if no bandwidth budget is configured
+func (pd *PixelDrainClient) BandwidthUsage() (utils.BandwidthUsage, error) {
+	if pd.Bandwidth == nil || pd.Bandwidth.Ledger == nil {
+		return utils.BandwidthUsage{}, nil
+	}
+
+	return pd.Bandwidth.Ledger.Usage(time.Now())
+}
@@ -0,0 +1,63 @@
+package pd_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Bandwidth_Budget(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("budget "), 100)
+	c := pd.New(&pd.ClientOptions{
+		Bandwidth: &pd.BandwidthBudget{
+			Ledger:      utils.NewBandwidthLedger(filepath.Join(t.TempDir(), "bandwidth.json")),
+			DailyUpload: int64(len(content)) + 10,
+		},
+	}, nil)
+
+	hashes := filepath.Join(t.TempDir(), "hashes.csv")
+	upload := func() (*pd.ResponseUpload, error) {
+		return c.UploadPOST(&pd.RequestUpload{
+			Source:     bytes.NewReader(content),
+			SourceSize: int64(len(content)),
+			FileName:   "budget.txt",
+			Anonymous:  true,
+			URL:        server.URL + "/file",
+		}, hashes)
+	}
+
+	rsp, err := upload()
+	assert.NoError(t, err)
+	assert.Equal(t, "mock-file-id", rsp.ID)
+
+	usage, err := c.BandwidthUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), usage.DayUpload)
+	assert.Equal(t, int64(len(content)), usage.MonthUpload)
+
+	// the second upload would exceed the daily budget
+	_, err = upload()
+	var exceeded *pd.BudgetExceededError
+	if assert.True(t, errors.As(err, &exceeded)) {
+		assert.Equal(t, "day", exceeded.Period)
+		assert.Equal(t, utils.Upload, exceeded.Direction)
+	}
+
+	// the download is counted separately
+	_, err = c.Download(&pd.RequestDownload{
+		PathToSave: filepath.Join(t.TempDir(), "cat.jpg"),
+		ID:         "K1dA8U5W",
+		URL:        server.URL + "/file/K1dA8U5W",
+	})
+	assert.NoError(t, err)
+	usage, _ = c.BandwidthUsage()
+	assert.Equal(t, int64(37621), usage.DayDownload)
+}
@@ -0,0 +1,54 @@
+package pd
+
+import "time"
+
+// Checkpoint is the position of a chunked upload after the server confirmed a chunk. Embedders persist it,
+// e.g. in their own database, and pass it as RequestUpload.Resume to continue the upload in another process.
+type Checkpoint struct {
+	Path      string    `json:"path"`
+	URL       string    `json:"url"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	ChunkSize int64     `json:"chunk_size"`
+	Offset    int64     `json:"offset"` // bytes the server confirmed, the upload continues here
+	Chunk     int       `json:"chunk"`  // index of the confirmed chunk, counted from 0
+	Done      bool      `json:"done"`   // the last chunk was confirmed, the checkpoint can be dropped
+}
+
+// CheckpointFunc receives the checkpoints of chunked uploads. An error stops the upload, the same as a
+// failed save of the ChunkStatePath, so no chunk is sent which the embedder could not record.
+type CheckpointFunc func(Checkpoint) error
+
+// checkpoint passes the state after the chunk to the hook of the client
+func (pd *PixelDrainClient) checkpoint(state *chunkState, chunk int) error {
+	if pd.Checkpoint == nil {
+		return nil
+	}
+
+	return pd.Checkpoint(Checkpoint{
+		Path:      state.Path,
+		URL:       state.URL,
+		Size:      state.Size,
+		ModTime:   state.ModTime,
+		ChunkSize: state.ChunkSize,
+		Offset:    state.Offset,
+		Chunk:     chunk,
+		Done:      state.Offset >= state.Size,
+	})
+}
+
+// resumeState returns the chunk state of the checkpoint, nil if there is none
+func (c *Checkpoint) resumeState() *chunkState {
+	if c == nil {
+		return nil
+	}
+
+	return &chunkState{
+		Path:      c.Path,
+		URL:       c.URL,
+		Size:      c.Size,
+		ModTime:   c.ModTime,
+		ChunkSize: c.ChunkSize,
+		Offset:    c.Offset,
+	}
+}
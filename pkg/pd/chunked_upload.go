@@ -0,0 +1,242 @@
+package pd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// chunkState is saved after every chunk, so an interrupted upload continues after the last completed chunk
+type chunkState struct {
+	Path      string    `json:"path"`
+	URL       string    `json:"url"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	ChunkSize int64     `json:"chunk_size"`
+	Offset    int64     `json:"offset"` // bytes the server confirmed
+}
+
+// resumes checks if the saved state belongs to the same file and chunk size
+func (s *chunkState) resumes(saved *chunkState) bool {
+	return saved != nil && saved.Path == s.Path && saved.URL == s.URL && saved.Size == s.Size &&
+		saved.ModTime.Equal(s.ModTime) && saved.ChunkSize == s.ChunkSize
+}
+
+// uploadChunked sends the file or the Source in ChunkSize parts with a Content-Range header per PUT request.
+// A chunk which fails with a network error or a 5xx status is retried, the final chunk answers with the file.
+// pixeldrain doesn't assemble chunks, it is only used with ClientOptions.ChunkedUploads.
+func (pd *PixelDrainClient) uploadChunked(r *RequestUpload) (*ResponseUpload, error) {
+	ctx, rec := withTimings(context.Background())
+	state := chunkState{
+		Path:      r.PathToFile,
+		URL:       r.URL,
+		Size:      r.SourceSize,
+		ChunkSize: r.ChunkSize,
+	}
+
+	var src io.ReaderAt = r.Source
+	if r.Source == nil {
+		info, err := os.Stat(r.PathToFile)
+		if err != nil {
+			return nil, err
+		}
+		state.Size, state.ModTime = info.Size(), info.ModTime()
+
+		pd.openFiles.acquire()
+		defer pd.openFiles.release()
+		file, err := os.Open(r.PathToFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		src = file
+	}
+
+	// a checkpoint of the caller is preferred over the state saved at ChunkStatePath
+	saved := r.Resume.resumeState()
+	if saved == nil {
+		var err error
+		if saved, err = loadChunkState(pd.stateStore(), r.ChunkStatePath); err != nil {
+			return nil, err
+		}
+	}
+	if state.resumes(saved) {
+		log.Printf("Resuming upload of %s at byte %d", r.GetFileName(), saved.Offset)
+		state.Offset = saved.Offset
+	}
+
+	policy := pd.RetryPolicy
+	if r.ChunkRetries > 0 {
+		policy.MaxAttempts = r.ChunkRetries
+	}
+
+	queueStart := time.Now()
+	if err := pd.reserveBandwidth(ctx, utils.Upload, state.Size-state.Offset); err != nil {
+		return nil, err
+	}
+	rec.add(queueWait, queueStart)
+
+	// progress is reported per finished chunk, a resumed upload starts at the saved offset
+	progress := utils.NewProgressCounter(state.Size, r.Progress)
+	progress.Add(state.Offset)
+
+	var data []byte
+	var statusCode int
+	for state.Offset < state.Size {
+		end := state.Offset + state.ChunkSize
+		if end > state.Size {
+			end = state.Size
+		}
+
+		var err error
+		data, statusCode, err = pd.putChunk(ctx, r, src, state.Offset, end, state.Size, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		pd.recordBandwidth(utils.Upload, end-state.Offset)
+		progress.Add(end - state.Offset)
+		state.Offset = end
+		if err := saveChunkState(pd.stateStore(), r.ChunkStatePath, &state); err != nil {
+			return nil, err
+		}
+		if err := pd.checkpoint(&state, int((end-1)/state.ChunkSize)); err != nil {
+			return nil, err
+		}
+	}
+
+	progress.Finish()
+
+	uploadRsp := &ResponseUpload{}
+	if len(data) > 0 {
+		if err := decodeResponse(data, uploadRsp); err != nil {
+			return nil, err
+		}
+	}
+	uploadRsp.StatusCode = statusCode
+	uploadRsp.Success = statusCode == http.StatusCreated || statusCode == http.StatusOK
+
+	// hash and sniff the whole source once more, the chunks may have been sent by several runs
+	hashStart := time.Now()
+	body := newHashingReader(io.NewSectionReader(src, 0, state.Size))
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return nil, err
+	}
+	rec.add(hashing, hashStart)
+	uploadRsp.Hash = body.Sum()
+	uploadRsp.Size = body.size
+	uploadRsp.MimeType = body.MimeType()
+
+	if uploadRsp.ID != "" {
+		if err := pd.checkAssembled(r, uploadRsp.ID, state.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Verify {
+		if err := pd.verifyTransfer(utils.Upload, uploadRsp.ID, r.Auth, r.PathToFile, uploadRsp.Hash, r.PathToFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.ChunkStatePath != "" {
+		_ = pd.stateStore().Delete(r.ChunkStatePath)
+	}
+	uploadRsp.Timings = pd.finishTimings(rec, utils.Upload, uploadRsp.ID)
+	pd.prefetchThumbnails(uploadRsp, r.Auth)
+	pd.notifyUpload(r.GetFileName(), uploadRsp)
+
+	return uploadRsp, nil
+}
+
+// checkAssembled compares the size of the file the server stores with the size of the source. A server which
+// keeps every chunk as a file of its own, like pixeldrain, answers with the size of the last chunk.
+func (pd *PixelDrainClient) checkAssembled(r *RequestUpload, id string, size int64) error {
+	// the info endpoint of the server which received the chunks
+	infoURL := r.URL[:strings.LastIndex(r.URL, "/")+1] + url.PathEscape(id) + "/info"
+	info, err := pd.GetFileInfo(&RequestFileInfo{ID: id, Auth: r.Auth, URL: infoURL})
+	if err != nil {
+		return err
+	}
+	if !info.Success {
+		return &APIError{StatusCode: info.StatusCode, Value: info.Value, Message: info.Message}
+	}
+	if info.Size != size {
+		return fmt.Errorf("the server stored %d of %d bytes of %s, it does not assemble chunked uploads", info.Size, size, r.GetFileName())
+	}
+
+	return nil
+}
+
+// putChunk sends the bytes [start, end) of the file, failed attempts are retried with the policy
+func (pd *PixelDrainClient) putChunk(ctx context.Context, r *RequestUpload, src io.ReaderAt, start, end, size int64, policy RetryPolicy) ([]byte, int, error) {
+	header := pd.header(requestAuth(r.Auth, r.Anonymous))
+	header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, size))
+
+	rsp, err := pd.send(ctx, policy, func() (*http.Request, error) {
+		httpReq, err := newRequest(ctx, http.MethodPut, r.URL, header, io.NewSectionReader(src, start, end-start))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.ContentLength = end - start
+
+		return httpReq, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	statusCode := rsp.StatusCode
+	if statusCode >= 400 {
+		return nil, statusCode, newAPIError(statusCode, data)
+	}
+
+	return data, statusCode, nil
+}
+
+func loadChunkState(store utils.StateStore, key string) (*chunkState, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	data, found, err := store.Get(key)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	state := &chunkState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.New("invalid chunk state " + key + ": " + err.Error())
+	}
+
+	return state, nil
+}
+
+// saveChunkState stores the state, the file store writes a temporary file and renames it, so a crash never leaves a half written state
+func saveChunkState(store utils.StateStore, key string, state *chunkState) error {
+	if key == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return store.Put(key, data)
+}
@@ -0,0 +1,211 @@
+package pd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkServer assembles chunked PUT uploads and fails the chunks for which fail returns true
+func chunkServer(received *bytes.Buffer, ranges *[]string, fail func(contentRange string) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/info") {
+			_, _ = fmt.Fprintf(w, `{"id": "chunked-id", "size": %d}`, received.Len())
+			return
+		}
+
+		contentRange := r.Header.Get("Content-Range")
+		if fail(contentRange) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		*ranges = append(*ranges, contentRange)
+		_, _ = io.Copy(received, r.Body)
+
+		var start, end, size int64
+		_, _ = fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &size)
+		if end+1 < size {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "chunked-id"}`))
+	}))
+}
+
+func TestPD_UploadPUT_Chunked(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.bin")
+	content := bytes.Repeat([]byte("0123456789"), 5)
+	_ = os.WriteFile(path, content, 0644)
+
+	var received bytes.Buffer
+	var ranges []string
+	failed := false
+	server := chunkServer(&received, &ranges, func(contentRange string) bool {
+		// the second chunk fails once
+		if contentRange == "bytes 20-39/50" && !failed {
+			failed = true
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+
+	c := pd.New(&pd.ClientOptions{ChunkedUploads: true}, nil)
+	rsp, err := c.UploadPUT(&pd.RequestUpload{
+		PathToFile: path,
+		FileName:   "large.bin",
+		URL:        server.URL + "/file/large.bin",
+		ChunkSize:  20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "chunked-id", rsp.ID)
+	assert.True(t, rsp.Success)
+	assert.Equal(t, int64(50), rsp.Size)
+	assert.Equal(t, []string{"bytes 0-19/50", "bytes 20-39/50", "bytes 40-49/50"}, ranges)
+	assert.Equal(t, content, received.Bytes())
+}
+
+func TestPD_UploadPUT_ChunkedResume(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.bin")
+	statePath := filepath.Join(dir, "large.bin.state")
+	_ = os.WriteFile(path, bytes.Repeat([]byte("abcde"), 10), 0644)
+
+	var received bytes.Buffer
+	var ranges []string
+	down := true
+	server := chunkServer(&received, &ranges, func(contentRange string) bool {
+		return down && contentRange != "bytes 0-19/50"
+	})
+	defer server.Close()
+
+	upload := func() (*pd.ResponseUpload, error) {
+		return pd.New(&pd.ClientOptions{ChunkedUploads: true}, nil).UploadPUT(&pd.RequestUpload{
+			PathToFile:     path,
+			FileName:       "large.bin",
+			URL:            server.URL + "/file/large.bin",
+			ChunkSize:      20,
+			ChunkStatePath: statePath,
+			ChunkRetries:   1,
+		})
+	}
+
+	_, err := upload()
+	assert.Error(t, err)
+	assert.FileExists(t, statePath)
+
+	down = false
+	rsp, err := upload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "chunked-id", rsp.ID)
+	assert.Equal(t, []string{"bytes 0-19/50", "bytes 20-39/50", "bytes 40-49/50"}, ranges)
+	assert.NoFileExists(t, statePath)
+}
+
+func TestPD_UploadPUT_ChunkedCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.bin")
+	_ = os.WriteFile(path, bytes.Repeat([]byte("klmno"), 10), 0644)
+
+	var received bytes.Buffer
+	var ranges []string
+	down := true
+	server := chunkServer(&received, &ranges, func(contentRange string) bool {
+		return down && contentRange != "bytes 0-19/50"
+	})
+	defer server.Close()
+
+	// the checkpoints are persisted by the caller instead of a ChunkStatePath
+	var checkpoints []pd.Checkpoint
+	c := pd.New(&pd.ClientOptions{ChunkedUploads: true, Checkpoint: func(cp pd.Checkpoint) error {
+		checkpoints = append(checkpoints, cp)
+		return nil
+	}}, nil)
+	upload := func(resume *pd.Checkpoint) (*pd.ResponseUpload, error) {
+		return c.UploadPUT(&pd.RequestUpload{
+			PathToFile:   path,
+			FileName:     "large.bin",
+			URL:          server.URL + "/file/large.bin",
+			ChunkSize:    20,
+			ChunkRetries: 1,
+			Resume:       resume,
+		})
+	}
+
+	_, err := upload(nil)
+	assert.Error(t, err)
+	if !assert.Len(t, checkpoints, 1) {
+		return
+	}
+	assert.Equal(t, int64(20), checkpoints[0].Offset)
+	assert.Equal(t, 0, checkpoints[0].Chunk)
+	assert.False(t, checkpoints[0].Done)
+
+	down = false
+	rsp, err := upload(&checkpoints[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "chunked-id", rsp.ID)
+	assert.Equal(t, []string{"bytes 0-19/50", "bytes 20-39/50", "bytes 40-49/50"}, ranges)
+	if assert.Len(t, checkpoints, 3) {
+		assert.Equal(t, int64(50), checkpoints[2].Offset)
+		assert.Equal(t, 2, checkpoints[2].Chunk)
+		assert.True(t, checkpoints[2].Done)
+	}
+}
+
+// TestPD_UploadPUT_ChunkedNotAssembled fails the upload if the server kept the last chunk only, like pixeldrain
+func TestPD_UploadPUT_ChunkedNotAssembled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.bin")
+	_ = os.WriteFile(path, bytes.Repeat([]byte("pqrst"), 10), 0644)
+
+	var last int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprintf(w, `{"id": "last-chunk", "size": %d}`, last)
+			return
+		}
+		last, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "last-chunk"}`))
+	}))
+	defer server.Close()
+
+	req := &pd.RequestUpload{
+		PathToFile: path,
+		FileName:   "large.bin",
+		URL:        server.URL + "/file/large.bin",
+		ChunkSize:  20,
+	}
+	_, err := pd.New(&pd.ClientOptions{ChunkedUploads: true}, nil).UploadPUT(req)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "stored 10 of 50 bytes")
+	}
+
+	// without the option a ChunkSize is refused before anything is sent
+	last = 0
+	_, err = pd.New(nil, nil).UploadPUT(req)
+	assert.EqualError(t, err, pd.ErrChunkedUploads)
+	assert.Equal(t, int64(0), last)
+}
@@ -0,0 +1,25 @@
+package pd
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// Compression of an upload, the file is decompressed again by Download
+type Compression string
+
+const (
+	CompressGzip Compression = "gzip"
+	// CompressZstd is not built in, the Go zstd packages need a newer Go than go-pd. An upload with it fails with
+	// ErrUnknownTransform unless the caller registers an encoder and decoder, e.g. of klauspost/compress/zstd.
+	CompressZstd Compression = "zstd"
+)
+
+func init() {
+	RegisterEncoder(string(CompressGzip), func(dst io.Writer, _ string) (io.WriteCloser, error) {
+		return gzip.NewWriter(dst), nil
+	})
+	RegisterDecoder(string(CompressGzip), func(src io.Reader, _ string) (io.Reader, error) {
+		return gzip.NewReader(src)
+	})
+}
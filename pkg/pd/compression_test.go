@@ -0,0 +1,64 @@
+package pd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_Upload_Compress sends the compressed file, Download decompresses it and the log keeps the original size
+func TestPD_Upload_Compress(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "uploads.csv")
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, UploadLogPath: logPath, DisableDedup: true}, nil)
+
+	plain := bytes.Repeat([]byte("GET /cat.jpg 200\n"), 10000)
+	path := filepath.Join(dir, "access.log")
+	assert.NoError(t, os.WriteFile(path, plain, 0o644))
+
+	for _, encrypt := range []string{"", "hunter2"} {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, Compress: pd.CompressGzip, EncryptWith: encrypt}, "")
+		assert.NoError(t, err)
+		assert.Less(t, len(stored()), len(plain)/10)
+
+		var out bytes.Buffer
+		_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: &out, Key: encrypt})
+		assert.NoError(t, err)
+		assert.Equal(t, plain, out.Bytes())
+
+		// Raw keeps the file as it is stored
+		out.Reset()
+		_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: &out, Raw: true})
+		assert.NoError(t, err)
+		assert.Equal(t, stored(), out.Bytes())
+	}
+
+	var infos []utils.UploadInfo
+	assert.NoError(t, utils.EachUploadInfo(logPath, func(info utils.UploadInfo) error {
+		infos = append(infos, info)
+		return nil
+	}))
+	if assert.Len(t, infos, 2) {
+		assert.Equal(t, "access.log", infos[0].FileName)
+		assert.Equal(t, int64(len(plain)), infos[0].FileSize)
+		assert.Equal(t, "gzip", infos[0].Transforms)
+		assert.Equal(t, "gzip+aes-gcm", infos[1].Transforms)
+		assert.Equal(t, int64(len(stored())), infos[1].StoredSize)
+	}
+
+	// zstd is not built in
+	_, err := c.UploadPUT(&pd.RequestUpload{PathToFile: path, FileName: "access.log", Compress: pd.CompressZstd})
+	assert.ErrorIs(t, err, pd.ErrUnknownTransform)
+	assert.EqualError(t, err, "unknown go-pd transform: zstd compression is not built in, only gzip is")
+}
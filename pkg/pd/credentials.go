@@ -0,0 +1,168 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvAPIKey      = "PD_API_KEY" // environment variable of the API key
+	KeyringService = "go-pd"      // service name of the API key in the OS keyring
+	KeyringUser    = "api-key"    // account name of the API key in the OS keyring
+)
+
+// ErrKeyringUnavailable is returned by SystemKeyring on a system without a keyring, e.g. a headless Linux server
+var ErrKeyringUnavailable = errors.New("no keyring available on this system")
+
+// AuthSource returns an API key, an empty key means the source has none
+type AuthSource func() (string, error)
+
+// NewAuth returns the Auth with the key of the first source which has one, an Auth without a key if none has.
+// An error of a source ends the chain, so a broken keyring isn't silently skipped.
+func NewAuth(sources ...AuthSource) (Auth, error) {
+	for _, source := range sources {
+		key, err := source()
+		if err != nil {
+			return Auth{}, err
+		}
+		if key != "" {
+			return Auth{APIKey: key}, nil
+		}
+	}
+
+	return Auth{}, nil
+}
+
+// DefaultAuth loads the API key from the explicit key, the PD_API_KEY environment variable, the OS keyring and the
+// config file at DefaultConfigPath, in this order
+func DefaultAuth(explicit string) (Auth, error) {
+	return NewAuth(FromKey(explicit), FromEnv(EnvAPIKey), FromKeyring(SystemKeyring{}), FromConfigFile(DefaultConfigPath()))
+}
+
+// SetAPIKey stores the API key in the OS keyring, where DefaultAuth finds it, instead of a .env file
+func SetAPIKey(key string) error {
+	return SystemKeyring{}.Set(KeyringService, KeyringUser, key)
+}
+
+// FromKey returns the key, e.g. of a flag
+func FromKey(key string) AuthSource {
+	return func() (string, error) {
+		return key, nil
+	}
+}
+
+// FromEnv returns the key of the environment variable
+func FromEnv(name string) AuthSource {
+	return func() (string, error) {
+		return os.Getenv(name), nil
+	}
+}
+
+// FromKeyring returns the key stored by SetAPIKey, a system without keyring has no key
+func FromKeyring(keyring Keyring) AuthSource {
+	return func() (string, error) {
+		key, err := keyring.Get(KeyringService, KeyringUser)
+		if errors.Is(err, ErrKeyringUnavailable) {
+			return "", nil
+		}
+
+		return key, err
+	}
+}
+
+// FromConfigFile returns the api_key of the YAML config file, a missing file has no key
+func FromConfigFile(path string) AuthSource {
+	return func() (string, error) {
+		if path == "" {
+			return "", nil
+		}
+
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var config struct {
+			APIKey string `yaml:"api_key"`
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return "", fmt.Errorf("config file %s: %w", path, err)
+		}
+
+		return config.APIKey, nil
+	}
+}
+
+// DefaultConfigPath returns go-pd/config.yaml in the user config directory of os.UserConfigDir, that is
+// ~/.config/go-pd/config.yaml ($XDG_CONFIG_HOME if set) on Linux, ~/Library/Application Support/go-pd/config.yaml
+// on macOS and %AppData%\go-pd\config.yaml on Windows
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "go-pd", "config.yaml")
+}
+
+// Keyring keeps secrets in a credential store, a missing secret is returned as empty string
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+}
+
+// SystemKeyring is the keyring of the OS through go-keyring, the macOS keychain, the Windows credential manager and
+// the Secret Service of Linux desktops. A system without one, e.g. a Linux server without a D-Bus session or without
+// a Secret Service, fails with ErrKeyringUnavailable.
+type SystemKeyring struct{}
+
+// Get returns the secret of the service and user.
+func (SystemKeyring) Get(service, user string) (string, error) {
+	secret, err := keyring.Get(service, user)
+	switch {
+	case errors.Is(err, keyring.ErrNotFound):
+		return "", nil
+	case keyringMissing(err):
+		return "", fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+
+	return secret, err
+}
+
+// Set stores the secret of the service and user, an existing secret is replaced.
+func (SystemKeyring) Set(service, user, secret string) error {
+	err := keyring.Set(service, user, secret)
+	if keyringMissing(err) {
+		return fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+	if err != nil {
+		return fmt.Errorf("storing the secret in the keyring: %w", err)
+	}
+
+	return nil
+}
+
+// keyringMissing reports if the error of go-keyring means there is no keyring: an unsupported OS, no D-Bus session
+// to connect to or no Secret Service on it
+func keyringMissing(err error) bool {
+	var opErr *net.OpError
+	var dbusErr dbus.Error
+	switch {
+	case errors.Is(err, keyring.ErrUnsupportedPlatform), errors.As(err, &opErr):
+		return true
+	case errors.As(err, &dbusErr):
+		return dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown"
+	}
+
+	return false
+}
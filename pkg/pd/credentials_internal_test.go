@@ -0,0 +1,40 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+// TestKeyringMissing tells a system without keyring apart from a keyring which fails
+func TestKeyringMissing(t *testing.T) {
+	assert.True(t, keyringMissing(keyring.ErrUnsupportedPlatform))
+	assert.True(t, keyringMissing(&net.OpError{Op: "dial", Net: "unix", Err: errors.New("no such file or directory")}))
+	assert.True(t, keyringMissing(dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}))
+	assert.True(t, keyringMissing(fmt.Errorf("session: %w", keyring.ErrUnsupportedPlatform)))
+
+	assert.False(t, keyringMissing(nil))
+	assert.False(t, keyringMissing(keyring.ErrNotFound))
+	assert.False(t, keyringMissing(dbus.Error{Name: "org.freedesktop.Secret.Error.IsLocked"}))
+}
+
+// TestSystemKeyring stores secrets with quotes, backslashes and non-ASCII text as they are
+func TestSystemKeyring(t *testing.T) {
+	keyring.MockInit()
+
+	secret, err := SystemKeyring{}.Get(KeyringService, KeyringUser)
+	assert.NoError(t, err)
+	assert.Empty(t, secret)
+
+	for _, secret := range []string{"4a7f-key", `say "hi" \o/`, "günter"} {
+		assert.NoError(t, SetAPIKey(secret))
+		auth, err := NewAuth(FromKeyring(SystemKeyring{}))
+		assert.NoError(t, err)
+		assert.Equal(t, secret, auth.APIKey)
+	}
+}
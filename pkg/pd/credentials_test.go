@@ -0,0 +1,71 @@
+package pd_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKeyring keeps the secrets in memory
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) Get(service, user string) (string, error) {
+	return k[service+"/"+user], nil
+}
+
+func (k fakeKeyring) Set(service, user, secret string) error {
+	k[service+"/"+user] = secret
+	return nil
+}
+
+// brokenKeyring fails like a keyring which can't be read
+type brokenKeyring struct{ err error }
+
+func (k brokenKeyring) Get(string, string) (string, error) { return "", k.err }
+func (k brokenKeyring) Set(string, string, string) error   { return k.err }
+
+// TestNewAuth takes the key of the first source which has one
+func TestNewAuth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("api_key: config-key\nconcurrency: 2\n"), 0o600))
+	keyring := fakeKeyring{}
+	assert.NoError(t, keyring.Set(pd.KeyringService, pd.KeyringUser, "keyring-key"))
+	t.Setenv(pd.EnvAPIKey, "env-key")
+
+	chain := func(explicit string, keyring pd.Keyring) []pd.AuthSource {
+		return []pd.AuthSource{pd.FromKey(explicit), pd.FromEnv(pd.EnvAPIKey), pd.FromKeyring(keyring),
+			pd.FromConfigFile(configPath)}
+	}
+
+	auth, err := pd.NewAuth(chain("flag-key", keyring)...)
+	assert.NoError(t, err)
+	assert.Equal(t, "flag-key", auth.APIKey)
+
+	auth, err = pd.NewAuth(chain("", keyring)...)
+	assert.NoError(t, err)
+	assert.Equal(t, "env-key", auth.APIKey)
+
+	t.Setenv(pd.EnvAPIKey, "")
+	auth, err = pd.NewAuth(chain("", keyring)...)
+	assert.NoError(t, err)
+	assert.Equal(t, "keyring-key", auth.APIKey)
+
+	auth, err = pd.NewAuth(chain("", fakeKeyring{})...)
+	assert.NoError(t, err)
+	assert.Equal(t, "config-key", auth.APIKey)
+
+	// no keyring on the system and no config file, the auth is anonymous
+	auth, err = pd.NewAuth(pd.FromKeyring(brokenKeyring{fmt.Errorf("keychain: %w", pd.ErrKeyringUnavailable)}),
+		pd.FromConfigFile(filepath.Join(t.TempDir(), "missing.yaml")))
+	assert.NoError(t, err)
+	assert.False(t, auth.IsAuthAvailable())
+
+	// a keyring which fails is reported
+	_, err = pd.NewAuth(pd.FromKeyring(brokenKeyring{errors.New("locked")}), pd.FromConfigFile(configPath))
+	assert.EqualError(t, err, "locked")
+}
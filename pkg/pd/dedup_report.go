@@ -0,0 +1,177 @@
+package pd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// DedupDecision is what happened with a local file whose content was already on pixeldrain
+type DedupDecision string
+
+const (
+	DedupSkipped DedupDecision = "skipped" // not uploaded, the content was uploaded before or is in the target list
+	DedupResumed DedupDecision = "resumed" // the completed upload of an earlier, interrupted run was found in the UploadCache
+	DedupLinked  DedupDecision = "linked"  // the existing remote file was added to the list instead of uploading it
+)
+
+// DedupEntry maps a local path to the existing remote file it was deduplicated against
+type DedupEntry struct {
+	Time     time.Time     `json:"time"`
+	Decision DedupDecision `json:"decision"`
+	DuplicateMatch
+}
+
+// DedupReport collects the dedup decisions of a run. Set it as ClientOptions.DedupReport and export it with
+// WriteJSON, WriteCSV or Save after the run, it is safe for concurrent use.
+type DedupReport struct {
+	mu      sync.Mutex
+	entries []DedupEntry
+}
+
+// NewDedupReport returns an empty report
+func NewDedupReport() *DedupReport {
+	return &DedupReport{}
+}
+
+// Add records a decision
+func (r *DedupReport) Add(decision DedupDecision, match DuplicateMatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, DedupEntry{Time: time.Now(), Decision: decision, DuplicateMatch: match})
+}
+
+// Entries returns a copy of the recorded decisions in the order they were made
+func (r *DedupReport) Entries() []DedupEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]DedupEntry(nil), r.entries...)
+}
+
+// WriteJSON writes the decisions as JSON array
+func (r *DedupReport) WriteJSON(w io.Writer) error {
+	entries := r.Entries()
+	if entries == nil {
+		entries = []DedupEntry{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// dedupCSVHeader are the columns of WriteCSV
+var dedupCSVHeader = []string{"time", "decision", "path", "hash", "original_path", "id", "name", "url", "uploaded_at"}
+
+// WriteCSV writes the decisions as CSV with a header row
+func (r *DedupReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(dedupCSVHeader); err != nil {
+		return err
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, e := range r.Entries() {
+		record := []string{
+			formatTime(e.Time), string(e.Decision), e.Path, e.Hash, e.OriginalPath, e.ID, e.Name, e.URL, formatTime(e.UploadedAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Save writes the report to the file, as CSV for a ".csv" extension and as JSON otherwise
+func (r *DedupReport) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = r.WriteCSV(file)
+	} else {
+		err = r.WriteJSON(file)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// recordDedup adds the decision to the DedupReport of the client if there is one
+func (pd *PixelDrainClient) recordDedup(decision DedupDecision, match DuplicateMatch) {
+	if pd.DedupReport != nil {
+		pd.DedupReport.Add(decision, match)
+	}
+}
+
+// duplicateMatch describes the local file whose hash is stored, the remote file is the ID of the store and the
+// upload log of the account fills in the rest
+func (pd *PixelDrainClient) duplicateMatch(store utils.HashStore, auth Auth, filePath, hash string) *DuplicateMatch {
+	match := &DuplicateMatch{Path: filePath, Hash: hash}
+	if finder, ok := store.(utils.HashFinder); ok {
+		match.OriginalPath, _, _ = finder.Find(hash)
+	}
+	if idStore, ok := store.(utils.HashIDStore); ok {
+		if id, found, _ := idStore.FindID(hash); found {
+			match.ID, match.URL = id, fileURL(id)
+		}
+	}
+
+	pd.findOriginalUpload(match, auth)
+	return match
+}
+
+// saveHash records the uploaded file for the duplicate check, with the pixeldrain ID if the store keeps IDs
+func (pd *PixelDrainClient) saveHash(store utils.HashStore, filePath, hash, id string) error {
+	if idStore, ok := store.(utils.HashIDStore); ok && id != "" {
+		return idStore.SaveID(filePath, hash, id)
+	}
+
+	return store.Save(filePath, hash)
+}
+
+// findOriginalUpload fills in the remote file of a duplicate from the upload log, the last successful upload wins.
+// Only the upload of the ID from the store is used, or without one an upload of the same API key, so the file of
+// another account sharing the log isn't reported.
+func (pd *PixelDrainClient) findOriginalUpload(match *DuplicateMatch, auth Auth) {
+	finder, ok := pd.uploadLog().(utils.UploadFinder)
+	if !ok {
+		return
+	}
+
+	uploads, err := finder.FindByHash(match.Hash)
+	if err != nil {
+		return
+	}
+	for i := len(uploads) - 1; i >= 0; i-- {
+		upload := uploads[i]
+		if upload.ID == "" || (match.ID != "" && upload.ID != match.ID) || (match.ID == "" && upload.Uploader != auth.APIKey) {
+			continue
+		}
+		match.ID = upload.ID
+		match.Name = upload.FileName
+		match.URL = fileURL(upload.ID)
+		match.UploadedAt, _ = time.Parse(time.RFC3339, upload.UploadDateTime)
+		return
+	}
+}
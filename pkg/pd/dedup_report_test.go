@@ -0,0 +1,98 @@
+package pd_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_DedupReport(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	report := pd.NewDedupReport()
+	c := pd.New(&pd.ClientOptions{
+		UploadLog:   utils.CSVUploadLog{Path: filepath.Join(dir, "uploads.csv")},
+		DedupReport: report,
+	}, nil)
+
+	original := filepath.Join(dir, "original.txt")
+	copied := filepath.Join(dir, "copy.txt")
+	assert.NoError(t, os.WriteFile(original, []byte("dedup me"), 0644))
+	assert.NoError(t, os.WriteFile(copied, []byte("dedup me"), 0644))
+
+	hashes := filepath.Join(dir, "hashes.csv")
+	for _, path := range []string{original, copied} {
+		_, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, URL: server.URL + "/file"}, hashes)
+		assert.NoError(t, err)
+	}
+
+	entries := report.Entries()
+	if !assert.Len(t, entries, 1) {
+		return
+	}
+	assert.Equal(t, pd.DedupSkipped, entries[0].Decision)
+	assert.Equal(t, copied, entries[0].Path)
+	assert.Equal(t, original, entries[0].OriginalPath)
+	assert.Equal(t, "mock-file-id", entries[0].ID)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteJSON(&buf))
+	var decoded []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	if assert.Len(t, decoded, 1) {
+		assert.Equal(t, "skipped", decoded[0]["decision"])
+		assert.Equal(t, "mock-file-id", decoded[0]["id"])
+	}
+
+	path := filepath.Join(dir, "report.csv")
+	assert.NoError(t, report.Save(path))
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "decision", records[0][1])
+		assert.Equal(t, []string{"skipped", copied}, records[1][1:3])
+		assert.Equal(t, "mock-file-id", records[1][5])
+	}
+}
+
+// knownHashes knows every hash but keeps neither paths nor IDs
+type knownHashes struct{}
+
+func (knownHashes) Exists(string) (bool, error) { return true, nil }
+func (knownHashes) Save(string, string) error   { return nil }
+
+// TestPD_DedupReport_Account the original of a duplicate is only taken from the uploads of the same account
+func TestPD_DedupReport_Account(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cat.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("meow"), 0644))
+	hash, err := utils.CalculateFileHash(path)
+	assert.NoError(t, err)
+
+	uploadLog := utils.CSVUploadLog{Path: filepath.Join(dir, "uploads.csv")}
+	assert.NoError(t, uploadLog.Record(utils.UploadInfo{FileName: "cat.txt", Uploader: "alice-key", Hash: hash, ID: "alice-id"}))
+	assert.NoError(t, uploadLog.Record(utils.UploadInfo{FileName: "cat.txt", Uploader: "bob-key", Hash: hash, ID: "bob-id"}))
+
+	c := pd.New(&pd.ClientOptions{UploadLog: uploadLog, HashStore: knownHashes{}, HashNamespace: "shared"}, nil)
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Auth: pd.Auth{APIKey: "alice-key"}}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice-id", rsp.Duplicate.ID)
+
+	rsp, err = c.UploadPOST(&pd.RequestUpload{PathToFile: path, Auth: pd.Auth{APIKey: "carol-key"}}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, rsp.Duplicate.ID, "the upload of another account")
+}
@@ -0,0 +1,74 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultDeleteConcurrency the number of parallel deletes of DeleteMany if nothing else is configured
+const DefaultDeleteConcurrency = 8
+
+// ErrMissingFileIDs is returned by DeleteMany and GetFileInfoMany without IDs
+const ErrMissingFileIDs = "file ids are required"
+
+// DeleteMany DELETE /api/file/{id} for every ID with a bounded number of parallel requests. A failed delete
+// does not stop the others, the result of every ID is returned in the order of the IDs.
+func (pd *PixelDrainClient) DeleteMany(r *RequestDeleteMany) (*ResponseDeleteMany, error) {
+	if len(r.IDs) == 0 {
+		return nil, errors.New(ErrMissingFileIDs)
+	}
+
+	workers := r.Concurrency
+	if workers <= 0 {
+		workers = DefaultDeleteConcurrency
+	}
+
+	results := make([]DeleteResult, len(r.IDs))
+	Parallel(len(r.IDs), workers, func(i int) {
+		results[i] = pd.deleteOne(r.IDs[i], r)
+	})
+
+	rspStruct := &ResponseDeleteMany{Results: results}
+	for _, result := range results {
+		if result.Err != nil {
+			rspStruct.Failed++
+		} else {
+			rspStruct.Deleted++
+		}
+	}
+
+	return rspStruct, nil
+}
+
+// deleteOne deletes the file, an unsuccessful response is returned as *APIError
+func (pd *PixelDrainClient) deleteOne(id string, r *RequestDeleteMany) DeleteResult {
+	result := DeleteResult{ID: id}
+	rsp, err := pd.Delete(&RequestDelete{ID: id, Auth: r.Auth, Immediately: r.Immediately})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.StatusCode = rsp.StatusCode
+	if !rsp.Success {
+		if r.IgnoreNotFound && rsp.StatusCode == http.StatusNotFound {
+			return result
+		}
+		result.Err = &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	return result
+}
+
+// Err returns the failed deletes joined as one error, nil if all files were deleted
+func (r *ResponseDeleteMany) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", result.ID, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_DeleteMany(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		switch strings.TrimPrefix(r.URL.Path, "/file/") {
+		case "gone":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success": false, "value": "not_found"}`))
+		case "foreign":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"success": false, "value": "forbidden"}`))
+		default:
+			_, _ = w.Write([]byte(`{"success": true, "value": "file_deleted"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	ids := []string{"a", "b", "gone", "c", "foreign", "d", "e", "f"}
+	rsp, err := c.DeleteMany(&pd.RequestDeleteMany{IDs: ids, Concurrency: 3, IgnoreNotFound: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 7, rsp.Deleted)
+	assert.Equal(t, 1, rsp.Failed)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+	for i, result := range rsp.Results {
+		assert.Equal(t, ids[i], result.ID)
+	}
+
+	var apiErr *pd.APIError
+	assert.ErrorAs(t, rsp.Results[4].Err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.ErrorContains(t, rsp.Err(), "delete foreign")
+
+	_, err = c.DeleteMany(&pd.RequestDeleteMany{})
+	assert.EqualError(t, err, pd.ErrMissingFileIDs)
+}
@@ -0,0 +1,121 @@
+package pd
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Describer returns the list description of an uploaded file, e.g. a caption for a gallery
+type Describer interface {
+	Describe(filePath string) string
+}
+
+// DescribeFunc is a Describer callback
+type DescribeFunc func(filePath string) string
+
+// Describe calls the callback
+func (f DescribeFunc) Describe(filePath string) string {
+	return f(filePath)
+}
+
+// sidecarFiles is implemented by describers which read metadata files that must not be uploaded themselves
+type sidecarFiles interface {
+	IsSidecar(filePath string) bool
+}
+
+// SidecarDescriber uses the content of a text file next to the uploaded file, e.g. "cat.jpg.txt" for "cat.jpg"
+type SidecarDescriber struct {
+	Ext string // extension appended to the file name, ".txt" if empty
+}
+
+func (d SidecarDescriber) ext() string {
+	if d.Ext == "" {
+		return ".txt"
+	}
+
+	return d.Ext
+}
+
+// Describe returns the trimmed content of the sidecar file or "" if there is none
+func (d SidecarDescriber) Describe(filePath string) string {
+	data, err := os.ReadFile(filePath + d.ext())
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// IsSidecar checks if the file is the sidecar of another existing file
+func (d SidecarDescriber) IsSidecar(filePath string) bool {
+	if !strings.HasSuffix(filePath, d.ext()) {
+		return false
+	}
+
+	_, err := os.Stat(strings.TrimSuffix(filePath, d.ext()))
+	return err == nil
+}
+
+// CSVDescriber reads the descriptions from a metadata file with "file name,description" rows
+type CSVDescriber struct {
+	Path         string
+	descriptions map[string]string
+}
+
+// NewCSVDescriber loads the metadata file
+func NewCSVDescriber(path string) (*CSVDescriber, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &CSVDescriber{Path: path, descriptions: map[string]string{}}
+	for _, record := range records {
+		d.descriptions[filepath.ToSlash(record[0])] = record[1]
+	}
+
+	return d, nil
+}
+
+// Describe looks up the file by its path relative to the metadata file, then by its name
+func (d *CSVDescriber) Describe(filePath string) string {
+	if relPath, err := filepath.Rel(filepath.Dir(d.Path), filePath); err == nil {
+		if description, ok := d.descriptions[filepath.ToSlash(relPath)]; ok {
+			return description
+		}
+	}
+
+	return d.descriptions[filepath.Base(filePath)]
+}
+
+// IsSidecar checks if the file is the metadata file
+func (d *CSVDescriber) IsSidecar(filePath string) bool {
+	a, errA := filepath.Abs(filePath)
+	b, errB := filepath.Abs(d.Path)
+	return errA == nil && errB == nil && a == b
+}
+
+// describe returns the description of the file with the Describer of the client
+func (pd *PixelDrainClient) describe(filePath string) string {
+	if pd.Describer == nil {
+		return ""
+	}
+
+	return pd.Describer.Describe(filePath)
+}
+
+// isSidecar checks if the file only holds metadata of the Describer and is skipped by directory uploads
+func (pd *PixelDrainClient) isSidecar(filePath string) bool {
+	sidecars, ok := pd.Describer.(sidecarFiles)
+	return ok && sidecars.IsSidecar(filePath)
+}
@@ -0,0 +1,66 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVDescriber(t *testing.T) {
+	dir := t.TempDir()
+	metadata := filepath.Join(dir, "descriptions.csv")
+	_ = os.WriteFile(metadata, []byte("sub/cat.jpg,A sleeping cat\ndog.jpg,A dog\n"), 0644)
+
+	d, err := pd.NewCSVDescriber(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "A sleeping cat", d.Describe(filepath.Join(dir, "sub", "cat.jpg")))
+	assert.Equal(t, "A dog", d.Describe(filepath.Join(dir, "other", "dog.jpg")))
+	assert.Equal(t, "", d.Describe(filepath.Join(dir, "bird.jpg")))
+	assert.True(t, d.IsSidecar(metadata))
+}
+
+func TestUploadDirectory_Descriptions(t *testing.T) {
+	var list pd.RequestCreateList
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/list" {
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &list)
+			_, _ = w.Write([]byte(`{"success": true, "id": "gallery"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "cat-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := filepath.Join(dir, "gallery")
+	_ = os.Mkdir(files, 0755)
+	_ = os.WriteFile(filepath.Join(files, "cat.jpg"), []byte("a cat picture"), 0644)
+	_ = os.WriteFile(filepath.Join(files, "cat.jpg.txt"), []byte("A sleeping cat\n"), 0644)
+
+	c := pd.New(&pd.ClientOptions{
+		UploadRules: pd.UploadRules{{ListTitle: "Gallery"}},
+		HashStore:   utils.NewCSVHashStore(filepath.Join(dir, "hashes.csv")),
+		Describer:   pd.SidecarDescriber{},
+	}, nil)
+
+	if _, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: files, URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Gallery", list.Title)
+	assert.Equal(t, []pd.ListFile{{ID: "cat-id", Description: "A sleeping cat"}}, list.Files)
+}
@@ -0,0 +1,58 @@
+package pd
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// DoJSON sends a request to the pixeldrain API and decodes the JSON response into T.
+// It is an escape hatch for endpoints or response fields this package doesn't cover yet.
+// The path is relative to the API URL ("/file/{id}/info") or a full URL, the body is sent as JSON if not nil.
+// Responses with a status code >= 400 are returned as *APIError.
+func DoJSON[T any](ctx context.Context, pd *PixelDrainClient, auth Auth, method, path string, body interface{}) (*T, error) {
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = pd.API.URL + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	header := pd.header(auth)
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = jsonBody(header, body); err != nil {
+			return nil, err
+		}
+	}
+
+	rsp, err := pd.request(ctx, strings.ToUpper(method), url, header, data)
+	if pd.Debug && rsp != nil {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode := rsp.StatusCode
+	if statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
+
+	result := new(T)
+	if len(data) > 0 {
+		if err := rsp.decode(data, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
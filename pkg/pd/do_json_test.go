@@ -0,0 +1,45 @@
+package pd_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_DoJSON decodes a response into a caller defined struct
+func TestPD_DoJSON(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	type fileInfo struct {
+		ID         string `json:"id"`
+		HashSha256 string `json:"hash_sha256"`
+	}
+
+	c := pd.New(nil, nil)
+	rsp, err := pd.DoJSON[fileInfo](context.Background(), c, pd.Auth{}, http.MethodGet, server.URL+"/file/K1dA8U5W/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "K1dA8U5W", rsp.ID)
+	assert.Equal(t, "1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b", rsp.HashSha256)
+}
+
+// TestPD_DoJSON_APIError maps error status codes to an APIError
+func TestPD_DoJSON_APIError(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	_, err := pd.DoJSON[map[string]interface{}](context.Background(), c, pd.Auth{}, "PATCH", server.URL+"/file/K1dA8U5W", nil)
+
+	var apiErr *pd.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusMethodNotAllowed, apiErr.StatusCode)
+}
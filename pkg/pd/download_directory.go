@@ -0,0 +1,200 @@
+package pd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// ErrMissingDirectory is returned by directory downloads without a target directory
+const ErrMissingDirectory = "directory is required"
+
+// remoteEntry is a file of a list or the account which is mirrored
+type remoteEntry struct {
+	ID          string
+	Name        string
+	Hash        string // SHA-256 if the listing has it, fetched from the file info otherwise
+	Description string // list description, empty for the files of the account
+}
+
+// DownloadDirectory mirrors a list, or all files of the account if no ListID is given, into the directory.
+// It is the inverse of UploadDirectory: with the upload log and its LogRoot, or the paths in the list descriptions,
+// every file is saved at the path it was uploaded from, relative to the directory. Files which already exist with
+// the same SHA-256 are skipped. Files are saved as they are stored on pixeldrain, so their hashes compare. The
+// download stops at the first failed file unless ContinueOnError is set.
+func (pd *PixelDrainClient) DownloadDirectory(r *RequestDownloadDirectory) (*ResponseDownloadDirectory, error) {
+	if r.Directory == "" {
+		return nil, errors.New(ErrMissingDirectory)
+	}
+
+	entries, err := pd.remoteEntries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := uploadedPaths(r.LogPath, r.LogRoot)
+	if err != nil {
+		return nil, err
+	}
+	if r.PathsFromDescriptions {
+		for _, entry := range entries {
+			if rel, ok := pathFromDescription(entry.Description); ok {
+				paths[entry.ID] = rel
+			}
+		}
+	}
+
+	result := &ResponseDownloadDirectory{}
+	used := map[string]string{}
+	for _, entry := range entries {
+		target := filepath.Join(r.Directory, localName(entry, paths, used))
+		fileResult := BatchFileResult{Path: target, ID: entry.ID, URL: fileURL(entry.ID)}
+
+		err := pd.mirrorFile(r, entry, target)
+		switch {
+		case errors.Is(err, errUpToDate):
+			fileResult.Status = BatchSkippedDuplicate
+			err = nil
+		case err != nil:
+			log.Printf("Error downloading file %s to %s: %v", entry.ID, target, err)
+			fileResult.Status, fileResult.Error = BatchFailed, err.Error()
+		default:
+			fileResult.Status = BatchCompleted
+		}
+		result.Files = append(result.Files, fileResult)
+
+		if err != nil && !r.ContinueOnError {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// errUpToDate is returned by mirrorFile if the local file already has the content of the remote file
+var errUpToDate = errors.New("file is up to date")
+
+// mirrorFile downloads the file to the target unless the target has the same SHA-256
+func (pd *PixelDrainClient) mirrorFile(r *RequestDownloadDirectory, entry remoteEntry, target string) error {
+	if entry.Hash == "" {
+		info, err := pd.GetFileInfo(&RequestFileInfo{ID: entry.ID, Auth: r.Auth})
+		if err != nil {
+			return err
+		}
+		if !info.Success {
+			return &APIError{StatusCode: info.StatusCode, Value: info.Value, Message: info.Message}
+		}
+		entry.Hash = info.HashSha256
+	}
+
+	if _, err := os.Stat(target); err == nil && entry.Hash != "" {
+		local, err := pd.calculateFileHash(target)
+		if err != nil {
+			return err
+		}
+		if local == entry.Hash {
+			return errUpToDate
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	log.Printf("Downloading file %s to %s", entry.ID, target)
+	rsp, err := pd.Download(&RequestDownload{ID: entry.ID, PathToSave: target, Auth: r.Auth, Raw: true, Verify: r.Verify})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	return nil
+}
+
+// remoteEntries returns the files of the list or of the account
+func (pd *PixelDrainClient) remoteEntries(r *RequestDownloadDirectory) ([]remoteEntry, error) {
+	var entries []remoteEntry
+	if r.ListID != "" {
+		list, err := pd.GetList(&RequestGetList{ID: r.ListID, Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		if list.StatusCode != http.StatusOK {
+			return nil, &APIError{StatusCode: list.StatusCode, Value: list.Value, Message: list.Message}
+		}
+		for _, file := range list.Files {
+			entries = append(entries, remoteEntry{ID: file.ID, Name: file.Name, Description: file.Description})
+		}
+
+		return entries, nil
+	}
+
+	files, err := pd.GetUserFiles(&RequestGetUserFiles{Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	if !files.Success {
+		return nil, &APIError{StatusCode: files.StatusCode, Value: files.Value, Message: files.Message}
+	}
+	for _, file := range files.Files {
+		entries = append(entries, remoteEntry{ID: file.ID, Name: file.Name, Hash: file.HashSha256})
+	}
+
+	return entries, nil
+}
+
+// uploadedPaths returns the paths relative to root of the files uploaded from below root by ID
+func uploadedPaths(logPath, root string) (map[string]string, error) {
+	paths := map[string]string{}
+	if logPath == "" || root == "" {
+		return paths, nil
+	}
+
+	err := utils.EachUploadInfo(logPath, func(info utils.UploadInfo) error {
+		id := info.ID
+		if id == "" && info.URL != "" {
+			id = path.Base(info.URL)
+		}
+		if id == "" || !strings.HasPrefix(info.UploadStatus, "2") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, info.DirectoryPath)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+		paths[id] = rel
+
+		return nil
+	})
+
+	return paths, err
+}
+
+// localName returns the path of the file inside of the directory. A name which is already used by another
+// file of the mirror gets the ID appended, names from pixeldrain can't leave the directory.
+func localName(entry remoteEntry, paths map[string]string, used map[string]string) string {
+	name, ok := paths[entry.ID]
+	if !ok {
+		name = filepath.Base(filepath.FromSlash(entry.Name))
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			name = entry.ID
+		}
+	}
+
+	if id, taken := used[name]; taken && id != entry.ID {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + " (" + entry.ID + ")" + ext
+	}
+	used[name] = entry.ID
+
+	return name
+}
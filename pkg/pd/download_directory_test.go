@@ -0,0 +1,91 @@
+package pd_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_DownloadDirectory(t *testing.T) {
+	content := map[string]string{"a": "first", "b": "second", "c": "third"}
+	names := map[string]string{"a": "cat.jpg", "b": "notes.txt", "c": "cat.jpg"}
+	hash := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	var downloads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user/files" {
+			var files []pd.FileGetUser
+			for _, id := range []string{"a", "b", "c"} {
+				files = append(files, pd.FileGetUser{ID: id, Name: names[id], HashSha256: hash(content[id])})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+			return
+		}
+
+		atomic.AddInt32(&downloads, 1)
+		_, _ = w.Write([]byte(content[strings.TrimPrefix(r.URL.Path, "/file/")]))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mirror")
+	root := filepath.Join(dir, "photos")
+	logPath := filepath.Join(dir, "uploads.csv")
+	err := utils.SaveUploadInfoToCSV(utils.UploadInfo{ID: "b", DirectoryPath: filepath.Join(root, "2024", "notes.txt"), UploadStatus: "201"}, logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	r := &pd.RequestDownloadDirectory{Directory: target, LogPath: logPath, LogRoot: root}
+
+	rsp, err := c.DownloadDirectory(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the uploaded path is restored, the second cat.jpg gets its ID appended
+	paths := map[string]string{
+		"a": filepath.Join(target, "cat.jpg"),
+		"b": filepath.Join(target, "2024", "notes.txt"),
+		"c": filepath.Join(target, "cat (c).jpg"),
+	}
+	for _, file := range rsp.Files {
+		assert.Equal(t, pd.BatchCompleted, file.Status)
+		assert.Equal(t, paths[file.ID], file.Path)
+		data, err := os.ReadFile(file.Path)
+		assert.NoError(t, err)
+		assert.Equal(t, content[file.ID], string(data))
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&downloads))
+
+	// unchanged files are skipped, a changed local file is downloaded again
+	assert.NoError(t, os.WriteFile(paths["a"], []byte("changed"), 0644))
+	rsp, err = c.DownloadDirectory(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, pd.BatchCompleted, rsp.Files[0].Status)
+	assert.Equal(t, pd.BatchSkippedDuplicate, rsp.Files[1].Status)
+	assert.Equal(t, pd.BatchSkippedDuplicate, rsp.Files[2].Status)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&downloads))
+
+	_, err = c.DownloadDirectory(&pd.RequestDownloadDirectory{})
+	assert.EqualError(t, err, pd.ErrMissingDirectory)
+}
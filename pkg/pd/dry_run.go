@@ -0,0 +1,40 @@
+package pd
+
+import "log"
+
+// dryRunUpload runs the local checks of UploadPOST for the file without sending anything. The file is hashed
+// and looked up in the upload cache and the duplicate check, the hash store is not changed.
+func (pd *PixelDrainClient) dryRunUpload(r *RequestUpload, hashFilePath string) BatchFileResult {
+	result := BatchFileResult{Path: r.PathToFile}
+
+	fileHash, err := pd.duplicateCheckHash(r)
+	if err != nil {
+		result.Status, result.Error = BatchFailed, err.Error()
+		return result
+	}
+	result.Hash = fileHash
+
+	if id, found, err := pd.lookupUpload(fileHash, r); err != nil {
+		result.Status, result.Error = BatchFailed, err.Error()
+		return result
+	} else if found {
+		result.Status = BatchSkippedDuplicate
+		result.Duplicate = &DuplicateMatch{Path: r.PathToFile, Hash: fileHash, ID: id, URL: fileURL(id)}
+		return result
+	}
+
+	isDuplicate, err := pd.hashStore(hashFilePath, r).Exists(fileHash)
+	if err != nil {
+		result.Status, result.Error = BatchFailed, err.Error()
+		return result
+	}
+	if isDuplicate {
+		result.Status = BatchSkippedDuplicate
+		result.Duplicate = pd.duplicateMatch(pd.hashStore(hashFilePath, r), r.Auth, r.PathToFile, fileHash)
+		return result
+	}
+
+	log.Printf("Dry run, would upload file: %s", r.PathToFile)
+	result.Status = BatchWouldUpload
+	return result
+}
@@ -0,0 +1,48 @@
+package pd_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "known.txt"), []byte("known"), 0644))
+	knownHash, err := utils.CalculateFileHash(filepath.Join(dir, "known.txt"))
+	assert.NoError(t, err)
+
+	hashStore := utils.NewMemoryHashStore()
+	assert.NoError(t, hashStore.Save("/elsewhere/known.txt", knownHash))
+
+	transport := &countingTransport{}
+	c := pd.New(&pd.ClientOptions{
+		HTTPClient: &http.Client{Transport: transport},
+		HashStore:  hashStore,
+		UploadLog:  utils.CSVUploadLog{Path: filepath.Join(t.TempDir(), "uploads.csv")},
+	}, nil)
+
+	rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir, DryRun: true, GroupBy: pd.GroupByMimeCategory})
+	assert.NoError(t, err)
+	if assert.Len(t, rsp.Files, 2) {
+		assert.Equal(t, pd.BatchSkippedDuplicate, rsp.Files[0].Status)
+		assert.Equal(t, knownHash, rsp.Files[0].Hash)
+		assert.Equal(t, pd.BatchWouldUpload, rsp.Files[1].Status)
+		assert.NotEmpty(t, rsp.Files[1].Hash)
+	}
+	assert.Empty(t, rsp.Lists)
+
+	syncRsp, err := c.Sync(&pd.RequestSync{Directory: dir, ListID: "L", DeleteRemote: true, DownloadNew: true, DryRun: true})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "known.txt"), filepath.Join(dir, "new.txt")}, syncRsp.Uploaded)
+	assert.NoFileExists(t, filepath.Join(dir, pd.SyncManifestName))
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&transport.requests))
+}
@@ -0,0 +1,261 @@
+package pd
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	TransformAESGCM = "aes-gcm" // passphrase encryption of go-pd, AES-256-GCM with a key derived by PBKDF2-SHA256
+	TransformAge    = "age"     // age encryption to X25519 recipients with filippo.io/age
+)
+
+var (
+	// ErrMissingKey the downloaded file is encrypted and the request has no Key
+	ErrMissingKey = errors.New("the file is encrypted, a key is required")
+	// ErrDecrypt the key is wrong or the encrypted file was changed or truncated
+	ErrDecrypt = errors.New("decryption failed, wrong key or damaged file")
+)
+
+const (
+	gcmChunkSize  = 64 * 1024 // plaintext bytes sealed at once
+	gcmMaxIter    = 10000000  // rejects headers which would keep the decoder busy for minutes
+	gcmSaltSize   = 16
+	gcmPrefixSize = 7 // random part of the nonce, followed by the chunk counter and the last chunk flag
+	gcmHeaderSize = gcmSaltSize + 4 + gcmPrefixSize
+)
+
+// gcmIterations are the PBKDF2 iterations for new files, the count is stored in the header, so files written
+// with another count still decrypt. The tests lower it.
+var gcmIterations uint32 = 600000
+
+func init() {
+	RegisterEncoder(TransformAESGCM, newGCMWriter)
+	RegisterDecoder(TransformAESGCM, newGCMReader)
+}
+
+// encryptTransform is the transform of an EncryptWith key, age recipients start with "age1" and everything else is
+// a passphrase
+func encryptTransform(key string) string {
+	if strings.HasPrefix(key, "age1") {
+		return TransformAge
+	}
+
+	return TransformAESGCM
+}
+
+// gcmWriter seals the plaintext in chunks, the nonce of each chunk holds its number and whether it is the last
+// one, so reordered, dropped or truncated chunks fail to decrypt
+type gcmWriter struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buf     []byte
+}
+
+func newGCMWriter(dst io.Writer, key string) (io.WriteCloser, error) {
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+
+	header := make([]byte, gcmHeaderSize)
+	if _, err := rand.Read(header[:gcmSaltSize]); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(header[gcmSaltSize:], gcmIterations)
+	if _, err := rand.Read(header[gcmSaltSize+4:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(key, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &gcmWriter{dst: dst, aead: aead, header: header, buf: make([]byte, 0, gcmChunkSize)}, nil
+}
+
+func (w *gcmWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// a full chunk is only sealed once more data follows, Close seals the last one
+		if len(w.buf) == gcmChunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):gcmChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close seals the last chunk, it doesn't close the underlying writer
+func (w *gcmWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *gcmWriter) seal(last bool) error {
+	if w.counter == ^uint32(0) {
+		return errors.New("encrypted file too large")
+	}
+
+	sealed := w.aead.Seal(nil, gcmNonce(w.header, w.counter, last), w.buf, w.header)
+	w.counter++
+	w.buf = w.buf[:0]
+
+	_, err := w.dst.Write(sealed)
+	return err
+}
+
+// gcmReader opens the chunks of a gcmWriter
+type gcmReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	sealed  []byte
+	plain   []byte
+	last    bool
+	err     error
+}
+
+func newGCMReader(src io.Reader, key string) (io.Reader, error) {
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+
+	header := make([]byte, gcmHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, ErrDecrypt
+	}
+	if iterations := binary.BigEndian.Uint32(header[gcmSaltSize:]); iterations == 0 || iterations > gcmMaxIter {
+		return nil, ErrDecrypt
+	}
+
+	aead, err := newGCM(key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcmReader{
+		src:    bufio.NewReader(src),
+		aead:   aead,
+		header: header,
+		sealed: make([]byte, gcmChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (r *gcmReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.last {
+			return 0, io.EOF
+		}
+		r.err = r.open()
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+// open reads and decrypts the next chunk, a chunk is the last one if the stream ends after it
+func (r *gcmReader) open() error {
+	n, err := io.ReadFull(r.src, r.sealed)
+	switch {
+	case err == io.EOF:
+		// the last chunk is missing
+		return ErrDecrypt
+	case err == io.ErrUnexpectedEOF:
+		r.last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.src.Peek(1); err == io.EOF {
+			r.last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.aead.Open(r.plain[:0], gcmNonce(r.header, r.counter, r.last), r.sealed[:n], r.header)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.counter++
+	r.plain = plain
+
+	return nil
+}
+
+// newGCM derives the AES-256 key of the passphrase with the salt and iterations of the header
+func newGCM(passphrase string, header []byte) (cipher.AEAD, error) {
+	iterations := int(binary.BigEndian.Uint32(header[gcmSaltSize:]))
+	key := pbkdf2SHA256([]byte(passphrase), header[:gcmSaltSize], iterations, 32)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// gcmNonce is the random prefix of the header, the big endian chunk number and 1 for the last chunk
+func gcmNonce(header []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, gcmPrefixSize+5)
+	nonce = append(nonce, header[gcmSaltSize+4:]...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+// pbkdf2SHA256 is PBKDF2 of RFC 8018 with HMAC-SHA256, crypto/pbkdf2 needs a newer Go than this module
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen)
+	u := make([]byte, 0, sha256.Size)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
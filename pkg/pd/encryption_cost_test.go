@@ -0,0 +1,6 @@
+package pd
+
+// the full PBKDF2 cost takes most of a second per encrypted file, more under -race, the tests don't need it
+func init() {
+	gcmIterations = 1000
+}
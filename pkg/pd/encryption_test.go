@@ -0,0 +1,162 @@
+package pd_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// storingServer keeps the last upload of POST or PUT and serves it on GET
+func storingServer() (*httptest.Server, func() []byte) {
+	var mu sync.Mutex
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			stored, _ = io.ReadAll(file)
+		case http.MethodPut:
+			stored, _ = io.ReadAll(r.Body)
+		default:
+			_, _ = w.Write(stored)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "encrypted-id"}`))
+	}))
+
+	return server, func() []byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return stored
+	}
+}
+
+// TestPD_Upload_EncryptWith stores only the encrypted file on pixeldrain, Download decrypts it with the passphrase
+func TestPD_Upload_EncryptWith(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	// an exact multiple of the chunk size and some odd sizes
+	for _, size := range []int{0, 11, 64 * 1024, 200000} {
+		plain := bytes.Repeat([]byte("secret cat "), size/11+1)[:size]
+		path := filepath.Join(t.TempDir(), "cat.txt")
+		assert.NoError(t, os.WriteFile(path, plain, 0o644))
+
+		for _, upload := range []func(*pd.RequestUpload) (*pd.ResponseUpload, error){
+			func(r *pd.RequestUpload) (*pd.ResponseUpload, error) { return c.UploadPOST(r, "") },
+			c.UploadPUT,
+		} {
+			rsp, err := upload(&pd.RequestUpload{PathToFile: path, FileName: "cat.txt", Anonymous: true, EncryptWith: "hunter2"})
+			assert.NoError(t, err)
+			assert.Equal(t, "encrypted-id", rsp.ID)
+			if size > 0 {
+				assert.False(t, bytes.Contains(stored(), plain[:11]), "plaintext was sent")
+			}
+
+			var out bytes.Buffer
+			_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: &out, Key: "hunter2"})
+			assert.NoError(t, err)
+			assert.Equal(t, plain, out.Bytes(), "size %d", size)
+
+			_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: io.Discard, Key: "hunter3"})
+			assert.ErrorIs(t, err, pd.ErrDecrypt)
+
+			_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: io.Discard})
+			assert.ErrorIs(t, err, pd.ErrMissingKey)
+		}
+	}
+}
+
+// TestPD_Upload_EncryptWith_Damaged detects truncated and changed files
+func TestPD_Upload_EncryptWith_Damaged(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	plain := bytes.Repeat([]byte("x"), 100000)
+	_, err := c.UploadPUT(&pd.RequestUpload{File: io.NopCloser(bytes.NewReader(plain)), FileName: "x.bin", EncryptWith: "hunter2"})
+	assert.NoError(t, err)
+	encrypted := stored()
+
+	decrypt := func(data []byte) error {
+		damaged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(data)
+		}))
+		defer damaged.Close()
+		_, err := c.Download(&pd.RequestDownload{ID: "x", URL: damaged.URL, Writer: io.Discard, Key: "hunter2"})
+		return err
+	}
+
+	assert.NoError(t, decrypt(encrypted))
+
+	// the second chunk is dropped, the first one is not marked as the last
+	assert.ErrorIs(t, decrypt(encrypted[:len(encrypted)-(100000-64*1024)-16]), pd.ErrDecrypt)
+
+	flipped := append([]byte{}, encrypted...)
+	flipped[len(flipped)-1] ^= 1
+	assert.ErrorIs(t, decrypt(flipped), pd.ErrDecrypt)
+}
+
+// TestPD_Upload_EncryptWith_Age encrypts to age recipients, the file is decrypted with the identity
+func TestPD_Upload_EncryptWith_Age(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	identity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	req := &pd.RequestUpload{File: io.NopCloser(strings.NewReader("meow")), FileName: "cat.txt", EncryptWith: "age1recipient"}
+	_, err = c.UploadPUT(req)
+	assert.Error(t, err, "not an age recipient")
+
+	req.File = io.NopCloser(strings.NewReader("meow"))
+	req.EncryptWith = identity.Recipient().String() + "," + other.Recipient().String()
+	_, err = c.UploadPUT(req)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(stored(), []byte("meow")))
+
+	for _, key := range []*age.X25519Identity{identity, other} {
+		var out bytes.Buffer
+		_, err = c.Download(&pd.RequestDownload{ID: "encrypted-id", Writer: &out, Key: "# created: now\n" + key.String()})
+		assert.NoError(t, err)
+		assert.Equal(t, "meow", out.String())
+	}
+
+	stranger, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	_, err = c.Download(&pd.RequestDownload{ID: "encrypted-id", Writer: io.Discard, Key: stranger.String()})
+	assert.ErrorIs(t, err, pd.ErrDecrypt)
+	_, err = c.Download(&pd.RequestDownload{ID: "encrypted-id", Writer: io.Discard})
+	assert.ErrorIs(t, err, pd.ErrMissingKey)
+}
@@ -0,0 +1,129 @@
+package pd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// envelopeMagic marks files which were transformed (encrypted, compressed) by go-pd before the upload
+var envelopeMagic = []byte("GOPD\x00\x01")
+
+// ErrUnknownTransform the downloaded file was transformed with something this client can't reverse
+var ErrUnknownTransform = errors.New("unknown go-pd transform")
+
+// Envelope is the small header written in front of transformed uploads. It lists the transforms
+// in the order they were applied, so Download can detect and reverse them without the caller
+// tracking which files were transformed.
+type Envelope struct {
+	Transforms []string `json:"transforms"`
+	Name       string   `json:"name,omitempty"` // original file name
+	Size       int64    `json:"size,omitempty"` // original file size in bytes
+}
+
+// Decoder reverses a transform, key is the key or passphrase of the download request
+type Decoder func(src io.Reader, key string) (io.Reader, error)
+
+// Encoder applies a transform to everything written to the returned writer, Close flushes it without closing dst.
+// The key is the EncryptWith key of the upload request.
+type Encoder func(dst io.Writer, key string) (io.WriteCloser, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{}
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{}
+)
+
+// RegisterDecoder makes a transform reversible on download
+func RegisterDecoder(name string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[name] = d
+}
+
+// RegisterEncoder makes a transform available for uploads or replaces a built-in one
+func RegisterEncoder(name string, e Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[name] = e
+}
+
+// WriteEnvelope writes the header, the transformed content has to follow it
+func WriteEnvelope(w io.Writer, e *Envelope) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0xFFFF {
+		return errors.New("envelope too large")
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+2+len(data))
+	header = append(header, envelopeMagic...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(data)))
+	header = append(header, data...)
+
+	_, err = w.Write(header)
+	return err
+}
+
+// ReadEnvelope reads the header if the stream starts with one, otherwise it returns nil and leaves the stream untouched
+func ReadEnvelope(r *bufio.Reader) (*Envelope, error) {
+	magic, err := r.Peek(len(envelopeMagic))
+	if err != nil || !bytes.Equal(magic, envelopeMagic) {
+		return nil, nil
+	}
+
+	if _, err := r.Discard(len(envelopeMagic)); err != nil {
+		return nil, err
+	}
+
+	var size uint16
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// decodeEnvelope returns the original content if the stream has an envelope
+func decodeEnvelope(src io.Reader, key string) (io.Reader, *Envelope, error) {
+	br := bufio.NewReader(src)
+	e, err := ReadEnvelope(br)
+	if err != nil || e == nil {
+		return br, nil, err
+	}
+
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	var out io.Reader = br
+	for i := len(e.Transforms) - 1; i >= 0; i-- {
+		d, ok := decoders[e.Transforms[i]]
+		if !ok {
+			return nil, e, fmt.Errorf("%w: %s", ErrUnknownTransform, e.Transforms[i])
+		}
+		out, err = d(out, key)
+		if err != nil {
+			return nil, e, err
+		}
+	}
+
+	return out, e, nil
+}
@@ -0,0 +1,84 @@
+package pd_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+func TestPD_Envelope(t *testing.T) {
+	var buf bytes.Buffer
+	err := pd.WriteEnvelope(&buf, &pd.Envelope{Transforms: []string{"gzip"}, Name: "cat.jpg", Size: 42})
+	assert.NoError(t, err)
+	buf.WriteString("content")
+
+	br := bufio.NewReader(&buf)
+	e, err := pd.ReadEnvelope(br)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gzip"}, e.Transforms)
+	assert.Equal(t, "cat.jpg", e.Name)
+
+	rest, _ := io.ReadAll(br)
+	assert.Equal(t, "content", string(rest))
+
+	// plain content has no envelope and stays untouched
+	br = bufio.NewReader(strings.NewReader("plain"))
+	e, err = pd.ReadEnvelope(br)
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+	rest, _ = io.ReadAll(br)
+	assert.Equal(t, "plain", string(rest))
+}
+
+// TestPD_Download_Envelope reverses the transforms of an uploaded envelope on download
+func TestPD_Download_Envelope(t *testing.T) {
+	pd.RegisterDecoder("test-base64", func(src io.Reader, key string) (io.Reader, error) {
+		if key != "secret" {
+			return nil, errors.New("wrong key")
+		}
+		return base64.NewDecoder(base64.StdEncoding, src), nil
+	})
+
+	var body bytes.Buffer
+	_ = pd.WriteEnvelope(&body, &pd.Envelope{Transforms: []string{"test-base64"}})
+	body.WriteString(base64.StdEncoding.EncodeToString([]byte("hello pixeldrain")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body.Bytes())
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "decoded.txt")
+	c := pd.New(nil, nil)
+	rsp, err := c.Download(&pd.RequestDownload{
+		ID:         "envelope",
+		PathToSave: path,
+		Key:        "secret",
+		URL:        server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, true, rsp.Success)
+	assert.Equal(t, "hello pixeldrain", string(content))
+
+	// Raw keeps the stored bytes
+	_, err = c.Download(&pd.RequestDownload{ID: "envelope", PathToSave: path, Raw: true, URL: server.URL})
+	assert.NoError(t, err)
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, body.Bytes(), content)
+}
@@ -0,0 +1,37 @@
+package pd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIError is returned if pixeldrain answers with an error status code
+type APIError struct {
+	StatusCode int    `json:"status_code"`
+	Value      string `json:"value"`
+	Message    string `json:"message"`
+
+	RetryAfter time.Duration `json:"-"` // pause requested by the server with a 429 status
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("pixeldrain: status %d %s", e.StatusCode, e.Value)
+	}
+
+	return fmt.Sprintf("pixeldrain: status %d %s: %s", e.StatusCode, e.Value, e.Message)
+}
+
+// newAPIError parses the error body of a response, the value and message are kept verbatim.
+// A body which is not JSON, e.g. from a proxy in front of pixeldrain, becomes the message.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	apiErr.StatusCode = statusCode
+
+	return apiErr
+}
@@ -0,0 +1,89 @@
+package pd
+
+import (
+	"os"
+	"sync"
+)
+
+// DefaultMaxOpenFiles the number of files a client keeps open at the same time if nothing else is configured
+const DefaultMaxOpenFiles = 64
+
+// fdBudget limits the number of files the client keeps open at the same time, a nil budget is unlimited
+type fdBudget chan struct{}
+
+func newFDBudget(n int) fdBudget {
+	if n < 0 {
+		return nil
+	}
+	if n == 0 {
+		n = DefaultMaxOpenFiles
+	}
+
+	return make(fdBudget, n)
+}
+
+func (b fdBudget) acquire() {
+	if b != nil {
+		b <- struct{}{}
+	}
+}
+
+func (b fdBudget) release() {
+	if b != nil {
+		<-b
+	}
+}
+
+// lazyFile opens the file on the first read and gives the budget back on close,
+// so queued uploads don't hold a file descriptor before they send any bytes
+type lazyFile struct {
+	path   string
+	budget fdBudget
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+func newLazyFile(path string, budget fdBudget) *lazyFile {
+	return &lazyFile{path: path, budget: budget}
+}
+
+func (f *lazyFile) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		f.budget.acquire()
+		file, err := os.Open(f.path)
+		if err != nil {
+			f.budget.release()
+			f.mu.Unlock()
+			return 0, err
+		}
+		f.file = file
+	}
+	file := f.file
+	f.mu.Unlock()
+
+	return file.Read(p)
+}
+
+// Close can be called more than once, only the first call closes the file
+func (f *lazyFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	if f.file == nil {
+		return nil
+	}
+	defer f.budget.release()
+
+	return f.file.Close()
+}
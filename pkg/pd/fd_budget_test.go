@@ -0,0 +1,42 @@
+package pd
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyFile_Budget(t *testing.T) {
+	budget := newFDBudget(1)
+
+	first := newLazyFile("testdata/cat.jpg", budget)
+	second := newLazyFile("testdata/cat_unique1.jpg", budget)
+
+	// nothing is opened before the first read
+	assert.Equal(t, 0, len(budget))
+
+	buf := make([]byte, 16)
+	_, err := first.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(budget))
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, second)
+		_ = second.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("second file was opened while the budget was exhausted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, first.Close())
+	assert.NoError(t, first.Close())
+	<-done
+	assert.Equal(t, 0, len(budget))
+}
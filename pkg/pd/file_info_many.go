@@ -0,0 +1,105 @@
+package pd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// FileInfoBatchSize is the number of IDs GetFileInfoMany asks for in one request
+const FileInfoBatchSize = 100
+
+// GetFileInfoMany GET /api/file/{id},{id}/info
+// returns the infos of the files by ID with one request per FileInfoBatchSize IDs instead of one per file, e.g. to
+// reconcile a list. Files which don't exist (anymore) are missing from the map.
+func (pd *PixelDrainClient) GetFileInfoMany(r *RequestFileInfoMany) (map[string]*ResponseFileInfo, error) {
+	if len(r.IDs) == 0 {
+		return nil, errors.New(ErrMissingFileIDs)
+	}
+
+	infos := make(map[string]*ResponseFileInfo, len(r.IDs))
+	for start := 0; start < len(r.IDs); start += FileInfoBatchSize {
+		end := start + FileInfoBatchSize
+		if end > len(r.IDs) {
+			end = len(r.IDs)
+		}
+
+		batch, err := pd.getFileInfoBatch(r.IDs[start:end], r)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range batch {
+			infos[info.ID] = info
+		}
+	}
+
+	return infos, nil
+}
+
+func (pd *PixelDrainClient) getFileInfoBatch(ids []string, r *RequestFileInfoMany) ([]*ResponseFileInfo, error) {
+	baseURL := r.URL
+	if baseURL == "" {
+		baseURL = pd.API.URL + pd.API.File
+	}
+	url := fmt.Sprintf("%s/%s/info", baseURL, strings.Join(ids, ","))
+
+	rsp, err := pd.request(context.Background(), http.MethodGet, url, pd.header(r.Auth), nil)
+	if pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	// a single ID which doesn't exist
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError(rsp.StatusCode, data)
+	}
+
+	// several IDs are answered with an array, a single one with the info itself
+	var raws []json.RawMessage
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		err = json.Unmarshal(trimmed, &raws)
+	default:
+		var wrapped struct {
+			Files []json.RawMessage `json:"files"`
+		}
+		err = json.Unmarshal(trimmed, &wrapped)
+		raws = wrapped.Files
+		if err == nil && wrapped.Files == nil {
+			raws = []json.RawMessage{trimmed}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*ResponseFileInfo, 0, len(raws))
+	for _, raw := range raws {
+		info := &ResponseFileInfo{}
+		if err := rsp.decode(raw, info); err != nil {
+			return nil, err
+		}
+		if info.ID == "" {
+			continue
+		}
+		info.StatusCode = http.StatusOK
+		info.Success = true
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
@@ -0,0 +1,70 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_GetFileInfoMany asks for many files with one request per batch, missing files are left out
+func TestPD_GetFileInfoMany(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		ids := strings.Split(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/file/"), "/info"), ",")
+
+		var infos []map[string]interface{}
+		for _, id := range ids {
+			if !strings.HasPrefix(id, "gone") {
+				infos = append(infos, map[string]interface{}{"id": id, "name": id + ".jpg", "size": 42})
+			}
+		}
+		switch {
+		case len(ids) > 1:
+			_ = json.NewEncoder(w).Encode(infos)
+		case len(infos) == 1:
+			_ = json.NewEncoder(w).Encode(infos[0])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success": false, "value": "not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	var ids []string
+	for i := 0; i < pd.FileInfoBatchSize+50; i++ {
+		ids = append(ids, fmt.Sprintf("id%d", i))
+	}
+	ids = append(ids, "gone1")
+
+	infos, err := c.GetFileInfoMany(&pd.RequestFileInfoMany{IDs: ids})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Len(t, infos, pd.FileInfoBatchSize+50)
+	assert.Equal(t, "id7.jpg", infos["id7"].Name)
+	assert.True(t, infos["id7"].Success)
+	assert.NotContains(t, infos, "gone1")
+
+	infos, err = c.GetFileInfoMany(&pd.RequestFileInfoMany{IDs: []string{"id1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), infos["id1"].Size)
+
+	infos, err = c.GetFileInfoMany(&pd.RequestFileInfoMany{IDs: []string{"gone2"}})
+	assert.NoError(t, err)
+	assert.Empty(t, infos)
+
+	_, err = c.GetFileInfoMany(&pd.RequestFileInfoMany{})
+	assert.EqualError(t, err, pd.ErrMissingFileIDs)
+}
@@ -0,0 +1,260 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	ErrMissingFSPath     = "filesystem path is required"
+	ErrMissingFSTarget   = "filesystem target is required"
+	ErrInvalidBucketName = "bucket name is required and can't contain a slash"
+)
+
+// FSNodeDir and FSNodeFile are the types of a filesystem node
+const (
+	FSNodeDir  = "dir"
+	FSNodeFile = "file"
+)
+
+// Filesystem is the sub-API for the filesystem of an account, e.g. "/me/photos/cat.jpg".
+// Use Capabilities to check if the server supports it.
+type Filesystem struct {
+	pd *PixelDrainClient
+}
+
+// FS returns the filesystem sub-API of the client
+func (pd *PixelDrainClient) FS() *Filesystem {
+	return &Filesystem{pd: pd}
+}
+
+// Rename POST /api/filesystem/{path} action=rename
+// curl -X POST -i -H "Authorization: Basic <TOKEN>" -F "action=rename" -F "target=/me/new.jpg" https://pixeldrain.com/api/filesystem/me/old.jpg
+func (fs *Filesystem) Rename(r *RequestFSRename) (*ResponseFSRename, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+	if r.Target == "" {
+		return nil, errors.New(ErrMissingFSTarget)
+	}
+
+	if r.URL == "" {
+		r.URL = fs.pd.API.URL + fs.pd.API.Filesystem + escapeFSPath(r.Path)
+	}
+
+	header := fs.pd.header(r.Auth)
+	data := formBody(header, url.Values{
+		"action": {"rename"},
+		"target": {r.Target},
+	})
+
+	rsp, err := fs.pd.request(context.Background(), http.MethodPost, r.URL, header, data)
+	if fs.pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
+
+	rspStruct := &ResponseFSRename{Path: r.Target}
+	rspStruct.StatusCode = rsp.StatusCode
+	rspStruct.Success = true
+
+	return rspStruct, nil
+}
+
+// Move moves the file or directory into the target directory and keeps its name
+func (fs *Filesystem) Move(r *RequestFSMove) (*ResponseFSRename, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+	if r.TargetDir == "" {
+		return nil, errors.New(ErrMissingFSTarget)
+	}
+
+	return fs.Rename(&RequestFSRename{
+		Path:   r.Path,
+		Target: path.Join(r.TargetDir, path.Base(r.Path)),
+		Auth:   r.Auth,
+		URL:    r.URL,
+	})
+}
+
+// escapeFSPath escapes every segment of a filesystem path and keeps the slashes
+func escapeFSPath(p string) string {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+// CreateBucket creates a bucket, a directory at the root of the filesystem, e.g. "/backups"
+func (fs *Filesystem) CreateBucket(r *RequestFSCreateBucket) (*ResponseFSNode, error) {
+	if r.Name == "" || strings.Contains(strings.Trim(r.Name, "/"), "/") {
+		return nil, errors.New(ErrInvalidBucketName)
+	}
+
+	return fs.Mkdir(&RequestFSMkdir{Path: "/" + strings.Trim(r.Name, "/"), Auth: r.Auth, URL: r.URL})
+}
+
+// Mkdir POST /api/filesystem/{path} action=mkdir, with Parents the missing parent directories are created too
+// curl -X POST -i -H "Authorization: Basic <TOKEN>" -F "action=mkdirall" https://pixeldrain.com/api/filesystem/me/photos/2024
+func (fs *Filesystem) Mkdir(r *RequestFSMkdir) (*ResponseFSNode, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+
+	if r.URL == "" {
+		r.URL = fs.pd.API.URL + fs.pd.API.Filesystem + escapeFSPath(r.Path)
+	}
+
+	action := "mkdir"
+	if r.Parents {
+		action = "mkdirall"
+	}
+	header := fs.pd.header(r.Auth)
+	data := formBody(header, url.Values{"action": {action}})
+
+	rsp, err := fs.pd.request(context.Background(), http.MethodPost, r.URL, header, data)
+	if fs.pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
+
+	rspStruct := &ResponseFSNode{FSNode: FSNode{Type: FSNodeDir, Path: r.Path, Name: path.Base(r.Path)}}
+	rspStruct.StatusCode = rsp.StatusCode
+	rspStruct.Success = true
+
+	return rspStruct, nil
+}
+
+// Upload PUT /api/filesystem/{path}, the file is stored under the path, e.g. "/me/photos/cat.jpg".
+// The body is sent like UploadPUT, so retries, progress and the bandwidth limit apply.
+// curl -X PUT -i -H "Authorization: Basic <TOKEN>" -T cat.jpg https://pixeldrain.com/api/filesystem/me/photos/cat.jpg
+func (fs *Filesystem) Upload(r *RequestFSUpload) (*ResponseUpload, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+	if r.PathToFile == "" && r.File == nil {
+		return nil, errors.New(ErrMissingPathToFile)
+	}
+
+	if r.URL == "" {
+		r.URL = fs.pd.API.URL + fs.pd.API.Filesystem + escapeFSPath(r.Path)
+	}
+
+	extra := map[string]string{}
+	if r.Parents {
+		extra["make_parents"] = "true"
+	}
+
+	return fs.pd.UploadPUT(&RequestUpload{
+		PathToFile: r.PathToFile,
+		File:       r.File,
+		FileName:   path.Base(r.Path),
+		Auth:       r.Auth,
+		URL:        r.URL,
+		Extra:      extra,
+		Progress:   r.Progress,
+	})
+}
+
+// List GET /api/filesystem/{path}?stat, the node of the path and the children of a directory
+// curl -i -H "Authorization: Basic <TOKEN>" https://pixeldrain.com/api/filesystem/me/photos?stat
+func (fs *Filesystem) List(r *RequestFSList) (*ResponseFSList, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+
+	if r.URL == "" {
+		r.URL = fs.pd.API.URL + fs.pd.API.Filesystem + escapeFSPath(r.Path) + "?stat"
+	}
+
+	rsp, err := fs.pd.request(context.Background(), http.MethodGet, r.URL, fs.pd.header(r.Auth), nil)
+	if fs.pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
+
+	rspStruct := &ResponseFSList{}
+	err = rsp.decode(data, rspStruct)
+	if err != nil {
+		return nil, err
+	}
+	rspStruct.StatusCode = rsp.StatusCode
+	rspStruct.Success = true
+
+	return rspStruct, nil
+}
+
+// Delete DELETE /api/filesystem/{path}, a directory which is not empty is only deleted with Recursive
+// curl -X DELETE -i -H "Authorization: Basic <TOKEN>" https://pixeldrain.com/api/filesystem/me/photos?recursive
+func (fs *Filesystem) Delete(r *RequestFSDelete) (*ResponseDefault, error) {
+	if r.Path == "" {
+		return nil, errors.New(ErrMissingFSPath)
+	}
+
+	if r.URL == "" {
+		r.URL = fs.pd.API.URL + fs.pd.API.Filesystem + escapeFSPath(r.Path)
+		if r.Recursive {
+			r.URL += "?recursive"
+		}
+	}
+
+	rsp, err := fs.pd.request(context.Background(), http.MethodDelete, r.URL, fs.pd.header(r.Auth), nil)
+	if fs.pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
+
+	rspStruct := &ResponseDefault{}
+	rspStruct.StatusCode = rsp.StatusCode
+	rspStruct.Success = true
+
+	return rspStruct, nil
+}
@@ -0,0 +1,135 @@
+package pd_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilesystem_Rename(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	api := pd.DefaultAPISpec
+	api.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &api}, nil)
+
+	rsp, err := c.FS().Rename(&pd.RequestFSRename{
+		Path:   "/me/photos/cat.jpg",
+		Target: "/me/photos/kitty.jpg",
+		Auth:   pd.Auth{APIKey: "test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, rsp.Success)
+	assert.Equal(t, "/me/photos/kitty.jpg", rsp.Path)
+
+	_, err = c.FS().Rename(&pd.RequestFSRename{Path: "/me/photos/cat.jpg"})
+	assert.EqualError(t, err, pd.ErrMissingFSTarget)
+}
+
+func TestFilesystem_Move(t *testing.T) {
+	var path, target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		target = r.FormValue("target")
+		_, _ = w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	api := pd.DefaultAPISpec
+	api.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &api}, nil)
+
+	rsp, err := c.FS().Move(&pd.RequestFSMove{
+		Path:      "/me/my photos/cat.jpg",
+		TargetDir: "/me/archive",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "/filesystem/me/my%20photos/cat.jpg", path)
+	assert.Equal(t, "/me/archive/cat.jpg", target)
+	assert.Equal(t, "/me/archive/cat.jpg", rsp.Path)
+}
+
+func TestFilesystem_Nodes(t *testing.T) {
+	var requests []string
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := r.Method + " " + r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			request += "?" + r.URL.RawQuery
+		}
+		switch r.Method {
+		case http.MethodPost:
+			request += " " + r.FormValue("action")
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "abc"}`))
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"path": [
+				{"type": "dir", "path": "/backups", "name": "backups"}],
+				"base_index": 0,
+				"children": [
+				{"type": "dir", "path": "/backups/2024", "name": "2024"},
+				{"type": "file", "path": "/backups/db dump.sql", "name": "db dump.sql", "file_size": 4}]}`))
+		}
+		requests = append(requests, request)
+	}))
+	defer server.Close()
+
+	api := pd.DefaultAPISpec
+	api.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &api, DisableUploadLog: true}, nil)
+	auth := pd.Auth{APIKey: "test"}
+
+	_, err := c.FS().CreateBucket(&pd.RequestFSCreateBucket{Name: "backups/2024", Auth: auth})
+	assert.EqualError(t, err, pd.ErrInvalidBucketName)
+
+	bucket, err := c.FS().CreateBucket(&pd.RequestFSCreateBucket{Name: "backups", Auth: auth})
+	assert.NoError(t, err)
+	assert.True(t, bucket.IsDir())
+	assert.Equal(t, "/backups", bucket.Path)
+
+	_, err = c.FS().Mkdir(&pd.RequestFSMkdir{Path: "/backups/2024/01", Parents: true, Auth: auth})
+	assert.NoError(t, err)
+
+	_, err = c.FS().Upload(&pd.RequestFSUpload{
+		Path:    "/backups/db dump.sql",
+		File:    io.NopCloser(strings.NewReader("dump")),
+		Parents: true,
+		Auth:    auth,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "dump", uploaded)
+
+	list, err := c.FS().List(&pd.RequestFSList{Path: "/backups", Auth: auth})
+	assert.NoError(t, err)
+	assert.Equal(t, "backups", list.Node().Name)
+	if assert.Len(t, list.Children, 2) {
+		assert.True(t, list.Children[0].IsDir())
+		assert.Equal(t, int64(4), list.Children[1].FileSize)
+	}
+
+	_, err = c.FS().Delete(&pd.RequestFSDelete{Path: "/backups", Recursive: true, Auth: auth})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"POST /filesystem/backups mkdir",
+		"POST /filesystem/backups/2024/01 mkdirall",
+		"PUT /filesystem/backups/db%20dump.sql?make_parents=true",
+		"GET /filesystem/backups?stat",
+		"DELETE /filesystem/backups?recursive",
+	}, requests)
+}
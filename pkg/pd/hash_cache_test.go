@@ -0,0 +1,111 @@
+package pd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_HashCache an unchanged file is checked with the cached hash, ForceHash reads it again
+func TestPD_HashCache(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	cache := utils.NewMemoryHashCache()
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, HashStore: utils.NewMemoryHashStore(), HashCache: cache})
+
+	dir := t.TempDir()
+	cat := filepath.Join(dir, "cat.txt")
+	assert.NoError(t, os.WriteFile(cat, []byte("meow"), 0644))
+	dog := filepath.Join(dir, "dog.txt")
+	assert.NoError(t, os.WriteFile(dog, []byte("woof"), 0644))
+
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.Nil(t, rsp.Duplicate)
+
+	// the cache claims that the unchanged dog.txt has the hash of cat.txt, so it isn't read
+	abs, _ := filepath.Abs(dog)
+	info, _ := os.Stat(dog)
+	assert.NoError(t, cache.Record(abs, info.Size(), info.ModTime(), rsp.Hash))
+	skipped, err := c.UploadPOST(&pd.RequestUpload{PathToFile: dog, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.NotNil(t, skipped.Duplicate)
+
+	uploaded, err := c.UploadPOST(&pd.RequestUpload{PathToFile: dog, Anonymous: true, ForceHash: true}, "")
+	assert.NoError(t, err)
+	assert.Nil(t, uploaded.Duplicate)
+	assert.NotEqual(t, rsp.Hash, uploaded.Hash)
+	assert.Len(t, server.Files(), 2)
+
+	// the forced hash replaced the wrong one
+	hash, found, err := cache.Lookup(abs, info.Size(), info.ModTime())
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uploaded.Hash, hash)
+}
+
+// TestPD_HashCache_Reused the SHA-256 of the duplicate check is the hash of the upload, the file isn't read again
+func TestPD_HashCache_Reused(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	cache := utils.NewMemoryHashCache()
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, HashStore: utils.NewMemoryHashStore(), HashCache: cache})
+
+	cat := filepath.Join(t.TempDir(), "cat.txt")
+	assert.NoError(t, os.WriteFile(cat, []byte("meow"), 0644))
+	abs, _ := filepath.Abs(cat)
+	info, _ := os.Stat(cat)
+	cached := strings.Repeat("c", 64)
+	assert.NoError(t, cache.Record(abs, info.Size(), info.ModTime(), cached))
+
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, cached, rsp.Hash)
+}
+
+// TestPD_HashAlgorithm the duplicate check stores and matches the hashes of the configured algorithm
+func TestPD_HashAlgorithm(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	store := utils.NewMemoryHashStore()
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, HashStore: store, HashAlgorithm: utils.HashMD5})
+
+	dir := t.TempDir()
+	cat := filepath.Join(dir, "cat.txt")
+	assert.NoError(t, os.WriteFile(cat, []byte("meow"), 0644))
+	copyOfCat := filepath.Join(dir, "copy.txt")
+	assert.NoError(t, os.WriteFile(copyOfCat, []byte("meow"), 0644))
+
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.Len(t, rsp.Hash, 64, "the response keeps the SHA-256 pixeldrain stores")
+
+	hashes, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "md5:4a4be40c96ac6314e91d93f38043a634", hashes[cat])
+
+	skipped, err := c.UploadPOST(&pd.RequestUpload{PathToFile: copyOfCat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.NotNil(t, skipped.Duplicate)
+	assert.Equal(t, rsp.ID, skipped.ID)
+
+	// BLAKE3 is built in, its hash doesn't match the MD5 row
+	c.HashAlgorithm = utils.HashBLAKE3
+	rsp, err = c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	assert.Nil(t, rsp.Duplicate)
+	hashes, err = store.Load()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashes[cat], "blake3:"), hashes[cat])
+
+	c.HashAlgorithm = "whirlpool"
+	_, err = c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.Error(t, err, "the algorithm is not registered")
+}
@@ -0,0 +1,43 @@
+package pd
+
+import "sync"
+
+// hashLocks serializes the uploads of files with the same content. Without it two goroutines uploading equal
+// files both pass the duplicate check before either has saved the hash, and the content is uploaded twice.
+type hashLocks struct {
+	mu    sync.Mutex
+	locks map[string]*hashLock
+}
+
+type hashLock struct {
+	mu   sync.Mutex
+	refs int // goroutines holding or waiting for the lock, the entry is removed at 0
+}
+
+// lock blocks until no other upload of the hash is running and returns the unlock function
+func (l *hashLocks) lock(hash string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*hashLock{}
+	}
+	entry, ok := l.locks[hash]
+	if !ok {
+		entry = &hashLock{}
+		l.locks[hash] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+
+	return func() {
+		entry.mu.Unlock()
+
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, hash)
+		}
+		l.mu.Unlock()
+	}
+}
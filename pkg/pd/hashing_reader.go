@@ -0,0 +1,55 @@
+package pd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// hashingReader computes the SHA-256, size and MIME type of an upload body while it is sent
+type hashingReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+	head []byte
+}
+
+func newHashingReader(r io.Reader) *hashingReader {
+	return &hashingReader{r: r, hash: sha256.New()}
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if n > 0 {
+		h.hash.Write(p[:n])
+		h.size += int64(n)
+		if missing := 512 - len(h.head); missing > 0 {
+			if missing > n {
+				missing = n
+			}
+			h.head = append(h.head, p[:missing]...)
+		}
+	}
+	return n, err
+}
+
+// Sum returns the hex encoded SHA-256 of the bytes read so far
+func (h *hashingReader) Sum() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+// MimeType returns the MIME type sniffed from the first bytes
+func (h *hashingReader) MimeType() string {
+	return http.DetectContentType(h.head)
+}
+
+// Close closes the wrapped reader, the HTTP transport closes request bodies after sending
+func (h *hashingReader) Close() error {
+	if closer, ok := h.r.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package pd
+
+// Hooks let an embedding application react to the uploads of the client, e.g. insert the uploaded files into its
+// own database instead of parsing the upload log. Every hook is optional. They are called on the goroutine of the
+// upload, so concurrent uploads call them concurrently, and they should return quickly.
+type Hooks struct {
+	// OnUploadStart is called before the file is checked and sent
+	OnUploadStart func(r *RequestUpload)
+	// OnUploadComplete is called after the file was uploaded, or found as an upload of an earlier run
+	OnUploadComplete func(r *RequestUpload, rsp *ResponseUpload)
+	// OnDuplicateSkipped is called instead of OnUploadComplete if the duplicate check skipped the upload,
+	// rsp.Duplicate is the match
+	OnDuplicateSkipped func(r *RequestUpload, rsp *ResponseUpload)
+	// OnError is called instead of OnUploadComplete if the upload failed
+	OnError func(r *RequestUpload, err error)
+}
+
+func (h Hooks) uploadStart(r *RequestUpload) {
+	if h.OnUploadStart != nil {
+		h.OnUploadStart(r)
+	}
+}
+
+// uploadDone calls the hook of the outcome, an upload of a directory has no response of its own and calls none
+func (h Hooks) uploadDone(r *RequestUpload, rsp *ResponseUpload, err error) {
+	switch {
+	case err != nil:
+		if h.OnError != nil {
+			h.OnError(r, err)
+		}
+	case rsp == nil:
+	case rsp.Duplicate != nil:
+		if h.OnDuplicateSkipped != nil {
+			h.OnDuplicateSkipped(r, rsp)
+		}
+	default:
+		if h.OnUploadComplete != nil {
+			h.OnUploadComplete(r, rsp)
+		}
+	}
+}
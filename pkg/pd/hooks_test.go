@@ -0,0 +1,73 @@
+package pd_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_Hooks every upload calls the start hook and the hook of its outcome once
+func TestPD_Hooks(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	c := server.Client(&pd.ClientOptions{
+		DisableUploadLog: true,
+		HashStore:        utils.NewMemoryHashStore(),
+		RetryPolicy:      &pd.RetryPolicy{MaxAttempts: 1},
+		Hooks: pd.Hooks{
+			OnUploadStart: func(r *pd.RequestUpload) {
+				record("start " + r.GetFileName())
+			},
+			OnUploadComplete: func(r *pd.RequestUpload, rsp *pd.ResponseUpload) {
+				record("complete " + r.GetFileName() + " " + rsp.ID)
+			},
+			OnDuplicateSkipped: func(r *pd.RequestUpload, rsp *pd.ResponseUpload) {
+				record("duplicate " + r.GetFileName() + " of " + rsp.Duplicate.ID)
+			},
+			OnError: func(r *pd.RequestUpload, err error) {
+				record("error " + r.GetFileName())
+			},
+		},
+	})
+
+	dir := t.TempDir()
+	cat := filepath.Join(dir, "cat.txt")
+	assert.NoError(t, os.WriteFile(cat, []byte("meow"), 0644))
+	copyOfCat := filepath.Join(dir, "copy.txt")
+	assert.NoError(t, os.WriteFile(copyOfCat, []byte("meow"), 0644))
+
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: cat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	_, err = c.UploadPOST(&pd.RequestUpload{PathToFile: copyOfCat, Anonymous: true}, "")
+	assert.NoError(t, err)
+	putRsp, err := c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("woof"), FileName: "dog.txt", Anonymous: true})
+	assert.NoError(t, err)
+
+	server.Inject(pdtest.Fault{Status: http.StatusInternalServerError})
+	_, err = c.UploadPUT(&pd.RequestUpload{PathToFile: cat, FileName: "cat.txt", Anonymous: true})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"start cat.txt", "complete cat.txt " + rsp.ID,
+		"start copy.txt", "duplicate copy.txt of " + rsp.ID,
+		"start dog.txt", "complete dog.txt " + putRsp.ID,
+		"start cat.txt", "error cat.txt",
+	}, events)
+}
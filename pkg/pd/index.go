@@ -0,0 +1,171 @@
+package pd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexEntry is a line of the index of an uploaded directory
+type IndexEntry struct {
+	Path         string // path relative to the uploaded directory, with forward slashes
+	URL          string
+	ThumbnailURL string // set for images
+}
+
+// IndexList is a list created by the upload of a directory
+type IndexList struct {
+	Title string
+	URL   string
+}
+
+// Index returns the uploaded files, skipped duplicates with a known URL included, and the created lists sorted by
+// title after the list of the whole directory. Failed files are left out.
+func (rsp *ResponseUploadDirectory) Index() ([]IndexEntry, []IndexList) {
+	var entries []IndexEntry
+	for _, file := range rsp.Files {
+		id, url := file.ID, file.URL
+		if file.Status == BatchSkippedDuplicate && file.Duplicate != nil {
+			id, url = file.Duplicate.ID, file.Duplicate.URL
+			if url == "" && id != "" {
+				url = fileURL(id)
+			}
+		}
+		if url == "" {
+			continue
+		}
+
+		entry := IndexEntry{Path: rsp.relativePath(file.Path), URL: url}
+		if id != "" && strings.HasPrefix(mime.TypeByExtension(filepath.Ext(file.Path)), "image/") {
+			entry.ThumbnailURL = thumbnailURL(id)
+		}
+		entries = append(entries, entry)
+	}
+
+	var lists []IndexList
+	for title, url := range rsp.ListURLs {
+		lists = append(lists, IndexList{Title: title, URL: url})
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Title < lists[j].Title })
+	// the list of the whole directory comes first
+	if rsp.ListURL != "" {
+		lists = append([]IndexList{{Title: rsp.indexTitle(), URL: rsp.ListURL}}, lists...)
+	}
+
+	return entries, lists
+}
+
+// relativePath returns the path of the file inside of the uploaded directory
+func (rsp *ResponseUploadDirectory) relativePath(path string) string {
+	if rsp.Directory != "" {
+		if rel, err := filepath.Rel(rsp.Directory, path); err == nil {
+			path = rel
+		}
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// indexTitle is the heading of the index, the name of the uploaded directory
+func (rsp *ResponseUploadDirectory) indexTitle() string {
+	if rsp.Directory == "" {
+		return "Uploaded files"
+	}
+
+	return filepath.Base(rsp.Directory)
+}
+
+// markdownEscaper escapes the characters of a path which would break a table cell or a link text
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`, "`", "\\`")
+
+// WriteMarkdownIndex writes the index as markdown table, images are shown with their thumbnail, ready to be pasted
+// into a forum or a wiki
+func (rsp *ResponseUploadDirectory) WriteMarkdownIndex(w io.Writer) error {
+	entries, lists := rsp.Index()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscaper.Replace(rsp.indexTitle()))
+	if len(entries) > 0 {
+		b.WriteString("| File | Link | Preview |\n| --- | --- | --- |\n")
+		for _, e := range entries {
+			preview := ""
+			if e.ThumbnailURL != "" {
+				preview = fmt.Sprintf("[![%s](%s)](%s)", markdownEscaper.Replace(filepath.Base(e.Path)), e.ThumbnailURL, e.URL)
+			}
+			fmt.Fprintf(&b, "| %s | <%s> | %s |\n", markdownEscaper.Replace(e.Path), e.URL, preview)
+		}
+	}
+	if len(lists) > 0 {
+		b.WriteString("\n## Lists\n\n")
+		for _, l := range lists {
+			fmt.Fprintf(&b, "- [%s](%s)\n", markdownEscaper.Replace(l.Title), l.URL)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Entries}}
+<table>
+<tr><th>File</th><th>Link</th><th>Preview</th></tr>
+{{- range .Entries}}
+<tr><td>{{.Path}}</td><td><a href="{{.URL}}">{{.URL}}</a></td><td>{{if .ThumbnailURL}}<a href="{{.URL}}"><img src="{{.ThumbnailURL}}" alt="{{.Path}}"></a>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Lists}}
+<h2>Lists</h2>
+<ul>
+{{- range .Lists}}
+<li><a href="{{.URL}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTMLIndex writes the index as HTML page with a table of the files, images are shown with their thumbnail
+func (rsp *ResponseUploadDirectory) WriteHTMLIndex(w io.Writer) error {
+	entries, lists := rsp.Index()
+
+	return htmlIndexTemplate.Execute(w, struct {
+		Title   string
+		Entries []IndexEntry
+		Lists   []IndexList
+	}{rsp.indexTitle(), entries, lists})
+}
+
+// SaveIndex writes the index to the file, as HTML for a ".html" or ".htm" extension and as markdown otherwise
+func (rsp *ResponseUploadDirectory) SaveIndex(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		err = rsp.WriteHTMLIndex(file)
+	default:
+		err = rsp.WriteMarkdownIndex(file)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package pd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestUploadDirectory_Index writes the uploaded files with their URL and the thumbnails of images
+func TestUploadDirectory_Index(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, HashStore: utils.NewMemoryHashStore()})
+
+	dir := filepath.Join(t.TempDir(), "holiday")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "photos"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "photos", "cat.jpg"), []byte("meow"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes|draft.txt"), []byte("notes"), 0644))
+
+	indexPath := filepath.Join(t.TempDir(), "index.md")
+	rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir, IndexPath: indexPath})
+	assert.NoError(t, err)
+
+	ids := map[string]string{}
+	for _, file := range rsp.Files {
+		ids[filepath.Base(file.Path)] = file.ID
+	}
+	data, err := os.ReadFile(indexPath)
+	assert.NoError(t, err)
+	markdown := string(data)
+	assert.True(t, strings.HasPrefix(markdown, "# holiday\n"))
+	assert.Contains(t, markdown, "| notes\\|draft.txt | <"+pd.BaseURL+"u/"+ids["notes|draft.txt"]+"> |  |\n")
+	assert.Contains(t, markdown, "| photos/cat.jpg | <"+pd.BaseURL+"u/"+ids["cat.jpg"]+"> | [![cat.jpg]("+
+		pd.APIURL+"/file/"+ids["cat.jpg"]+"/thumbnail)]("+pd.BaseURL+"u/"+ids["cat.jpg"]+") |\n")
+
+	htmlPath := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, rsp.SaveIndex(htmlPath))
+	data, err = os.ReadFile(htmlPath)
+	assert.NoError(t, err)
+	page := string(data)
+	assert.Contains(t, page, "<h1>holiday</h1>")
+	assert.Contains(t, page, `<img src="`+pd.APIURL+"/file/"+ids["cat.jpg"]+`/thumbnail" alt="photos/cat.jpg">`)
+	assert.Contains(t, page, "<td>notes|draft.txt</td>")
+}
+
+// TestResponseUploadDirectory_Index leaves out failed files and links duplicates to their original
+func TestResponseUploadDirectory_Index(t *testing.T) {
+	rsp := &pd.ResponseUploadDirectory{
+		Directory: "/data",
+		Files: []pd.BatchFileResult{
+			{Path: "/data/a.png", Status: pd.BatchSkippedDuplicate, Duplicate: &pd.DuplicateMatch{ID: "abc"}},
+			{Path: "/data/b.txt", Status: pd.BatchFailed, Error: "boom"},
+		},
+		ListURLs: map[string]string{"zebra": "https://pixeldrain.com/l/z", "alpaca": "https://pixeldrain.com/l/a"},
+	}
+
+	entries, lists := rsp.Index()
+	assert.Equal(t, []pd.IndexEntry{{Path: "a.png", URL: pd.BaseURL + "u/abc", ThumbnailURL: pd.APIURL + "/file/abc/thumbnail"}}, entries)
+	assert.Equal(t, []pd.IndexList{{Title: "alpaca", URL: "https://pixeldrain.com/l/a"}, {Title: "zebra", URL: "https://pixeldrain.com/l/z"}}, lists)
+}
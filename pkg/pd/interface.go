@@ -0,0 +1,89 @@
+package pd
+
+import (
+	"context"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// PixelDrainAPI is implemented by PixelDrainClient. Depend on it instead of the client to replace pixeldrain in
+// unit tests with a mock, e.g. generated with
+//
+//	mockgen -destination=mock_pd.go -package=mocks github.com/itsDarianNgo/go-pd/pkg/pd PixelDrainAPI
+//
+// The iterators of Go 1.23 are in PixelDrainIterators.
+type PixelDrainAPI interface {
+	// files
+	UploadPOST(r *RequestUpload, hashFilePath string) (*ResponseUpload, error)
+	UploadPUT(r *RequestUpload) (*ResponseUpload, error)
+	UploadStream(r *RequestUploadStream) (*ResponseUpload, error)
+	UploadFromURL(r *RequestUploadFromURL) (*ResponseUpload, error)
+	UploadDirectory(r *RequestUploadDirectory) (*ResponseUploadDirectory, error)
+	UploadDirectoryAsArchive(r *RequestUploadArchive) (*ResponseUpload, error)
+	UploadBatch(ctx context.Context, r *RequestUploadBatch) (*ResponseUploadBatch, error)
+	UploadChanged(r *RequestUploadChanged) (*ResponseUploadChanged, error)
+	Download(r *RequestDownload) (*ResponseDownload, error)
+	DownloadDirectory(r *RequestDownloadDirectory) (*ResponseDownloadDirectory, error)
+	RestoreFromManifest(r *RequestRestoreManifest) (*ResponseDownloadDirectory, error)
+	GetFileInfo(r *RequestFileInfo) (*ResponseFileInfo, error)
+	GetFileInfoMany(r *RequestFileInfoMany) (map[string]*ResponseFileInfo, error)
+	DownloadThumbnail(r *RequestThumbnail) (*ResponseThumbnail, error)
+	GetThumbnailBytes(r *RequestThumbnail) (*ResponseThumbnailBytes, error)
+	Delete(r *RequestDelete) (*ResponseDelete, error)
+	DeleteMany(r *RequestDeleteMany) (*ResponseDeleteMany, error)
+	UndoDelete(id string) (bool, error)
+	Tombstones() ([]Tombstone, error)
+	ExecuteDueDeletes(auth Auth) ([]string, error)
+	RunDeleteScheduler(ctx context.Context, interval time.Duration, auth Auth) error
+	Watch(ctx context.Context, r *RequestWatch) error
+	OpenRemote(r *RequestRemoteFile) (*RemoteFile, error)
+	ListZipContents(r *RequestRemoteFile) ([]ZipEntry, error)
+	ExtractFromRemoteZip(r *RequestExtractZip) (*ResponseExtractZip, error)
+	VerifyLog(r *RequestVerifyLog) (*ResponseVerifyLog, error)
+
+	// lists
+	CreateList(r *RequestCreateList) (*ResponseCreateList, error)
+	GetList(r *RequestGetList) (*ResponseGetList, error)
+	UpdateList(r *RequestUpdateList) (*ResponseUpdateList, error)
+	DeleteList(r *RequestDeleteList) (*ResponseDeleteList, error)
+	Sync(r *RequestSync) (*ResponseSync, error)
+	UploadToList(r *RequestUploadToList) (*ResponseUploadToList, error)
+
+	// user
+	GetUser(r *RequestGetUser) (*ResponseGetUser, error)
+	GetUserFiles(r *RequestGetUserFiles) (*ResponseGetUserFiles, error)
+	GetUserLists(r *RequestGetUserLists) (*ResponseGetUserLists, error)
+	GetUserActivity(r *RequestGetUserActivity) (*ResponseGetUserActivity, error)
+	GetUserTransactions(r *RequestGetUserTransactions) (*ResponseGetUserTransactions, error)
+	SweepAccount(r *RequestSweepAccount) (*ResponseSweepAccount, error)
+	PurgeAccount(r *RequestPurgeAccount) (*ResponsePurgeAccount, error)
+
+	// filesystem
+	FS() *Filesystem
+
+	// client
+	Capabilities(ctx context.Context, r *RequestCapabilities) (*Capabilities, error)
+	PlanBatch(r *RequestPlanBatch) (*BatchPlan, error)
+	EnforcePlan(plan *BatchPlan)
+	BandwidthUsage() (utils.BandwidthUsage, error)
+	RateLimit() *RateLimitInfo
+	Version() string
+	UserAgent() string
+}
+
+// FilesystemAPI is implemented by Filesystem, the sub-API returned by FS
+type FilesystemAPI interface {
+	Rename(r *RequestFSRename) (*ResponseFSRename, error)
+	Move(r *RequestFSMove) (*ResponseFSRename, error)
+	CreateBucket(r *RequestFSCreateBucket) (*ResponseFSNode, error)
+	Mkdir(r *RequestFSMkdir) (*ResponseFSNode, error)
+	Upload(r *RequestFSUpload) (*ResponseUpload, error)
+	List(r *RequestFSList) (*ResponseFSList, error)
+	Delete(r *RequestFSDelete) (*ResponseDefault, error)
+}
+
+var (
+	_ PixelDrainAPI = (*PixelDrainClient)(nil)
+	_ FilesystemAPI = (*Filesystem)(nil)
+)
@@ -0,0 +1,44 @@
+package pd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// fakeUserFiles replaces pixeldrain in a consumer test, the methods it doesn't override panic
+type fakeUserFiles struct {
+	pd.PixelDrainAPI
+	files []pd.FileGetUser
+}
+
+func (f *fakeUserFiles) GetUserFiles(*pd.RequestGetUserFiles) (*pd.ResponseGetUserFiles, error) {
+	return &pd.ResponseGetUserFiles{Files: f.files}, nil
+}
+
+// accountSize is code of a consumer which depends on the interface instead of the client
+func accountSize(api pd.PixelDrainAPI) (int64, error) {
+	rsp, err := api.GetUserFiles(&pd.RequestGetUserFiles{})
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, file := range rsp.Files {
+		size += file.Size
+	}
+
+	return size, nil
+}
+
+// TestPD_PixelDrainAPI the client and a fake are interchangeable for the consumer
+func TestPD_PixelDrainAPI(t *testing.T) {
+	var api pd.PixelDrainAPI = pd.New(nil, nil)
+	assert.NotNil(t, api.FS())
+
+	size, err := accountSize(&fakeUserFiles{files: []pd.FileGetUser{{Size: 10}, {Size: 32}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), size)
+}
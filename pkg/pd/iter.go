@@ -0,0 +1,113 @@
+//go:build go1.23
+
+package pd
+
+import (
+	"context"
+	"iter"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// Files iterates the files of the user account from r.Page on. All files are fetched in one request when the
+// iteration starts, as pixeldrain doesn't page them. A failed request ends the iteration with the error.
+func (pd *PixelDrainClient) Files(ctx context.Context, r *RequestGetUserFiles) iter.Seq2[FileGetUser, error] {
+	return func(yield func(FileGetUser, error) bool) {
+		all := *r
+		all.Page, all.Limit = 0, 0
+		rsp, err := pd.GetUserFiles(&all)
+		if err != nil {
+			yield(FileGetUser{}, err)
+			return
+		}
+
+		files := rsp.Files
+		if r.Limit > 0 && r.Page > 0 {
+			files = files[min(r.Page*r.Limit, len(files)):]
+		}
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				yield(FileGetUser{}, err)
+				return
+			}
+			if !yield(file, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Lists iterates the lists of the user account. All lists are fetched in one request when the iteration starts.
+func (pd *PixelDrainClient) Lists(ctx context.Context, r *RequestGetUserLists) iter.Seq2[ListsGetUser, error] {
+	return func(yield func(ListsGetUser, error) bool) {
+		rsp, err := pd.GetUserLists(r)
+		if err != nil {
+			yield(ListsGetUser{}, err)
+			return
+		}
+
+		for _, list := range rsp.Lists {
+			if err := ctx.Err(); err != nil {
+				yield(ListsGetUser{}, err)
+				return
+			}
+			if !yield(list, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ListFiles iterates the files of a list. All files are fetched in one request when the iteration starts.
+func (pd *PixelDrainClient) ListFiles(ctx context.Context, r *RequestGetList) iter.Seq2[FileGetList, error] {
+	return func(yield func(FileGetList, error) bool) {
+		rsp, err := pd.GetList(r)
+		if err != nil {
+			yield(FileGetList{}, err)
+			return
+		}
+
+		for _, file := range rsp.Files {
+			if err := ctx.Err(); err != nil {
+				yield(FileGetList{}, err)
+				return
+			}
+			if !yield(file, nil) {
+				return
+			}
+		}
+	}
+}
+
+// History iterates the upload log, CSVFilePath if the path is empty. The log is read row by row.
+func (pd *PixelDrainClient) History(ctx context.Context, path string) iter.Seq2[utils.UploadInfo, error] {
+	if path == "" {
+		path = CSVFilePath
+	}
+
+	return func(yield func(utils.UploadInfo, error) bool) {
+		err := utils.EachUploadInfo(path, func(info utils.UploadInfo) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !yield(info, nil) {
+				return utils.ErrStopIteration
+			}
+			return nil
+		})
+		if err != nil {
+			yield(utils.UploadInfo{}, err)
+		}
+	}
+}
+
+// PixelDrainIterators is implemented by PixelDrainClient, the iterators next to PixelDrainAPI. Like the iterators
+// it needs Go 1.23, the rest of the package builds with the Go version of go.mod.
+type PixelDrainIterators interface {
+	Files(ctx context.Context, r *RequestGetUserFiles) iter.Seq2[FileGetUser, error]
+	Lists(ctx context.Context, r *RequestGetUserLists) iter.Seq2[ListsGetUser, error]
+	ListFiles(ctx context.Context, r *RequestGetList) iter.Seq2[FileGetList, error]
+	History(ctx context.Context, path string) iter.Seq2[utils.UploadInfo, error]
+}
+
+var _ PixelDrainIterators = (*PixelDrainClient)(nil)
@@ -0,0 +1,107 @@
+//go:build go1.23
+
+package pd_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Iterators(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	ctx := context.Background()
+
+	var files []string
+	for file, err := range c.Files(ctx, &pd.RequestGetUserFiles{URL: server.URL + "/user/files"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file.ID)
+	}
+	assert.Equal(t, []string{"tUxgDCoQ"}, files)
+
+	var lists []string
+	for list, err := range c.Lists(ctx, &pd.RequestGetUserLists{URL: server.URL + "/user/lists"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		lists = append(lists, list.ID)
+	}
+	assert.Equal(t, []string{"Cap4T1LP", "fiEm5arj"}, lists)
+
+	// breaking out of the loop stops the iteration
+	var listFiles []string
+	for file, err := range c.ListFiles(ctx, &pd.RequestGetList{ID: "123", URL: server.URL + "/list/123"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		listFiles = append(listFiles, file.ID)
+		break
+	}
+	assert.Equal(t, []string{"_SqVWi"}, listFiles)
+}
+
+func TestPD_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload_logs.csv")
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := utils.SaveUploadInfoToCSV(utils.UploadInfo{FileName: name, FileSize: 42}, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := pd.New(nil, nil)
+	var names []string
+	for info, err := range c.History(context.Background(), path) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, info.FileName)
+		assert.Equal(t, int64(42), info.FileSize)
+	}
+	assert.Equal(t, []string{"a.jpg", "b.jpg"}, names)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range c.History(ctx, path) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestPD_Files_Pages(t *testing.T) {
+	var requests int32
+	server := userFilesServer(testUserFiles(), &requests)
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	r := &pd.RequestGetUserFiles{URL: server.URL, Limit: 2, Sort: pd.SortByName, Filter: &pd.FileFilter{MimeType: "image/*"}}
+
+	var files []string
+	for file, err := range c.Files(context.Background(), r) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file.ID)
+	}
+
+	assert.Equal(t, []string{"id-a", "id-b", "id-e"}, files)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "the files are fetched once")
+
+	r.Page = 1
+	files = nil
+	for file, err := range c.Files(context.Background(), r) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file.ID)
+	}
+	assert.Equal(t, []string{"id-e"}, files)
+}
@@ -0,0 +1,84 @@
+package pd
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// ListGrouping creates lists for the files of a directory upload which no UploadRule assigns to a list
+type ListGrouping int
+
+const (
+	GroupNone           ListGrouping = iota // only the lists of the upload rules are created
+	GroupByMimeCategory                     // one list per MIME category, e.g. "Images", "Videos" and "Documents"
+	GroupBySubdirectory                     // one list per subdirectory, files at the top go into a list named after the directory
+)
+
+// titles of the lists created by GroupByMimeCategory
+const (
+	CategoryImages    = "Images"
+	CategoryVideos    = "Videos"
+	CategoryAudio     = "Audio"
+	CategoryDocuments = "Documents"
+	CategoryArchives  = "Archives"
+	CategoryOther     = "Other"
+)
+
+// documentExtensions are office and text formats, the sniffed type of office files is only "application/zip"
+var documentExtensions = map[string]bool{
+	".pdf": true, ".txt": true, ".md": true, ".rtf": true, ".csv": true, ".epub": true,
+	".doc": true, ".docx": true, ".odt": true, ".xls": true, ".xlsx": true, ".ods": true,
+	".ppt": true, ".pptx": true, ".odp": true,
+}
+
+var archiveExtensions = map[string]bool{
+	".zip": true, ".tar": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".7z": true, ".rar": true, ".zst": true,
+}
+
+// MimeCategory returns the list title of GroupByMimeCategory for a file, the extension is used before the detected MIME type
+func MimeCategory(filePath, mimeType string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	switch {
+	case documentExtensions[ext]:
+		return CategoryDocuments
+	case archiveExtensions[ext]:
+		return CategoryArchives
+	}
+
+	if byExt := mime.TypeByExtension(ext); byExt != "" {
+		mimeType = byExt
+	}
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return CategoryImages
+	case strings.HasPrefix(mimeType, "video/"):
+		return CategoryVideos
+	case strings.HasPrefix(mimeType, "audio/"):
+		return CategoryAudio
+	case strings.HasPrefix(mimeType, "text/"), mimeType == "application/pdf":
+		return CategoryDocuments
+	case mimeType == "application/zip", mimeType == "application/x-gzip":
+		return CategoryArchives
+	}
+
+	return CategoryOther
+}
+
+// listTitle returns the title of the list the file is grouped into, empty for GroupNone
+func (g ListGrouping) listTitle(directory, filePath, mimeType string) string {
+	switch g {
+	case GroupByMimeCategory:
+		return MimeCategory(filePath, mimeType)
+	case GroupBySubdirectory:
+		rel, err := filepath.Rel(directory, filepath.Dir(filePath))
+		if err != nil || rel == "." {
+			return filepath.Base(filepath.Clean(directory))
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMimeCategory(t *testing.T) {
+	tests := []struct {
+		path, mimeType, category string
+	}{
+		{"cat.jpg", "", pd.CategoryImages},
+		{"scan", "image/png", pd.CategoryImages},
+		{"report.docx", "application/zip", pd.CategoryDocuments},
+		{"notes", "text/plain; charset=utf-8", pd.CategoryDocuments},
+		{"backup.tar.gz", "application/x-gzip", pd.CategoryArchives},
+		{"song", "audio/mpeg", pd.CategoryAudio},
+		{"clip", "video/webm", pd.CategoryVideos},
+		{"data.bin", "application/octet-stream", pd.CategoryOther},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.category, pd.MimeCategory(tt.path, tt.mimeType), tt.path)
+	}
+}
+
+// listServer accepts uploads and records the number of files of every created list by title
+func listServer(lists map[string]int) *httptest.Server {
+	var mu sync.Mutex
+	uploads := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/list" {
+			var body pd.RequestCreateList
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			lists[body.Title] = len(body.Files)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"success": true, "id": "list-%d"}`, len(lists))
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, r.Body)
+		uploads++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"success": true, "id": "file-%d"}`, uploads)
+	}))
+}
+
+func TestUploadDirectory_GroupBy(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"top.png":           "top image",
+		"photos/a.png":      "image a",
+		"photos/b.jpg":      "image b",
+		"docs/report.pdf":   "report",
+		"docs/old/note.txt": "note",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, "share", name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	tests := []struct {
+		groupBy pd.ListGrouping
+		lists   map[string]int
+	}{
+		{pd.GroupByMimeCategory, map[string]int{pd.CategoryImages: 3, pd.CategoryDocuments: 2}},
+		{pd.GroupBySubdirectory, map[string]int{"share": 1, "photos": 2, "docs": 1, "docs/old": 1}},
+	}
+
+	for _, tt := range tests {
+		lists := map[string]int{}
+		server := listServer(lists)
+
+		client := pd.New(&pd.ClientOptions{HashNamespace: "lists"}, nil)
+		rsp, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+			Directory:    filepath.Join(dir, "share"),
+			Auth:         pd.Auth{APIKey: "test-api-key"},
+			URL:          server.URL,
+			HashFilePath: filepath.Join(t.TempDir(), "hashes.csv"),
+			GroupBy:      tt.groupBy,
+		})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, tt.lists, lists)
+		assert.Len(t, rsp.ListURLs, len(tt.lists))
+		for title, id := range rsp.Lists {
+			assert.Equal(t, pd.BaseURL+"l/"+id, rsp.ListURLs[title])
+		}
+	}
+}
+
+// TestUploadDirectory_CreateList adds all files of the directory to one list
+func TestUploadDirectory_CreateList(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "share")
+	for _, name := range []string{"top.png", "photos/a.png", "docs/report.pdf"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	for _, title := range []string{"", "Holiday 2024"} {
+		lists := map[string]int{}
+		server := listServer(lists)
+
+		client := pd.New(&pd.ClientOptions{HashNamespace: "lists"}, nil)
+		rsp, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+			Directory:    dir,
+			Auth:         pd.Auth{APIKey: "test-api-key"},
+			URL:          server.URL,
+			HashFilePath: filepath.Join(t.TempDir(), "hashes.csv"),
+			GroupBy:      pd.GroupByMimeCategory,
+			CreateList:   true,
+			ListTitle:    title,
+		})
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if title == "" {
+			title = "share"
+		}
+		assert.Equal(t, map[string]int{title: 3, pd.CategoryImages: 2, pd.CategoryDocuments: 1}, lists)
+		assert.Equal(t, pd.BaseURL+"l/"+rsp.ListID, rsp.ListURL)
+		assert.NotContains(t, rsp.Lists, title)
+	}
+}
@@ -0,0 +1,124 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ErrMissingListID is returned by list requests without an ID
+const ErrMissingListID = "list id is required"
+
+// UpdateList PUT /api/list/{id} renames the list and adds or removes files. pixeldrain replaces the title and
+// all files of the list with the request, so the current list is fetched first unless the title and Files are set.
+func (pd *PixelDrainClient) UpdateList(r *RequestUpdateList) (*ResponseUpdateList, error) {
+	if r.ID == "" {
+		return nil, errors.New(ErrMissingListID)
+	}
+
+	if r.URL == "" {
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.List+"/%s", r.ID)
+	}
+
+	title, files := r.Title, r.Files
+	if title == "" || files == nil {
+		list, err := pd.GetList(&RequestGetList{ID: r.ID, Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		if list.StatusCode != http.StatusOK {
+			return nil, &APIError{StatusCode: list.StatusCode, Value: list.Value, Message: list.Message}
+		}
+
+		if title == "" {
+			title = list.Title
+		}
+		if files == nil {
+			files = make([]ListFile, 0, len(list.Files))
+			for _, file := range list.Files {
+				files = append(files, ListFile{ID: file.ID, Description: file.Description})
+			}
+		}
+	}
+
+	remove := make(map[string]bool, len(r.RemoveFiles))
+	for _, id := range r.RemoveFiles {
+		remove[id] = true
+	}
+	kept := make([]ListFile, 0, len(files)+len(r.AddFiles))
+	for _, file := range append(files, r.AddFiles...) {
+		if !remove[file.ID] {
+			kept = append(kept, file)
+		}
+	}
+
+	rspDefault, err := pd.putList(r.URL, map[string]interface{}{"title": title, "files": kept}, r.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResponseUpdateList{
+		ID:              r.ID,
+		Title:           title,
+		Files:           kept,
+		ResponseDefault: *rspDefault,
+	}, nil
+}
+
+// DeleteList DELETE /api/list/{id}, the files of the list are kept
+func (pd *PixelDrainClient) DeleteList(r *RequestDeleteList) (*ResponseDeleteList, error) {
+	if r.ID == "" {
+		return nil, errors.New(ErrMissingListID)
+	}
+
+	if r.URL == "" {
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.List+"/%s", r.ID)
+	}
+
+	rsp, err := pd.request(context.Background(), http.MethodDelete, r.URL, pd.header(r.Auth), nil)
+	if pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rspStruct := &ResponseDeleteList{}
+	err = rsp.decodeJSON(rspStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	rspStruct.StatusCode = rsp.StatusCode
+
+	return rspStruct, nil
+}
+
+// putList sends the JSON body to PUT /api/list/{id}
+func (pd *PixelDrainClient) putList(url string, body interface{}, auth Auth) (*ResponseDefault, error) {
+	header := pd.header(auth)
+	data, err := jsonBody(header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := pd.request(context.Background(), http.MethodPut, url, header, data)
+	if pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rspStruct := &ResponseDefault{}
+	err = rsp.decodeJSON(rspStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	rspStruct.StatusCode = rsp.StatusCode
+
+	return rspStruct, nil
+}
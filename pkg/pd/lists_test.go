@@ -0,0 +1,88 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_UpdateList(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	// the title and the files of the list are kept, only the changes are applied
+	rsp, err := c.UpdateList(&pd.RequestUpdateList{
+		ID:          "123",
+		AddFiles:    []pd.ListFile{{ID: "K1dA8U5W", Description: "cat"}},
+		RemoveFiles: []string{"_SqVWi"},
+		Auth:        pd.Auth{APIKey: "test-api-key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, rsp.Success)
+	assert.Equal(t, "Rust in Peace", rsp.Title)
+	assert.Equal(t, []pd.ListFile{{ID: "RKwgZb"}, {ID: "K1dA8U5W", Description: "cat"}}, rsp.Files)
+
+	_, err = c.UpdateList(&pd.RequestUpdateList{})
+	assert.EqualError(t, err, pd.ErrMissingListID)
+}
+
+func TestPD_UpdateList_Body(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// title and files are set, the list is not fetched
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/list/abc", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	rsp, err := c.UpdateList(&pd.RequestUpdateList{
+		ID:    "abc",
+		Title: "renamed",
+		Files: []pd.ListFile{{ID: "a"}, {ID: "b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, "renamed", body["title"])
+	assert.Len(t, body["files"], 2)
+}
+
+func TestPD_DeleteList(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	rsp, err := c.DeleteList(&pd.RequestDeleteList{ID: "123", Auth: pd.Auth{APIKey: "test-api-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+	assert.Equal(t, true, rsp.Success)
+	assert.Equal(t, "list_deleted", rsp.Value)
+
+	_, err = c.DeleteList(&pd.RequestDeleteList{})
+	assert.EqualError(t, err, pd.ErrMissingListID)
+}
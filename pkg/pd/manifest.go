@@ -0,0 +1,178 @@
+package pd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// ManifestVersion is the format of the manifests written by UploadDirectory
+const ManifestVersion = 1
+
+// ErrMissingManifestPath is returned by RestoreFromManifest without a manifest
+const ErrMissingManifestPath = "manifest path is required"
+
+// Manifest describes an uploaded directory tree, RestoreFromManifest downloads it again
+type Manifest struct {
+	Version   int             `json:"version"`
+	Directory string          `json:"directory"` // name of the uploaded directory
+	Created   time.Time       `json:"created"`
+	Files     []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry is an uploaded file, or a skipped duplicate whose original is known
+type ManifestEntry struct {
+	Path    string    `json:"path"` // relative to the directory, with forward slashes
+	ID      string    `json:"id"`
+	Hash    string    `json:"hash"` // SHA-256 of the local file
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// LoadManifest reads a manifest written by UploadDirectory
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.New("invalid manifest " + path + ": " + err.Error())
+	}
+	if m.Version > ManifestVersion {
+		return nil, fmt.Errorf("manifest %s has version %d, only %d is supported", path, m.Version, ManifestVersion)
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest as indented JSON
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// add records the file of the directory, the size and modification time are read before the upload, as the file
+// may be moved or deleted after it
+func (m *Manifest) add(directory string, info os.FileInfo, filePath string, rsp *ResponseUpload) {
+	if m == nil || rsp == nil || rsp.ID == "" {
+		return
+	}
+
+	rel, err := filepath.Rel(directory, filePath)
+	if err != nil {
+		return
+	}
+	hash := rsp.Hash
+	if hash == "" && rsp.Duplicate != nil {
+		hash = rsp.Duplicate.Hash
+	}
+
+	m.Files = append(m.Files, ManifestEntry{
+		Path:    filepath.ToSlash(rel),
+		ID:      rsp.ID,
+		Hash:    hash,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+}
+
+// RestoreFromManifest downloads the files of the manifest into the directory at their paths and restores their
+// modification times. Files which already exist with the hash of the manifest are skipped, a downloaded file with
+// another hash fails with *ChecksumMismatchError. Paths which would leave the directory fail as well. The restore
+// stops at the first failed file unless ContinueOnError is set.
+func (pd *PixelDrainClient) RestoreFromManifest(r *RequestRestoreManifest) (*ResponseDownloadDirectory, error) {
+	if r.ManifestPath == "" {
+		return nil, errors.New(ErrMissingManifestPath)
+	}
+	if r.Directory == "" {
+		return nil, errors.New(ErrMissingDirectory)
+	}
+
+	manifest, err := LoadManifest(r.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResponseDownloadDirectory{}
+	for _, entry := range manifest.Files {
+		target := filepath.Join(r.Directory, filepath.FromSlash(entry.Path))
+		fileResult := BatchFileResult{Path: target, ID: entry.ID, URL: fileURL(entry.ID), Hash: entry.Hash}
+
+		err := pd.restoreFile(r, entry, target)
+		switch {
+		case errors.Is(err, errUpToDate):
+			fileResult.Status = BatchSkippedDuplicate
+			err = nil
+		case err != nil:
+			log.Printf("Error restoring file %s to %s: %v", entry.ID, target, err)
+			fileResult.Status, fileResult.Error = BatchFailed, err.Error()
+		default:
+			fileResult.Status = BatchCompleted
+		}
+		result.Files = append(result.Files, fileResult)
+
+		if err != nil && !r.ContinueOnError {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// restoreFile downloads the file of the entry to the target unless the target has the hash of the manifest
+func (pd *PixelDrainClient) restoreFile(r *RequestRestoreManifest, entry ManifestEntry, target string) error {
+	if !filepath.IsLocal(filepath.FromSlash(entry.Path)) {
+		return fmt.Errorf("path %s of the manifest is outside of the directory", entry.Path)
+	}
+
+	if _, err := os.Stat(target); err == nil && entry.Hash != "" {
+		local, err := pd.calculateFileHash(target)
+		if err != nil {
+			return err
+		}
+		if local == entry.Hash {
+			return errUpToDate
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	log.Printf("Restoring file %s to %s", entry.ID, target)
+	rsp, err := pd.Download(&RequestDownload{ID: entry.ID, PathToSave: target, Auth: r.Auth, Key: r.Key})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	if entry.Hash != "" {
+		local, err := pd.calculateFileHash(target)
+		if err != nil {
+			return err
+		}
+		if local != entry.Hash {
+			return &ChecksumMismatchError{Direction: utils.Download, ID: entry.ID, Path: target, Expected: entry.Hash, Actual: local}
+		}
+	}
+
+	if !entry.ModTime.IsZero() {
+		return os.Chtimes(target, entry.ModTime, entry.ModTime)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package pd_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_RestoreFromManifest uploads a tree with a manifest and restores it with the modification times
+func TestPD_RestoreFromManifest(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, HashStore: utils.NewMemoryHashStore()})
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "tree")
+	mtime := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, name := range []string{"top.txt", "sub/dir/file.txt"} {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		assert.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	_, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: source, ManifestPath: manifestPath})
+	assert.NoError(t, err)
+
+	manifest, err := pd.LoadManifest(manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, pd.ManifestVersion, manifest.Version)
+	assert.Equal(t, "tree", manifest.Directory)
+	assert.Len(t, manifest.Files, 2)
+	for _, entry := range manifest.Files {
+		assert.NotEmpty(t, entry.ID)
+		assert.Len(t, entry.Hash, 64)
+		assert.Equal(t, int64(len(entry.Path)), entry.Size)
+		assert.True(t, mtime.Equal(entry.ModTime))
+	}
+
+	target := filepath.Join(dir, "restored")
+	r := &pd.RequestRestoreManifest{ManifestPath: manifestPath, Directory: target}
+	rsp, err := c.RestoreFromManifest(r)
+	assert.NoError(t, err)
+	for _, file := range rsp.Files {
+		assert.Equal(t, pd.BatchCompleted, file.Status)
+	}
+	for _, name := range []string{"top.txt", "sub/dir/file.txt"} {
+		path := filepath.Join(target, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, name, string(data))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.True(t, mtime.Equal(info.ModTime()))
+	}
+
+	// restored files are skipped, a file with another hash than the manifest fails
+	manifest.Files[0].Hash = "0000"
+	manifest.Files = append(manifest.Files, pd.ManifestEntry{Path: "../escape.txt", ID: manifest.Files[1].ID})
+	assert.NoError(t, manifest.Save(manifestPath))
+	assert.NoError(t, os.Remove(filepath.Join(target, filepath.FromSlash(manifest.Files[0].Path))))
+	r.ContinueOnError = true
+	rsp, err = c.RestoreFromManifest(r)
+	assert.NoError(t, err)
+	assert.Equal(t, pd.BatchFailed, rsp.Files[0].Status)
+	assert.Equal(t, pd.BatchSkippedDuplicate, rsp.Files[1].Status)
+	assert.Equal(t, pd.BatchFailed, rsp.Files[2].Status)
+
+	r.ContinueOnError = false
+	_, err = c.RestoreFromManifest(r)
+	var mismatch *pd.ChecksumMismatchError
+	assert.True(t, errors.As(err, &mismatch))
+}
@@ -0,0 +1,63 @@
+package pd
+
+import (
+	"net/http"
+)
+
+// RoundTripperFunc is an http.RoundTripper of a function
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls the function
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the sending of every request to the API, e.g. for headers, metrics, tracing or request signing.
+// It is called for every attempt of a retried request. Like an http.RoundTripper it must not change the request
+// it gets, change a clone instead.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// BeforeRequest is a Middleware which calls fn with a clone of every request before it is sent, the clone is sent.
+// An error of fn cancels the request.
+func BeforeRequest(fn func(req *http.Request) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := fn(req); err != nil {
+				if req.Body != nil {
+					_ = req.Body.Close()
+				}
+				return nil, err
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// AfterResponse is a Middleware which calls fn with every request and its response or error once it is received.
+// The body of the response is still unread.
+func AfterResponse(fn func(req *http.Request, rsp *http.Response, err error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			rsp, err := next.RoundTrip(req)
+			fn(req, rsp, err)
+
+			return rsp, err
+		})
+	}
+}
+
+// roundTripper sends the request with the HTTP client through the middleware, the first middleware is the outermost.
+// The instrumentation of the Tracer and Meter wraps all of them.
+func (pd *PixelDrainClient) roundTripper() http.RoundTripper {
+	var rt http.RoundTripper = RoundTripperFunc(pd.Client.HTTPClient.Do)
+	for i := len(pd.Middleware) - 1; i >= 0; i-- {
+		rt = pd.Middleware[i](rt)
+	}
+	if pd.Tracer != nil || pd.Meter != nil {
+		rt = pd.instrument(rt)
+	}
+
+	return rt
+}
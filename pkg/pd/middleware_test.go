@@ -0,0 +1,89 @@
+package pd_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+)
+
+// TestPD_Middleware signs every request, sees every attempt of a retried request and runs in order
+func TestPD_Middleware(t *testing.T) {
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get("X-Signature"))
+		_, _ = w.Write([]byte(`{"success": true, "id": "abc", "name": "cat.jpg"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	var statuses []int
+	wrap := func(name string) pd.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return pd.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, Middleware: []pd.Middleware{
+		wrap("outer"),
+		pd.BeforeRequest(func(req *http.Request) error {
+			req.Header.Set("X-Signature", "signed-"+req.Method)
+			return nil
+		}),
+		pd.AfterResponse(func(req *http.Request, rsp *http.Response, err error) {
+			statuses = append(statuses, rsp.StatusCode)
+		}),
+		wrap("inner"),
+	}}, nil)
+
+	info, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cat.jpg", info.Name)
+	assert.Equal(t, []string{"signed-GET"}, signatures)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, []int{http.StatusOK}, statuses)
+}
+
+// TestPD_Middleware_Retries the hooks see every attempt, an error of BeforeRequest cancels the request
+func TestPD_Middleware_Retries(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	id := server.AddFile("cat.txt", []byte("meow"))
+	server.Inject(pdtest.Fault{Status: http.StatusBadGateway, Times: 1})
+
+	var statuses []int
+	block := false
+	policy := pd.DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, RetryPolicy: &policy, Middleware: []pd.Middleware{
+		pd.BeforeRequest(func(req *http.Request) error {
+			if block {
+				return errors.New("blocked")
+			}
+			return nil
+		}),
+		pd.AfterResponse(func(req *http.Request, rsp *http.Response, err error) {
+			statuses = append(statuses, rsp.StatusCode)
+		}),
+	}})
+
+	_, err := c.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{http.StatusBadGateway, http.StatusOK}, statuses)
+
+	block = true
+	_, err = c.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.ErrorContains(t, err, "blocked")
+	assert.Equal(t, 2, server.Requests())
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 )
 
+// MockFileUploadServer answers with canned responses for the tests of this package.
+// The tests of other packages use the stateful fake of the pdtest package.
 func MockFileUploadServer() *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -52,7 +54,33 @@ func MockFileUploadServer() *httptest.Server {
 				return
 			}
 
+			// ##########################################
+			// POST /filesystem/{path}
+			if strings.HasPrefix(r.URL.EscapedPath(), "/filesystem/") {
+				_ = r.ParseForm()
+				if r.FormValue("action") != "rename" || r.FormValue("target") == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"success": false, "value": "invalid_action"}`))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"success": true}`))
+				return
+			}
+
 		case "PUT":
+			// ##########################################
+			// PUT /list/{id}
+			if strings.HasPrefix(r.URL.EscapedPath(), "/list/") {
+				w.WriteHeader(http.StatusOK)
+				str := `{
+				"success": true
+			}`
+				_, _ = w.Write([]byte(str))
+				return
+			}
+
 			// ##########################################
 			// PUT /file/{name}
 			if !strings.Contains(r.URL.EscapedPath(), "/file/") {
@@ -286,6 +314,18 @@ func MockFileUploadServer() *httptest.Server {
 				_, _ = w.Write([]byte(str))
 			}
 
+			// ##########################################
+			// DELETE /list/{id}
+			if r.URL.EscapedPath() == "/list/123" {
+				w.WriteHeader(http.StatusOK)
+				str := `{
+					"success": true,
+					"value": "list_deleted",
+					"message": "The list has been deleted."
+				}`
+				_, _ = w.Write([]byte(str))
+			}
+
 			return
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -1,31 +1,41 @@
 package pd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/imroc/req"
 	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
 )
 
 const (
 	Name             = "PixelDrain.com"
+	Version          = "1.1.0" // version of this package, send with every request in the User-Agent
 	BaseURL          = "https://pixeldrain.com/"
 	APIURL           = BaseURL + "api"
-	DefaultUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.117 Safari/537.36"
+	DefaultUserAgent = "go-pd/" + Version + " (+https://github.com/itsDarianNgo/go-pd)"
 	// errors
 	ErrMissingPathToFile = "file path or file reader is required"
 	ErrMissingFileID     = "file id is required"
 	ErrMissingFilename   = "if you use ReadCloser you need to specify the filename"
+	ErrChunkedUploads    = "chunked uploads are not supported by pixeldrain, enable ClientOptions.ChunkedUploads for a server which assembles them"
 	CSVFilePath          = "upload_logs.csv" // Path to the CSV file
 )
 
@@ -35,16 +45,116 @@ type ClientOptions struct {
 	EnableCookies     bool
 	EnableInsecureTLS bool
 	Timeout           time.Duration
+	HTTPClient        *http.Client      // custom transport, tracing or pooling, ProxyURL, cookies, TLS and Timeout are ignored if set
+	SourceClient      *http.Client      // fetches the sources of UploadFromURL, verifies TLS whatever EnableInsecureTLS is if nil
+	UploadRules       UploadRules       // per file policies for directory uploads
+	HashStore         utils.HashStore   // duplicate check store, replaces the hashFilePath CSV if set, wrap it with utils.NewSyncHashStore if it is not goroutine safe
+	API               *APISpec          // pin the API URL and endpoint paths, DefaultAPISpec if nil
+	MaxOpenFiles      int               // files opened at the same time for hashing and uploading, 0 = DefaultMaxOpenFiles, -1 = unlimited
+	HashNamespace     string            // fixed namespace for the duplicate check, by default it is separated per account
+	UploadCache       utils.UploadCache // remote IDs of completed uploads, makes re-runs of interrupted batches skip finished files
+	HashCache         utils.HashCache   // hashes of checked files by size and modification time, unchanged files are not hashed again
+	Describer         Describer         // descriptions of the files added to lists by directory and list uploads
+	Bandwidth         *BandwidthBudget  // count the transferred bytes and limit them per day and month
+	RetryPolicy       *RetryPolicy      // retries of transient failures of all requests, DefaultRetryPolicy if nil
+	StateStore        utils.StateStore  // state of batches, chunked uploads and UploadChanged, files at their paths if nil
+	UploadLog         utils.UploadLog   // log of the uploads, the CSV file at CSVFilePath if nil
+	DisableUploadLog  bool              // uploads are not logged, UploadLog is ignored
+	DisableDedup      bool              // no duplicate check, the hashes of uploads are not saved, HashStore and RemoteDedup are ignored
+	RemoteDedup       bool              // the duplicate check also matches the sha256 of the files in the account of the request
+	RemoteDedupTTL    time.Duration     // how long the files listing of RemoteDedup is reused, DefaultRemoteDedupTTL if 0
+	QuarantineDir     string            // local files which fail the Verify of an upload or download are moved here
+	DedupReport       *DedupReport      // collects which local files were deduplicated against which remote files
+	Checkpoint        CheckpointFunc    // called after every confirmed chunk of a chunked upload to persist its progress elsewhere
+	ThumbnailCache    *ThumbnailCache   // thumbnails of GetThumbnailBytes are served from and stored in it
+	ThumbnailSizes    []ThumbnailSize   // square thumbnail sizes which are cached after every image upload, needs a ThumbnailCache
+	OnTimings         TimingsFunc       // receives the phase timings of every upload and download, e.g. for metrics
+	// ChunkedUploads sends PUT uploads larger than RequestUpload.ChunkSize as PUT requests with a Content-Range
+	// header per chunk. pixeldrain has no such protocol and keeps every chunk as a file of its own, so only enable
+	// it for a server or proxy which assembles the chunks. The size of the stored file is checked after the last chunk.
+	ChunkedUploads bool
+	// HashAlgorithm of the duplicate check, utils.HashSHA256 if empty. RemoteDedup only matches SHA-256.
+	HashAlgorithm utils.HashAlgorithm
+	// DeleteGrace records a Delete as tombstone instead of deleting the file, ExecuteDueDeletes or RunDeleteScheduler
+	// delete it after the grace period, UndoDelete cancels it. Protects against scripted mass deletes.
+	DeleteGrace  time.Duration
+	TombstoneKey string // StateStore key of the scheduled deletes, DefaultTombstoneKey if empty
+	// SharedStateDir keeps the 429 pause and the limits of EnforcePlan in files, so all processes using the
+	// directory, e.g. the CLI and a daemon, pause together and share the limits instead of each using them up
+	SharedStateDir string
+	// MaxUploadBytesPerSec and MaxDownloadBytesPerSec throttle all request and response bodies of the client, so
+	// background transfers leave bandwidth for others. They are shared through the SharedStateDir, 0 = unlimited.
+	MaxUploadBytesPerSec   int64
+	MaxDownloadBytesPerSec int64
+	// UploadLogPath and UploadLogFormat choose the file of the upload log if no UploadLog is set, e.g. JSON Lines
+	// for jq or a log shipper. An empty path is CSVFilePath with the extension of the format.
+	UploadLogPath   string
+	UploadLogFormat utils.UploadLogFormat
+	// Middleware wraps the sending of every request to the API, the first one is the outermost.
+	// Use BeforeRequest and AfterResponse for hooks.
+	Middleware []Middleware
+	// Webhook receives a JSON payload after every successful upload, nil sends none
+	Webhook *Webhook
+	// Hooks are called at the start and the end of every upload, e.g. to record the uploads in a database
+	Hooks Hooks
+	// Tracer and Meter instrument every request to the API with a span, its duration and retries and count the
+	// bytes uploaded and downloaded, e.g. backed by OpenTelemetry. Nil disables them.
+	Tracer Tracer
+	Meter  Meter
 }
 
+// Client is the transport of all requests
 type Client struct {
-	Header  req.Header
-	Request *req.Req
+	Header     http.Header  // sent with every request, e.g. the User-Agent
+	HTTPClient *http.Client // built from the ClientOptions if nil
 }
 
+// PixelDrainClient is safe for concurrent use, the credentials of a request are sent with that request only.
+// Set the options before the first request, EnforcePlan included.
 type PixelDrainClient struct {
-	Client *Client
-	Debug  bool
+	Client         *Client
+	SourceClient   *http.Client // fetches the sources of UploadFromURL without the credentials and TLS options of Client
+	Debug          bool
+	UploadRules    UploadRules
+	HashStore      utils.HashStore
+	HashNamespace  string
+	RemoteDedup    bool
+	RemoteDedupTTL time.Duration
+	UploadCache    utils.UploadCache
+	HashCache      utils.HashCache
+	HashAlgorithm  utils.HashAlgorithm
+	Describer      Describer
+	Bandwidth      *BandwidthBudget
+	RetryPolicy    RetryPolicy
+	StateStore     utils.StateStore
+	UploadLog      utils.UploadLog
+	QuarantineDir  string
+	DedupReport    *DedupReport
+	Checkpoint     CheckpointFunc
+	ChunkedUploads bool
+	ThumbnailCache *ThumbnailCache
+	ThumbnailSizes []ThumbnailSize
+	OnTimings      TimingsFunc
+	DeleteGrace    time.Duration
+	TombstoneKey   string
+	API            APISpec
+	Middleware     []Middleware
+	Tracer         Tracer
+	Meter          Meter
+	Webhook        *Webhook
+	Hooks          Hooks
+	openFiles      fdBudget
+	uploadLimiter  *utils.RateLimiter // set by EnforcePlan
+	requestPacer   *pacer             // set by EnforcePlan
+	uploadRate     *utils.RateLimiter // MaxUploadBytesPerSec
+	downloadRate   *utils.RateLimiter // MaxDownloadBytesPerSec
+	rateLimit      *rateLimitGate
+	sharedStateDir string
+	csvStores      sync.Map // *utils.CSVHashStore by hash file path
+	remoteStores   sync.Map // *remoteHashStore by API key
+	accounts       sync.Map // duplicate check namespace by API key
+	uploadHashes   hashLocks
+	tombstoneMu    sync.Mutex
 }
 
 // New - create a new PixelDrainClient
@@ -63,33 +173,118 @@ func New(opt *ClientOptions, c *Client) *PixelDrainClient {
 	// build default client if not available
 	if c == nil {
 		c = &Client{
-			Header: req.Header{
-				"User-Agent": DefaultUserAgent,
+			Header: http.Header{
+				"User-Agent": {DefaultUserAgent},
 			},
-			Request: req.New(),
 		}
 	}
 
-	// set the request options
-	c.Request.EnableCookie(opt.EnableCookies)
-	c.Request.EnableInsecureTLS(opt.EnableInsecureTLS)
-	c.Request.SetTimeout(opt.Timeout)
-	if opt.ProxyURL != "" {
-		_ = c.Request.SetProxyUrl(opt.ProxyURL)
+	// an injected client keeps its own transport, cookies and timeout
+	if c.HTTPClient == nil {
+		c.HTTPClient = opt.HTTPClient
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = newHTTPClient(opt)
+	}
+
+	api := DefaultAPISpec
+	if opt.API != nil {
+		api = *opt.API
+	}
+
+	retry := DefaultRetryPolicy
+	if opt.RetryPolicy != nil {
+		retry = *opt.RetryPolicy
 	}
 
 	pdc := &PixelDrainClient{
-		Client: c,
-		Debug:  opt.Debug,
+		Client:         c,
+		SourceClient:   opt.SourceClient,
+		Debug:          opt.Debug,
+		UploadRules:    opt.UploadRules,
+		HashStore:      opt.HashStore,
+		HashNamespace:  opt.HashNamespace,
+		RemoteDedup:    opt.RemoteDedup && !opt.DisableDedup,
+		RemoteDedupTTL: opt.RemoteDedupTTL,
+		UploadCache:    opt.UploadCache,
+		HashCache:      opt.HashCache,
+		HashAlgorithm:  opt.HashAlgorithm,
+		Describer:      opt.Describer,
+		Bandwidth:      opt.Bandwidth,
+		RetryPolicy:    retry,
+		StateStore:     opt.StateStore,
+		UploadLog:      opt.UploadLog,
+		QuarantineDir:  opt.QuarantineDir,
+		DedupReport:    opt.DedupReport,
+		Checkpoint:     opt.Checkpoint,
+		ChunkedUploads: opt.ChunkedUploads,
+		ThumbnailCache: opt.ThumbnailCache,
+		ThumbnailSizes: opt.ThumbnailSizes,
+		OnTimings:      opt.OnTimings,
+		DeleteGrace:    opt.DeleteGrace,
+		TombstoneKey:   opt.TombstoneKey,
+		API:            api,
+		Middleware:     opt.Middleware,
+		Tracer:         opt.Tracer,
+		Meter:          opt.Meter,
+		Webhook:        opt.Webhook,
+		Hooks:          opt.Hooks,
+		openFiles:      newFDBudget(opt.MaxOpenFiles),
+		sharedStateDir: opt.SharedStateDir,
+	}
+	if pdc.SourceClient == nil {
+		pdc.SourceClient = newSourceClient(opt)
+	}
+	if opt.SharedStateDir != "" {
+		if err := os.MkdirAll(opt.SharedStateDir, 0755); err != nil {
+			log.Printf("Error creating the shared state directory: %v", err)
+		}
+	}
+	if pdc.UploadLog == nil && (opt.UploadLogPath != "" || opt.UploadLogFormat != "") {
+		pdc.UploadLog = newUploadLog(opt.UploadLogFormat, opt.UploadLogPath)
+	}
+	if opt.DisableUploadLog {
+		pdc.UploadLog = utils.NopUploadLog{}
 	}
+	if opt.DisableDedup {
+		pdc.HashStore = utils.NopHashStore{}
+	}
+	pdc.rateLimit = &rateLimitGate{path: pdc.sharedPath(sharedPauseFile)}
+	pdc.uploadRate = utils.NewSharedRateLimiter(opt.MaxUploadBytesPerSec, pdc.sharedPath(sharedUploadBandwidthFile))
+	pdc.downloadRate = utils.NewSharedRateLimiter(opt.MaxDownloadBytesPerSec, pdc.sharedPath(sharedDownloadBandwidthFile))
 
 	return pdc
 }
 
+// Version returns the version of the package which sends the requests
+func (pd *PixelDrainClient) Version() string {
+	return Version
+}
+
+// UserAgent returns the User-Agent header the client sends
+func (pd *PixelDrainClient) UserAgent() string {
+	return pd.Client.Header.Get("User-Agent")
+}
+
 // UploadPOST POST /api/file | Updated method to include directory upload functionality
+// The hashFilePath is the CSV file of the duplicate check, an empty path skips the check unless the client has a HashStore.
 // curl -X POST -i -H "Authorization: Basic <TOKEN>" -F "file=@cat.jpg" https://pixeldrain.com/api/file
 func (pd *PixelDrainClient) UploadPOST(r *RequestUpload, hashFilePath string) (*ResponseUpload, error) {
-	if r.PathToFile == "" && r.File == nil {
+	return pd.uploadPOSTContext(context.Background(), r, hashFilePath)
+}
+
+// uploadPOSTContext uploads the file after the duplicate check and calls the Hooks of the client, cancelling the
+// context aborts the running request
+func (pd *PixelDrainClient) uploadPOSTContext(ctx context.Context, r *RequestUpload, hashFilePath string) (*ResponseUpload, error) {
+	pd.Hooks.uploadStart(r)
+	rsp, err := pd.uploadPOST(ctx, r, hashFilePath)
+	pd.Hooks.uploadDone(r, rsp, err)
+
+	return rsp, err
+}
+
+func (pd *PixelDrainClient) uploadPOST(ctx context.Context, r *RequestUpload, hashFilePath string) (*ResponseUpload, error) {
+	if r.PathToFile == "" && r.File == nil && r.Source == nil {
 		return nil, errors.New(ErrMissingPathToFile)
 	}
 
@@ -101,19 +296,60 @@ func (pd *PixelDrainClient) UploadPOST(r *RequestUpload, hashFilePath string) (*
 		}
 		if fileInfo.IsDir() {
 			// If it's a directory, use UploadDirectory method
-			return nil, pd.UploadDirectory(r.PathToFile, r.Auth, hashFilePath)
+			_, err := pd.UploadDirectory(&RequestUploadDirectory{
+				Directory:    r.PathToFile,
+				Auth:         r.Auth,
+				HashFilePath: hashFilePath,
+			})
+			return nil, err
 		}
 	}
 
 	// Check for duplicate file
 	if r.PathToFile != "" {
-		isDuplicate, err := utils.IsDuplicate(hashFilePath, r.PathToFile)
+		fileHash, err := pd.duplicateCheckHash(r)
+		if err != nil {
+			return nil, err
+		}
+
+		r.dedupHash = fileHash
+
+		// the duplicate check and the saved hash of a concurrent upload of the same content are seen as one step
+		unlock := pd.uploadHashes.lock(fileHash)
+		defer unlock()
+
+		// a completed upload of an earlier, interrupted run
+		if id, found, err := pd.lookupUpload(fileHash, r); err != nil {
+			return nil, err
+		} else if found {
+			log.Printf("File %s was already uploaded as %s. Skipping upload.", r.PathToFile, id)
+			pd.recordDedup(DedupResumed, DuplicateMatch{Path: r.PathToFile, Hash: fileHash, ID: id, URL: fileURL(id)})
+			return &ResponseUpload{
+				ID:       id,
+				Hash:     fileHash,
+				Size:     utils.GetFileSize(r.PathToFile),
+				MimeType: pd.getMimeType(r.PathToFile),
+				ResponseDefault: ResponseDefault{
+					Success:    true,
+					StatusCode: http.StatusOK,
+					Message:    "File already uploaded. Upload skipped.",
+				},
+			}, nil
+		}
+
+		isDuplicate, err := pd.hashStore(hashFilePath, r).Exists(fileHash)
 		if err != nil {
 			return nil, err
 		}
 		if isDuplicate {
 			log.Printf("File %s is a duplicate. Skipping upload.", r.PathToFile)
+			match := pd.duplicateMatch(pd.hashStore(hashFilePath, r), r.Auth, r.PathToFile, fileHash)
+			pd.recordDedup(DedupSkipped, *match)
+
+			// the ID of the original, if known, so callers can still link to the content
 			return &ResponseUpload{
+				ID:        match.ID,
+				Duplicate: match,
 				ResponseDefault: ResponseDefault{
 					Success:    false,
 					StatusCode: http.StatusConflict,
@@ -123,26 +359,115 @@ func (pd *PixelDrainClient) UploadPOST(r *RequestUpload, hashFilePath string) (*
 		}
 	}
 
-	return pd.uploadFile(r, hashFilePath)
+	return pd.sendFile(ctx, r, hashFilePath)
 }
 
+// uploadFile uploads the file without the duplicate check and calls the Hooks of the client
 func (pd *PixelDrainClient) uploadFile(r *RequestUpload, hashFilePath string) (*ResponseUpload, error) {
+	pd.Hooks.uploadStart(r)
+	rsp, err := pd.sendFile(context.Background(), r, hashFilePath)
+	pd.Hooks.uploadDone(r, rsp, err)
+
+	return rsp, err
+}
+
+// sendFile uploads the file, cancelling the context aborts the running request
+func (pd *PixelDrainClient) sendFile(ctx context.Context, r *RequestUpload, hashFilePath string) (*ResponseUpload, error) {
 	if r.URL == "" {
-		r.URL = fmt.Sprint(APIURL + "/file")
+		r.URL = fmt.Sprint(pd.API.URL + pd.API.File)
 	}
 
-	reqFileUpload := req.FileUpload{}
+	var fileName string
 	var filePath string
 	var fileSize int64
 	var mimeType string
+	var fileHash string
+	var sentFileInfo os.FileInfo
+
+	// newBody returns the upload body, it is called again to send the file anew after a failed attempt
+	var newBody func() io.ReadCloser
+
+	transforms, err := newUploadTransforms(r)
+	if err != nil {
+		return nil, err
+	}
+	// the compressed or encrypted bytes of the last attempt, pixeldrain hashes them instead of the file
+	var sent *hashingReader
+	// a File of known size which is sent without buffering, it is hashed while it is sent
+	var streamed *hashingReader
+	policy := pd.RetryPolicy
+
+	ctx, rec := withTimings(ctx)
+	hashStart := time.Now()
 
 	log.Printf("Starting upload for file: %s", r.PathToFile)
-	if r.File != nil {
+	if src, size, ok := replayableSource(r); ok {
+		if r.FileName == "" {
+			return nil, errors.New(ErrMissingFilename)
+		}
+		if r.Source == nil {
+			defer r.File.Close()
+		}
+		fileName = r.FileName
+
+		// sniff and hash through the ReaderAt, the source is not buffered in memory
+		head := make([]byte, 512)
+		n, err := src.ReadAt(head, 0)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		mimeType = http.DetectContentType(head[:n])
+		fileSize = size
+		hash := sha256.New()
+		if _, err := io.Copy(hash, io.NewSectionReader(src, 0, size)); err != nil {
+			return nil, err
+		}
+		fileHash = hex.EncodeToString(hash.Sum(nil))
+		newBody = func() io.ReadCloser {
+			return io.NopCloser(io.NewSectionReader(src, 0, size))
+		}
+
+		filePath = "N/A"
+		if r.PathToFile != "" {
+			filePath = r.PathToFile
+		}
+	} else if r.File != nil && r.FileSize > 0 {
+		if r.FileName == "" {
+			return nil, errors.New(ErrMissingFilename)
+		}
+		defer r.File.Close()
+		fileName = r.FileName
+		fileSize = r.FileSize
+
+		// the size is known, the reader is sent as it is and hashed while it is read
+		br := bufio.NewReader(r.File)
+		mimeType = r.ContentType
+		if mimeType == "" {
+			head, err := br.Peek(512)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			mimeType = http.DetectContentType(head)
+		}
+		streamed = newHashingReader(struct {
+			io.Reader
+			io.Closer
+		}{br, r.File})
+		newBody = func() io.ReadCloser {
+			return streamed
+		}
+		// the reader can be read once, a failed attempt can't be sent again
+		policy.MaxAttempts = 1
+
+		filePath = "N/A"
+		if r.PathToFile != "" {
+			filePath = r.PathToFile
+		}
+	} else if r.File != nil {
 		if r.FileName == "" {
 			return nil, errors.New(ErrMissingFilename)
 		}
-		reqFileUpload.FileName = r.FileName
-		reqFileUpload.FieldName = "file"
+		fileName = r.FileName
 
 		// Read the file into a buffer to determine the MIME type and size
 		var buf bytes.Buffer
@@ -153,9 +478,13 @@ func (pd *PixelDrainClient) uploadFile(r *RequestUpload, hashFilePath string) (*
 		r.File.Close()              // Close the original ReadCloser
 		r.File = io.NopCloser(&buf) // Reset the file reader
 
-		mimeType = http.DetectContentType(buf.Bytes()[:512])
+		mimeType = http.DetectContentType(buf.Bytes())
 		fileSize = size
-		reqFileUpload.File = io.NopCloser(bytes.NewReader(buf.Bytes()))
+		sum := sha256.Sum256(buf.Bytes())
+		fileHash = hex.EncodeToString(sum[:])
+		newBody = func() io.ReadCloser {
+			return io.NopCloser(bytes.NewReader(buf.Bytes()))
+		}
 
 		// Attempt to use the PathToFile if provided, otherwise mark as "N/A"
 		if r.PathToFile != "" {
@@ -164,91 +493,443 @@ func (pd *PixelDrainClient) uploadFile(r *RequestUpload, hashFilePath string) (*
 			filePath = "N/A" // No file path when using io.ReadCloser
 		}
 	} else {
-		file, err := os.Open(r.PathToFile)
+		info, err := os.Stat(r.PathToFile)
 		if err != nil {
 			return nil, err
 		}
+		sentFileInfo = info
+
+		// the file is opened when the request body is sent and counts against the open files budget
+		var opened []*lazyFile
 		defer func() {
-			if cerr := file.Close(); cerr != nil {
-				log.Printf("Error closing file: %v", cerr)
+			for _, file := range opened {
+				if cerr := file.Close(); cerr != nil {
+					log.Printf("Error closing file: %v", cerr)
+				}
 			}
 		}()
+		newBody = func() io.ReadCloser {
+			file := newLazyFile(r.PathToFile, pd.openFiles)
+			opened = append(opened, file)
+			return file
+		}
 
-		reqFileUpload.FileName = filepath.Base(r.PathToFile)
-		reqFileUpload.FieldName = "file"
-		reqFileUpload.File = file
+		fileName = r.GetFileName()
 
 		filePath = r.PathToFile
 		fileSize = utils.GetFileSize(filePath)
-		mimeType = utils.GetMimeType(filePath)
+		mimeType = pd.getMimeType(filePath)
 	}
+	rec.add(hashing, hashStart)
 
-	reqParams := req.Param{
-		"anonymous": r.Anonymous,
+	fields := map[string]string{
+		"anonymous": strconv.FormatBool(r.Anonymous),
+	}
+	for key, value := range r.Extra {
+		fields[key] = value
 	}
 
-	log.Printf("Sending POST request to %s with file: %s", r.URL, reqFileUpload.FileName)
-	if r.Auth.IsAuthAvailable() && !r.Anonymous {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	log.Printf("Sending POST request to %s with file: %s", r.URL, fileName)
+	header := pd.header(requestAuth(r.Auth, r.Anonymous))
+
+	queueStart := time.Now()
+	if err := pd.reserveBandwidth(ctx, utils.Upload, fileSize); err != nil {
+		return nil, err
 	}
+	rec.add(queueWait, queueStart)
+
+	// a failed attempt is sent anew, the body is read again from the start
+	rsp, err := pd.send(ctx, policy, func() (*http.Request, error) {
+		file := newBody()
+		if r.Progress != nil {
+			file = utils.NewProgressReader(file, fileSize, r.Progress)
+		}
+		if transforms != nil {
+			sent = newHashingReader(transforms.body(file, fileName, fileSize))
+			file = sent
+		}
+
+		// keep the limits of an enforced batch plan
+		if pd.uploadLimiter != nil {
+			file = utils.NewRateLimitedReader(file, pd.uploadLimiter)
+		}
+		paceStart := time.Now()
+		pd.requestPacer.wait()
+		rec.add(queueWait, paceStart)
+
+		body, contentType := multipartBody(fields, "file", fileName, file)
+		httpReq, err := newRequest(ctx, http.MethodPost, r.URL, header, body)
+		if err != nil {
+			_ = body.Close()
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", contentType)
 
-	rsp, err := pd.Client.Request.Post(r.URL, pd.Client.Header, reqFileUpload, reqParams)
+		return httpReq, nil
+	})
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if streamed != nil {
+		fileHash = streamed.Sum()
+		fileSize = streamed.size
+	}
+
+	uploadInfo := utils.UploadInfo{
+		FileName:       fileName,
+		DirectoryPath:  filePath,
+		UploadDateTime: time.Now().Format(time.RFC3339),
+		FileSize:       fileSize,
+		MIMEType:       mimeType,
+		Uploader:       r.Auth.APIKey,
+		UploadStatus:   fmt.Sprintf("%d", rsp.StatusCode),
+		FormattedSize:  utils.FormatFileSize(fileSize),
+	}
+	if transforms != nil {
+		uploadInfo.Transforms = transforms.String()
+	}
+
+	// pixeldrain rejected the upload, keep its error body in the log entry
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		apiErr := newAPIError(statusCode, data)
+		apiErr.RetryAfter = parseRetryAfter(rsp.Header.Get("Retry-After"))
+		log.Printf("Upload of file %s failed: %v", fileName, apiErr)
+
+		if filePath != "N/A" {
+			uploadInfo.ErrorValue = apiErr.Value
+			uploadInfo.ErrorMessage = apiErr.Message
+			if err := pd.uploadLog().Record(uploadInfo); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, apiErr
+	}
+
 	uploadRsp := &ResponseUpload{}
-	uploadRsp.StatusCode = rsp.Response().StatusCode
-	err = rsp.ToJSON(uploadRsp)
+	uploadRsp.StatusCode = rsp.StatusCode
+	err = rsp.decode(data, uploadRsp)
 	if err != nil {
 		log.Printf("Error parsing JSON response: %v", err)
 		return nil, err
 	}
+	pd.recordBandwidth(utils.Upload, fileSize)
 
-	log.Printf("File uploaded successfully: %s", reqFileUpload.FileName)
-	formattedFileSize := utils.FormatFileSize(fileSize)
+	log.Printf("File uploaded successfully: %s", fileName)
+
+	// Calculate the hash first, the remote ID is recorded before the logs below which may not be written if the process dies
+	if algorithm, sum := utils.ParseHash(r.dedupHash); fileHash == "" && r.dedupHash != "" && algorithm == utils.HashSHA256 {
+		// the duplicate check already read the file with SHA-256
+		fileHash = sum
+	}
+	if fileHash == "" {
+		hashStart = time.Now()
+		fileHash, err = pd.calculateFileHash(filePath)
+		if err != nil {
+			return nil, err
+		}
+		rec.add(hashing, hashStart)
+	}
+	uploadRsp.Hash = fileHash
+	uploadRsp.Size = fileSize
+	uploadRsp.MimeType = mimeType
+
+	// a file pixeldrain stores with another hash is neither logged nor recorded as uploaded
+	if r.Verify {
+		localFile, sentHash := "", fileHash
+		if sentFileInfo != nil {
+			localFile = r.PathToFile
+		}
+		if sent != nil {
+			sentHash = sent.Sum()
+		}
+		if err := pd.verifyTransfer(utils.Upload, uploadRsp.ID, r.Auth, filePath, sentHash, localFile); err != nil {
+			return nil, err
+		}
+	}
 
 	// Gather upload information and save it to CSV
 	if filePath != "N/A" {
-		uploadInfo := utils.UploadInfo{
-			FileName:       reqFileUpload.FileName,
-			DirectoryPath:  filePath,
-			URL:            uploadRsp.GetFileURL(),
-			UploadDateTime: time.Now().Format(time.RFC3339),
-			FileSize:       fileSize,
-			MIMEType:       mimeType,
-			Uploader:       r.Auth.APIKey,
-			UploadStatus:   fmt.Sprintf("%d", uploadRsp.StatusCode),
-			FormattedSize:  formattedFileSize,
+
+		dedupHash, err := pd.storedHash(r, filePath, fileHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if uploadRsp.Success && uploadRsp.ID != "" && pd.UploadCache != nil {
+			if err := pd.UploadCache.Record(pd.hashKey(dedupHash, r), uploadRsp.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		uploadInfo.URL = uploadRsp.GetFileURL()
+		uploadInfo.Hash = fileHash
+		uploadInfo.ID = uploadRsp.ID
+		if sent != nil {
+			uploadInfo.StoredSize = sent.size
 		}
 
 		log.Printf("Logging upload info for file in uploadFile: %s", filePath)
 
-		if err := utils.SaveUploadInfoToCSV(uploadInfo, CSVFilePath); err != nil {
+		if err := pd.uploadLog().Record(uploadInfo); err != nil {
 			return nil, err
 		}
 
-		// Calculate the hash and save it to CSV
-		fileHash, err := utils.CalculateFileHash(filePath)
-		if err != nil {
+		if err := pd.saveHash(pd.hashStore(hashFilePath, r), filePath, dedupHash, uploadRsp.ID); err != nil {
 			return nil, err
 		}
+	}
 
-		if err := utils.SaveFileHash(hashFilePath, filePath, fileHash); err != nil {
-			return nil, err
+	// the upload succeeded, a failed cleanup is only logged and leaves the local file in place
+	if sentFileInfo != nil {
+		if err := finishLocalFile(r, sentFileInfo, uploadRsp); err != nil {
+			log.Printf("Keeping uploaded file %s: %v", r.PathToFile, err)
 		}
 	}
+	uploadRsp.Timings = pd.finishTimings(rec, utils.Upload, uploadRsp.ID)
+	pd.prefetchThumbnails(uploadRsp, r.Auth)
+	pd.notifyUpload(fileName, uploadRsp)
 
 	return uploadRsp, nil
 }
 
+// uploadLog returns the configured UploadLog or the CSV file at CSVFilePath
+func (pd *PixelDrainClient) uploadLog() utils.UploadLog {
+	if pd.UploadLog == nil {
+		return utils.CSVUploadLog{Path: CSVFilePath}
+	}
+
+	return pd.UploadLog
+}
+
+// newUploadLog returns the upload log of the options, an unknown format is logged and the CSV file is used
+func newUploadLog(format utils.UploadLogFormat, path string) utils.UploadLog {
+	if path == "" && format != "" {
+		path = strings.TrimSuffix(CSVFilePath, filepath.Ext(CSVFilePath)) + "." + string(format)
+	}
+
+	uploadLog, err := utils.NewUploadLog(format, path)
+	if err != nil {
+		log.Printf("Error creating the upload log: %v", err)
+		return utils.CSVUploadLog{Path: CSVFilePath}
+	}
+
+	return uploadLog
+}
+
+// calculateFileHash hashes the file within the open files budget
+func (pd *PixelDrainClient) calculateFileHash(filePath string) (string, error) {
+	pd.openFiles.acquire()
+	defer pd.openFiles.release()
+
+	return utils.CalculateFileHash(filePath)
+}
+
+// hashAlgorithm returns the algorithm of the duplicate check
+func (pd *PixelDrainClient) hashAlgorithm() utils.HashAlgorithm {
+	if pd.HashAlgorithm == "" {
+		return utils.HashSHA256
+	}
+
+	return pd.HashAlgorithm
+}
+
+// dedupFileHash hashes the file with the algorithm of the duplicate check within the open files budget
+func (pd *PixelDrainClient) dedupFileHash(filePath string) (string, error) {
+	pd.openFiles.acquire()
+	defer pd.openFiles.release()
+
+	return utils.CalculateFileHashWith(filePath, pd.hashAlgorithm())
+}
+
+// storedHash returns the hash the duplicate check stores for an upload, its SHA-256 unless the client uses another
+// HashAlgorithm. The file is read again if the upload didn't go through the duplicate check of UploadPOST.
+func (pd *PixelDrainClient) storedHash(r *RequestUpload, filePath, sha256Hash string) (string, error) {
+	if pd.hashAlgorithm() == utils.HashSHA256 {
+		return sha256Hash, nil
+	}
+	if r.dedupHash != "" {
+		return r.dedupHash, nil
+	}
+
+	return pd.dedupFileHash(filePath)
+}
+
+// duplicateCheckHash returns the hash of the file for the duplicate check. With a HashCache of the client a file
+// with the size and modification time of its last check is not read again, a forced hash replaces the cached one.
+func (pd *PixelDrainClient) duplicateCheckHash(r *RequestUpload) (string, error) {
+	if pd.HashCache == nil {
+		return pd.dedupFileHash(r.PathToFile)
+	}
+
+	info, err := os.Stat(r.PathToFile)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(r.PathToFile)
+	if err != nil {
+		return "", err
+	}
+
+	if !r.ForceHash {
+		// a hash of another algorithm was cached before the algorithm of the client changed
+		if hash, found, err := pd.HashCache.Lookup(path, info.Size(), info.ModTime()); err != nil {
+			return "", err
+		} else if algorithm, _ := utils.ParseHash(hash); found && algorithm == pd.hashAlgorithm() {
+			return hash, nil
+		}
+	}
+
+	hash, err := pd.dedupFileHash(r.PathToFile)
+	if err != nil {
+		return "", err
+	}
+	if err := pd.HashCache.Record(path, info.Size(), info.ModTime(), hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// getMimeType detects the MIME type within the open files budget
+func (pd *PixelDrainClient) getMimeType(filePath string) string {
+	pd.openFiles.acquire()
+	defer pd.openFiles.release()
+
+	return utils.GetMimeType(filePath)
+}
+
+// hashStore returns the configured HashStore or a CSV store for the given hash file, without both there is no
+// local duplicate check. The entries are kept apart per account unless the client has a fixed HashNamespace.
+// With RemoteDedup the files of the account are checked after the local store.
+func (pd *PixelDrainClient) hashStore(hashFilePath string, r *RequestUpload) utils.HashStore {
+	var store utils.HashStore = pd.HashStore
+	if store == nil && hashFilePath != "" {
+		// one store per hash file, so the parsed rows are kept from upload to upload
+		csvStore, _ := pd.csvStores.LoadOrStore(hashFilePath, utils.NewCSVHashStore(hashFilePath))
+		store = csvStore.(*utils.CSVHashStore)
+	}
+	if store == nil {
+		store = utils.NopHashStore{}
+	}
+
+	local := utils.NewNamespacedHashStore(store, pd.hashNamespace(r))
+	local.Fallback = pd.legacyNamespaces(r)
+	if pd.RemoteDedup && r.Auth.IsAuthAvailable() && !r.Anonymous {
+		return utils.NewLayeredHashStore(local, pd.remoteHashes(r.Auth))
+	}
+
+	return local
+}
+
+// hashNamespace returns the namespace of the duplicate check and the upload cache for the request
+func (pd *PixelDrainClient) hashNamespace(r *RequestUpload) string {
+	if pd.HashNamespace == "" && r.Auth.IsAuthAvailable() && !r.Anonymous {
+		return pd.accountNamespace(r.Auth)
+	}
+
+	return pd.HashNamespace
+}
+
+// legacyNamespaces returns the namespaces earlier versions kept the hashes of the request in, the namespace of the
+// API key and the plain hashes from before the duplicate check was kept apart per account
+func (pd *PixelDrainClient) legacyNamespaces(r *RequestUpload) []string {
+	if pd.HashNamespace != "" || !r.Auth.IsAuthAvailable() || r.Anonymous {
+		return nil
+	}
+
+	return []string{keyNamespace(r.Auth), ""}
+}
+
+// accountNamespace returns the namespace of the account of the API key. It is named after the username, so a
+// rotated key or a second key of the account keeps the duplicate check. The username is looked up once per key,
+// if the lookup fails the namespace of the key is used.
+func (pd *PixelDrainClient) accountNamespace(auth Auth) string {
+	if namespace, ok := pd.accounts.Load(auth.APIKey); ok {
+		return namespace.(string)
+	}
+
+	namespace := keyNamespace(auth)
+	user, err := pd.GetUser(&RequestGetUser{Auth: auth})
+	if err == nil && user.Success && user.Username != "" {
+		namespace = "user-" + user.Username
+	} else {
+		log.Printf("Could not look up the account of the API key, its duplicate check is kept apart: %v", accountError(user, err))
+	}
+
+	actual, _ := pd.accounts.LoadOrStore(auth.APIKey, namespace)
+	return actual.(string)
+}
+
+// accountError is the error of a failed account lookup
+func accountError(user *ResponseGetUser, err error) error {
+	if err != nil {
+		return err
+	}
+	if user.Username == "" && user.Success {
+		return errors.New("no username")
+	}
+
+	return &APIError{StatusCode: user.StatusCode, Value: user.Value, Message: user.Message}
+}
+
+// keyNamespace names the namespace after the API key without exposing it
+func keyNamespace(auth Auth) string {
+	sum := sha256.Sum256([]byte(auth.APIKey))
+	return "account-" + hex.EncodeToString(sum[:8])
+}
+
+// hashKey returns the namespaced upload cache key of the hash
+func (pd *PixelDrainClient) hashKey(hash string, r *RequestUpload) string {
+	return namespacedKey(pd.hashNamespace(r), hash)
+}
+
+// namespacedKey prefixes the hash with the namespace, an empty namespace is the plain hash
+func namespacedKey(namespace, hash string) string {
+	if namespace != "" {
+		return namespace + ":" + hash
+	}
+
+	return hash
+}
+
+// lookupUpload checks the upload cache for a completed upload of the hash
+func (pd *PixelDrainClient) lookupUpload(hash string, r *RequestUpload) (string, bool, error) {
+	if pd.UploadCache == nil {
+		return "", false, nil
+	}
+
+	id, found, err := pd.UploadCache.Lookup(pd.hashKey(hash, r))
+	for _, namespace := range pd.legacyNamespaces(r) {
+		if found || err != nil {
+			break
+		}
+		id, found, err = pd.UploadCache.Lookup(namespacedKey(namespace, hash))
+	}
+
+	return id, found, err
+}
+
 // UploadPUT PUT /api/file/{name}
 // curl -X PUT -i -H "Authorization: Basic <TOKEN>" --upload-file cat.jpg https://pixeldrain.com/api/file/test_cat.jpg
 func (pd *PixelDrainClient) UploadPUT(r *RequestUpload) (*ResponseUpload, error) {
-	if r.PathToFile == "" && r.File == nil {
+	pd.Hooks.uploadStart(r)
+	rsp, err := pd.uploadPUT(r)
+	pd.Hooks.uploadDone(r, rsp, err)
+
+	return rsp, err
+}
+
+func (pd *PixelDrainClient) uploadPUT(r *RequestUpload) (*ResponseUpload, error) {
+	if r.PathToFile == "" && r.File == nil && r.Source == nil {
 		return nil, errors.New(ErrMissingPathToFile)
 	}
 
@@ -257,54 +938,142 @@ func (pd *PixelDrainClient) UploadPUT(r *RequestUpload) (*ResponseUpload, error)
 	}
 
 	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/file/%s", r.GetFileName())
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s", r.GetFileName())
 	}
 
-	var file io.ReadCloser
-	var err error
-	if r.File != nil {
-		file = r.File
+	transforms, err := newUploadTransforms(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.ChunkSize > 0 && !pd.ChunkedUploads {
+		return nil, errors.New(ErrChunkedUploads)
+	}
+
+	// newBody returns the upload body, a file which can only be read once is sent once
+	var newBody func() io.ReadCloser
+	policy := pd.RetryPolicy
+	size := int64(-1) // unknown sizes are sent chunked
+	// compressed or encrypted files are sent in one request, the chunks of a chunked upload are sent as they are
+	chunked := r.ChunkSize > 0 && transforms == nil
+	if r.Source != nil {
+		if chunked && r.SourceSize > r.ChunkSize {
+			return pd.uploadChunked(r)
+		}
+		size = r.SourceSize
+		newBody = func() io.ReadCloser {
+			return io.NopCloser(io.NewSectionReader(r.Source, 0, r.SourceSize))
+		}
+	} else if r.File != nil {
+		newBody = func() io.ReadCloser { return r.File }
+		policy.MaxAttempts = 1
 	} else {
-		file, err = os.Open(r.PathToFile)
+		fInfo, err := os.Stat(r.PathToFile)
 		if err != nil {
 			return nil, err
 		}
+		if chunked && fInfo.Size() > r.ChunkSize {
+			return pd.uploadChunked(r)
+		}
+		size = fInfo.Size()
+
+		// the file is opened when the request body is sent and counts against the open files budget
+		var opened []*lazyFile
+		defer func() {
+			for _, file := range opened {
+				_ = file.Close()
+			}
+		}()
+		newBody = func() io.ReadCloser {
+			file := newLazyFile(r.PathToFile, pd.openFiles)
+			opened = append(opened, file)
+			return file
+		}
 	}
 
+	ctx, rec := withTimings(context.Background())
+	queueStart := time.Now()
+	if err := pd.reserveBandwidth(ctx, utils.Upload, size); err != nil {
+		return nil, err
+	}
+	rec.add(queueWait, queueStart)
+
 	// we don't send this parameter due a bug of pixeldrain side
 	//reqParams := req.Param{
 	//	"anonymous": r.Anonymous,
 	//}
-
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() && !r.Anonymous {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	query := url.Values{}
+	for key, value := range r.Extra {
+		query.Set(key, value)
 	}
+	uploadURL := withQuery(r.URL, query)
+
+	header := pd.header(requestAuth(r.Auth, r.Anonymous))
+
+	var body *hashingReader
+	rsp, err := pd.send(ctx, policy, func() (*http.Request, error) {
+		src := newBody()
+		if r.Progress != nil {
+			src = utils.NewProgressReader(src, size, r.Progress)
+		}
+		contentLength := size
+		if transforms != nil {
+			src = transforms.body(src, r.GetFileName(), size)
+			contentLength = -1
+		}
+		body = newHashingReader(src)
+
+		httpReq, err := newRequest(ctx, http.MethodPut, uploadURL, header, body)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.ContentLength = contentLength
 
-	rsp, err := pd.Client.Request.Put(r.URL, pd.Client.Header, file)
+		return httpReq, nil
+	})
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
 	}
+
+	data, err := rsp.readBody()
 	if err != nil {
 		return nil, err
 	}
+	if statusCode := rsp.StatusCode; statusCode >= 400 {
+		return nil, newAPIError(statusCode, data)
+	}
 
 	uploadRsp := &ResponseUpload{}
-	uploadRsp.StatusCode = rsp.Response().StatusCode
+	uploadRsp.StatusCode = rsp.StatusCode
 	if uploadRsp.StatusCode == http.StatusCreated {
 		uploadRsp.Success = true
 	}
-	err = rsp.ToJSON(uploadRsp)
+	err = rsp.decode(data, uploadRsp)
 	if err != nil {
 		return nil, err
 	}
+	uploadRsp.Hash = body.Sum()
+	uploadRsp.Size = body.size
+	uploadRsp.MimeType = body.MimeType()
+	pd.recordBandwidth(utils.Upload, body.size)
+
+	if r.Verify {
+		if err := pd.verifyTransfer(utils.Upload, uploadRsp.ID, r.Auth, r.PathToFile, uploadRsp.Hash, r.PathToFile); err != nil {
+			return nil, err
+		}
+	}
+	uploadRsp.Timings = pd.finishTimings(rec, utils.Upload, uploadRsp.ID)
+	pd.prefetchThumbnails(uploadRsp, r.Auth)
+	pd.notifyUpload(r.GetFileName(), uploadRsp)
 
 	return uploadRsp, nil
 }
 
 // Download GET /api/file/{id}
 func (pd *PixelDrainClient) Download(r *RequestDownload) (*ResponseDownload, error) {
-	if r.PathToSave == "" {
+	if r.PathToSave == "" && r.Writer == nil {
 		return nil, errors.New(ErrMissingPathToFile)
 	}
 
@@ -313,30 +1082,33 @@ func (pd *PixelDrainClient) Download(r *RequestDownload) (*ResponseDownload, err
 	}
 
 	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/file/%s", r.ID)
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s", r.ID)
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	if r.Resume && r.Writer == nil {
+		return pd.resumeDownload(r)
+	}
+	if r.Segments > 1 && r.Writer == nil {
+		return pd.segmentedDownload(r)
 	}
 
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	ctx, rec := withTimings(context.Background())
+	rsp, err := pd.request(ctx, http.MethodGet, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	if rsp.Response().StatusCode != 200 {
+	if rsp.StatusCode != 200 {
 		defaultRsp := &ResponseDefault{}
-		err = rsp.ToJSON(defaultRsp)
+		err = rsp.decodeJSON(defaultRsp)
 		if err != nil {
 			return nil, err
 		}
 
-		defaultRsp.StatusCode = rsp.Response().StatusCode
+		defaultRsp.StatusCode = rsp.StatusCode
 		defaultRsp.Success = false
 
 		downloadRsp := &ResponseDownload{
@@ -346,7 +1118,37 @@ func (pd *PixelDrainClient) Download(r *RequestDownload) (*ResponseDownload, err
 		return downloadRsp, nil
 	}
 
-	err = rsp.ToFile(r.PathToSave)
+	// stream into the writer of the caller, e.g. to proxy the file without a temp file
+	if r.Writer != nil {
+		size, sum, err := pd.writeDownload(rsp, r, r.Writer)
+		if err != nil {
+			return nil, err
+		}
+		if r.Verify {
+			if err := pd.verifyTransfer(utils.Download, r.ID, r.Auth, r.ID, sum, ""); err != nil {
+				return nil, err
+			}
+		}
+
+		fileName := r.ID
+		if _, params, err := mime.ParseMediaType(rsp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+			fileName = params["filename"]
+		}
+
+		downloadRsp := &ResponseDownload{
+			FileName: fileName,
+			FileSize: size,
+			Timings:  pd.finishTimings(rec, utils.Download, r.ID),
+			ResponseDefault: ResponseDefault{
+				StatusCode: rsp.StatusCode,
+				Success:    true,
+			},
+		}
+
+		return downloadRsp, nil
+	}
+
+	err = pd.saveDownload(rsp, r)
 	if err != nil {
 		return nil, err
 	}
@@ -360,8 +1162,9 @@ func (pd *PixelDrainClient) Download(r *RequestDownload) (*ResponseDownload, err
 		FilePath: r.PathToSave,
 		FileName: fInfo.Name(),
 		FileSize: fInfo.Size(),
+		Timings:  pd.finishTimings(rec, utils.Download, r.ID),
 		ResponseDefault: ResponseDefault{
-			StatusCode: rsp.Response().StatusCode,
+			StatusCode: rsp.StatusCode,
 			Success:    true,
 		},
 	}
@@ -369,6 +1172,97 @@ func (pd *PixelDrainClient) Download(r *RequestDownload) (*ResponseDownload, err
 	return downloadRsp, nil
 }
 
+// saveDownload writes the response body to PathToSave
+func (pd *PixelDrainClient) saveDownload(rsp *httpResponse, r *RequestDownload) error {
+	if !r.Verify {
+		file, err := os.Create(r.PathToSave)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = pd.writeDownload(rsp, r, file)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+
+		return err
+	}
+
+	// a verified download replaces the file at PathToSave only if the hash matches
+	tmp, err := os.CreateTemp(filepath.Dir(r.PathToSave), filepath.Base(r.PathToSave)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, sum, err := pd.writeDownload(rsp, r, tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := pd.verifyTransfer(utils.Download, r.ID, r.Auth, r.PathToSave, sum, tmp.Name()); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), r.PathToSave)
+}
+
+// writeDownload copies the response body to the writer, files with a go-pd envelope are decoded unless Raw is set
+func (pd *PixelDrainClient) writeDownload(rsp *httpResponse, r *RequestDownload, w io.Writer) (int64, string, error) {
+	body := rsp.Body
+	defer body.Close()
+
+	rec := rsp.timings()
+	if size := rsp.ContentLength; size > 0 {
+		start := time.Now()
+		if err := pd.reserveBandwidth(context.Background(), utils.Download, size); err != nil {
+			return 0, "", err
+		}
+		rec.add(queueWait, start)
+	}
+	defer rec.add(transfer, time.Now())
+
+	var src io.Reader = body
+	if r.Progress != nil {
+		src = utils.NewProgressReader(body, rsp.ContentLength, r.Progress)
+	}
+
+	// pixeldrain hashes the stored bytes, so the hash is taken before an envelope is decoded
+	var raw hash.Hash
+	if r.Verify {
+		raw = sha256.New()
+		src = io.TeeReader(src, raw)
+	}
+
+	if !r.Raw {
+		decoded, e, err := decodeEnvelope(src, r.Key)
+		if err != nil {
+			return 0, "", err
+		}
+		if e != nil {
+			log.Printf("Decoding %s with %v", r.ID, e.Transforms)
+		}
+		src = decoded
+	}
+
+	n, err := io.Copy(w, src)
+	pd.recordBandwidth(utils.Download, n)
+
+	var sum string
+	if raw != nil {
+		// an envelope may not have been read to its end by the decoder
+		if _, cerr := io.Copy(io.Discard, src); err == nil {
+			err = cerr
+		}
+		sum = hex.EncodeToString(raw.Sum(nil))
+	}
+
+	return n, sum, err
+}
+
 // GetFileInfo GET /api/file/{id}/info
 func (pd *PixelDrainClient) GetFileInfo(r *RequestFileInfo) (*ResponseFileInfo, error) {
 	if r.ID == "" {
@@ -376,28 +1270,23 @@ func (pd *PixelDrainClient) GetFileInfo(r *RequestFileInfo) (*ResponseFileInfo,
 	}
 
 	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/file/%s/info", r.ID)
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s/info", r.ID)
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
-	}
-
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	rsp, err := pd.request(context.Background(), http.MethodGet, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	fileInfoRsp := &ResponseFileInfo{}
-	fileInfoRsp.StatusCode = rsp.Response().StatusCode
+	fileInfoRsp.StatusCode = rsp.StatusCode
 	if fileInfoRsp.StatusCode == http.StatusOK {
 		fileInfoRsp.Success = true
 	}
-	err = rsp.ToJSON(fileInfoRsp)
+	err = rsp.decodeJSON(fileInfoRsp)
 	if err != nil {
 		return nil, err
 	}
@@ -411,51 +1300,84 @@ func (pd *PixelDrainClient) DownloadThumbnail(r *RequestThumbnail) (*ResponseThu
 		return nil, errors.New(ErrMissingPathToFile)
 	}
 
-	if r.ID == "" {
-		return nil, errors.New(ErrMissingFileID)
+	rsp, err := pd.getThumbnail(r)
+	if err != nil {
+		return nil, err
 	}
 
-	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/file/%s/thumbnail", r.ID)
+	err = rsp.saveTo(r.PathToSave)
+	if err != nil {
+		return nil, err
 	}
 
-	queryParams := req.QueryParam{}
-	if r.Width != "" {
-		queryParams["width"] = r.Width
-	}
-	if r.Height != "" {
-		queryParams["height"] = r.Height
+	fInfo, err := os.Stat(r.PathToSave)
+	if err != nil {
+		return nil, err
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	rspStruct := &ResponseThumbnail{
+		FilePath: r.PathToSave,
+		FileName: fInfo.Name(),
+		FileSize: fInfo.Size(),
+		ResponseDefault: ResponseDefault{
+			StatusCode: rsp.StatusCode,
+			Success:    true,
+		},
 	}
 
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header, queryParams)
-	if pd.Debug {
-		log.Println(rsp.Dump())
+	return rspStruct, nil
+}
+
+// GetThumbnailBytes GET /api/file/{id}/thumbnail?width=x&height=x
+// returns the thumbnail in memory instead of saving it to PathToSave, the ThumbnailCache of the client is used if set
+func (pd *PixelDrainClient) GetThumbnailBytes(r *RequestThumbnail) (*ResponseThumbnailBytes, error) {
+	if pd.ThumbnailCache != nil && r.ID != "" {
+		if data, ok := pd.ThumbnailCache.Get(r.ID, r.Width, r.Height); ok {
+			return &ResponseThumbnailBytes{
+				Data:        data,
+				ContentType: http.DetectContentType(data),
+				ResponseDefault: ResponseDefault{
+					StatusCode: http.StatusOK,
+					Success:    true,
+				},
+			}, nil
+		}
 	}
+
+	rsp, err := pd.getThumbnail(r)
 	if err != nil {
 		return nil, err
 	}
 
-	err = rsp.ToFile(r.PathToSave)
-	if err != nil {
-		return nil, err
+	if rsp.StatusCode != http.StatusOK {
+		defaultRsp := &ResponseDefault{}
+		err = rsp.decodeJSON(defaultRsp)
+		if err != nil {
+			return nil, err
+		}
+
+		defaultRsp.StatusCode = rsp.StatusCode
+		defaultRsp.Success = false
+
+		return &ResponseThumbnailBytes{ResponseDefault: *defaultRsp}, nil
 	}
 
-	fInfo, err := os.Stat(r.PathToSave)
+	data, err := rsp.readBody()
 	if err != nil {
 		return nil, err
 	}
 
-	rspStruct := &ResponseThumbnail{
-		FilePath: r.PathToSave,
-		FileName: fInfo.Name(),
-		FileSize: fInfo.Size(),
+	if pd.ThumbnailCache != nil {
+		if err := pd.ThumbnailCache.Put(r.ID, r.Width, r.Height, data); err != nil {
+			log.Printf("Error caching the thumbnail of %s: %v", r.ID, err)
+		}
+	}
+
+	rspStruct := &ResponseThumbnailBytes{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
 		ResponseDefault: ResponseDefault{
-			StatusCode: rsp.Response().StatusCode,
+			StatusCode: rsp.StatusCode,
 			Success:    true,
 		},
 	}
@@ -463,36 +1385,78 @@ func (pd *PixelDrainClient) DownloadThumbnail(r *RequestThumbnail) (*ResponseThu
 	return rspStruct, nil
 }
 
-// Delete DELETE /api/file/{id}
+// getThumbnail validates the thumbnail request and sends it
+func (pd *PixelDrainClient) getThumbnail(r *RequestThumbnail) (*httpResponse, error) {
+	if r.ID == "" {
+		return nil, errors.New(ErrMissingFileID)
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	if r.URL == "" {
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s/thumbnail", r.ID)
+	}
+
+	query := url.Values{}
+	if r.Width != 0 {
+		query.Set("width", strconv.Itoa(int(r.Width)))
+	}
+	if r.Height != 0 {
+		query.Set("height", strconv.Itoa(int(r.Height)))
+	}
+
+	rsp, err := pd.request(context.Background(), http.MethodGet, withQuery(r.URL, query), pd.header(r.Auth), nil)
+	if pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+// Delete DELETE /api/file/{id}, with a DeleteGrace of the client the delete is only scheduled
 func (pd *PixelDrainClient) Delete(r *RequestDelete) (*ResponseDelete, error) {
 	if r.ID == "" {
 		return nil, errors.New(ErrMissingFileID)
 	}
 
 	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/file/%s", r.ID)
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s", r.ID)
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	if pd.DeleteGrace > 0 && !r.Immediately {
+		return pd.scheduleDelete(r)
 	}
 
-	rsp, err := pd.Client.Request.Delete(r.URL, pd.Client.Header)
+	return pd.deleteNow(r)
+}
+
+// deleteNow sends the DELETE request without the grace period
+func (pd *PixelDrainClient) deleteNow(r *RequestDelete) (*ResponseDelete, error) {
+	rsp, err := pd.request(context.Background(), http.MethodDelete, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseDelete{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
+	if rspStruct.Success && pd.ThumbnailCache != nil {
+		if err := pd.ThumbnailCache.Invalidate(r.ID); err != nil {
+			log.Printf("Error removing the cached thumbnails of %s: %v", r.ID, err)
+		}
+	}
 
 	return rspStruct, nil
 }
@@ -500,31 +1464,30 @@ func (pd *PixelDrainClient) Delete(r *RequestDelete) (*ResponseDelete, error) {
 // CreateList POST /api/list
 func (pd *PixelDrainClient) CreateList(r *RequestCreateList) (*ResponseCreateList, error) {
 	if r.URL == "" {
-		r.URL = APIURL + "/list"
+		r.URL = pd.API.URL + pd.API.List
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() && !r.Anonymous {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+	header := pd.header(requestAuth(r.Auth, r.Anonymous))
+	data, err := jsonBody(header, r)
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := json.Marshal(r)
-
-	rsp, err := pd.Client.Request.Post(r.URL, pd.Client.Header, data)
+	rsp, err := pd.request(context.Background(), http.MethodPost, r.URL, header, data)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseCreateList{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
 
 	return rspStruct, nil
 }
@@ -536,29 +1499,24 @@ func (pd *PixelDrainClient) GetList(r *RequestGetList) (*ResponseGetList, error)
 	}
 
 	if r.URL == "" {
-		r.URL = fmt.Sprintf(APIURL+"/list/%s", r.ID)
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.List+"/%s", r.ID)
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
-	}
-
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	rsp, err := pd.request(context.Background(), http.MethodGet, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseGetList{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
 
 	return rspStruct, nil
 }
@@ -566,35 +1524,30 @@ func (pd *PixelDrainClient) GetList(r *RequestGetList) (*ResponseGetList, error)
 // GetUser GET /api/user
 func (pd *PixelDrainClient) GetUser(r *RequestGetUser) (*ResponseGetUser, error) {
 	if r.URL == "" {
-		r.URL = APIURL + "/user"
-	}
-
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+		r.URL = pd.API.URL + pd.API.User
 	}
 
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	rsp, err := pd.request(context.Background(), http.MethodGet, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseGetUser{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
 	status := false
-	if rsp.Response().StatusCode == http.StatusOK {
+	if rsp.StatusCode == http.StatusOK {
 		status = true
 	}
 
 	rspStruct.Success = status
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
 
 	return rspStruct, nil
 }
@@ -602,35 +1555,31 @@ func (pd *PixelDrainClient) GetUser(r *RequestGetUser) (*ResponseGetUser, error)
 // GetUserFiles GET /api/user/files
 func (pd *PixelDrainClient) GetUserFiles(r *RequestGetUserFiles) (*ResponseGetUserFiles, error) {
 	if r.URL == "" {
-		r.URL = APIURL + "/user/files"
+		r.URL = pd.API.URL + pd.API.User + "/files"
 	}
 
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
-	}
-
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	rsp, err := pd.request(context.Background(), http.MethodGet, withQuery(r.URL, r.query()), pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseGetUserFiles{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
 	status := false
-	if rsp.Response().StatusCode == http.StatusOK {
+	if rsp.StatusCode == http.StatusOK {
 		status = true
 	}
 
 	rspStruct.Success = status
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
+	r.page(rspStruct)
 
 	return rspStruct, nil
 }
@@ -638,44 +1587,57 @@ func (pd *PixelDrainClient) GetUserFiles(r *RequestGetUserFiles) (*ResponseGetUs
 // GetUserLists GET /api/user/lists
 func (pd *PixelDrainClient) GetUserLists(r *RequestGetUserLists) (*ResponseGetUserLists, error) {
 	if r.URL == "" {
-		r.URL = APIURL + "/user/lists"
-	}
-
-	// pixeldrain want an empty username and the APIKey as password
-	if r.Auth.IsAuthAvailable() {
-		addBasicAuthHeader(pd.Client.Header, "", r.Auth.APIKey)
+		r.URL = pd.API.URL + pd.API.User + "/lists"
 	}
 
-	rsp, err := pd.Client.Request.Get(r.URL, pd.Client.Header)
+	rsp, err := pd.request(context.Background(), http.MethodGet, r.URL, pd.header(r.Auth), nil)
 	if pd.Debug {
-		log.Println(rsp.Dump())
+		log.Println(rsp.dump())
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	rspStruct := &ResponseGetUserLists{}
-	err = rsp.ToJSON(rspStruct)
+	err = rsp.decodeJSON(rspStruct)
 	if err != nil {
 		return nil, err
 	}
 
 	status := false
-	if rsp.Response().StatusCode == http.StatusOK {
+	if rsp.StatusCode == http.StatusOK {
 		status = true
 	}
 
 	rspStruct.Success = status
-	rspStruct.StatusCode = rsp.Response().StatusCode
+	rspStruct.StatusCode = rsp.StatusCode
 
 	return rspStruct, nil
 }
 
-// pixeldrain want an empty username and the APIKey as password
-// addBasicAuthHeader create a http basic auth header from username and password
-func addBasicAuthHeader(h req.Header, u string, p string) *req.Header {
-	h["Authorization"] = "Basic " + generateBasicAuthToken(u, p)
-	return &h
+// header returns the headers of a request, a copy of Client.Header with the basic auth of the API key if there is one.
+// Client.Header itself is never changed, so requests with other credentials can run at the same time.
+func (pd *PixelDrainClient) header(auth Auth) http.Header {
+	h := pd.Client.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	// pixeldrain want an empty username and the APIKey as password
+	if auth.IsAuthAvailable() {
+		h.Set("Authorization", "Basic "+generateBasicAuthToken("", auth.APIKey))
+	}
+
+	return h
+}
+
+// requestAuth returns the credentials sent with a request, anonymous requests send none
+func requestAuth(auth Auth, anonymous bool) Auth {
+	if anonymous {
+		return Auth{}
+	}
+
+	return auth
 }
 
 // generateBasicAuthToken generate string for basic auth header
@@ -684,39 +1646,210 @@ func generateBasicAuthToken(u string, p string) string {
 	return base64.StdEncoding.EncodeToString([]byte(auth))
 }
 
-// UploadDirectory uploads all files in the given directory and its subdirectories
-func (pd *PixelDrainClient) UploadDirectory(directoryPath string, auth Auth, baseURL ...string) error {
-	// Use the provided base URL if present
-	apiURL := APIURL
-	if len(baseURL) > 0 {
-		apiURL = baseURL[0]
+// UploadDirectory uploads all files in the given directory and its subdirectories and reports the result per file.
+// The UploadRules of the client decide per file if it is uploaded anonymously and to which list it is added.
+// The upload stops at the first failed file unless ContinueOnError is set, the files up to it are reported
+// together with the error. A DryRun only hashes the files and reports them as BatchWouldUpload or skipped, no
+// file is uploaded and no list is created.
+func (pd *PixelDrainClient) UploadDirectory(r *RequestUploadDirectory) (*ResponseUploadDirectory, error) {
+	if r.URL == "" {
+		r.URL = pd.API.URL
+	}
+
+	// Get the appropriate hash file path based on the environment
+	if r.HashFilePath == "" {
+		r.HashFilePath = utils.GetHashFilePath()
 	}
 
-	files, err := utils.GetFilesInDirectory(directoryPath)
+	entries, err := utils.ScanDirectory(r.Directory)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Get the appropriate hash file path based on the environment
-	hashFilePath := utils.GetHashFilePath()
+	result := &ResponseUploadDirectory{Lists: map[string]string{}, ListURLs: map[string]string{}, Directory: r.Directory}
+
+	// collect the uploaded files per list title, keep the order of the first appearance
+	var listTitles []string
+	listFiles := map[string][]ListFile{}
+	// the list of the whole directory, an ID uploaded twice is added once
+	var allFiles []ListFile
+	inAllFiles := map[string]bool{}
+
+	var manifest *Manifest
+	if r.ManifestPath != "" && !r.DryRun {
+		manifest = &Manifest{Version: ManifestVersion, Directory: filepath.Base(filepath.Clean(r.Directory)), Created: time.Now().UTC()}
+	}
+
+	for _, entry := range entries {
+		filePath := entry.Path
+		if pd.isSidecar(filePath) {
+			continue
+		}
+
+		status, pipe, err := checkSpecialFile(r, entry)
+		if status != "" {
+			log.Printf("Not uploading file %s: %v", filePath, err)
+			result.Files = append(result.Files, BatchFileResult{Path: filePath, Status: status, Error: err.Error()})
+			if status == BatchFailed && !r.ContinueOnError {
+				return result, err
+			}
+			continue
+		}
 
-	for _, filePath := range files {
 		reqUpload := &RequestUpload{
-			PathToFile: filePath,
-			Anonymous:  false,
-			Auth:       auth,
-			URL:        apiURL + "/file",
+			PathToFile:  filePath,
+			Anonymous:   false,
+			Auth:        r.Auth,
+			URL:         r.URL + pd.API.File,
+			EncryptWith: r.EncryptWith,
+			Compress:    r.Compress,
+			ForceHash:   r.ForceHash,
+		}
+		if r.Progress != nil {
+			reqUpload.Progress = func(p utils.Progress) { r.Progress(filePath, p) }
+		}
+
+		if r.PreservePaths == PathInFileName {
+			reqUpload.FileName = flatFileName(r.Directory, filePath)
+		}
+		description := pd.describeInDirectory(r, filePath)
+
+		rule := pd.matchUploadRule(r.Directory, filePath)
+		rule.apply(reqUpload)
+
+		if r.DryRun {
+			if pipe {
+				result.Files = append(result.Files, BatchFileResult{Path: filePath, Status: BatchWouldUpload})
+			} else {
+				result.Files = append(result.Files, pd.dryRunUpload(reqUpload, r.HashFilePath))
+			}
+			continue
 		}
 
 		log.Printf("Uploading file: %s", filePath)
-		resp, err := pd.UploadPOST(reqUpload, hashFilePath)
-		if err != nil {
+		var resp *ResponseUpload
+		info, statErr := os.Stat(filePath)
+		if pipe {
+			err = readPipe(reqUpload)
+		}
+		if err == nil {
+			resp, err = pd.retryRateLimited(context.Background(), func() (*ResponseUpload, error) {
+				return pd.UploadPOST(reqUpload, r.HashFilePath)
+			})
+		}
+
+		fileResult := BatchFileResult{Path: filePath}
+		switch {
+		case err != nil:
 			log.Printf("Error uploading file %s: %v", filePath, err)
-			return err
+			fileResult.Status, fileResult.Error = BatchFailed, err.Error()
+		case resp.Duplicate != nil:
+			fileResult.Status, fileResult.Duplicate = BatchSkippedDuplicate, resp.Duplicate
+		default:
+			fileResult.Status, fileResult.ID, fileResult.URL = BatchCompleted, resp.ID, resp.GetFileURL()
+		}
+		result.Files = append(result.Files, fileResult)
+
+		if err != nil {
+			if r.ContinueOnError {
+				continue
+			}
+			return result, err
 		}
 
 		log.Printf("Upload response for file %s: %+v", filePath, resp)
+		if statErr == nil && !pipe {
+			manifest.add(r.Directory, info, filePath, resp)
+		}
+
+		if r.CreateList && resp.ID != "" && !inAllFiles[resp.ID] {
+			allFiles = append(allFiles, ListFile{ID: resp.ID, Description: description})
+			inAllFiles[resp.ID] = true
+		}
+
+		// the list of a rule wins over the automatic grouping
+		var title string
+		if rule != nil && rule.ListTitle != "" {
+			title = rule.ListTitle
+		} else if r.GroupBy != GroupNone {
+			title = r.GroupBy.listTitle(r.Directory, filePath, resp.MimeType)
+		}
+		if title != "" && resp.ID != "" {
+			if _, ok := listFiles[title]; !ok {
+				listTitles = append(listTitles, title)
+			}
+			listFiles[title] = append(listFiles[title], ListFile{ID: resp.ID, Description: description})
+		}
+	}
+
+	for _, title := range listTitles {
+		reqList := &RequestCreateList{
+			Title: title,
+			Files: listFiles[title],
+			Auth:  r.Auth,
+			URL:   r.URL + pd.API.List,
+		}
+
+		rsp, err := pd.CreateList(reqList)
+		if err != nil {
+			return result, err
+		}
+		result.Lists[title] = rsp.ID
+		result.ListURLs[title] = listURL(rsp.ID)
+
+		log.Printf("Created list %s with %d files: %s", title, len(reqList.Files), rsp.ID)
+	}
+
+	if len(allFiles) > 0 {
+		title := r.ListTitle
+		if title == "" {
+			title = filepath.Base(filepath.Clean(r.Directory))
+		}
+
+		rsp, err := pd.CreateList(&RequestCreateList{
+			Title: title,
+			Files: allFiles,
+			Auth:  r.Auth,
+			URL:   r.URL + pd.API.List,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.ListID, result.ListURL = rsp.ID, listURL(rsp.ID)
+
+		log.Printf("Created list %s of the directory with %d files: %s", title, len(allFiles), rsp.ID)
+	}
+
+	if manifest != nil {
+		if err := manifest.Save(r.ManifestPath); err != nil {
+			return result, err
+		}
+	}
+
+	if r.IndexPath != "" && !r.DryRun {
+		if err := result.SaveIndex(r.IndexPath); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// matchUploadRule returns the upload rule for a file inside of the given directory
+func (pd *PixelDrainClient) matchUploadRule(directoryPath string, filePath string) *UploadRule {
+	if len(pd.UploadRules) == 0 {
+		return nil
+	}
+
+	relPath, err := filepath.Rel(directoryPath, filePath)
+	if err != nil {
+		relPath = filepath.Base(filePath)
+	}
+
+	// the content of a named pipe can be read only once, it is matched by its path
+	if info, err := os.Stat(filePath); err == nil && !info.Mode().IsRegular() {
+		return pd.UploadRules.Match(relPath, 0, "")
 	}
 
-	return nil
+	return pd.UploadRules.Match(relPath, utils.GetFileSize(filePath), utils.GetMimeType(filePath))
 }
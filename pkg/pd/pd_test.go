@@ -1,8 +1,19 @@
 package pd_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -82,6 +93,14 @@ func TestPD_UploadPOST(t *testing.T) {
 	fmt.Println("POST Req: " + rsp.GetFileURL())
 }
 
+// TestPD_UserAgent checks the versioned default User-Agent
+func TestPD_UserAgent(t *testing.T) {
+	c := pd.New(nil, nil)
+
+	assert.Equal(t, pd.Version, c.Version())
+	assert.True(t, strings.HasPrefix(c.UserAgent(), "go-pd/"+pd.Version))
+}
+
 // TestPD_UploadPOST_Integration is an integration test for the POST upload method
 func TestPD_UploadPOST_Integration(t *testing.T) {
 	SetupTestEnvironment()
@@ -184,6 +203,416 @@ func TestPD_UploadPOST_DuplicateDetection_Integration(t *testing.T) {
 	assert.Empty(t, rsp.ID)
 }
 
+// TestPD_UploadPOST_ExtraFields checks that additional form fields are sent with the upload
+func TestPD_UploadPOST_ExtraFields(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.FormValue("new_option")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "extra-id"}`))
+	}))
+	defer server.Close()
+
+	req := &pd.RequestUpload{
+		File:      io.NopCloser(strings.NewReader("extra fields")),
+		FileName:  "extra.txt",
+		Anonymous: true,
+		URL:       server.URL + "/file",
+		Extra:     map[string]string{"new_option": "enabled"},
+	}
+
+	c := pd.New(nil, nil)
+	rsp, err := c.UploadPOST(req, testHashFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "extra-id", rsp.ID)
+	assert.Equal(t, "enabled", received)
+	assert.Equal(t, int64(len("extra fields")), rsp.Size)
+	assert.Equal(t, "text/plain; charset=utf-8", rsp.MimeType)
+	assert.Len(t, rsp.Hash, 64)
+}
+
+// TestPD_UploadPOST_DuplicateMatch reports the original of a skipped duplicate
+func TestPD_UploadPOST_DuplicateMatch(t *testing.T) {
+	SetupTestEnvironment()
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	hashFilePath := utils.GetHashFilePath()
+	if err := utils.SaveFileHash(hashFilePath, "testdata/original_cat.jpg", "1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pd.RequestUpload{
+		PathToFile: "testdata/cat.jpg",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+	}
+
+	c := pd.New(nil, nil)
+	rsp, err := c.UploadPOST(req, hashFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 409, rsp.StatusCode)
+	assert.Equal(t, "testdata/cat.jpg", rsp.Duplicate.Path)
+	assert.Equal(t, "testdata/original_cat.jpg", rsp.Duplicate.OriginalPath)
+}
+
+// TestPD_UploadPOST_DuplicateID answers a renamed copy with the remote file from the hash store
+func TestPD_UploadPOST_DuplicateID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "stored-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	data, _ := os.ReadFile("testdata/cat.jpg")
+	_ = os.WriteFile(filepath.Join(dir, "cat.jpg"), data, 0644)
+	_ = os.WriteFile(filepath.Join(dir, "renamed_cat.jpg"), data, 0644)
+
+	store := utils.NewMemoryHashStore()
+	c := pd.New(&pd.ClientOptions{HashStore: store, DisableUploadLog: true}, nil)
+	upload := func(name string) *pd.ResponseUpload {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			PathToFile: filepath.Join(dir, name),
+			Anonymous:  true,
+			URL:        server.URL + "/file",
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	assert.Equal(t, 201, upload("cat.jpg").StatusCode)
+	rsp := upload("renamed_cat.jpg")
+	assert.Equal(t, 409, rsp.StatusCode)
+	assert.Equal(t, filepath.Join(dir, "cat.jpg"), rsp.Duplicate.OriginalPath)
+	assert.Equal(t, "stored-id", rsp.Duplicate.ID)
+	assert.Equal(t, "https://pixeldrain.com/u/stored-id", rsp.Duplicate.URL)
+	assert.Equal(t, "stored-id", rsp.ID)
+	assert.Equal(t, "https://pixeldrain.com/u/stored-id", rsp.GetFileURL())
+}
+
+// accountServer accepts uploads and answers GET /user with the account of the API key
+func accountServer(accounts map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, key, _ := r.BasicAuth()
+		if r.URL.Path == "/user" {
+			_, _ = fmt.Fprintf(w, `{"username": %q}`, accounts[key])
+			return
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "account-file"}`))
+	}))
+}
+
+// TestPD_UploadPOST_DuplicatePerAccount the same file is no duplicate for a second account, but for a second key of
+// the same account
+func TestPD_UploadPOST_DuplicatePerAccount(t *testing.T) {
+	server := accountServer(map[string]string{"alice-key": "alice", "alice-rotated": "alice", "bob-key": "bob", "carol-key": "carol"})
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cat.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("a cat per account"), 0644))
+	hashFilePath := filepath.Join(dir, "hashes.csv")
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true}, nil)
+	upload := func(apiKey, path string) *pd.ResponseUpload {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			PathToFile: path,
+			Auth:       pd.Auth{APIKey: apiKey},
+			URL:        server.URL + "/file",
+		}, hashFilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	assert.Equal(t, 201, upload("alice-key", path).StatusCode)
+	assert.Equal(t, 409, upload("alice-key", path).StatusCode)
+	assert.Equal(t, 409, upload("alice-rotated", path).StatusCode)
+	assert.Equal(t, 201, upload("bob-key", path).StatusCode)
+
+	// the plain hashes of a store from before the accounts were kept apart still count
+	legacy := filepath.Join(dir, "legacy.txt")
+	assert.NoError(t, os.WriteFile(legacy, []byte("uploaded by an old version"), 0644))
+	sum := sha256.Sum256([]byte("uploaded by an old version"))
+	assert.NoError(t, utils.NewCSVHashStore(hashFilePath).Save(legacy, hex.EncodeToString(sum[:])))
+	c = pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true}, nil)
+	assert.Equal(t, 409, upload("carol-key", legacy).StatusCode)
+}
+
+// TestPD_UploadPOST_UploadCache skips files which an interrupted run already uploaded
+func TestPD_UploadPOST_UploadCache(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "cached-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache := utils.NewFileUploadCache(filepath.Join(dir, "uploads.cache"))
+	upload := func(hashFilePath string) *pd.ResponseUpload {
+		c := pd.New(&pd.ClientOptions{UploadCache: cache}, nil)
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			PathToFile: "testdata/cat.jpg",
+			Anonymous:  true,
+			URL:        server.URL + "/file",
+		}, hashFilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	assert.Equal(t, "cached-id", upload(filepath.Join(dir, "first.csv")).ID)
+
+	// the hash file of the first run was lost, the cache still knows the upload
+	rsp := upload(filepath.Join(dir, "second.csv"))
+	assert.Equal(t, "cached-id", rsp.ID)
+	assert.True(t, rsp.Success)
+	assert.Equal(t, 1, uploads)
+}
+
+// TestPD_UploadPOST_APIError keeps the error body of a rejected upload
+func TestPD_UploadPOST_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = w.Write([]byte(`{"success": false, "value": "file_too_large", "message": "The file you tried to upload is too large"}`))
+	}))
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	rsp, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile: "testdata/cat.jpg",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+	}, filepath.Join(t.TempDir(), "hashes.csv"))
+
+	var apiErr *pd.APIError
+	assert.Nil(t, rsp)
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, apiErr.StatusCode)
+		assert.Equal(t, "file_too_large", apiErr.Value)
+		assert.Equal(t, "The file you tried to upload is too large", apiErr.Message)
+	}
+
+	logs, err := os.ReadFile(pd.CSVFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(logs), "413,file_too_large,The file you tried to upload is too large")
+}
+
+// TestPD_UploadPOST_AfterUpload deletes or archives the local file after the upload
+func TestPD_UploadPOST_AfterUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "spool-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	hashFilePath := filepath.Join(dir, "hashes.csv")
+	c := pd.New(nil, nil)
+
+	deleted := filepath.Join(dir, "deleted.txt")
+	archived := filepath.Join(dir, "archived.txt")
+	_ = os.WriteFile(deleted, []byte("delete me"), 0644)
+	_ = os.WriteFile(archived, []byte("archive me"), 0644)
+
+	_, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile:        deleted,
+		Anonymous:         true,
+		URL:               server.URL + "/file",
+		DeleteAfterUpload: true,
+	}, hashFilePath)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, deleted)
+
+	_, err = c.UploadPOST(&pd.RequestUpload{
+		PathToFile: archived,
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+		ArchiveDir: filepath.Join(dir, "archive"),
+	}, hashFilePath)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, archived)
+	assert.FileExists(t, filepath.Join(dir, "archive", "archived.txt"))
+}
+
+// TestPD_UploadPOST_ArchiveSameName keeps the archived copies of two files with the same name
+func TestPD_UploadPOST_ArchiveSameName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "spool-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}, nil)
+
+	for _, sub := range []string{"sub", "other"} {
+		path := filepath.Join(dir, sub, "a.txt")
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte("from "+sub), 0644))
+
+		_, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, URL: server.URL + "/file", ArchiveDir: archive}, "")
+		assert.NoError(t, err)
+		assert.NoFileExists(t, path)
+	}
+
+	entries, err := os.ReadDir(archive)
+	assert.NoError(t, err)
+	var contents []string
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(archive, entry.Name()))
+		assert.NoError(t, err)
+		contents = append(contents, string(data))
+	}
+	assert.ElementsMatch(t, []string{"from sub", "from other"}, contents)
+	assert.FileExists(t, filepath.Join(archive, "a.txt"))
+}
+
+// TestPD_UploadPOST_Disabled uploads without the upload log and the duplicate check
+func TestPD_UploadPOST_Disabled(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "thin-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	hashFilePath := filepath.Join(dir, "hashes.csv")
+	logSize := func() int64 {
+		info, err := os.Stat(pd.CSVFilePath)
+		if err != nil {
+			return -1
+		}
+		return info.Size()
+	}
+	before := logSize()
+
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}, nil)
+	for i := 0; i < 2; i++ {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			PathToFile: "testdata/cat.jpg",
+			Anonymous:  true,
+			URL:        server.URL + "/file",
+		}, hashFilePath)
+		assert.NoError(t, err)
+		assert.Equal(t, 201, rsp.StatusCode)
+	}
+	assert.Equal(t, 2, uploads)
+	assert.NoFileExists(t, hashFilePath)
+	assert.Equal(t, before, logSize())
+
+	// without a hash file there is no duplicate check either
+	c = pd.New(&pd.ClientOptions{UploadLog: utils.CSVUploadLog{Path: filepath.Join(dir, "log.csv")}}, nil)
+	for i := 0; i < 2; i++ {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{
+			PathToFile: "testdata/cat.jpg",
+			Anonymous:  true,
+			URL:        server.URL + "/file",
+		}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 201, rsp.StatusCode)
+	}
+	assert.Equal(t, 4, uploads)
+}
+
+// TestPD_UploadPOST_UploadLogFormat writes the upload log as JSON Lines
+func TestPD_UploadPOST_UploadLogFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "jsonl-id"}`))
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "uploads.jsonl")
+	c := pd.New(&pd.ClientOptions{UploadLogPath: logPath, UploadLogFormat: utils.UploadLogJSONL, DisableDedup: true}, nil)
+	_, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile: "testdata/cat.jpg",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+	}, "")
+	assert.NoError(t, err)
+
+	uploads, err := utils.JSONLUploadLog{Path: logPath}.FindByHash("1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b")
+	assert.NoError(t, err)
+	if assert.Len(t, uploads, 1) {
+		assert.Equal(t, "jsonl-id", uploads[0].ID)
+	}
+}
+
+// TestPD_UploadPOST_StreamFileSize sends a File of known size while it is written, it isn't read into memory first
+func TestPD_UploadPOST_StreamFileSize(t *testing.T) {
+	started := make(chan struct{})
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received, _ = io.ReadAll(file)
+		assert.Equal(t, "stream.bin", header.Filename)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "stream-id"}`))
+	}))
+	defer server.Close()
+
+	// the rest of the file is only written once the request reached the server
+	pr, pw := io.Pipe()
+	streaming := make(chan bool, 1)
+	go func() {
+		_, _ = pw.Write([]byte("first half "))
+		select {
+		case <-started:
+			streaming <- true
+		case <-time.After(5 * time.Second):
+			streaming <- false
+		}
+		_, _ = pw.Write([]byte("second half"))
+		_ = pw.Close()
+	}()
+
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}, nil)
+	rsp, err := c.UploadPOST(&pd.RequestUpload{
+		File:        pr,
+		FileSize:    22,
+		ContentType: "application/x-test",
+		FileName:    "stream.bin",
+		Anonymous:   true,
+		URL:         server.URL + "/file",
+	}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "first half second half", string(received))
+	assert.Equal(t, "stream-id", rsp.ID)
+	assert.Equal(t, int64(22), rsp.Size)
+	assert.Equal(t, "application/x-test", rsp.MimeType)
+	sum := sha256.Sum256(received)
+	assert.Equal(t, hex.EncodeToString(sum[:]), rsp.Hash)
+	assert.True(t, <-streaming, "the request was not sent while the file was written")
+}
+
 // TestPD_UploadPUT is a unit test for the PUT upload method
 func TestPD_UploadPUT(t *testing.T) {
 	server := pd.MockFileUploadServer()
@@ -206,6 +635,9 @@ func TestPD_UploadPUT(t *testing.T) {
 	assert.Equal(t, 201, rsp.StatusCode)
 	assert.NotEmpty(t, rsp.ID)
 	assert.Equal(t, "https://pixeldrain.com/u/123456", rsp.GetFileURL())
+	assert.Equal(t, "1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b", rsp.Hash)
+	assert.Equal(t, utils.GetFileSize("testdata/cat.jpg"), rsp.Size)
+	assert.Equal(t, "image/jpeg", rsp.MimeType)
 	fmt.Println("PUT Req: " + rsp.GetFileURL())
 }
 
@@ -283,6 +715,30 @@ func TestPD_Download(t *testing.T) {
 	assert.Equal(t, true, rsp.Success)
 }
 
+// TestPD_Download_Writer streams the download into a writer
+func TestPD_Download_Writer(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	req := &pd.RequestDownload{
+		Writer: &buf,
+		ID:     "K1dA8U5W",
+		URL:    server.URL + "/file/K1dA8U5W",
+	}
+
+	c := pd.New(nil, nil)
+	rsp, err := c.Download(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := os.ReadFile("testdata/cat.jpg")
+	assert.True(t, rsp.Success)
+	assert.Equal(t, int64(len(expected)), rsp.FileSize)
+	assert.Equal(t, expected, buf.Bytes())
+}
+
 // TestPD_Download_Integration run a real integration test against the service
 func TestPD_Download_Integration(t *testing.T) {
 	if testing.Short() {
@@ -394,8 +850,8 @@ func TestPD_DownloadThumbnail(t *testing.T) {
 
 	req := &pd.RequestThumbnail{
 		ID:         "K1dA8U5W",
-		Height:     "64",
-		Width:      "64",
+		Height:     pd.ThumbnailSize64,
+		Width:      pd.ThumbnailSize64,
 		PathToSave: "testdata/cat_download_thumbnail.jpg",
 		URL:        testURL,
 	}
@@ -413,6 +869,31 @@ func TestPD_DownloadThumbnail(t *testing.T) {
 	assert.Equal(t, int64(51680), rsp.FileSize)
 }
 
+// TestPD_GetThumbnailBytes is a unit test for the GET "thumbnail" method without saving to disk
+func TestPD_GetThumbnailBytes(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+	testURL := server.URL + "/file/K1dA8U5W/thumbnail"
+
+	req := &pd.RequestThumbnail{
+		ID:     "K1dA8U5W",
+		Height: pd.ThumbnailSize64,
+		Width:  pd.ThumbnailSize64,
+		URL:    testURL,
+	}
+
+	c := pd.New(nil, nil)
+	rsp, err := c.GetThumbnailBytes(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 200, rsp.StatusCode)
+	assert.Equal(t, true, rsp.Success)
+	assert.Equal(t, "image/jpeg", rsp.ContentType)
+	assert.Equal(t, 51680, len(rsp.Data))
+}
+
 // TestPD_DownloadThumbnail_Integration run a real integration test against the service
 func TestPD_DownloadThumbnail_Integration(t *testing.T) {
 	if testing.Short() {
@@ -437,8 +918,8 @@ func TestPD_DownloadThumbnail_Integration(t *testing.T) {
 
 	reqThumbnail := &pd.RequestThumbnail{
 		ID:         fileIDPost,
-		Height:     "64",
-		Width:      "64",
+		Height:     pd.ThumbnailSize64,
+		Width:      pd.ThumbnailSize64,
 		PathToSave: "testdata/cat_download_thumbnail.jpg",
 	}
 
@@ -854,8 +1335,11 @@ func TestUploadDirectory(t *testing.T) {
 	server := pd.MockFileUploadServer()
 	defer server.Close()
 
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
 	clientOptions := &pd.ClientOptions{
 		Debug: true,
+		API:   &spec,
 	}
 
 	client := pd.New(clientOptions, nil)
@@ -866,12 +1350,124 @@ func TestUploadDirectory(t *testing.T) {
 	}
 
 	// Use the mock server URL as the base URL
-	err := client.UploadDirectory("testdata/test_directory", auth, server.URL)
+	rsp, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory: "testdata/test_directory",
+		Auth:      auth,
+		URL:       server.URL,
+	})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	// Additional checks can be added to validate the upload and logging
+	assert.NotEmpty(t, rsp.Files)
+	assert.Empty(t, rsp.Failed())
+}
+
+func TestUploadDirectory_WithRules(t *testing.T) {
+	SetupTestEnvironment()
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	clientOptions := &pd.ClientOptions{
+		API: &spec,
+		UploadRules: pd.UploadRules{
+			{Pattern: "test_directory_3/*", Anonymous: true},
+			{MimeType: "image/*", ListTitle: "Images"},
+		},
+	}
+
+	client := pd.New(clientOptions, nil)
+
+	auth := pd.Auth{
+		APIKey: "test-api-key",
+	}
+
+	rsp, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory: "testdata/test_directory",
+		Auth:      auth,
+		URL:       server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	assert.Empty(t, rsp.Failed())
+}
+
+func TestUploadDirectory_ContinueOnError(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		if uploads == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success": false, "value": "bad_name"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "dir-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := filepath.Join(dir, "files")
+	_ = os.Mkdir(files, 0755)
+	_ = os.WriteFile(filepath.Join(files, "a.txt"), []byte("continue a"), 0644)
+	_ = os.WriteFile(filepath.Join(files, "b.txt"), []byte("continue b"), 0644)
+
+	client := pd.New(&pd.ClientOptions{HashStore: utils.NewCSVHashStore(filepath.Join(dir, "hashes.csv"))}, nil)
+	req := &pd.RequestUploadDirectory{Directory: files, URL: server.URL}
+
+	rsp, err := client.UploadDirectory(req)
+	assert.Error(t, err)
+	assert.Len(t, rsp.Files, 1)
+	assert.Equal(t, pd.BatchFailed, rsp.Files[0].Status)
+
+	uploads = 0
+	req.ContinueOnError = true
+	rsp, err = client.UploadDirectory(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, rsp.Files, 2)
+	assert.Len(t, rsp.Failed(), 1)
+	assert.Equal(t, pd.BatchCompleted, rsp.Files[1].Status)
+	assert.Equal(t, "dir-id", rsp.Files[1].ID)
+}
+
+// TestUploadDirectory_Progress reports the progress per file
+func TestUploadDirectory_Progress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "progress-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := filepath.Join(dir, "files")
+	_ = os.Mkdir(files, 0755)
+	_ = os.WriteFile(filepath.Join(files, "a.txt"), []byte("progress a"), 0644)
+	_ = os.WriteFile(filepath.Join(files, "b.txt"), []byte("progress of b"), 0644)
+
+	var mu sync.Mutex
+	done := map[string]int64{}
+	client := pd.New(&pd.ClientOptions{HashStore: utils.NewMemoryHashStore(), DisableUploadLog: true}, nil)
+	_, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory: files,
+		URL:       server.URL,
+		Progress: func(filePath string, p utils.Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			if p.Done {
+				done[filepath.Base(filePath)] = p.Transferred
+			}
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a.txt": 10, "b.txt": 13}, done)
 }
 
 func TestUploadDirectory_Integration(t *testing.T) {
@@ -891,7 +1487,11 @@ func TestUploadDirectory_Integration(t *testing.T) {
 	// Use the actual API URL
 	apiURL := "https://pixeldrain.com/api"
 
-	err := client.UploadDirectory("testdata/test_directory", auth, apiURL)
+	_, err := client.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory: "testdata/test_directory",
+		Auth:      auth,
+		URL:       apiURL,
+	})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -968,3 +1568,39 @@ func TestIsDuplicate(t *testing.T) {
 
 	assert.True(t, isDuplicate)
 }
+
+// TestPD_UploadPOST_ConcurrentDuplicates uploads equal files at the same time, only the first one may be sent
+func TestPD_UploadPOST_ConcurrentDuplicates(t *testing.T) {
+	var uploads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		atomic.AddInt32(&uploads, 1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "concurrent-id"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	hashes := filepath.Join(dir, "hashes.csv")
+	client := pd.New(nil, nil)
+
+	var wg sync.WaitGroup
+	var duplicates int32
+	for i := 0; i < 8; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("copy%d.txt", i))
+		assert.NoError(t, os.WriteFile(path, []byte("same content"), 0644))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rsp, err := client.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, URL: server.URL}, hashes)
+			if assert.NoError(t, err) && rsp.Duplicate != nil {
+				atomic.AddInt32(&duplicates, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&uploads))
+	assert.Equal(t, int32(7), atomic.LoadInt32(&duplicates))
+}
@@ -0,0 +1,357 @@
+// Package pdtest is a fake pixeldrain server for the tests of the code which uses go-pd.
+// Uploads are kept in memory, the info, download, delete and user files endpoints work on them,
+// and faults like 429, 500 or slow responses can be injected per method and path.
+package pdtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// File a file stored by the fake server
+type File struct {
+	ID         string
+	Name       string
+	Data       []byte
+	MimeType   string
+	DateUpload time.Time
+	Downloads  int64
+}
+
+// Fault is injected into the requests it matches instead of the answer of the fake
+type Fault struct {
+	Method string        // e.g. http.MethodPost, every method if empty
+	Path   string        // prefix of the path, e.g. "/file", every path if empty
+	Status int           // status of the answer, e.g. http.StatusTooManyRequests, 0 only delays the request
+	Delay  time.Duration // pause before the request is answered
+	Times  int           // requests the fault is injected into, 0 for all until ClearFaults
+}
+
+// matches checks the method and path of the request
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// Server the fake pixeldrain, the API is served at the root of the URL of the embedded httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	files    map[string]*File
+	faults   []*Fault
+	requests int
+	nextID   int
+}
+
+// NewServer starts a fake pixeldrain without files, close it after the test
+func NewServer() *Server {
+	s := &Server{files: map[string]*File{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// APISpec the API spec of the client for the fake server
+func (s *Server) APISpec() pd.APISpec {
+	spec := pd.DefaultAPISpec
+	spec.URL = s.URL
+
+	return spec
+}
+
+// Client returns a client of the fake server. Without options nothing is logged or written next to the test,
+// the upload log and the duplicate check are disabled.
+func (s *Server) Client(options *pd.ClientOptions) *pd.PixelDrainClient {
+	if options == nil {
+		options = &pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}
+	}
+	opts := *options
+	spec := s.APISpec()
+	opts.API = &spec
+
+	return pd.New(&opts, nil)
+}
+
+// AddFile stores a file like an upload and returns its ID
+func (s *Server) AddFile(name string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.store(name, data).ID
+}
+
+// File returns a copy of the stored file
+func (s *Server) File(id string) (File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[id]
+	if !ok {
+		return File{}, false
+	}
+
+	return *file, true
+}
+
+// Files returns copies of the stored files in the order of their upload
+func (s *Server) Files() []File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedFiles()
+}
+
+// Inject adds a fault, faults are matched in the order they were added
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests the number of requests the server received, the ones with faults included
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fault := s.fault(r)
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			if fault.Status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			writeError(w, fault.Status, "injected_fault", http.StatusText(fault.Status))
+			return
+		}
+	}
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/file":
+		s.uploadPOST(w, r)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/file/"):
+		s.uploadPUT(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/file/") && strings.HasSuffix(path, "/info"):
+		s.info(w, strings.TrimSuffix(strings.TrimPrefix(path, "/file/"), "/info"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/file/"):
+		s.download(w, r, strings.TrimPrefix(path, "/file/"))
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/file/"):
+		s.delete(w, strings.TrimPrefix(path, "/file/"))
+	case r.Method == http.MethodGet && path == "/user/files":
+		s.userFiles(w)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "the fake pixeldrain doesn't serve "+r.Method+" "+path)
+	}
+}
+
+// fault counts the request and returns the first matching fault, nil if there is none
+func (s *Server) fault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+func (s *Server) uploadPOST(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "no_file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	name := r.FormValue("name")
+	if name == "" {
+		name = header.Filename
+	}
+	s.upload(w, name, file)
+}
+
+func (s *Server) uploadPUT(w http.ResponseWriter, r *http.Request) {
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/file/"))
+	if err != nil || name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "no_file", "the file name is missing")
+		return
+	}
+	s.upload(w, name, r.Body)
+}
+
+func (s *Server) upload(w http.ResponseWriter, name string, body io.Reader) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "read_error", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	file := s.store(name, data)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "id": file.ID})
+}
+
+// info answers a single ID with the info of the file and several IDs with the infos of the existing files
+func (s *Server) info(w http.ResponseWriter, ids string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.Contains(ids, ",") {
+		file, ok := s.files[ids]
+		if !ok {
+			writeError(w, http.StatusNotFound, "not_found", "the file could not be found")
+			return
+		}
+		writeJSON(w, http.StatusOK, fileInfo(file))
+		return
+	}
+
+	infos := []pd.ResponseFileInfo{}
+	for _, id := range strings.Split(ids, ",") {
+		if file, ok := s.files[id]; ok {
+			infos = append(infos, fileInfo(file))
+		}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) download(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	file, ok := s.files[id]
+	if ok {
+		file.Downloads++
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "the file could not be found")
+		return
+	}
+
+	w.Header().Set("Content-Type", file.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	http.ServeContent(w, r, file.Name, file.DateUpload, bytes.NewReader(file.Data))
+}
+
+func (s *Server) delete(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	_, ok := s.files[id]
+	delete(s.files, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "the file could not be found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "value": "file_deleted", "message": "The file has been deleted."})
+}
+
+func (s *Server) userFiles(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := []pd.ResponseFileInfo{}
+	for _, file := range s.sortedFiles() {
+		files = append(files, fileInfo(&file))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "files": files})
+}
+
+// store saves the file under a new ID, the caller holds the lock
+func (s *Server) store(name string, data []byte) *File {
+	s.nextID++
+	file := &File{
+		ID:         fmt.Sprintf("fake%04d", s.nextID),
+		Name:       name,
+		Data:       data,
+		MimeType:   http.DetectContentType(data),
+		DateUpload: time.Now().UTC().Truncate(time.Second),
+	}
+	s.files[file.ID] = file
+
+	return file
+}
+
+// sortedFiles copies of the files ordered by ID, the caller holds the lock
+func (s *Server) sortedFiles() []File {
+	files := make([]File, 0, len(s.files))
+	for _, file := range s.files {
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+
+	return files
+}
+
+func fileInfo(file *File) pd.ResponseFileInfo {
+	sum := sha256.Sum256(file.Data)
+	info := pd.ResponseFileInfo{
+		ID:         file.ID,
+		Name:       file.Name,
+		Size:       int64(len(file.Data)),
+		Downloads:  file.Downloads,
+		DateUpload: file.DateUpload,
+		MimeType:   file.MimeType,
+		HashSha256: hex.EncodeToString(sum[:]),
+		CanEdit:    true,
+	}
+	info.StatusCode = http.StatusOK
+	info.Success = true
+
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, value, message string) {
+	writeJSON(w, status, map[string]interface{}{"success": false, "value": value, "message": message})
+}
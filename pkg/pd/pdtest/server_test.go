@@ -0,0 +1,99 @@
+package pdtest_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+)
+
+// TestServer_Files uploads, inspects, downloads and deletes a file on the fake
+func TestServer_Files(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	c := server.Client(nil)
+
+	path := filepath.Join(t.TempDir(), "cat.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("meow"), 0o644))
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true}, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	stored, ok := server.File(rsp.ID)
+	assert.True(t, ok)
+	assert.Equal(t, "cat.txt", stored.Name)
+
+	putRsp, err := c.UploadPUT(&pd.RequestUpload{PathToFile: path, FileName: "dog.txt", Anonymous: true})
+	assert.NoError(t, err)
+	assert.Len(t, server.Files(), 2)
+
+	info, err := c.GetFileInfo(&pd.RequestFileInfo{ID: rsp.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), info.Size)
+	assert.Equal(t, "cat.txt", info.Name)
+
+	infos, err := c.GetFileInfoMany(&pd.RequestFileInfoMany{IDs: []string{rsp.ID, putRsp.ID, "missing"}})
+	assert.NoError(t, err)
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "dog.txt", infos[putRsp.ID].Name)
+
+	var downloaded bytes.Buffer
+	_, err = c.Download(&pd.RequestDownload{ID: rsp.ID, Writer: &downloaded})
+	assert.NoError(t, err)
+	assert.Equal(t, "meow", downloaded.String())
+
+	files, err := c.GetUserFiles(&pd.RequestGetUserFiles{Auth: pd.Auth{APIKey: "key"}})
+	assert.NoError(t, err)
+	assert.Len(t, files.Files, 2)
+
+	_, err = c.Delete(&pd.RequestDelete{ID: rsp.ID, Auth: pd.Auth{APIKey: "key"}})
+	assert.NoError(t, err)
+	_, ok = server.File(rsp.ID)
+	assert.False(t, ok)
+
+	info, err = c.GetFileInfo(&pd.RequestFileInfo{ID: rsp.ID})
+	assert.NoError(t, err)
+	assert.False(t, info.Success)
+	assert.Equal(t, http.StatusNotFound, info.StatusCode)
+	assert.Equal(t, "not_found", info.Value)
+}
+
+// TestServer_Faults the client retries injected server errors and rate limits, a slow response runs into the timeout
+func TestServer_Faults(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	id := server.AddFile("cat.txt", []byte("meow"))
+
+	policy := pd.DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, RetryPolicy: &policy})
+
+	server.Inject(pdtest.Fault{Path: "/file", Status: http.StatusInternalServerError, Times: 1})
+	server.Inject(pdtest.Fault{Path: "/file", Status: http.StatusTooManyRequests, Times: 1})
+	info, err := c.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.NoError(t, err)
+	assert.Equal(t, "cat.txt", info.Name)
+	assert.Equal(t, 3, server.Requests())
+
+	server.Inject(pdtest.Fault{Method: http.MethodGet, Status: http.StatusServiceUnavailable})
+	info, err = c.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, info.StatusCode)
+	assert.Equal(t, "injected_fault", info.Value)
+	assert.Equal(t, 3+policy.MaxAttempts, server.Requests())
+	server.ClearFaults()
+
+	server.Inject(pdtest.Fault{Delay: time.Second})
+	slow := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, Timeout: 50 * time.Millisecond, RetryPolicy: &pd.RetryPolicy{MaxAttempts: 1}})
+	start := time.Now()
+	_, err = slow.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,192 @@
+package pd
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// DefaultMaxConcurrency upper bound of the suggested concurrency if the limits don't set one
+const DefaultMaxConcurrency = 4
+
+// Limits the known rate and bandwidth limits of the account and the connection, 0 means unknown
+type Limits struct {
+	RequestsPerMinute  int   // API requests per minute
+	BytesPerSecond     int64 // upload bandwidth
+	MaxConcurrency     int   // parallel uploads, DefaultMaxConcurrency if 0
+	MaxFileSize        int64 // largest file the account may upload
+	TransferCapLeft    int64 // bytes left of the monthly transfer cap
+	RequestOverhead    int   // extra requests of the batch, e.g. 1 for the list creation
+	AvgRequestDuration time.Duration
+}
+
+// LimitsFromUser fills the file size limit and the transfer cap from the account
+func LimitsFromUser(user *ResponseGetUser) Limits {
+	limits := Limits{MaxFileSize: user.Subscription.FileSizeLimit}
+	if user.MonthlyTransferCap > 0 {
+		limits.TransferCapLeft = user.MonthlyTransferCap - user.MonthlyTransferUsed
+	}
+
+	return limits
+}
+
+type RequestPlanBatch struct {
+	Paths  []string
+	Limits Limits
+}
+
+// BatchPlan the estimate of a batch before it is started
+type BatchPlan struct {
+	Files            int           `json:"files"`
+	Bytes            int64         `json:"bytes"`
+	Requests         int           `json:"requests"`
+	ExpectedDuration time.Duration `json:"expected_duration"`
+	Concurrency      int           `json:"concurrency"` // suggested number of parallel uploads
+	TooLarge         []string      `json:"too_large"`   // files above the file size limit of the account
+	Warnings         []string      `json:"warnings"`    // limits the batch will run into
+	Limits           Limits        `json:"limits"`
+}
+
+// PlanBatch estimates the requests, bytes and duration of uploading the files under the given limits.
+// The duration is bound by the slower of the bandwidth and the request rate.
+func (pd *PixelDrainClient) PlanBatch(r *RequestPlanBatch) (*BatchPlan, error) {
+	plan := &BatchPlan{Limits: r.Limits}
+	for _, path := range r.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if r.Limits.MaxFileSize > 0 && info.Size() > r.Limits.MaxFileSize {
+			plan.TooLarge = append(plan.TooLarge, path)
+			continue
+		}
+
+		plan.Files++
+		plan.Bytes += info.Size()
+	}
+	plan.Requests = plan.Files + r.Limits.RequestOverhead
+
+	var bandwidthTime, requestTime time.Duration
+	if r.Limits.BytesPerSecond > 0 {
+		bandwidthTime = time.Duration(float64(plan.Bytes) / float64(r.Limits.BytesPerSecond) * float64(time.Second))
+	}
+	if r.Limits.RequestsPerMinute > 0 {
+		requestTime = time.Duration(float64(plan.Requests) / float64(r.Limits.RequestsPerMinute) * float64(time.Minute))
+	}
+
+	maxConcurrency := r.Limits.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+
+	// parallel uploads only help while the bandwidth is not used up, i.e. for many small files
+	// where the latency of the requests dominates
+	plan.Concurrency = maxConcurrency
+	if bandwidthTime > 0 && requestTime >= bandwidthTime {
+		plan.Concurrency = 1
+	}
+	if r.Limits.AvgRequestDuration > 0 && r.Limits.RequestsPerMinute > 0 {
+		// requests in flight at the allowed rate
+		inFlight := math.Ceil(float64(r.Limits.RequestsPerMinute) / float64(time.Minute) * float64(r.Limits.AvgRequestDuration))
+		if int(inFlight) < plan.Concurrency {
+			plan.Concurrency = int(inFlight)
+		}
+	}
+	if plan.Files < plan.Concurrency {
+		plan.Concurrency = plan.Files
+	}
+	if plan.Concurrency < 1 {
+		plan.Concurrency = 1
+	}
+
+	plan.ExpectedDuration = bandwidthTime
+	if requestTime > plan.ExpectedDuration {
+		plan.ExpectedDuration = requestTime
+	}
+	if plan.ExpectedDuration == 0 && r.Limits.AvgRequestDuration > 0 {
+		plan.ExpectedDuration = time.Duration(int64(plan.Requests) * int64(r.Limits.AvgRequestDuration) / int64(plan.Concurrency))
+	}
+
+	if len(plan.TooLarge) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d files exceed the file size limit of %s", len(plan.TooLarge), utils.FormatFileSize(r.Limits.MaxFileSize)))
+	}
+	if r.Limits.TransferCapLeft > 0 && plan.Bytes > r.Limits.TransferCapLeft {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("the batch of %s exceeds the %s left of the monthly transfer cap", utils.FormatFileSize(plan.Bytes), utils.FormatFileSize(r.Limits.TransferCapLeft)))
+	}
+
+	return plan, nil
+}
+
+// EnforcePlan makes the client keep the limits of the plan, uploads are throttled to the bandwidth
+// and paced to the request rate. A nil plan removes the limits.
+func (pd *PixelDrainClient) EnforcePlan(plan *BatchPlan) {
+	if plan == nil {
+		pd.uploadLimiter = nil
+		pd.requestPacer = nil
+		return
+	}
+
+	pd.uploadLimiter = utils.NewSharedRateLimiter(plan.Limits.BytesPerSecond, pd.sharedPath(sharedUploadRateFile))
+	pd.requestPacer = newPacer(plan.Limits.RequestsPerMinute, pd.sharedPath(sharedPacerFile))
+}
+
+// pacer spaces requests evenly to keep a requests per minute limit
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	path     string // slots shared by all processes using the file, see ClientOptions.SharedStateDir
+}
+
+func newPacer(requestsPerMinute int, path string) *pacer {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+
+	return &pacer{interval: time.Minute / time.Duration(requestsPerMinute), path: path}
+}
+
+// wait blocks until the next request may be sent, a nil pacer never blocks
+func (p *pacer) wait() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	slot := p.take(now)
+	p.mu.Unlock()
+
+	time.Sleep(slot.Sub(now))
+}
+
+// take returns the next free slot and moves it on by the interval
+func (p *pacer) take(now time.Time) time.Time {
+	if p.path != "" {
+		var slot time.Time
+		_, err := updateSharedTime(p.path, func(next time.Time) time.Time {
+			if next.Before(now) {
+				next = now
+			}
+			slot = next
+			return next.Add(p.interval)
+		})
+		if err == nil {
+			return slot
+		}
+		log.Printf("Error sharing the request pacer: %v", err)
+	}
+
+	if p.next.Before(now) {
+		p.next = now
+	}
+	slot := p.next
+	p.next = p.next.Add(p.interval)
+
+	return slot
+}
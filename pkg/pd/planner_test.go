@@ -0,0 +1,65 @@
+package pd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_PlanBatch(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, name)
+		_ = os.WriteFile(path, make([]byte, 1000), 0644)
+		paths = append(paths, path)
+	}
+	large := filepath.Join(dir, "large")
+	_ = os.WriteFile(large, make([]byte, 5000), 0644)
+	paths = append(paths, large)
+
+	c := pd.New(nil, nil)
+	plan, err := c.PlanBatch(&pd.RequestPlanBatch{
+		Paths: paths,
+		Limits: pd.Limits{
+			RequestsPerMinute: 60,
+			BytesPerSecond:    100,
+			MaxFileSize:       2000,
+			TransferCapLeft:   2500,
+			RequestOverhead:   1,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, plan.Files)
+	assert.Equal(t, int64(3000), plan.Bytes)
+	assert.Equal(t, 4, plan.Requests)
+	assert.Equal(t, []string{large}, plan.TooLarge)
+	assert.Len(t, plan.Warnings, 2)
+	// 3000 bytes at 100 B/s are slower than 4 requests at 1/s
+	assert.Equal(t, 30*time.Second, plan.ExpectedDuration)
+	assert.Equal(t, 3, plan.Concurrency)
+}
+
+func TestPD_PlanBatch_RequestBound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "small")
+	_ = os.WriteFile(path, []byte("small"), 0644)
+
+	c := pd.New(nil, nil)
+	plan, err := c.PlanBatch(&pd.RequestPlanBatch{
+		Paths:  []string{path, path},
+		Limits: pd.Limits{RequestsPerMinute: 6, BytesPerSecond: 1 << 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 20*time.Second, plan.ExpectedDuration)
+	assert.Equal(t, 1, plan.Concurrency)
+}
@@ -0,0 +1,280 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// FailureKind groups errors by what the user can do about them
+type FailureKind string
+
+const (
+	FailureNone        FailureKind = "none"
+	FailureUsage       FailureKind = "usage"        // missing or invalid arguments
+	FailureAuth        FailureKind = "auth"         // the API key is missing, wrong or lacks the permission
+	FailureNotFound    FailureKind = "not_found"    // the file or list doesn't exist on pixeldrain
+	FailureRateLimited FailureKind = "rate_limited" // pixeldrain asked to slow down
+	FailureBudget      FailureKind = "budget"       // the bandwidth budget of the client is used up
+	FailureTooLarge    FailureKind = "too_large"    // the file exceeds the limits of the account
+	FailureChecksum    FailureKind = "checksum"     // the transferred data differs from the data on pixeldrain
+	FailureLocalFile   FailureKind = "local_file"   // a local file is missing or can't be read
+	FailureNetwork     FailureKind = "network"      // pixeldrain could not be reached
+	FailureServer      FailureKind = "server"       // pixeldrain failed to handle the request
+	FailureCancelled   FailureKind = "cancelled"
+	FailurePartial     FailureKind = "partial" // some files of a batch failed
+	FailureUnknown     FailureKind = "unknown"
+)
+
+// exit codes of the CLI per kind of failure, scripts can tell retryable failures from permanent ones
+var exitCodes = map[FailureKind]int{
+	FailureNone:        0,
+	FailureUnknown:     1,
+	FailureUsage:       2,
+	FailureAuth:        3,
+	FailureNotFound:    4,
+	FailureRateLimited: 5,
+	FailureBudget:      5,
+	FailureTooLarge:    6,
+	FailureChecksum:    7,
+	FailureLocalFile:   8,
+	FailureNetwork:     9,
+	FailureServer:      9,
+	FailureCancelled:   130,
+	FailurePartial:     10,
+}
+
+// Message is the text shown for a kind of failure
+type Message struct {
+	Title  string // one line, e.g. for a notification
+	Action string // what the user can do, empty if there is nothing
+}
+
+// DefaultMessages are the English texts of the Presenter
+var DefaultMessages = map[FailureKind]Message{
+	FailureUsage:       {"The request is incomplete", "Check the given files and options."},
+	FailureAuth:        {"pixeldrain refused the API key", "Check the API key in the pixeldrain account settings."},
+	FailureNotFound:    {"The file or list was not found", "Check the ID, the file may have been deleted or expired."},
+	FailureRateLimited: {"pixeldrain asked to slow down", "Wait a few minutes and try again."},
+	FailureBudget:      {"The bandwidth budget is used up", "Wait for the budget to reset or raise it."},
+	FailureTooLarge:    {"The file is too large for the account", "Upgrade the account or split the file."},
+	FailureChecksum:    {"The transferred file is damaged", "Transfer the file again."},
+	FailureLocalFile:   {"A local file could not be read", "Check that the file exists and is readable."},
+	FailureNetwork:     {"pixeldrain could not be reached", "Check the internet connection and try again."},
+	FailureServer:      {"pixeldrain had a problem with the request", "Try again later."},
+	FailureCancelled:   {"The transfer was cancelled", ""},
+	FailurePartial:     {"%d of %d files failed", "Run it again to retry the failed files."},
+	FailureUnknown:     {"Something went wrong", ""},
+}
+
+// DefaultStatusLabels are the English names of the batch states in summaries
+var DefaultStatusLabels = map[BatchFileStatus]string{
+	BatchCompleted:         "uploaded",
+	BatchFailed:            "failed",
+	BatchAborted:           "aborted",
+	BatchNotStarted:        "not started",
+	BatchSkippedDuplicate:  "skipped as duplicate",
+	BatchWouldUpload:       "would be uploaded",
+	BatchSkippedEmpty:      "skipped as empty",
+	BatchSkippedSpecial:    "skipped as special file",
+	BatchSkippedUnreadable: "skipped as unreadable",
+}
+
+// Summary describes the outcome of an operation for end users instead of raw API responses
+type Summary struct {
+	Kind     FailureKind `json:"kind"`
+	Title    string      `json:"title"`
+	Detail   string      `json:"detail,omitempty"` // the cause, e.g. the message of pixeldrain
+	Action   string      `json:"action,omitempty"`
+	Failures []string    `json:"failures,omitempty"` // path and cause of the first failed files of a batch
+	ExitCode int         `json:"exit_code"`
+}
+
+func (s Summary) String() string {
+	lines := []string{s.Title}
+	if s.Detail != "" {
+		lines = append(lines, s.Detail)
+	}
+	for _, failure := range s.Failures {
+		lines = append(lines, "  "+failure)
+	}
+	if s.Action != "" {
+		lines = append(lines, s.Action)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Presenter turns errors and batch results into summaries. Replace Messages and StatusLabels to localize them,
+// missing entries fall back to the defaults.
+type Presenter struct {
+	Messages     map[FailureKind]Message
+	StatusLabels map[BatchFileStatus]string
+	MaxFailures  int // failed files listed in a batch summary, 0 = 5
+}
+
+// DefaultPresenter uses the English texts
+var DefaultPresenter = &Presenter{}
+
+// ExitCode returns the exit code of the CLI for the error, 0 for nil
+func ExitCode(err error) int {
+	return exitCodes[Classify(err)]
+}
+
+// Classify returns the kind of the error
+func Classify(err error) FailureKind {
+	var apiErr *APIError
+	var budgetErr *BudgetExceededError
+	var checksumErr *ChecksumMismatchError
+	var thumbnailErr *ThumbnailSizeError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return FailureNone
+	case errors.Is(err, context.Canceled):
+		return FailureCancelled
+	case errors.As(err, &apiErr):
+		return classifyStatus(apiErr.StatusCode)
+	case errors.As(err, &budgetErr):
+		return FailureBudget
+	case errors.As(err, &checksumErr):
+		return FailureChecksum
+	case errors.As(err, &thumbnailErr):
+		return FailureUsage
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, os.ErrPermission):
+		return FailureLocalFile
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return FailureNetwork
+	}
+
+	switch err.Error() {
+	case ErrMissingPathToFile, ErrMissingFileID, ErrMissingFilename, ErrMissingFileIDs, ErrMissingListID,
+		ErrMissingDirectory, ErrMissingListIDOrDirectory, ErrMissingFSPath, ErrMissingFSTarget:
+		return FailureUsage
+	case ErrEmptyFile, ErrUnsupportedFile:
+		return FailureLocalFile
+	}
+	if strings.HasPrefix(err.Error(), ErrSpecialFile) {
+		return FailureLocalFile
+	}
+
+	return FailureUnknown
+}
+
+// classifyStatus returns the kind of an error status of pixeldrain
+func classifyStatus(statusCode int) FailureKind {
+	switch {
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return FailureAuth
+	case statusCode == http.StatusNotFound:
+		return FailureNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return FailureRateLimited
+	case statusCode == http.StatusRequestEntityTooLarge:
+		return FailureTooLarge
+	case statusCode >= 500:
+		return FailureServer
+	case statusCode >= 400:
+		return FailureUsage
+	default:
+		return FailureUnknown
+	}
+}
+
+// Error summarizes the error, nil is no failure
+func (p *Presenter) Error(err error) Summary {
+	kind := Classify(err)
+	summary := p.summary(kind)
+	if err == nil {
+		return summary
+	}
+
+	// the message of pixeldrain instead of the status line
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Message != "":
+		summary.Detail = apiErr.Message
+	case errors.As(err, &apiErr):
+		summary.Detail = apiErr.Value
+	default:
+		summary.Detail = err.Error()
+	}
+
+	return summary
+}
+
+// Batch summarizes the results of a batch or directory upload and the error it returned
+func (p *Presenter) Batch(files []BatchFileResult, err error) Summary {
+	counts := map[BatchFileStatus]int{}
+	var order []BatchFileStatus
+	var failures []string
+	for _, file := range files {
+		if counts[file.Status] == 0 {
+			order = append(order, file.Status)
+		}
+		counts[file.Status]++
+		if file.Status == BatchFailed && len(failures) < p.maxFailures() {
+			failures = append(failures, fmt.Sprintf("%s: %s", file.Path, file.Error))
+		}
+	}
+
+	var parts []string
+	for _, status := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], p.statusLabel(status)))
+	}
+	detail := strings.Join(parts, ", ")
+
+	// the batch stopped, the error is the cause
+	if err != nil {
+		summary := p.Error(err)
+		summary.Detail = strings.TrimSpace(summary.Detail + "\n" + detail)
+		summary.Failures = failures
+		return summary
+	}
+
+	if counts[BatchFailed] == 0 {
+		summary := p.summary(FailureNone)
+		summary.Title = detail
+		return summary
+	}
+
+	summary := p.summary(FailurePartial)
+	summary.Title = fmt.Sprintf(summary.Title, counts[BatchFailed], len(files))
+	summary.Detail = detail
+	summary.Failures = failures
+
+	return summary
+}
+
+func (p *Presenter) summary(kind FailureKind) Summary {
+	msg, ok := p.Messages[kind]
+	if !ok {
+		msg = DefaultMessages[kind]
+	}
+
+	return Summary{Kind: kind, Title: msg.Title, Action: msg.Action, ExitCode: exitCodes[kind]}
+}
+
+func (p *Presenter) statusLabel(status BatchFileStatus) string {
+	if label, ok := p.StatusLabels[status]; ok {
+		return label
+	}
+	if label, ok := DefaultStatusLabels[status]; ok {
+		return label
+	}
+
+	return string(status)
+}
+
+func (p *Presenter) maxFailures() int {
+	if p.MaxFailures <= 0 {
+		return 5
+	}
+
+	return p.MaxFailures
+}
@@ -0,0 +1,70 @@
+package pd_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenter_Error(t *testing.T) {
+	tests := []struct {
+		err      error
+		kind     pd.FailureKind
+		exitCode int
+	}{
+		{nil, pd.FailureNone, 0},
+		{&pd.APIError{StatusCode: 401, Value: "unauthorized"}, pd.FailureAuth, 3},
+		{fmt.Errorf("upload: %w", &pd.APIError{StatusCode: 404, Value: "not_found"}), pd.FailureNotFound, 4},
+		{&pd.APIError{StatusCode: 429, Value: "rate_limited"}, pd.FailureRateLimited, 5},
+		{&pd.BudgetExceededError{Direction: "upload", Period: "day"}, pd.FailureBudget, 5},
+		{&pd.ChecksumMismatchError{}, pd.FailureChecksum, 7},
+		{errors.New(pd.ErrMissingFileID), pd.FailureUsage, 2},
+		{&os.PathError{Op: "open", Path: "a.txt", Err: os.ErrNotExist}, pd.FailureLocalFile, 8},
+		{context.Canceled, pd.FailureCancelled, 130},
+		{errors.New("boom"), pd.FailureUnknown, 1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.kind, pd.Classify(test.err), "%v", test.err)
+		assert.Equal(t, test.exitCode, pd.ExitCode(test.err), "%v", test.err)
+	}
+
+	// the message of pixeldrain is shown instead of the JSON status
+	summary := pd.DefaultPresenter.Error(&pd.APIError{StatusCode: 413, Value: "file_too_large", Message: "The file is larger than 20 GB"})
+	assert.Equal(t, "The file is too large for the account", summary.Title)
+	assert.Equal(t, "The file is larger than 20 GB", summary.Detail)
+
+	german := &pd.Presenter{Messages: map[pd.FailureKind]pd.Message{
+		pd.FailureAuth: {Title: "pixeldrain lehnt den API-Schlüssel ab"},
+	}}
+	assert.Equal(t, "pixeldrain lehnt den API-Schlüssel ab", german.Error(&pd.APIError{StatusCode: 403}).Title)
+	assert.Equal(t, "pixeldrain could not be reached", german.Error(context.DeadlineExceeded).Title)
+}
+
+func TestPresenter_Batch(t *testing.T) {
+	files := []pd.BatchFileResult{
+		{Path: "a.jpg", Status: pd.BatchCompleted},
+		{Path: "b.jpg", Status: pd.BatchCompleted},
+		{Path: "c.jpg", Status: pd.BatchSkippedDuplicate},
+		{Path: "d.jpg", Status: pd.BatchFailed, Error: "pixeldrain: status 500 internal"},
+	}
+
+	summary := pd.DefaultPresenter.Batch(files, nil)
+	assert.Equal(t, pd.FailurePartial, summary.Kind)
+	assert.Equal(t, "1 of 4 files failed", summary.Title)
+	assert.Equal(t, "2 uploaded, 1 skipped as duplicate, 1 failed", summary.Detail)
+	assert.Equal(t, []string{"d.jpg: pixeldrain: status 500 internal"}, summary.Failures)
+	assert.Equal(t, 10, summary.ExitCode)
+
+	summary = pd.DefaultPresenter.Batch(files[:3], nil)
+	assert.Equal(t, "2 uploaded, 1 skipped as duplicate", summary.Title)
+	assert.Equal(t, 0, summary.ExitCode)
+
+	summary = pd.DefaultPresenter.Batch(files, &pd.APIError{StatusCode: 401, Value: "unauthorized"})
+	assert.Equal(t, pd.FailureAuth, summary.Kind)
+	assert.Contains(t, summary.String(), "2 uploaded, 1 skipped as duplicate, 1 failed")
+}
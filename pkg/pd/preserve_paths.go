@@ -0,0 +1,56 @@
+package pd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PathPreservation keeps the directory tree of a directory upload, pixeldrain itself only stores file names
+type PathPreservation int
+
+const (
+	PathNone          PathPreservation = iota // the files are uploaded with their names only
+	PathInFileName                            // the relative path is the file name, e.g. "sub_dir_file.txt" for "sub/dir/file.txt"
+	PathInDescription                         // the relative path is the first line of the list descriptions, DownloadDirectory restores it
+)
+
+// flatFileName returns the path of the file relative to the directory with "_" instead of the separators
+func flatFileName(directory, filePath string) string {
+	rel, err := filepath.Rel(directory, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(filePath)
+	}
+
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "_")
+}
+
+// describeInDirectory returns the list description of a file of a directory upload, with PathInDescription the
+// relative path comes before the description of the Describer
+func (pd *PixelDrainClient) describeInDirectory(r *RequestUploadDirectory, filePath string) string {
+	description := pd.describe(filePath)
+	if r.PreservePaths != PathInDescription {
+		return description
+	}
+
+	rel, err := filepath.Rel(r.Directory, filePath)
+	if err != nil {
+		return description
+	}
+	if description == "" {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(rel) + "\n" + description
+}
+
+// pathFromDescription returns the relative path of a PathInDescription list description, a path which would
+// leave the directory is ignored
+func pathFromDescription(description string) (string, bool) {
+	line, _, _ := strings.Cut(description, "\n")
+	rel := filepath.FromSlash(strings.TrimSpace(line))
+	if rel == "" || !filepath.IsLocal(rel) {
+		return "", false
+	}
+
+	return filepath.Clean(rel), true
+}
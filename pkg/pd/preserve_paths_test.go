@@ -0,0 +1,112 @@
+package pd_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// treeServer keeps the uploaded files by ID and serves the created list with its descriptions
+func treeServer(names, content map[string]string) *httptest.Server {
+	var mu sync.Mutex
+	var list pd.RequestCreateList
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/file":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			id := fmt.Sprintf("file-%d", len(names)+1)
+			names[id], content[id] = header.Filename, string(data)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"success": true, "id": %q}`, id)
+		case r.Method == http.MethodPost && r.URL.Path == "/list":
+			_ = json.NewDecoder(r.Body).Decode(&list)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "list-1"}`))
+		case r.URL.Path == "/list/list-1":
+			var files []pd.FileGetList
+			for _, file := range list.Files {
+				files = append(files, pd.FileGetList{ID: file.ID, Name: names[file.ID], Description: file.Description})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "id": "list-1", "files": files})
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/file/"), "/info")
+			sum := sha256.Sum256([]byte(content[id]))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "id": id, "name": names[id], "hash_sha256": hex.EncodeToString(sum[:])})
+		default:
+			_, _ = w.Write([]byte(content[strings.TrimPrefix(r.URL.Path, "/file/")]))
+		}
+	}))
+}
+
+// TestUploadDirectory_PreservePaths uploads a tree and downloads it again at the same paths
+func TestUploadDirectory_PreservePaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"top.txt", "sub/dir/file.txt", "sub/file.txt"} {
+		path := filepath.Join(dir, "tree", filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	names, content := map[string]string{}, map[string]string{}
+	server := treeServer(names, content)
+	defer server.Close()
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true}, nil)
+
+	_, err := c.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory:     filepath.Join(dir, "tree"),
+		HashFilePath:  filepath.Join(dir, "hashes.csv"),
+		PreservePaths: pd.PathInFileName,
+	})
+	assert.NoError(t, err)
+	var flat []string
+	for _, name := range names {
+		flat = append(flat, name)
+	}
+	assert.ElementsMatch(t, []string{"top.txt", "sub_dir_file.txt", "sub_file.txt"}, flat)
+
+	for id := range names {
+		delete(names, id)
+	}
+	rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory:     filepath.Join(dir, "tree"),
+		HashFilePath:  filepath.Join(dir, "other-hashes.csv"),
+		PreservePaths: pd.PathInDescription,
+		CreateList:    true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "list-1", rsp.ListID)
+
+	// the two file.txt would collide without the paths of the descriptions
+	target := filepath.Join(dir, "restored")
+	download, err := c.DownloadDirectory(&pd.RequestDownloadDirectory{Directory: target, ListID: "list-1", PathsFromDescriptions: true})
+	assert.NoError(t, err)
+	assert.Len(t, download.Files, 3)
+	for _, name := range []string{"top.txt", "sub/dir/file.txt", "sub/file.txt"} {
+		data, err := os.ReadFile(filepath.Join(target, filepath.FromSlash(name)))
+		assert.NoError(t, err)
+		assert.Equal(t, name, string(data))
+	}
+}
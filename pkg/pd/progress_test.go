@@ -0,0 +1,47 @@
+package pd_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Progress(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+
+	var uploads []utils.Progress
+	_, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile: "testdata/cat.jpg",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+		Progress:   func(p utils.Progress) { uploads = append(uploads, p) },
+	}, filepath.Join(t.TempDir(), "hashes.csv"))
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, uploads) {
+		last := uploads[len(uploads)-1]
+		assert.True(t, last.Done)
+		assert.Equal(t, int64(37621), last.Transferred)
+		assert.Equal(t, int64(37621), last.Total)
+		assert.Equal(t, float64(100), last.Percent())
+	}
+
+	var downloads []utils.Progress
+	_, err = c.Download(&pd.RequestDownload{
+		PathToSave: filepath.Join(t.TempDir(), "cat.jpg"),
+		ID:         "K1dA8U5W",
+		URL:        server.URL + "/file/K1dA8U5W",
+		Progress:   func(p utils.Progress) { downloads = append(downloads, p) },
+	})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, downloads) {
+		last := downloads[len(downloads)-1]
+		assert.True(t, last.Done)
+		assert.Equal(t, int64(37621), last.Transferred)
+	}
+}
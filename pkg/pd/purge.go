@@ -0,0 +1,103 @@
+package pd
+
+import (
+	"errors"
+	"log"
+	"path"
+	"time"
+)
+
+// ErrEmptyPurgeFilter is returned by PurgeAccount without any condition, All has to be set to empty the account
+const ErrEmptyPurgeFilter = "purge filter is empty, set All to delete every file of the account"
+
+// PurgeAccount deletes the files of the account which match the Filter and OlderThan, e.g. the large logs of last
+// year to get below the storage limit. Lists whose title matches ListTitle are deleted too, their files only if they
+// match. A DryRun reports the matches without deleting anything. A failed delete does not stop the others.
+func (pd *PixelDrainClient) PurgeAccount(r *RequestPurgeAccount) (*ResponsePurgeAccount, error) {
+	filter := r.Filter
+	if r.OlderThan > 0 {
+		before := time.Now().Add(-r.OlderThan)
+		if filter.UploadedBefore.IsZero() || before.Before(filter.UploadedBefore) {
+			filter.UploadedBefore = before
+		}
+	}
+	if filter == (FileFilter{}) && !r.All {
+		return nil, errors.New(ErrEmptyPurgeFilter)
+	}
+
+	files, err := pd.GetUserFiles(&RequestGetUserFiles{Auth: r.Auth, Filter: &filter})
+	if err != nil {
+		return nil, err
+	}
+	if !files.Success {
+		return nil, &APIError{StatusCode: files.StatusCode, Value: files.Value, Message: files.Message}
+	}
+
+	report := &ResponsePurgeAccount{Files: files.Files}
+	ids := make([]string, len(files.Files))
+	size := map[string]int64{}
+	for i, file := range files.Files {
+		ids[i] = file.ID
+		size[file.ID] = file.Size
+	}
+
+	if r.ListTitle != "" {
+		lists, err := pd.GetUserLists(&RequestGetUserLists{Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		if !lists.Success {
+			return nil, &APIError{StatusCode: lists.StatusCode, Value: lists.Value, Message: lists.Message}
+		}
+		for _, list := range lists.Lists {
+			if ok, _ := path.Match(r.ListTitle, list.Title); ok {
+				report.Lists = append(report.Lists, list)
+			}
+		}
+	}
+
+	if r.DryRun {
+		for _, file := range report.Files {
+			report.FreedBytes += file.Size
+		}
+		return report, nil
+	}
+
+	if len(ids) > 0 {
+		log.Printf("Purging %d files of the account", len(ids))
+		deleted, err := pd.DeleteMany(&RequestDeleteMany{
+			IDs:            ids,
+			Auth:           r.Auth,
+			Concurrency:    r.Concurrency,
+			Immediately:    r.Immediately,
+			IgnoreNotFound: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.Results = deleted.Results
+		report.Deleted, report.Failed = deleted.Deleted, deleted.Failed
+		for _, result := range deleted.Results {
+			if result.Err == nil {
+				report.FreedBytes += size[result.ID]
+			}
+		}
+	}
+
+	for _, list := range report.Lists {
+		rsp, err := pd.DeleteList(&RequestDeleteList{ID: list.ID, Auth: r.Auth})
+		result := DeleteResult{ID: list.ID, Err: err}
+		if err == nil {
+			result.StatusCode = rsp.StatusCode
+			if !rsp.Success {
+				result.Err = &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+			}
+		}
+		if result.Err != nil {
+			report.Failed++
+		}
+		report.ListResults = append(report.ListResults, result)
+	}
+
+	return report, nil
+}
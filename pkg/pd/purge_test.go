@@ -0,0 +1,78 @@
+package pd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_PurgeAccount deletes the old large logs and the matching lists of the account
+func TestPD_PurgeAccount(t *testing.T) {
+	old := time.Now().AddDate(-1, 0, 0).Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/user/files":
+			_, _ = fmt.Fprintf(w, `{"success": true, "files": [
+				{"id": "old-log", "name": "2023.log", "size": 3000, "date_upload": %q},
+				{"id": "small-log", "name": "tiny.log", "size": 10, "date_upload": %q},
+				{"id": "new-log", "name": "today.log", "size": 3000, "date_upload": %q},
+				{"id": "old-cat", "name": "cat.jpg", "size": 3000, "date_upload": %q}]}`, old, old, recent, old)
+		case r.URL.Path == "/user/lists":
+			_, _ = w.Write([]byte(`{"success": true, "lists": [{"id": "l1", "title": "logs 2023"}, {"id": "l2", "title": "cats"}]}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/"))
+			_, _ = w.Write([]byte(`{"success": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	auth := pd.Auth{APIKey: "key"}
+
+	_, err := c.PurgeAccount(&pd.RequestPurgeAccount{Auth: auth})
+	assert.EqualError(t, err, pd.ErrEmptyPurgeFilter)
+
+	req := &pd.RequestPurgeAccount{
+		Auth:      auth,
+		Filter:    pd.FileFilter{Name: "*.log", MinSize: 100},
+		OlderThan: 30 * 24 * time.Hour,
+		ListTitle: "logs *",
+		DryRun:    true,
+	}
+	rsp, err := c.PurgeAccount(req)
+	assert.NoError(t, err)
+	if assert.Len(t, rsp.Files, 1) {
+		assert.Equal(t, "old-log", rsp.Files[0].ID)
+	}
+	assert.Len(t, rsp.Lists, 1)
+	assert.Equal(t, int64(3000), rsp.FreedBytes)
+	assert.Empty(t, deleted)
+
+	req.DryRun = false
+	rsp, err = c.PurgeAccount(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rsp.Deleted)
+	assert.Equal(t, 0, rsp.Failed)
+	assert.Equal(t, int64(3000), rsp.FreedBytes)
+	sort.Strings(deleted)
+	assert.Equal(t, []string{"file/old-log", "list/l1"}, deleted)
+}
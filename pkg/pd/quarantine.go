@@ -0,0 +1,138 @@
+package pd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// QuarantineLogName is the log of quarantined files in the quarantine directory, one JSON event per line
+const QuarantineLogName = "quarantine.jsonl"
+
+// ChecksumMismatchError is returned if the SHA-256 of a transferred file differs from the hash pixeldrain stores
+type ChecksumMismatchError struct {
+	Direction      string // utils.Upload or utils.Download
+	ID             string // pixeldrain ID of the file
+	Path           string // local file
+	Expected       string // hash on pixeldrain
+	Actual         string // hash of the local data
+	QuarantinePath string // where the local file was moved, empty if it was not moved
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	msg := fmt.Sprintf("checksum mismatch after %s of %s (%s): pixeldrain has %s, local data has %s", e.Direction, e.Path, e.ID, e.Expected, e.Actual)
+	if e.QuarantinePath != "" {
+		msg += ", moved to " + e.QuarantinePath
+	}
+
+	return msg
+}
+
+// QuarantineEvent is the line written to QuarantineLogName for every quarantined file
+type QuarantineEvent struct {
+	Time           time.Time `json:"time"`
+	Direction      string    `json:"direction"`
+	ID             string    `json:"id"`
+	Path           string    `json:"path"`
+	QuarantinePath string    `json:"quarantine_path"`
+	Expected       string    `json:"expected_sha256"`
+	Actual         string    `json:"actual_sha256"`
+}
+
+// remoteHash returns the SHA-256 pixeldrain stores for the file
+func (pd *PixelDrainClient) remoteHash(id string, auth Auth) (string, error) {
+	info, err := pd.GetFileInfo(&RequestFileInfo{ID: id, Auth: auth})
+	if err != nil {
+		return "", err
+	}
+	if !info.Success {
+		return "", fmt.Errorf("no file info for %s: status %d", id, info.StatusCode)
+	}
+
+	return info.HashSha256, nil
+}
+
+// quarantine moves the file into the QuarantineDir of the client and logs the event there.
+// Without a QuarantineDir the file stays in place, the mismatch is only returned as error.
+func (pd *PixelDrainClient) quarantine(file string, mismatch *ChecksumMismatchError) error {
+	if pd.QuarantineDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(pd.QuarantineDir, 0755); err != nil {
+		return err
+	}
+
+	// the time prefix keeps several bad copies of the same file apart
+	now := time.Now()
+	target := filepath.Join(pd.QuarantineDir, now.Format("20060102T150405.000000000")+"-"+filepath.Base(mismatch.Path))
+	log.Printf("Quarantining %s to %s: %v", file, target, mismatch)
+	if err := os.Rename(file, target); err != nil {
+		// rename does not work across file systems, copy and remove instead
+		if err := copyFile(file, target); err != nil {
+			return err
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	mismatch.QuarantinePath = target
+
+	line, err := json.Marshal(QuarantineEvent{
+		Time:           now,
+		Direction:      mismatch.Direction,
+		ID:             mismatch.ID,
+		Path:           mismatch.Path,
+		QuarantinePath: target,
+		Expected:       mismatch.Expected,
+		Actual:         mismatch.Actual,
+	})
+	if err != nil {
+		return err
+	}
+
+	logPath := filepath.Join(pd.QuarantineDir, QuarantineLogName)
+	return utils.WithFileLock(logPath, func() error {
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// verifyTransfer compares the hash of the local data with the one pixeldrain stores, on a mismatch the local
+// file is quarantined if there is one
+func (pd *PixelDrainClient) verifyTransfer(direction, id string, auth Auth, path, actual, localFile string) error {
+	expected, err := pd.remoteHash(id, auth)
+	if err != nil {
+		return err
+	}
+	if expected == actual {
+		return nil
+	}
+
+	mismatch := &ChecksumMismatchError{
+		Direction: direction,
+		ID:        id,
+		Path:      path,
+		Expected:  expected,
+		Actual:    actual,
+	}
+	if localFile != "" {
+		if err := pd.quarantine(localFile, mismatch); err != nil {
+			log.Printf("Error quarantining %s: %v", localFile, err)
+		}
+	}
+
+	return mismatch
+}
@@ -0,0 +1,129 @@
+package pd_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// corruptServer serves content for every file while the info reports the hash of other content
+func corruptServer(content string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/file":
+			_, _ = r.MultipartReader()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "corrupt"}`))
+		case filepath.Base(r.URL.Path) == "info":
+			_, _ = w.Write([]byte(`{"success": true, "id": "corrupt", "hash_sha256": "0000"}`))
+		default:
+			_, _ = w.Write([]byte(content))
+		}
+	}))
+}
+
+func TestPD_Download_Verify(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	path := filepath.Join(t.TempDir(), "cat.jpg")
+	rsp, err := c.Download(&pd.RequestDownload{ID: "K1dA8U5W", PathToSave: path, Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, rsp.Success)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(37621), info.Size())
+}
+
+func TestPD_Download_VerifyQuarantine(t *testing.T) {
+	server := corruptServer("corrupted")
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	quarantine := filepath.Join(t.TempDir(), "quarantine")
+	c := pd.New(&pd.ClientOptions{API: &spec, QuarantineDir: quarantine}, nil)
+
+	path := filepath.Join(t.TempDir(), "good.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("good data"), 0644))
+
+	_, err := c.Download(&pd.RequestDownload{ID: "corrupt", PathToSave: path, Verify: true})
+	var mismatch *pd.ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	assert.Equal(t, utils.Download, mismatch.Direction)
+	assert.Equal(t, "0000", mismatch.Expected)
+
+	// the existing file is kept, the corrupted download is in the quarantine
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, "good data", string(data))
+	data, err = os.ReadFile(mismatch.QuarantinePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "corrupted", string(data))
+	assert.Equal(t, quarantine, filepath.Dir(mismatch.QuarantinePath))
+
+	f, err := os.Open(filepath.Join(quarantine, pd.QuarantineLogName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []pd.QuarantineEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e pd.QuarantineEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "corrupt", events[0].ID)
+		assert.Equal(t, mismatch.QuarantinePath, events[0].QuarantinePath)
+	}
+}
+
+func TestPD_UploadPOST_VerifyQuarantine(t *testing.T) {
+	server := corruptServer("")
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	quarantine := filepath.Join(t.TempDir(), "quarantine")
+	c := pd.New(&pd.ClientOptions{API: &spec, QuarantineDir: quarantine}, nil)
+
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("local data"), 0644))
+
+	rsp, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile: path,
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+		Verify:     true,
+	}, filepath.Join(t.TempDir(), "hashes.csv"))
+	assert.Nil(t, rsp)
+	var mismatch *pd.ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	assert.Equal(t, utils.Upload, mismatch.Direction)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	data, _ := os.ReadFile(mismatch.QuarantinePath)
+	assert.Equal(t, "local data", string(data))
+}
@@ -0,0 +1,81 @@
+package pd
+
+import (
+	"fmt"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// Unlimited is returned by the quota helpers for a limit the account doesn't have
+const Unlimited int64 = -1
+
+// StorageLimit the storage space of the subscription in bytes, Unlimited if the plan has no limit
+func (r *ResponseGetUser) StorageLimit() int64 {
+	if r.Subscription.StorageSpace <= 0 {
+		return Unlimited
+	}
+
+	return r.Subscription.StorageSpace
+}
+
+// RemainingStorage the bytes which can still be stored, Unlimited if the plan has no limit
+func (r *ResponseGetUser) RemainingStorage() int64 {
+	limit := r.StorageLimit()
+	if limit == Unlimited {
+		return Unlimited
+	}
+
+	return remaining(limit, r.StorageSpaceUsed)
+}
+
+// TransferLimit the monthly transfer cap in bytes, the cap set by the user wins over the cap of the subscription.
+// Unlimited if neither is set.
+func (r *ResponseGetUser) TransferLimit() int64 {
+	if r.MonthlyTransferCap > 0 {
+		return r.MonthlyTransferCap
+	}
+	if r.Subscription.MonthlyTransferCap > 0 {
+		return r.Subscription.MonthlyTransferCap
+	}
+
+	return Unlimited
+}
+
+// RemainingTransfer the bytes which can still be transferred this month, Unlimited if there is no cap
+func (r *ResponseGetUser) RemainingTransfer() int64 {
+	limit := r.TransferLimit()
+	if limit == Unlimited {
+		return Unlimited
+	}
+
+	return remaining(limit, r.MonthlyTransferUsed)
+}
+
+// CheckUpload returns the limit an upload of the given size would exceed, nil if it fits
+func (r *ResponseGetUser) CheckUpload(size int64) error {
+	if limit := r.Subscription.FileSizeLimit; limit > 0 && size > limit {
+		return fmt.Errorf("%s exceeds the file size limit of %s", utils.FormatFileSize(size), utils.FormatFileSize(limit))
+	}
+	if left := r.RemainingStorage(); left != Unlimited && size > left {
+		return fmt.Errorf("%s exceeds the %s of storage space left", utils.FormatFileSize(size), utils.FormatFileSize(left))
+	}
+	if left := r.RemainingTransfer(); left != Unlimited && size > left {
+		return fmt.Errorf("%s exceeds the %s left of the monthly transfer cap", utils.FormatFileSize(size), utils.FormatFileSize(left))
+	}
+
+	return nil
+}
+
+// CanUpload reports whether an upload of the given size fits the file size limit, the storage space and the transfer cap
+func (r *ResponseGetUser) CanUpload(size int64) bool {
+	return r.CheckUpload(size) == nil
+}
+
+// remaining the part of the limit which is not used, 0 if the usage is above the limit
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+
+	return limit - used
+}
@@ -0,0 +1,46 @@
+package pd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_ResponseGetUser_Quota checks the remaining storage and transfer against the limits of the plan
+func TestPD_ResponseGetUser_Quota(t *testing.T) {
+	user := &pd.ResponseGetUser{
+		Subscription: pd.GetUserSubscription{
+			FileSizeLimit:      1000,
+			StorageSpace:       5000,
+			MonthlyTransferCap: 10000,
+		},
+		StorageSpaceUsed:    4500,
+		MonthlyTransferUsed: 2000,
+	}
+
+	assert.Equal(t, int64(5000), user.StorageLimit())
+	assert.Equal(t, int64(500), user.RemainingStorage())
+	assert.Equal(t, int64(10000), user.TransferLimit())
+	assert.Equal(t, int64(8000), user.RemainingTransfer())
+	assert.True(t, user.CanUpload(500))
+	assert.False(t, user.CanUpload(501))
+	assert.ErrorContains(t, user.CheckUpload(501), "storage space left")
+
+	// the cap set by the user is below the cap of the plan
+	user.MonthlyTransferCap = 2100
+	assert.Equal(t, int64(100), user.RemainingTransfer())
+	assert.ErrorContains(t, user.CheckUpload(200), "monthly transfer cap")
+
+	// the usage above the limit leaves nothing
+	user.StorageSpaceUsed = 6000
+	assert.Equal(t, int64(0), user.RemainingStorage())
+
+	// a free plan has no storage limit and no transfer cap, only the file size limit
+	free := &pd.ResponseGetUser{Subscription: pd.GetUserSubscription{FileSizeLimit: 1000, StorageSpace: -1}}
+	assert.Equal(t, pd.Unlimited, free.RemainingStorage())
+	assert.Equal(t, pd.Unlimited, free.RemainingTransfer())
+	assert.True(t, free.CanUpload(1000))
+	assert.ErrorContains(t, free.CheckUpload(1001), "file size limit")
+}
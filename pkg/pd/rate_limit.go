@@ -0,0 +1,214 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxRateLimitRetries attempts of an upload which pixeldrain answers with 429 Too Many Requests
+	MaxRateLimitRetries = 8
+	// maxRateLimitBackoff upper bound of the pause if the server sends no Retry-After
+	maxRateLimitBackoff = time.Minute
+)
+
+// rateLimitGate pauses all uploads of a client after a 429, so a batch waits as a whole
+// instead of failing file after file
+type rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+	path  string         // shared pause of all processes using the file, see ClientOptions.SharedStateDir
+	last  *RateLimitInfo // quota of the last response which reported one
+}
+
+// pause holds all uploads for the duration, a longer running pause is kept
+func (g *rateLimitGate) pause(d time.Duration) {
+	if g == nil {
+		return
+	}
+
+	until := time.Now().Add(d)
+	g.mu.Lock()
+	if until.After(g.until) {
+		g.until = until
+	}
+	g.mu.Unlock()
+
+	if g.path != "" {
+		_, err := updateSharedTime(g.path, func(stored time.Time) time.Time {
+			if until.After(stored) {
+				return until
+			}
+			return stored
+		})
+		if err != nil {
+			log.Printf("Error sharing the rate limit pause: %v", err)
+		}
+	}
+}
+
+// wait blocks until the pause is over or the context is cancelled
+func (g *rateLimitGate) wait(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	until := g.until
+	g.mu.Unlock()
+
+	// another process may have been told to pause
+	if g.path != "" {
+		if stored, err := updateSharedTime(g.path, func(stored time.Time) time.Time { return stored }); err == nil && stored.After(until) {
+			until = stored
+		}
+	}
+
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryRateLimited calls the upload again while pixeldrain answers with 429, honoring Retry-After
+// and backing off exponentially without it
+func (pd *PixelDrainClient) retryRateLimited(ctx context.Context, upload func() (*ResponseUpload, error)) (*ResponseUpload, error) {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		rsp, err := upload()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || attempt >= MaxRateLimitRetries {
+			return rsp, err
+		}
+
+		wait := apiErr.RetryAfter
+		if wait <= 0 {
+			wait = backoff
+			if backoff *= 2; backoff > maxRateLimitBackoff {
+				backoff = maxRateLimitBackoff
+			}
+		}
+
+		pd.rateLimit.pause(wait)
+		if err := pd.rateLimit.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseRetryAfter reads the Retry-After header as seconds or HTTP date, 0 if missing or invalid
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// RateLimitInfo is the quota pixeldrain reports in the rate limit headers of a response
+type RateLimitInfo struct {
+	Limit      int           `json:"limit"`     // requests of the window, -1 if not reported
+	Remaining  int           `json:"remaining"` // requests left in the window, -1 if not reported
+	Reset      time.Time     `json:"reset"`     // start of the next window, zero if not reported
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// parseRateLimit reads the RateLimit-* headers and their X-RateLimit-* variants, nil if the response has none
+func parseRateLimit(header http.Header) *RateLimitInfo {
+	if header == nil {
+		return nil
+	}
+
+	get := func(name string) string {
+		if value := header.Get("RateLimit-" + name); value != "" {
+			return value
+		}
+		return header.Get("X-RateLimit-" + name)
+	}
+	atoi := func(value string) int {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+
+	limit, remaining, reset := get("Limit"), get("Remaining"), get("Reset")
+	retryAfter := parseRetryAfter(header.Get("Retry-After"))
+	if limit == "" && remaining == "" && reset == "" && retryAfter == 0 {
+		return nil
+	}
+
+	info := &RateLimitInfo{Limit: atoi(limit), Remaining: atoi(remaining), RetryAfter: retryAfter}
+	// the reset is the seconds until the window ends, or a unix time in some X-RateLimit variants
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(reset), 10, 64); err == nil && seconds >= 0 {
+		if seconds > 1e9 {
+			info.Reset = time.Unix(seconds, 0)
+		} else {
+			info.Reset = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+
+	return info
+}
+
+// observe keeps the quota of a response and pauses all requests until the reset once it is used up,
+// so a batch waits instead of running into 429s
+func (g *rateLimitGate) observe(info *RateLimitInfo) {
+	if g == nil || info == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.last = info
+	g.mu.Unlock()
+
+	if info.Remaining == 0 && !info.Reset.IsZero() {
+		if d := time.Until(info.Reset); d > 0 {
+			if d > maxRateLimitBackoff {
+				d = maxRateLimitBackoff
+			}
+			g.pause(d)
+		}
+	}
+}
+
+// RateLimit returns the quota of the last response which reported one, nil if none did yet.
+// Batch jobs use it to slow down before pixeldrain has to throttle them.
+func (pd *PixelDrainClient) RateLimit() *RateLimitInfo {
+	pd.rateLimit.mu.Lock()
+	defer pd.rateLimit.mu.Unlock()
+
+	if pd.rateLimit.last == nil {
+		return nil
+	}
+	info := *pd.rateLimit.last
+
+	return &info
+}
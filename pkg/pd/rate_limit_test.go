@@ -0,0 +1,159 @@
+package pd_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadDirectory_RetryAfter(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		uploads++
+		if uploads == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"success": false, "value": "rate_limited"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "after-pause"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := filepath.Join(dir, "files")
+	_ = os.Mkdir(files, 0755)
+	_ = os.WriteFile(filepath.Join(files, "a.txt"), []byte("rate limited a"), 0644)
+	_ = os.WriteFile(filepath.Join(files, "b.txt"), []byte("rate limited b"), 0644)
+
+	c := pd.New(&pd.ClientOptions{HashStore: utils.NewCSVHashStore(filepath.Join(dir, "hashes.csv"))}, nil)
+
+	start := time.Now()
+	if _, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: files, URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, 3, uploads)
+}
+
+func TestRateLimit_SharedStateDir(t *testing.T) {
+	paused := make(chan struct{})
+	var uploads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if atomic.AddInt32(&uploads, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"success": false, "value": "rate_limited"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "shared"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared")
+	file := filepath.Join(dir, "a.txt")
+	_ = os.WriteFile(file, []byte("shared pause"), 0644)
+
+	// two clients stand for two processes using the same state directory
+	first := pd.New(&pd.ClientOptions{SharedStateDir: shared, HashStore: utils.NewCSVHashStore(filepath.Join(dir, "first.csv"))}, nil)
+	second := pd.New(&pd.ClientOptions{SharedStateDir: shared, HashStore: utils.NewCSVHashStore(filepath.Join(dir, "second.csv"))}, nil)
+
+	start := time.Now()
+	done := make(chan error)
+	go func() {
+		_, err := first.UploadBatch(context.Background(), &pd.RequestUploadBatch{Paths: []string{file}, URL: server.URL})
+		done <- err
+	}()
+	go func() {
+		// the pause is written once the first client got the 429
+		for {
+			if _, err := os.Stat(filepath.Join(shared, "rate_limit_pause")); err == nil {
+				close(paused)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	<-paused
+	_, err := second.UploadPOST(&pd.RequestUpload{PathToFile: file, Anonymous: true, URL: server.URL}, filepath.Join(dir, "hashes.csv"))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	assert.NoError(t, <-done)
+}
+
+func TestPD_MaxBytesPerSec(t *testing.T) {
+	content := make([]byte, 48<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "T"}`))
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, MaxUploadBytesPerSec: 32 << 10, MaxDownloadBytesPerSec: 32 << 10}, nil)
+
+	// the bucket holds one second, the rest of the body waits for it to refill
+	start := time.Now()
+	_, err := c.UploadPUT(&pd.RequestUpload{Source: bytes.NewReader(content), SourceSize: int64(len(content)), FileName: "t.bin"})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	start = time.Now()
+	_, err = c.Download(&pd.RequestDownload{ID: "T", PathToSave: filepath.Join(t.TempDir(), "t.bin")})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestPD_RateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1")
+		_, _ = w.Write([]byte(`{"id": "K1dA8U5W", "size": 37621}`))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	assert.Nil(t, c.RateLimit())
+
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, rsp.RateLimit) {
+		assert.Equal(t, 100, rsp.RateLimit.Limit)
+		assert.Equal(t, 0, rsp.RateLimit.Remaining)
+		assert.WithinDuration(t, time.Now().Add(time.Second), rsp.RateLimit.Reset, time.Second)
+	}
+	assert.Equal(t, rsp.RateLimit, c.RateLimit())
+
+	// the quota is used up, the next request waits for the reset
+	start := time.Now()
+	_, err = c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W"})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,109 @@
+package pd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// rawExtraSetter is implemented by every response which embeds ResponseDefault
+type rawExtraSetter interface {
+	setRawExtra(map[string]json.RawMessage)
+}
+
+func (r *ResponseDefault) setRawExtra(extra map[string]json.RawMessage) {
+	r.RawExtra = extra
+}
+
+// rateLimitSetter is implemented by every response which embeds ResponseDefault
+type rateLimitSetter interface {
+	setRateLimit(*RateLimitInfo)
+}
+
+func (r *ResponseDefault) setRateLimit(info *RateLimitInfo) {
+	r.RateLimit = info
+}
+
+// knownFields caches the lower case JSON names of the fields per response type
+var knownFields sync.Map
+
+// decodeResponse decodes the JSON object into v and keeps the fields v has no field for in its RawExtra,
+// so new fields of the API can be read before they are added to the response structs
+func decodeResponse(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	setter, ok := v.(rawExtraSetter)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		// not an object, e.g. null
+		return nil
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	for name := range fields {
+		// encoding/json matches the names case-insensitive
+		if known[strings.ToLower(name)] {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		setter.setRawExtra(fields)
+	}
+
+	return nil
+}
+
+// jsonFieldNames returns the names encoding/json decodes into the struct, fields of embedded structs included
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if names, ok := knownFields.Load(t); ok {
+		return names.(map[string]bool)
+	}
+
+	names := map[string]bool{}
+	if t.Kind() == reflect.Struct {
+		collectFieldNames(t, names)
+	}
+	knownFields.Store(t, names)
+
+	return names
+}
+
+func collectFieldNames(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectFieldNames(embedded, names)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+}
@@ -0,0 +1,103 @@
+package pd
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRemoteDedupTTL is how long the files listing of RemoteDedup is reused, files deleted in the meantime are
+// found again after it
+const DefaultRemoteDedupTTL = 5 * time.Minute
+
+// remoteHashStore is the duplicate check against the files of an account, the sha256 of the files listing is
+// matched, so uploads from other machines are detected too. The listing is fetched with the first check and again
+// once it is older than the RemoteDedupTTL of the client.
+type remoteHashStore struct {
+	pd   *PixelDrainClient
+	auth Auth
+
+	mu       sync.Mutex
+	ids      map[string]string // file ID by hash
+	loadedAt time.Time         // zero until the listing is fetched
+}
+
+// load fetches the files of the account if the listing expired, a failed listing is fetched again by the next check
+func (s *remoteHashStore) load() error {
+	if s.loaded() && time.Since(s.loadedAt) < s.ttl() {
+		return nil
+	}
+
+	rsp, err := s.pd.GetUserFiles(&RequestGetUserFiles{Auth: s.auth})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success {
+		return &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	s.ids = make(map[string]string, len(rsp.Files))
+	for _, file := range rsp.Files {
+		if file.HashSha256 != "" {
+			s.ids[file.HashSha256] = file.ID
+		}
+	}
+	s.loadedAt = time.Now()
+
+	return nil
+}
+
+// loaded reports if the listing was fetched
+func (s *remoteHashStore) loaded() bool {
+	return !s.loadedAt.IsZero()
+}
+
+// ttl returns the RemoteDedupTTL of the client or DefaultRemoteDedupTTL
+func (s *remoteHashStore) ttl() time.Duration {
+	if s.pd.RemoteDedupTTL > 0 {
+		return s.pd.RemoteDedupTTL
+	}
+
+	return DefaultRemoteDedupTTL
+}
+
+// Exists checks if a file of the account has the hash.
+func (s *remoteHashStore) Exists(hash string) (bool, error) {
+	_, found, err := s.FindID(hash)
+	return found, err
+}
+
+// Save is a no-op, uploads are recorded in the local store.
+func (s *remoteHashStore) Save(string, string) error {
+	return nil
+}
+
+// SaveID remembers an uploaded file of the account, so the listing doesn't have to be fetched again.
+func (s *remoteHashStore) SaveID(_, hash, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded() {
+		s.ids[hash] = id
+	}
+
+	return nil
+}
+
+// FindID returns the ID of the remote file with the hash.
+func (s *remoteHashStore) FindID(hash string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", false, err
+	}
+
+	id, found := s.ids[hash]
+	return id, found, nil
+}
+
+// remoteHashes returns the remote store of the account, one per API key for the lifetime of the client
+func (pd *PixelDrainClient) remoteHashes(auth Auth) *remoteHashStore {
+	store, _ := pd.remoteStores.LoadOrStore(auth.APIKey, &remoteHashStore{pd: pd, auth: auth})
+	return store.(*remoteHashStore)
+}
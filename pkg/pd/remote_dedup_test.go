@@ -0,0 +1,93 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPD_RemoteDedup skips a file which another machine uploaded to the account
+func TestPD_RemoteDedup(t *testing.T) {
+	listings, uploads := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/files":
+			listings++
+			_, _ = w.Write([]byte(`{"files": [{"id": "remote-id", "name": "cat.jpg",
+				"hash_sha256": "1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b"}]}`))
+		case "/file":
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "new-id"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, HashStore: utils.NewMemoryHashStore(), RemoteDedup: true, DisableUploadLog: true}, nil)
+	upload := func(path string, auth pd.Auth) *pd.ResponseUpload {
+		rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Auth: auth, URL: server.URL + "/file"}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	account := pd.Auth{APIKey: "remote-account"}
+	rsp := upload("testdata/cat.jpg", account)
+	assert.Equal(t, http.StatusConflict, rsp.StatusCode)
+	assert.Equal(t, "remote-id", rsp.ID)
+	assert.Equal(t, "remote-id", rsp.Duplicate.ID)
+
+	assert.Equal(t, http.StatusCreated, upload("testdata/cat_unique3.jpg", account).StatusCode)
+	assert.Equal(t, 1, listings)
+	assert.Equal(t, 1, uploads)
+
+	// anonymous uploads have no account to check
+	assert.Equal(t, http.StatusCreated, upload("testdata/cat.jpg", pd.Auth{}).StatusCode)
+	assert.Equal(t, 1, listings)
+}
+
+// TestPD_RemoteDedup_TTL an expired listing is fetched again, a file deleted in the meantime is uploaded again
+func TestPD_RemoteDedup_TTL(t *testing.T) {
+	listings, uploads := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/files":
+			listings++
+			if listings > 1 {
+				// the file was deleted after the first listing
+				_, _ = w.Write([]byte(`{"files": []}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"files": [{"id": "remote-id", "name": "cat.jpg",
+				"hash_sha256": "1af93d68009bdfd52e1da100a019a30b5fe083d2d1130919225ad0fd3d1fed0b"}]}`))
+		case "/file":
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "new-id"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, HashStore: utils.NewMemoryHashStore(), RemoteDedup: true, RemoteDedupTTL: time.Millisecond, DisableUploadLog: true}, nil)
+	account := pd.Auth{APIKey: "remote-account"}
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: "testdata/cat.jpg", Auth: account, URL: server.URL + "/file"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rsp.StatusCode)
+
+	time.Sleep(5 * time.Millisecond)
+	rsp, err = c.UploadPOST(&pd.RequestUpload{PathToFile: "testdata/cat.jpg", Auth: account, URL: server.URL + "/file"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rsp.StatusCode)
+	assert.Equal(t, 2, listings)
+	assert.Equal(t, 1, uploads)
+}
@@ -0,0 +1,237 @@
+package pd
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+const (
+	ErrRangeNotSupported = "the server does not support range requests"
+	ErrMissingZipEntry   = "zip entry name is required"
+	// RemoteReadAhead is the minimum size of a range request, small reads of the zip decoder are served from it
+	RemoteReadAhead = 64 << 10
+)
+
+// RemoteFile reads parts of a file on pixeldrain with range requests, only the requested bytes are downloaded
+type RemoteFile struct {
+	pd   *PixelDrainClient
+	url  string
+	auth Auth
+	size int64
+
+	mu     sync.Mutex
+	buf    []byte // last range which was downloaded
+	bufOff int64
+}
+
+// OpenRemote returns a RemoteFile of the file, its size is taken from the file info
+func (pd *PixelDrainClient) OpenRemote(r *RequestRemoteFile) (*RemoteFile, error) {
+	if r.ID == "" {
+		return nil, errors.New(ErrMissingFileID)
+	}
+
+	if r.URL == "" {
+		r.URL = fmt.Sprintf(pd.API.URL+pd.API.File+"/%s", r.ID)
+	}
+
+	info, err := pd.GetFileInfo(&RequestFileInfo{ID: r.ID, Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	if !info.Success {
+		return nil, &APIError{StatusCode: info.StatusCode, Value: info.Value, Message: info.Message}
+	}
+
+	return &RemoteFile{pd: pd, url: r.URL, auth: r.Auth, size: info.Size}, nil
+}
+
+// Size returns the size of the remote file in bytes
+func (f *RemoteFile) Size() int64 {
+	return f.size
+}
+
+// ReadAt implements io.ReaderAt, reads smaller than RemoteReadAhead download RemoteReadAhead bytes
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if off < f.bufOff || off+int64(len(p)) > f.bufOff+int64(len(f.buf)) {
+		length := int64(len(p))
+		if length < RemoteReadAhead {
+			length = RemoteReadAhead
+		}
+		if off+length > f.size {
+			length = f.size - off
+		}
+
+		data, err := f.fetch(off, length)
+		if err != nil {
+			return 0, err
+		}
+		f.buf, f.bufOff = data, off
+	}
+
+	n := copy(p, f.buf[off-f.bufOff:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// fetch downloads the bytes [off, off+length) of the file
+func (f *RemoteFile) fetch(off, length int64) ([]byte, error) {
+	if err := f.pd.reserveBandwidth(context.Background(), utils.Download, length); err != nil {
+		return nil, err
+	}
+
+	header := f.pd.header(f.auth)
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+length-1))
+
+	rsp, err := f.pd.request(context.Background(), http.MethodGet, f.url, header, nil)
+	if f.pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return nil, err
+	}
+	f.pd.recordBandwidth(utils.Download, int64(len(data)))
+
+	switch {
+	case rsp.StatusCode >= 400:
+		return nil, newAPIError(rsp.StatusCode, data)
+	case rsp.StatusCode != http.StatusPartialContent:
+		// the whole file was sent, a zip is not read this way
+		return nil, errors.New(ErrRangeNotSupported)
+	case int64(len(data)) != length:
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return data, nil
+}
+
+// ZipEntry is a file or directory inside of a remote zip archive
+type ZipEntry struct {
+	Name             string      `json:"name"`
+	CompressedSize   int64       `json:"compressed_size"`
+	UncompressedSize int64       `json:"uncompressed_size"`
+	Modified         time.Time   `json:"modified"`
+	CRC32            uint32      `json:"crc32"`
+	Mode             os.FileMode `json:"mode"`
+	IsDir            bool        `json:"is_dir"`
+}
+
+// openRemoteZip reads the central directory of the zip, the compressed data of the entries is not downloaded
+func (pd *PixelDrainClient) openRemoteZip(r *RequestRemoteFile) (*zip.Reader, error) {
+	file, err := pd.OpenRemote(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(file, file.Size())
+}
+
+// ListZipContents GET /api/file/{id} with range requests, returns the entries of a zip file on pixeldrain.
+// Only the end of the archive with the central directory is downloaded.
+func (pd *PixelDrainClient) ListZipContents(r *RequestRemoteFile) ([]ZipEntry, error) {
+	archive, err := pd.openRemoteZip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ZipEntry, 0, len(archive.File))
+	for _, file := range archive.File {
+		entries = append(entries, ZipEntry{
+			Name:             file.Name,
+			CompressedSize:   int64(file.CompressedSize64),
+			UncompressedSize: int64(file.UncompressedSize64),
+			Modified:         file.Modified,
+			CRC32:            file.CRC32,
+			Mode:             file.Mode(),
+			IsDir:            file.FileInfo().IsDir(),
+		})
+	}
+
+	return entries, nil
+}
+
+// ExtractFromRemoteZip saves one entry of a zip file on pixeldrain to PathToSave, only the central directory
+// and the compressed bytes of the entry are downloaded. The checksum of the entry is verified by the zip reader.
+func (pd *PixelDrainClient) ExtractFromRemoteZip(r *RequestExtractZip) (*ResponseExtractZip, error) {
+	if r.Entry == "" {
+		return nil, errors.New(ErrMissingZipEntry)
+	}
+	if r.PathToSave == "" {
+		return nil, errors.New(ErrMissingPathToFile)
+	}
+
+	archive, err := pd.openRemoteZip(&RequestRemoteFile{ID: r.ID, Auth: r.Auth, URL: r.URL})
+	if err != nil {
+		return nil, err
+	}
+
+	var found *zip.File
+	for _, file := range archive.File {
+		if file.Name == r.Entry {
+			found = file
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("zip entry %s: %w", r.Entry, os.ErrNotExist)
+	}
+
+	entry, err := found.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+
+	if err := os.MkdirAll(filepath.Dir(r.PathToSave), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(r.PathToSave)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := io.Copy(file, entry)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// a corrupted entry must not be left behind as if it was extracted
+		_ = os.Remove(r.PathToSave)
+		return nil, err
+	}
+
+	return &ResponseExtractZip{
+		Entry:    r.Entry,
+		FilePath: r.PathToSave,
+		FileSize: size,
+		ResponseDefault: ResponseDefault{
+			Success:    true,
+			StatusCode: http.StatusOK,
+		},
+	}, nil
+}
@@ -0,0 +1,118 @@
+package pd_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingWriter counts the bytes of the response bodies
+type countingWriter struct {
+	http.ResponseWriter
+	sent *int64
+}
+
+func (w countingWriter) Write(b []byte) (int, error) {
+	atomic.AddInt64(w.sent, int64(len(b)))
+	return w.ResponseWriter.Write(b)
+}
+
+// zipServer serves the archive as file "archive" with range support if ranges is set
+func zipServer(archive []byte, ranges bool, sent *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			_, _ = fmt.Fprintf(w, `{"success": true, "id": "archive", "size": %d}`, len(archive))
+			return
+		}
+
+		w = countingWriter{ResponseWriter: w, sent: sent}
+		if !ranges {
+			_, _ = w.Write(archive)
+			return
+		}
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(archive))
+	}))
+}
+
+func testArchive(t *testing.T) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+
+	// a large incompressible entry, which must not be downloaded
+	large := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(large)
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: "large.bin", Method: zip.Store})
+	assert.NoError(t, err)
+	_, _ = w.Write(large)
+
+	w, err = writer.Create("docs/readme.txt")
+	assert.NoError(t, err)
+	_, _ = w.Write(bytes.Repeat([]byte("read me "), 100))
+	assert.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestPD_ListZipContents(t *testing.T) {
+	archive := testArchive(t)
+	var sent int64
+	server := zipServer(archive, true, &sent)
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	entries, err := c.ListZipContents(&pd.RequestRemoteFile{ID: "archive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "large.bin", entries[0].Name)
+		assert.Equal(t, int64(1<<20), entries[0].UncompressedSize)
+		assert.Equal(t, "docs/readme.txt", entries[1].Name)
+		assert.Equal(t, int64(800), entries[1].UncompressedSize)
+	}
+	assert.Less(t, atomic.LoadInt64(&sent), int64(len(archive)/4))
+
+	// only the central directory and the small entry are downloaded
+	atomic.StoreInt64(&sent, 0)
+	path := filepath.Join(t.TempDir(), "readme.txt")
+	rsp, err := c.ExtractFromRemoteZip(&pd.RequestExtractZip{ID: "archive", Entry: "docs/readme.txt", PathToSave: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(800), rsp.FileSize)
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, bytes.Repeat([]byte("read me "), 100), data)
+	assert.Less(t, atomic.LoadInt64(&sent), int64(len(archive)/4))
+
+	_, err = c.ExtractFromRemoteZip(&pd.RequestExtractZip{ID: "archive", Entry: "missing.txt", PathToSave: path + ".missing"})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPD_ListZipContents_NoRanges(t *testing.T) {
+	var sent int64
+	server := zipServer(testArchive(t), false, &sent)
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	_, err := c.ListZipContents(&pd.RequestRemoteFile{ID: "archive"})
+	assert.EqualError(t, err, pd.ErrRangeNotSupported)
+}
@@ -1,8 +1,13 @@
 package pd
 
 import (
+	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
 )
 
 // Auth hold the auth information
@@ -23,11 +28,48 @@ func (a *Auth) IsAuthAvailable() bool {
 // RequestUpload container for the upload information
 type RequestUpload struct {
 	File       io.ReadCloser
-	PathToFile string // path to the file "/home/user/cat.jpg"
-	FileName   string // just the filename "test.jpg"
-	Anonymous  bool   // if the upload is anonymous or with auth
+	Source     io.ReaderAt // replayable source instead of File, e.g. *os.File or *bytes.Reader, failed uploads are sent again from it
+	SourceSize int64       // size of Source in bytes
+	PathToFile string      // path to the file "/home/user/cat.jpg"
+	FileName   string      // just the filename "test.jpg"
+	Anonymous  bool        // if the upload is anonymous or with auth
 	Auth       Auth
-	URL        string // specific the upload endpoint, is set by default with the correct values
+	URL        string            // specific the upload endpoint, is set by default with the correct values
+	Extra      map[string]string // additional form fields (POST) or query params (PUT) for upload parameters not covered by this package
+	// DeleteAfterUpload removes the local file after a verified successful upload
+	DeleteAfterUpload bool
+	// ArchiveDir moves the local file into this directory after a verified successful upload instead of deleting it
+	ArchiveDir string
+	// ChunkSize splits PUT uploads of larger files into chunks of this size, 0 sends the file in one request.
+	// It needs ClientOptions.ChunkedUploads, pixeldrain itself doesn't assemble chunks.
+	ChunkSize int64
+	// ChunkStatePath keeps the progress of a chunked upload, an interrupted upload of the same file resumes from it.
+	// It is the key in the StateStore of the client if one is set.
+	ChunkStatePath string
+	// Resume continues a chunked upload at a Checkpoint the caller persisted, e.g. in another process.
+	// It is ignored if the file, URL or chunk size changed.
+	Resume *Checkpoint
+	// ChunkRetries attempts per chunk, the MaxAttempts of the client RetryPolicy if 0
+	ChunkRetries int
+	// Progress is called with the bytes sent, the total size and the rate while the file is uploaded
+	Progress utils.ProgressFunc
+	// Verify compares the hash pixeldrain stores with the local one, a mismatch returns *ChecksumMismatchError
+	Verify bool
+	// EncryptWith encrypts the file before it is sent, Download decrypts it with the same passphrase as Key.
+	// Keys starting with "age1" are age recipients, separated by commas, and Key is an age identity.
+	// The duplicate check still works with the hash of the unencrypted file.
+	EncryptWith string
+	// Compress compresses the file before it is sent (and encrypted), Download decompresses it again
+	Compress Compression
+	// FileSize of File in bytes, UploadPOST streams a File of known size instead of reading it into memory first.
+	// Such a File is sent once, a failed attempt is not retried.
+	FileSize int64
+	// ContentType of File, sniffed from its first bytes if empty
+	ContentType string
+	// ForceHash hashes the file for the duplicate check even if the HashCache of the client knows it unchanged
+	ForceHash bool
+
+	dedupHash string // hash of the duplicate check of UploadPOST, stored after the upload
 }
 
 // GetFileName return the filename from the path if no specific filename in the params
@@ -45,6 +87,33 @@ func (r *RequestUpload) GetFileName() string {
 type RequestDownload struct {
 	ID         string
 	PathToSave string
+	Writer     io.Writer // stream the file into the writer instead of saving it to PathToSave
+	Key        string    // key or passphrase to reverse transformed uploads, e.g. the EncryptWith passphrase
+	Raw        bool      // save compressed or encrypted uploads as they are stored on pixeldrain
+	Auth       Auth
+	URL        string             // specific the API endpoint, is set by default with the correct values
+	Progress   utils.ProgressFunc // called with the bytes received, the total size and the rate while the file is downloaded
+	Verify     bool               // compare the hash pixeldrain stores with the received one, a mismatch returns *ChecksumMismatchError
+	// Resume continues a partially written PathToSave with a range request and always checks the hash of the
+	// whole file. The file is saved as it is stored on pixeldrain like with Raw, it can't be streamed to a Writer.
+	Resume bool
+	// Segments downloads the file in this many parallel ranges of at least MinSegmentSize into PathToSave, which
+	// speeds up connections with a high latency. The file is saved as it is stored like with Raw, Resume wins.
+	Segments int
+}
+
+// RequestRemoteFile reads parts of a file with range requests
+type RequestRemoteFile struct {
+	ID   string
+	Auth Auth
+	URL  string // specific the API endpoint, is set by default with the correct values
+}
+
+// RequestExtractZip extracts one entry of a zip file on pixeldrain
+type RequestExtractZip struct {
+	ID         string
+	Entry      string // name of the entry inside of the archive, e.g. "photos/cat.jpg"
+	PathToSave string
 	Auth       Auth
 	URL        string // specific the API endpoint, is set by default with the correct values
 }
@@ -56,21 +125,242 @@ type RequestFileInfo struct {
 	URL  string
 }
 
+// RequestFileInfoMany the infos of several files are requested at once
+type RequestFileInfoMany struct {
+	IDs  []string
+	Auth Auth
+	URL  string // file endpoint without IDs, is set by default with the correct values
+}
+
 // RequestThumbnail the Thumbnail request needs the ID and width and height
 type RequestThumbnail struct {
 	ID         string
-	Width      string
-	Height     string
+	Width      ThumbnailSize // 0 uses the default size of pixeldrain
+	Height     ThumbnailSize // 0 uses the default size of pixeldrain
 	PathToSave string
 	Auth       Auth
 	URL        string
 }
 
+// ThumbnailSize edge length of a thumbnail in pixels, pixeldrain supports multiples of 16 up to 128
+type ThumbnailSize int
+
+const (
+	ThumbnailSize16  ThumbnailSize = 16
+	ThumbnailSize32  ThumbnailSize = 32
+	ThumbnailSize48  ThumbnailSize = 48
+	ThumbnailSize64  ThumbnailSize = 64
+	ThumbnailSize80  ThumbnailSize = 80
+	ThumbnailSize96  ThumbnailSize = 96
+	ThumbnailSize112 ThumbnailSize = 112
+	ThumbnailSize128 ThumbnailSize = 128
+)
+
+// IsValid checks if pixeldrain supports the size
+func (s ThumbnailSize) IsValid() bool {
+	return s >= ThumbnailSize16 && s <= ThumbnailSize128 && s%16 == 0
+}
+
+// ThumbnailSizeError is returned if the width or height of a thumbnail request is not supported
+type ThumbnailSizeError struct {
+	Width  ThumbnailSize
+	Height ThumbnailSize
+}
+
+func (e *ThumbnailSizeError) Error() string {
+	return fmt.Sprintf("unsupported thumbnail size %dx%d, width and height must be multiples of 16 between 16 and 128", e.Width, e.Height)
+}
+
+// Validate checks the width and height, unset values are allowed
+func (r *RequestThumbnail) Validate() error {
+	if (r.Width != 0 && !r.Width.IsValid()) || (r.Height != 0 && !r.Height.IsValid()) {
+		return &ThumbnailSizeError{Width: r.Width, Height: r.Height}
+	}
+
+	return nil
+}
+
 // RequestDelete delete the file if you are the owner with the given ID
+type RequestUploadChanged struct {
+	Directory string // local directory which is kept in step with the account
+	IndexPath string // index of the last uploaded state of the files, IndexFilePath by default, the key in the client StateStore if set
+	Anonymous bool
+	Auth      Auth
+	URL       string // API base URL, is set by default with the correct values
+}
+
+// RequestSync keeps a local directory and a list in step
+type RequestSync struct {
+	Directory    string // local directory
+	ListID       string // remote list
+	ManifestPath string // state of the last run, SyncManifestName inside of the directory by default, the key in the client StateStore if set
+	DeleteRemote bool   // delete the remote files of files removed locally and of replaced versions
+	DeleteLocal  bool   // delete the local files of files removed from the list
+	DownloadNew  bool   // download files which were added to the list by others
+	DryRun       bool   // report what would be uploaded and deleted without sending anything or changing files
+	Anonymous    bool
+	Auth         Auth
+	// Metrics counts the uploads and the files waiting for them, nil counts nothing
+	Metrics *SyncMetrics
+}
+
+type RequestUploadDirectory struct {
+	Directory       string
+	Auth            Auth
+	URL             string // API base URL, is set by default with the correct values
+	HashFilePath    string // hash file of the duplicate check, utils.GetHashFilePath() by default
+	ContinueOnError bool   // upload the remaining files after a failed file instead of stopping
+	DryRun          bool   // hash the files and report which would be uploaded or skipped, nothing is sent
+	// GroupBy creates a list per MIME category or subdirectory for the files without a list of an UploadRule
+	GroupBy ListGrouping
+	// EmptyFiles are uploaded by default, SpecialFiles (pipes, sockets and devices) are skipped, of them only
+	// named pipes can be uploaded. UnreadableFiles, including the files of unreadable directories, fail by default.
+	EmptyFiles      SpecialFileAction
+	SpecialFiles    SpecialFileAction
+	UnreadableFiles SpecialFileAction
+	// Progress is called with the path of the file while it is uploaded, e.g. for a progress bar per file
+	Progress func(filePath string, p utils.Progress)
+	// EncryptWith encrypts the files whose UploadRule has no key of its own, see RequestUpload.EncryptWith
+	EncryptWith string
+	// Compress compresses the files whose UploadRule has no compression of its own
+	Compress Compression
+	// IndexPath writes an index of the uploaded files after the upload, see ResponseUploadDirectory.SaveIndex
+	IndexPath string
+	// CreateList adds every uploaded file, skipped duplicates with a known ID included, to one more list, so the
+	// whole directory is shared with one link. The list is titled after the directory unless ListTitle is set.
+	CreateList bool
+	ListTitle  string
+	// PreservePaths keeps the path of a file in a subdirectory in its file name or its list descriptions
+	PreservePaths PathPreservation
+	// ManifestPath writes a Manifest with the path, ID, hash, size and modification time of every uploaded file,
+	// RestoreFromManifest downloads the tree again
+	ManifestPath string
+	// ForceHash hashes every file, see RequestUpload.ForceHash
+	ForceHash bool
+}
+
+// RequestUploadArchive uploads a directory as one tar or zip file
+type RequestUploadArchive struct {
+	Directory string
+	Format    ArchiveFormat // ArchiveTar by default
+	FileName  string        // name of the archive on pixeldrain, the directory name with the extension of the format by default
+	Anonymous bool
+	Auth      Auth
+	URL       string             // specific the upload endpoint, is set by default with the correct values
+	Progress  utils.ProgressFunc // called with the bytes of the archive sent so far, the total size is unknown
+}
+
+// RequestUploadStream uploads a reader of unknown length
+type RequestUploadStream struct {
+	Reader    io.Reader
+	FileName  string // name on pixeldrain, required
+	Size      int64  // expected size in bytes for the Progress, 0 if unknown
+	Anonymous bool
+	Auth      Auth
+	URL       string             // specific the upload endpoint, is set by default with the correct values
+	Progress  utils.ProgressFunc // called with the bytes sent while the reader is read
+}
+
+// RequestUploadFromURL uploads a file of another server
+type RequestUploadFromURL struct {
+	SourceURL string
+	Header    http.Header // sent with the request of the source, e.g. its authorization
+	FileName  string      // name on pixeldrain, taken from the Content-Disposition or the URL of the source by default
+	MaxSize   int64       // largest source in bytes which is uploaded, 0 means no limit
+	Anonymous bool
+	Auth      Auth
+	URL       string             // specific the upload endpoint, is set by default with the correct values
+	Progress  utils.ProgressFunc // called with the bytes sent, the total size is the Content-Length of the source
+}
+
+// RequestDownloadDirectory mirrors a list or the account into a local directory
+type RequestDownloadDirectory struct {
+	Directory       string // target directory, created if it doesn't exist
+	ListID          string // list to mirror, all files of the account if empty
+	Auth            Auth
+	LogPath         string // upload log to restore the paths of the files uploaded from below LogRoot
+	LogRoot         string // directory the files were uploaded from, the paths below it are kept
+	Verify          bool   // compare the hash pixeldrain stores with the received one
+	ContinueOnError bool   // download the remaining files after a failed file instead of stopping
+	// PathsFromDescriptions saves the files of the list at the paths UploadDirectory stored with PathInDescription
+	PathsFromDescriptions bool
+}
+
+// RequestRestoreManifest downloads the files of a Manifest
+type RequestRestoreManifest struct {
+	ManifestPath    string
+	Directory       string // target directory, created if it doesn't exist
+	Auth            Auth
+	Key             string // passphrase of encrypted uploads, see RequestDownload.Key
+	ContinueOnError bool   // restore the remaining files after a failed file instead of stopping
+}
+
+// RequestWatch uploads new and modified files of a directory while it is watched
+type RequestWatch struct {
+	Directory      string
+	Anonymous      bool
+	Auth           Auth
+	HashFilePath   string          // hash file of the duplicate check, utils.GetHashFilePath() by default
+	Interval       time.Duration   // time between two scans without notifications and the first retry, DefaultWatchInterval if 0
+	Debounce       time.Duration   // a file is uploaded after it stayed unchanged this long, DefaultWatchDebounce if 0
+	Ignore         []string        // glob patterns of the relative path or the file name, e.g. "*.tmp" or ".git/*"
+	UploadExisting bool            // upload the files which exist when the watch starts, they are skipped by default
+	Trigger        <-chan struct{} // scan right away, e.g. on a signal
+	Poll           bool            // scan every Interval instead of using file system notifications, e.g. on network shares
+	// OnUpload is called after every upload, rsp is nil if err is set
+	OnUpload func(filePath string, rsp *ResponseUpload, err error)
+	Metrics  *SyncMetrics // counts the uploads and the files waiting for them, nil counts nothing
+}
+
+type RequestUploadBatch struct {
+	Paths     []string
+	Anonymous bool
+	Auth      Auth
+	URL       string // specific the upload endpoint, is set by default with the correct values
+	StatePath string // state file of the batch, an existing state is resumed, the key in the client StateStore if set
+	// HashFilePath hash file of the duplicate check, utils.GetHashFilePath() by default
+	HashFilePath string
+}
+
+type RequestVerifyLog struct {
+	LogPath string // upload log to verify, CSVFilePath by default
+	Auth    Auth
+	URL     string // API base URL, is set by default with the correct values
+}
+
+// RequestSweepAccount compares the files of the account with the upload log
+type RequestSweepAccount struct {
+	LogPath     string // upload log with the local catalog, CSVFilePath by default
+	Auth        Auth
+	Concurrency int // parallel file info requests, DefaultSweepConcurrency if 0
+}
+
 type RequestDelete struct {
-	ID   string
-	Auth Auth
-	URL  string
+	ID          string
+	Auth        Auth
+	URL         string
+	Immediately bool // delete the file now, even if the client has a DeleteGrace
+}
+
+// RequestDeleteMany deletes several files in parallel
+type RequestDeleteMany struct {
+	IDs            []string
+	Auth           Auth
+	Concurrency    int  // parallel deletes, DefaultDeleteConcurrency if 0
+	Immediately    bool // delete the files now, even if the client has a DeleteGrace
+	IgnoreNotFound bool // files which are already gone count as deleted
+}
+
+// RequestPurgeAccount selects the files and lists PurgeAccount deletes
+type RequestPurgeAccount struct {
+	Auth        Auth
+	Filter      FileFilter    // every condition which is set must match, e.g. Name "*.log" and MinSize 1 GiB
+	OlderThan   time.Duration // files uploaded longer ago, combined with the Filter
+	ListTitle   string        // glob for the titles of lists which are deleted too
+	All         bool          // allow an empty filter, which deletes every file of the account
+	DryRun      bool          // report the matches without deleting them
+	Concurrency int           // parallel deletes, DefaultDeleteConcurrency if 0
+	Immediately bool          // delete the files now, even if the client has a DeleteGrace
 }
 
 // RequestCreateList parameters for creating new list
@@ -95,6 +385,24 @@ type RequestGetList struct {
 	URL  string
 }
 
+// RequestUpdateList renames a list and changes its files
+type RequestUpdateList struct {
+	ID          string
+	Title       string     // new title, the current title is kept if empty
+	Files       []ListFile // replaces all files of the list, the current files are kept if nil
+	AddFiles    []ListFile // appended to the files of the list
+	RemoveFiles []string   // IDs of the files which are removed from the list
+	Auth        Auth
+	URL         string
+}
+
+// RequestDeleteList deletes the list with the given ID, the files stay
+type RequestDeleteList struct {
+	ID   string
+	Auth Auth
+	URL  string
+}
+
 // RequestGetUser ...
 type RequestGetUser struct {
 	Auth Auth
@@ -103,8 +411,13 @@ type RequestGetUser struct {
 
 // RequestGetUserFiles ...
 type RequestGetUserFiles struct {
-	Auth Auth
-	URL  string
+	Auth       Auth
+	URL        string
+	Page       int           // page starting at 0, used if Limit is set
+	Limit      int           // files per page, 0 returns all files at once
+	Sort       UserFilesSort // order of the files, the order of pixeldrain if empty
+	Descending bool          // reverse the Sort order
+	Filter     *FileFilter   // applied to all files of the account on the client side, before the page is cut out
 }
 
 // RequestGetUserLists ...
@@ -112,3 +425,64 @@ type RequestGetUserLists struct {
 	Auth Auth
 	URL  string
 }
+
+type RequestGetUserActivity struct {
+	Page int // page of the events, 0 is the newest page
+	Auth Auth
+	URL  string
+}
+
+type RequestGetUserTransactions struct {
+	Auth Auth
+	URL  string
+}
+
+type RequestFSRename struct {
+	Path   string // current path, e.g. "/me/photos/cat.jpg"
+	Target string // new path, e.g. "/me/photos/kitty.jpg"
+	Auth   Auth
+	URL    string
+}
+
+type RequestFSMove struct {
+	Path      string // current path, e.g. "/me/photos/cat.jpg"
+	TargetDir string // directory the path is moved into, e.g. "/me/archive"
+	Auth      Auth
+	URL       string
+}
+
+type RequestFSCreateBucket struct {
+	Name string // name of the directory at the root, e.g. "backups"
+	Auth Auth
+	URL  string
+}
+
+type RequestFSMkdir struct {
+	Path    string // path of the new directory, e.g. "/me/photos/2024"
+	Parents bool   // create the missing parent directories too
+	Auth    Auth
+	URL     string
+}
+
+type RequestFSUpload struct {
+	Path       string        // path the file is stored under, e.g. "/me/photos/cat.jpg"
+	PathToFile string        // local file to upload
+	File       io.ReadCloser // or a reader, it is sent once without retries
+	Parents    bool          // create the missing parent directories of the path
+	Auth       Auth
+	URL        string
+	Progress   utils.ProgressFunc
+}
+
+type RequestFSList struct {
+	Path string // path of a directory or file, e.g. "/me/photos"
+	Auth Auth
+	URL  string
+}
+
+type RequestFSDelete struct {
+	Path      string // path of a file or directory, e.g. "/me/photos/cat.jpg"
+	Recursive bool   // delete a directory with everything in it
+	Auth      Auth
+	URL       string
+}
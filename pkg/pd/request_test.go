@@ -47,19 +47,32 @@ func TestPD_RequestDownload(t *testing.T) {
 func TestPD_RequestThumbnail(t *testing.T) {
 	r := &pd.RequestThumbnail{
 		ID:     "123",
-		Width:  "16",
-		Height: "16",
+		Width:  pd.ThumbnailSize16,
+		Height: pd.ThumbnailSize16,
 		URL:    "http://example.url",
 		Auth:   pd.Auth{APIKey: "test-key"},
 	}
 
 	assert.Equal(t, "123", r.ID)
-	assert.Equal(t, "16", r.Width)
-	assert.Equal(t, "16", r.Height)
+	assert.Equal(t, pd.ThumbnailSize16, r.Width)
+	assert.Equal(t, pd.ThumbnailSize16, r.Height)
 	assert.Equal(t, "http://example.url", r.URL)
 	assert.Equal(t, "test-key", r.Auth.APIKey)
 }
 
+func TestPD_RequestThumbnail_Validate(t *testing.T) {
+	r := &pd.RequestThumbnail{ID: "123"}
+	assert.NoError(t, r.Validate())
+
+	r.Width = pd.ThumbnailSize128
+	r.Height = 100
+	err := r.Validate()
+
+	var sizeErr *pd.ThumbnailSizeError
+	assert.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, pd.ThumbnailSize(100), sizeErr.Height)
+}
+
 func TestPD_RequestDelete(t *testing.T) {
 	r := &pd.RequestDelete{
 		ID:   "123",
@@ -1,6 +1,7 @@
 package pd
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -10,22 +11,155 @@ type ResponseDefault struct {
 	Success    bool   `json:"success"`
 	Value      string `json:"value,omitempty"`
 	Message    string `json:"message,omitempty"`
+	// RawExtra holds the fields of the JSON response the response struct has no field for, nil if there are none
+	RawExtra map[string]json.RawMessage `json:"-"`
+	// RateLimit is the quota reported with the response, nil if pixeldrain sent no rate limit headers
+	RateLimit *RateLimitInfo `json:"-"`
 }
 
 type ResponseUpload struct {
-	ID string `json:"id,omitempty"`
+	ID        string          `json:"id,omitempty"`        // ID of the uploaded file, of the original if a duplicate was skipped
+	Duplicate *DuplicateMatch `json:"duplicate,omitempty"` // set if the upload was skipped by the duplicate check
+	Hash      string          `json:"hash,omitempty"`      // SHA-256 of the local file, computed by the client
+	Size      int64           `json:"size,omitempty"`      // size of the local file in bytes
+	MimeType  string          `json:"mime_type,omitempty"` // MIME type detected from the local file
+	Timings   *Timings        `json:"timings,omitempty"`   // phases of the upload, nil if it was skipped
 	ResponseDefault
 }
 
 // GetFileURL return the full URl to the uploaded file
 func (rsp *ResponseUpload) GetFileURL() string {
-	return fmt.Sprintf("%su/%s", BaseURL, rsp.ID)
+	return fileURL(rsp.ID)
+}
+
+// fileURL return the full URL to the file with the given ID
+func fileURL(id string) string {
+	return fmt.Sprintf("%su/%s", BaseURL, id)
+}
+
+// thumbnailURL return the full URL to the thumbnail of the file with the given ID
+func thumbnailURL(id string) string {
+	return fmt.Sprintf("%s/file/%s/thumbnail", APIURL, id)
+}
+
+// listURL return the full URL to the list with the given ID
+func listURL(id string) string {
+	return fmt.Sprintf("%sl/%s", BaseURL, id)
+}
+
+type ResponseUploadChanged struct {
+	Uploaded  []string          `json:"uploaded"`  // new files which were not in the index
+	Replaced  []string          `json:"replaced"`  // changed files whose old remote file was deleted
+	Unchanged []string          `json:"unchanged"` // files with the same content as at the last upload
+	IDs       map[string]string `json:"ids"`       // remote ID per uploaded or replaced file
+}
+
+type ResponseSync struct {
+	Uploaded      []string          `json:"uploaded"`       // new local files which were uploaded and added to the list
+	Replaced      []string          `json:"replaced"`       // changed local files whose old version was removed from the list
+	Unchanged     []string          `json:"unchanged"`      // files with the same content as at the last run
+	Downloaded    []string          `json:"downloaded"`     // files added to the list by others
+	DeletedRemote []string          `json:"deleted_remote"` // files removed locally which were deleted on pixeldrain
+	DeletedLocal  []string          `json:"deleted_local"`  // files removed from the list which were deleted locally
+	IDs           map[string]string `json:"ids"`            // remote ID per uploaded, replaced or downloaded file
+}
+
+type ResponseUploadDirectory struct {
+	Files     []BatchFileResult `json:"files"`
+	Lists     map[string]string `json:"lists"`              // ID of every created list by title
+	ListURLs  map[string]string `json:"list_urls"`          // URL of every created list by title
+	Directory string            `json:"directory"`          // the uploaded directory, the paths of the index are relative to it
+	ListID    string            `json:"list_id,omitempty"`  // list of all files, see RequestUploadDirectory.CreateList
+	ListURL   string            `json:"list_url,omitempty"` // URL of the list of all files
+}
+
+// Failed returns the results of the files which could not be uploaded
+func (rsp *ResponseUploadDirectory) Failed() []BatchFileResult {
+	var failed []BatchFileResult
+	for _, file := range rsp.Files {
+		if file.Status == BatchFailed {
+			failed = append(failed, file)
+		}
+	}
+
+	return failed
+}
+
+type ResponseDownloadDirectory struct {
+	Files []BatchFileResult `json:"files"` // files which already exist with the same hash are skipped_duplicate
+}
+
+type ResponseUploadBatch struct {
+	Files     []BatchFileResult `json:"files"`
+	Cancelled bool              `json:"cancelled"`
+}
+
+// BatchFileResult the state of a single file of a batch
+type BatchFileResult struct {
+	Path   string          `json:"path"`
+	Status BatchFileStatus `json:"status"`
+	ID     string          `json:"id,omitempty"`
+	URL    string          `json:"url,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Hash   string          `json:"hash,omitempty"` // hash of the duplicate check of the local file, set by dry runs
+
+	Duplicate *DuplicateMatch `json:"duplicate,omitempty"` // the original of a skipped duplicate
+}
+
+// Unfinished returns the paths of the files which were neither uploaded nor skipped as duplicates
+func (rsp *ResponseUploadBatch) Unfinished() []string {
+	var paths []string
+	for _, file := range rsp.Files {
+		if !file.Status.finished() {
+			paths = append(paths, file.Path)
+		}
+	}
+
+	return paths
+}
+
+type ResponseVerifyLog struct {
+	Checked int              `json:"checked"`
+	OK      []VerifiedUpload `json:"ok"`
+	Missing []VerifiedUpload `json:"missing"` // deleted or expired on pixeldrain
+	Altered []VerifiedUpload `json:"altered"` // size or hash differ from the upload log
+}
+
+// VerifiedUpload a recorded upload checked by VerifyLog
+type VerifiedUpload struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Hash   string `json:"hash,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ResponseSweepAccount struct {
+	Checked     int          `json:"checked"` // files in the account
+	OK          []DriftEntry `json:"ok"`
+	Altered     []DriftEntry `json:"altered"`     // size or hash differ from the upload log
+	Unavailable []DriftEntry `json:"unavailable"` // listed in the account, but the info can't be fetched
+	Untracked   []DriftEntry `json:"untracked"`   // in the account, but not in the upload log
+	Missing     []DriftEntry `json:"missing"`     // in the upload log, but not in the account anymore
+}
+
+// DriftEntry a file checked by SweepAccount with the local and the remote values
+type DriftEntry struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	RemoteSize int64  `json:"remote_size,omitempty"`
+	RemoteHash string `json:"remote_hash,omitempty"`
+	Reason     string `json:"reason,omitempty"`
 }
 
 type ResponseDownload struct {
-	FilePath string `json:"file_path"`
-	FileName string `json:"file_name"`
-	FileSize int64  `json:"file_size"`
+	FilePath string   `json:"file_path"`
+	FileName string   `json:"file_name"`
+	FileSize int64    `json:"file_size"`
+	Timings  *Timings `json:"timings,omitempty"`
 	ResponseDefault
 }
 
@@ -43,9 +177,26 @@ type ResponseFileInfo struct {
 	ThumbnailHref     string    `json:"thumbnail_href"`
 	HashSha256        string    `json:"hash_sha256"`
 	CanEdit           bool      `json:"can_edit"`
+	// Availability is empty for files which can be downloaded, otherwise it tells why not, e.g.
+	// AvailabilityVirusDetected. AbuseType is set for files which were blocked after an abuse report.
+	Availability        string `json:"availability"`
+	AvailabilityMessage string `json:"availability_message"`
+	AbuseType           string `json:"abuse_type"`
+	AbuseReporterName   string `json:"abuse_reporter_name"`
 	ResponseDefault
 }
 
+// availability values of pixeldrain, files with them need a captcha which clients of the API can't solve
+const (
+	AvailabilityRateLimited   = "file_rate_limited_captcha_required"
+	AvailabilityVirusDetected = "virus_detected_captcha_required"
+)
+
+// IsAvailable checks if the file can be downloaded, blocked, flagged and rate limited files can't
+func (r *ResponseFileInfo) IsAvailable() bool {
+	return r.Availability == "" && r.AbuseType == ""
+}
+
 type ResponseThumbnail struct {
 	FilePath string `json:"file_path"`
 	FileName string `json:"file_name"`
@@ -53,15 +204,77 @@ type ResponseThumbnail struct {
 	ResponseDefault
 }
 
+type ResponseExtractZip struct {
+	Entry    string `json:"entry"`
+	FilePath string `json:"file_path"`
+	FileSize int64  `json:"file_size"` // uncompressed size in bytes
+	ResponseDefault
+}
+
+type ResponseThumbnailBytes struct {
+	Data        []byte `json:"-"`
+	ContentType string `json:"content_type"`
+	ResponseDefault
+}
+
 type ResponseDelete struct {
+	DeleteAt time.Time `json:"-"` // when the file is deleted if the delete was scheduled with the DeleteGrace of the client
 	ResponseDefault
 }
 
+// DeleteResult is the outcome of the delete of one file of DeleteMany
+type DeleteResult struct {
+	ID         string
+	StatusCode int
+	Err        error // nil if the file was deleted or its delete was scheduled
+}
+
+type ResponseDeleteMany struct {
+	Results []DeleteResult // in the order of the requested IDs
+	Deleted int
+	Failed  int
+}
+
+// ResponsePurgeAccount reports the matched and deleted files and lists of PurgeAccount
+type ResponsePurgeAccount struct {
+	Files       []FileGetUser  // files which matched the filter
+	Lists       []ListsGetUser // lists which matched the ListTitle
+	Results     []DeleteResult // in the order of the Files, empty for a DryRun
+	ListResults []DeleteResult // in the order of the Lists, empty for a DryRun
+	Deleted     int            // deleted files
+	Failed      int            // files and lists which could not be deleted
+	FreedBytes  int64          // size of the deleted files, of the matched files for a DryRun
+}
+
 type ResponseCreateList struct {
 	ID string `json:"id"`
 	ResponseDefault
 }
 
+// GetListURL return the full URL to the created list
+func (rsp *ResponseCreateList) GetListURL() string {
+	return listURL(rsp.ID)
+}
+
+type ResponseUploadToList struct {
+	ListID   string           `json:"list_id"`
+	Uploaded []string         `json:"uploaded"` // IDs of the uploaded files
+	Skipped  []DuplicateMatch `json:"skipped"`  // files not uploaded because they are already in the list or the account
+	Linked   []DuplicateMatch `json:"linked"`   // files whose existing upload was added to the list
+	ResponseDefault
+}
+
+// DuplicateMatch a local file skipped by the duplicate check and the original it matched
+type DuplicateMatch struct {
+	Path         string    `json:"path"`                    // local file which was not uploaded
+	Hash         string    `json:"hash"`                    // hash of the duplicate check of the local file, see utils.HashAlgorithm
+	OriginalPath string    `json:"original_path,omitempty"` // local path of the original upload if known
+	ID           string    `json:"id,omitempty"`            // pixeldrain ID of the original if known
+	Name         string    `json:"name,omitempty"`          // remote file name of the original if known
+	URL          string    `json:"url,omitempty"`
+	UploadedAt   time.Time `json:"uploaded_at,omitempty"`
+}
+
 type FileGetList struct {
 	DetailHref    string    `json:"detail_href"`
 	Description   string    `json:"description"`
@@ -75,6 +288,7 @@ type FileGetList struct {
 	Views         int64     `json:"views"`
 	BandwidthUsed int64     `json:"bandwidth_used"`
 	ThumbnailHref string    `json:"thumbnail_href"`
+	HashSha256    string    `json:"hash_sha256"` // empty if the listing doesn't include it
 }
 
 type ResponseGetList struct {
@@ -85,6 +299,17 @@ type ResponseGetList struct {
 	ResponseDefault
 }
 
+type ResponseUpdateList struct {
+	ID    string     `json:"id"`
+	Title string     `json:"title"`
+	Files []ListFile `json:"files"` // files of the list after the update
+	ResponseDefault
+}
+
+type ResponseDeleteList struct {
+	ResponseDefault
+}
+
 type ResponseGetUser struct {
 	Username            string              `json:"username"`
 	Email               string              `json:"email"`
@@ -137,8 +362,14 @@ type FileGetUser struct {
 	DownloadSpeedLimit  int64     `json:"download_speed_limit"`
 }
 
+// IsAvailable checks if the file can be downloaded, see ResponseFileInfo.IsAvailable
+func (f *FileGetUser) IsAvailable() bool {
+	return f.Availability == "" && f.AbuseType == ""
+}
+
 type ResponseGetUserFiles struct {
-	Files []FileGetUser `json:"files"`
+	Files   []FileGetUser `json:"files"`
+	HasMore bool          `json:"-"` // another page follows, set for requests with a Limit
 	ResponseDefault
 }
 
@@ -155,3 +386,105 @@ type ResponseGetUserLists struct {
 	Lists []ListsGetUser `json:"lists"`
 	ResponseDefault
 }
+
+// UserActivity an event of a file of the account, e.g. "file_deleted"
+type UserActivity struct {
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"`
+	FileID   string    `json:"file_id"`
+	FileName string    `json:"file_name"`
+}
+
+type ResponseGetUserActivity struct {
+	Events []UserActivity `json:"events"`
+	ResponseDefault
+}
+
+// UserTransaction the charges of a billing period, the amounts are in micro euro like BalanceMicroEur
+type UserTransaction struct {
+	Time               time.Time `json:"time"`
+	NewBalance         int64     `json:"new_balance"`
+	DepositAmount      int64     `json:"deposit_amount"`
+	SubscriptionCharge int64     `json:"subscription_charge"`
+	StorageCharge      int64     `json:"storage_charge"`
+	StorageUsed        int64     `json:"storage_used"` // bytes stored in the period
+	BandwidthCharge    int64     `json:"bandwidth_charge"`
+	BandwidthUsed      int64     `json:"bandwidth_used"` // bytes transferred in the period
+	AffiliateAmount    int64     `json:"affiliate_amount"`
+	AffiliateCount     int64     `json:"affiliate_count"`
+}
+
+// Charged the sum of the subscription, storage and bandwidth charges
+func (t UserTransaction) Charged() int64 {
+	return t.SubscriptionCharge + t.StorageCharge + t.BandwidthCharge
+}
+
+type ResponseGetUserTransactions struct {
+	Transactions []UserTransaction `json:"transactions"`
+	ResponseDefault
+}
+
+// Total sums the charges and the usage of all transactions, Time is the time of the newest one
+func (r *ResponseGetUserTransactions) Total() UserTransaction {
+	var total UserTransaction
+	for _, t := range r.Transactions {
+		if t.Time.After(total.Time) {
+			total.Time, total.NewBalance = t.Time, t.NewBalance
+		}
+		total.DepositAmount += t.DepositAmount
+		total.SubscriptionCharge += t.SubscriptionCharge
+		total.StorageCharge += t.StorageCharge
+		total.StorageUsed += t.StorageUsed
+		total.BandwidthCharge += t.BandwidthCharge
+		total.BandwidthUsed += t.BandwidthUsed
+		total.AffiliateAmount += t.AffiliateAmount
+		total.AffiliateCount += t.AffiliateCount
+	}
+
+	return total
+}
+
+type ResponseFSRename struct {
+	Path string `json:"path"` // new path of the renamed or moved node
+	ResponseDefault
+}
+
+// FSNode is a file or directory of the filesystem
+type FSNode struct {
+	Type      string    `json:"type"` // FSNodeDir or FSNodeFile
+	Path      string    `json:"path"`
+	Name      string    `json:"name"`
+	Created   time.Time `json:"created"`
+	Modified  time.Time `json:"modified"`
+	FileSize  int64     `json:"file_size"`
+	FileType  string    `json:"file_type"`
+	SHA256Sum string    `json:"sha256_sum"`
+	ID        string    `json:"id,omitempty"` // the file ID of a file which is shared
+}
+
+// IsDir reports whether the node is a directory
+func (n FSNode) IsDir() bool {
+	return n.Type == FSNodeDir
+}
+
+type ResponseFSNode struct {
+	FSNode
+	ResponseDefault
+}
+
+// ResponseFSList is the stat of a path, Path holds the nodes from the root to the path itself
+type ResponseFSList struct {
+	Path      []FSNode `json:"path"`
+	BaseIndex int      `json:"base_index"` // index of the listed node in Path
+	Children  []FSNode `json:"children"`
+	ResponseDefault
+}
+
+// Node returns the listed node itself, nil if the response has no path
+func (r *ResponseFSList) Node() *FSNode {
+	if r.BaseIndex < 0 || r.BaseIndex >= len(r.Path) {
+		return nil
+	}
+
+	return &r.Path[r.BaseIndex]
+}
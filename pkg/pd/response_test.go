@@ -1,6 +1,9 @@
 package pd_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -160,3 +163,64 @@ func TestPD_ResponseGetUserLists(t *testing.T) {
 	assert.Equal(t, "test", rsp.Value)
 	assert.Equal(t, "test message", rsp.Message)
 }
+
+func TestPD_ResponseRawExtra(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": true, "id": "abc", "Name": "cat.jpg", "delete_after_date": "", "branding": {"theme": "dark"}}`))
+	}))
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "abc", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// known fields are decoded as before, names are matched like encoding/json does
+	assert.Equal(t, "abc", rsp.ID)
+	assert.Equal(t, "cat.jpg", rsp.Name)
+	assert.Equal(t, map[string]json.RawMessage{
+		"delete_after_date": json.RawMessage(`""`),
+		"branding":          json.RawMessage(`{"theme": "dark"}`),
+	}, rsp.RawExtra)
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": true, "id": "abc"}`))
+	})
+	rsp, err = c.GetFileInfo(&pd.RequestFileInfo{ID: "abc", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, rsp.RawExtra)
+}
+
+func TestPD_ResponseFileInfo_IsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/virus":
+			_, _ = w.Write([]byte(`{"id": "virus", "availability": "virus_detected_captcha_required", "availability_message": "This file might contain a virus"}`))
+		case "/blocked":
+			_, _ = w.Write([]byte(`{"id": "blocked", "abuse_type": "copyright", "abuse_reporter_name": "someone"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": "ok", "availability": "", "abuse_type": ""}`))
+		}
+	}))
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "ok", URL: server.URL + "/ok"})
+	assert.NoError(t, err)
+	assert.True(t, rsp.IsAvailable())
+
+	rsp, err = c.GetFileInfo(&pd.RequestFileInfo{ID: "virus", URL: server.URL + "/virus"})
+	assert.NoError(t, err)
+	assert.False(t, rsp.IsAvailable())
+	assert.Equal(t, pd.AvailabilityVirusDetected, rsp.Availability)
+	assert.Equal(t, "This file might contain a virus", rsp.AvailabilityMessage)
+
+	rsp, err = c.GetFileInfo(&pd.RequestFileInfo{ID: "blocked", URL: server.URL + "/blocked"})
+	assert.NoError(t, err)
+	assert.False(t, rsp.IsAvailable())
+	assert.Equal(t, "copyright", rsp.AbuseType)
+	assert.Nil(t, rsp.RawExtra)
+}
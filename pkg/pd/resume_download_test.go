@@ -0,0 +1,63 @@
+package pd_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Download_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	sum := sha256.Sum256(content)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "R", "size": len(content), "hash_sha256": hex.EncodeToString(sum[:])})
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "big.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	target := filepath.Join(t.TempDir(), "big.bin")
+	assert.NoError(t, os.WriteFile(target, content[:4000], 0644))
+
+	rsp, err := c.Download(&pd.RequestDownload{ID: "R", PathToSave: target, Resume: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, rsp.Success)
+	assert.Equal(t, int64(len(content)), rsp.FileSize)
+	assert.Equal(t, []string{"bytes=4000-"}, ranges)
+	data, _ := os.ReadFile(target)
+	assert.Equal(t, content, data)
+
+	// a complete file is only checked
+	rsp, err = c.Download(&pd.RequestDownload{ID: "R", PathToSave: target, Resume: true})
+	assert.NoError(t, err)
+	assert.True(t, rsp.Success)
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rsp.StatusCode)
+
+	// a partial file with other content fails the hash check of the whole file
+	assert.NoError(t, os.WriteFile(target, bytes.Repeat([]byte("x"), 4000), 0644))
+	_, err = c.Download(&pd.RequestDownload{ID: "R", PathToSave: target, Resume: true})
+	var mismatch *pd.ChecksumMismatchError
+	assert.True(t, errors.As(err, &mismatch))
+}
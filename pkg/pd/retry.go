@@ -0,0 +1,189 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// RetryPolicy decides which failed requests are sent again and how long the client pauses in between
+type RetryPolicy struct {
+	MaxAttempts        int           // attempts including the first one, 1 or less sends every request once
+	InitialBackoff     time.Duration // pause before the second attempt, doubled after every attempt
+	MaxBackoff         time.Duration // upper bound of the pause, 0 = no bound
+	RetryServerErrors  bool          // retry on 5xx responses
+	RetryRateLimited   bool          // retry on 429 Too Many Requests, honoring Retry-After
+	RetryNetworkErrors bool          // retry if the connection fails or breaks
+	// RetryNonIdempotent retries uploads and deletions on network errors and 5xx responses as well. A request
+	// which reached the server before the connection broke is then sent twice, e.g. as a duplicate upload.
+	// Requests which provably never reached the server and 429 responses are retried for all methods.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used if ClientOptions.RetryPolicy is nil, it retries uploads and deletions only if they
+// never reached the server
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:        3,
+	InitialBackoff:     500 * time.Millisecond,
+	MaxBackoff:         30 * time.Second,
+	RetryServerErrors:  true,
+	RetryRateLimited:   true,
+	RetryNetworkErrors: true,
+}
+
+// NoRetry sends every request once
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// retryable reports if the result of an attempt is a transient failure the policy retries, req is nil if the
+// request could not be built
+func (p RetryPolicy) retryable(req *http.Request, rsp *httpResponse, err error) bool {
+	if req == nil {
+		return false
+	}
+	repeatable := p.RetryNonIdempotent || idempotent(req)
+
+	if err != nil {
+		return p.RetryNetworkErrors && (repeatable || notSent(err))
+	}
+
+	statusCode := rsp.StatusCode
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		// the server refused the request without handling it
+		return p.RetryRateLimited
+	case statusCode >= 500:
+		return p.RetryServerErrors && repeatable
+	default:
+		return false
+	}
+}
+
+// idempotent reports if sending the request twice has the effect of sending it once, the chunks of a chunked
+// upload write the same bytes at the same offset again
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPut:
+		return req.Header.Get("Content-Range") != ""
+	default:
+		return false
+	}
+}
+
+// notSent reports if the connection of a failed request was never opened, so the server didn't see the request
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// send sends the request built by newReq until the response is no transient failure or the policy gives up.
+// newReq has to build the request anew, including a body which was read by the failed attempt.
+// All requests wait for a running 429 pause.
+func (pd *PixelDrainClient) send(ctx context.Context, policy RetryPolicy, newReq func() (*http.Request, error)) (*httpResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rec := timingsFrom(ctx)
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		if err := pd.rateLimit.wait(ctx); err != nil {
+			return nil, err
+		}
+		rec.add(queueWait, start)
+
+		var httpReq *http.Request
+		rsp, err := pd.do(func() (*http.Request, error) {
+			var err error
+			httpReq, err = newReq()
+			return httpReq, err
+		})
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(httpReq, rsp, err) {
+			return rsp, err
+		}
+
+		if pd.Meter != nil {
+			var attrs []Attribute
+			if rsp != nil {
+				attrs = append(attrs, Attribute{AttrStatusCode, rsp.StatusCode})
+			}
+			pd.Meter.Add(MetricRequestRetries, 1, attrs...)
+		}
+
+		wait := backoff
+		if backoff *= 2; policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+
+		if err != nil {
+			log.Printf("Retrying request after %v: %v", wait, err)
+		} else {
+			// the body of the failed attempt is read, so the connection is reused
+			_, _ = rsp.readBody()
+			statusCode := rsp.StatusCode
+			log.Printf("Retrying request after %v: status %d", wait, statusCode)
+
+			// a 429 pauses all requests of the client, not only this one
+			if statusCode == http.StatusTooManyRequests {
+				if retryAfter := parseRetryAfter(rsp.Header.Get("Retry-After")); retryAfter > wait {
+					wait = retryAfter
+				}
+				pd.rateLimit.pause(wait)
+				continue
+			}
+		}
+
+		start = time.Now()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		rec.add(queueWait, start)
+	}
+}
+
+// do builds the request and sends it with the HTTP client once
+func (pd *PixelDrainClient) do(newReq func() (*http.Request, error)) (*httpResponse, error) {
+	httpReq, err := newReq()
+	if err != nil {
+		return nil, err
+	}
+
+	// the bodies of all requests count against the bandwidth limits of the client
+	if pd.uploadRate != nil && httpReq.Body != nil {
+		httpReq.Body = utils.NewRateLimitedReader(httpReq.Body, pd.uploadRate)
+	}
+
+	rsp, err := pd.roundTripper().RoundTrip(traced(httpReq))
+	if err != nil {
+		return nil, err
+	}
+	if pd.downloadRate != nil {
+		rsp.Body = utils.NewRateLimitedReader(rsp.Body, pd.downloadRate)
+	}
+	rateLimit := parseRateLimit(rsp.Header)
+	pd.rateLimit.observe(rateLimit)
+
+	return &httpResponse{Response: rsp, rateLimit: rateLimit}, nil
+}
+
+// request sends the request with the retry policy of the client, the body is sent anew by every attempt
+func (pd *PixelDrainClient) request(ctx context.Context, method, url string, header http.Header, body []byte) (*httpResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return pd.send(ctx, pd.RetryPolicy, func() (*http.Request, error) {
+		return newRequest(ctx, method, url, header, bytesBody(body))
+	})
+}
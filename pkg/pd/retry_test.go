@@ -0,0 +1,88 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func flakyServer(failures int32) (*httptest.Server, *int32) {
+	var attempts int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "K1dA8U5W", "size": 37621}`))
+	})), &attempts
+}
+
+func TestPD_RetryPolicy(t *testing.T) {
+	server, attempts := flakyServer(2)
+	defer server.Close()
+
+	c := pd.New(&pd.ClientOptions{RetryPolicy: &pd.RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    10 * time.Millisecond,
+		RetryServerErrors: true,
+	}}, nil)
+
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W", URL: server.URL + "/file/K1dA8U5W/info"})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, rsp.StatusCode)
+	assert.Equal(t, "K1dA8U5W", rsp.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(attempts))
+}
+
+func TestPD_RetryPolicy_NoRetry(t *testing.T) {
+	server, attempts := flakyServer(1)
+	defer server.Close()
+
+	c := pd.New(&pd.ClientOptions{RetryPolicy: &pd.NoRetry}, nil)
+
+	rsp, _ := c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W", URL: server.URL + "/file/K1dA8U5W/info"})
+	if rsp != nil {
+		assert.Equal(t, http.StatusServiceUnavailable, rsp.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(attempts))
+}
+
+// TestPD_RetryPolicy_NonIdempotent retries a failed upload only on request or if it never reached the server
+func TestPD_RetryPolicy_NonIdempotent(t *testing.T) {
+	upload := func(policy pd.RetryPolicy, url string) (int32, error) {
+		var attempts int32
+		policy.InitialBackoff = time.Millisecond
+		c := pd.New(&pd.ClientOptions{RetryPolicy: &policy, DisableUploadLog: true, DisableDedup: true, Middleware: []pd.Middleware{
+			pd.BeforeRequest(func(*http.Request) error {
+				atomic.AddInt32(&attempts, 1)
+				return nil
+			}),
+		}}, nil)
+		_, err := c.UploadPOST(&pd.RequestUpload{PathToFile: "testdata/cat.jpg", Anonymous: true, URL: url}, "")
+		return atomic.LoadInt32(&attempts), err
+	}
+
+	server, _ := flakyServer(1)
+	attempts, err := upload(pd.DefaultRetryPolicy, server.URL+"/file")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), attempts, "the upload may have reached the server")
+	server.Close()
+
+	server, _ = flakyServer(1)
+	optIn := pd.DefaultRetryPolicy
+	optIn.RetryNonIdempotent = true
+	attempts, err = upload(optIn, server.URL+"/file")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempts)
+	server.Close()
+
+	// the closed server refuses the connection, the upload never reached it
+	attempts, _ = upload(pd.DefaultRetryPolicy, server.URL+"/file")
+	assert.Equal(t, int32(pd.DefaultRetryPolicy.MaxAttempts), attempts)
+}
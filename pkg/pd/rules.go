@@ -0,0 +1,85 @@
+package pd
+
+import (
+	"path"
+	"path/filepath"
+)
+
+// UploadRule decides how a single file of a directory upload is handled.
+// Every matcher that is set must match, empty matchers match all files.
+type UploadRule struct {
+	Pattern   string // glob for the file name or the path relative to the upload directory, e.g. "*.jpg" or "private/*"
+	MimeType  string // glob for the detected MIME type, e.g. "image/*"
+	MinSize   int64  // minimum file size in bytes, 0 means no lower limit
+	MaxSize   int64  // maximum file size in bytes, 0 means no upper limit
+	Anonymous bool   // upload the file anonymously instead of to the account
+	ListTitle string // add the uploaded file to a list with this title
+	// DeleteAfterUpload removes the local file after a verified successful upload
+	DeleteAfterUpload bool
+	// ArchiveDir moves the local file into this directory after a verified successful upload, e.g. for spool directories
+	ArchiveDir string
+	// EncryptWith encrypts the file before the upload, see RequestUpload.EncryptWith
+	EncryptWith string
+	// Compress compresses the file before the upload, e.g. CompressGzip for logs
+	Compress Compression
+}
+
+// UploadRules are evaluated in order, the first matching rule wins
+type UploadRules []UploadRule
+
+// Matches checks the rule against the relative path, size and MIME type of a file
+func (r *UploadRule) Matches(relPath string, size int64, mimeType string) bool {
+	if r.Pattern != "" {
+		relPath = filepath.ToSlash(relPath)
+		nameOK, _ := path.Match(r.Pattern, path.Base(relPath))
+		pathOK, _ := path.Match(r.Pattern, relPath)
+		if !nameOK && !pathOK {
+			return false
+		}
+	}
+
+	if r.MimeType != "" {
+		if ok, _ := path.Match(r.MimeType, mimeType); !ok {
+			return false
+		}
+	}
+
+	if r.MinSize > 0 && size < r.MinSize {
+		return false
+	}
+
+	if r.MaxSize > 0 && size > r.MaxSize {
+		return false
+	}
+
+	return true
+}
+
+// Match returns the first rule matching the file or nil if no rule matches
+func (rules UploadRules) Match(relPath string, size int64, mimeType string) *UploadRule {
+	for i := range rules {
+		if rules[i].Matches(relPath, size, mimeType) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// apply sets the options of the rule on the upload of a matched file, the encryption and compression of the
+// request are kept if the rule has none of its own. A nil rule changes nothing.
+func (r *UploadRule) apply(reqUpload *RequestUpload) {
+	if r == nil {
+		return
+	}
+
+	reqUpload.Anonymous = r.Anonymous
+	reqUpload.DeleteAfterUpload = r.DeleteAfterUpload
+	reqUpload.ArchiveDir = r.ArchiveDir
+	if r.EncryptWith != "" {
+		reqUpload.EncryptWith = r.EncryptWith
+	}
+	if r.Compress != "" {
+		reqUpload.Compress = r.Compress
+	}
+}
@@ -0,0 +1,40 @@
+package pd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+func TestPD_UploadRule_Matches(t *testing.T) {
+	r := &pd.UploadRule{
+		Pattern:  "*.jpg",
+		MimeType: "image/*",
+		MaxSize:  1024,
+	}
+
+	assert.True(t, r.Matches("sub/cat.jpg", 512, "image/jpeg"))
+	assert.False(t, r.Matches("sub/cat.png", 512, "image/png"))
+	assert.False(t, r.Matches("sub/cat.jpg", 512, "text/plain"))
+	assert.False(t, r.Matches("sub/cat.jpg", 2048, "image/jpeg"))
+
+	r = &pd.UploadRule{Pattern: "private/*"}
+	assert.True(t, r.Matches("private/cat.jpg", 0, ""))
+	assert.False(t, r.Matches("public/cat.jpg", 0, ""))
+
+	r = &pd.UploadRule{}
+	assert.True(t, r.Matches("any/file.bin", 99, "application/octet-stream"))
+}
+
+func TestPD_UploadRules_Match(t *testing.T) {
+	rules := pd.UploadRules{
+		{Pattern: "*.txt", Anonymous: true},
+		{MinSize: 100, ListTitle: "Big Files"},
+	}
+
+	assert.True(t, rules.Match("notes.txt", 500, "text/plain").Anonymous)
+	assert.Equal(t, "Big Files", rules.Match("cat.jpg", 500, "image/jpeg").ListTitle)
+	assert.Nil(t, rules.Match("cat.jpg", 10, "image/jpeg"))
+}
@@ -0,0 +1,160 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// MinSegmentSize is the smallest segment of a segmented download, smaller files use fewer segments
+const MinSegmentSize = 8 << 20
+
+// segmentedDownload fetches the file in parallel ranges into a temporary file next to PathToSave, which is
+// renamed once all segments are complete. A server which ignores the ranges gets a normal download instead.
+func (pd *PixelDrainClient) segmentedDownload(r *RequestDownload) (*ResponseDownload, error) {
+	whole := *r
+	whole.Segments = 0
+
+	info, err := pd.GetFileInfo(&RequestFileInfo{ID: r.ID, Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	if !info.Success {
+		return &ResponseDownload{ResponseDefault: info.ResponseDefault}, nil
+	}
+
+	segments := r.Segments
+	if limit := int(info.Size / MinSegmentSize); segments > limit {
+		segments = limit
+	}
+	if segments < 2 {
+		return pd.Download(&whole)
+	}
+
+	if err := pd.reserveBandwidth(context.Background(), utils.Download, info.Size); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.PathToSave), filepath.Base(r.PathToSave)+".*.part")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = pd.fetchSegments(r, tmp, info.Size, segments)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if errors.Is(err, errRangeIgnored) {
+		log.Printf("Downloading %s as a whole, the server ignored the range", r.ID)
+		return pd.Download(&whole)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pd.recordBandwidth(utils.Download, info.Size)
+
+	if r.Verify {
+		sum, err := pd.calculateFileHash(tmp.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := pd.verifyTransfer(utils.Download, r.ID, r.Auth, r.PathToSave, sum, tmp.Name()); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp.Name(), r.PathToSave); err != nil {
+		return nil, err
+	}
+
+	return &ResponseDownload{
+		FilePath: r.PathToSave,
+		FileName: filepath.Base(r.PathToSave),
+		FileSize: info.Size,
+		ResponseDefault: ResponseDefault{
+			StatusCode: http.StatusOK,
+			Success:    true,
+		},
+	}, nil
+}
+
+// errRangeIgnored is returned by a segment which received the whole file
+var errRangeIgnored = errors.New(ErrRangeNotSupported)
+
+// fetchSegments writes the segments into the file at their offsets, the first failed segment cancels the others
+func (pd *PixelDrainClient) fetchSegments(r *RequestDownload, file *os.File, size int64, segments int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := file.Truncate(size); err != nil {
+		return err
+	}
+
+	// all segments report into one counter
+	progress := utils.NewProgressWriter(io.Discard, size, r.Progress)
+
+	var once sync.Once
+	var firstErr error
+	segmentSize := size / int64(segments)
+	Parallel(segments, segments, func(i int) {
+		start := int64(i) * segmentSize
+		end := start + segmentSize - 1
+		if i == segments-1 {
+			end = size - 1
+		}
+
+		if err := pd.fetchSegment(ctx, r, file, progress, start, end); err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+	})
+	if firstErr != nil {
+		return firstErr
+	}
+	progress.Finish()
+
+	return nil
+}
+
+// fetchSegment downloads the bytes [start, end] of the file and writes them at start
+func (pd *PixelDrainClient) fetchSegment(ctx context.Context, r *RequestDownload, file *os.File, progress io.Writer, start, end int64) error {
+	header := pd.header(r.Auth)
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	rsp, err := pd.request(ctx, http.MethodGet, r.URL, header, nil)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	switch {
+	case rsp.StatusCode == http.StatusOK:
+		return errRangeIgnored
+	case rsp.StatusCode != http.StatusPartialContent:
+		data, _ := rsp.readBody()
+		return newAPIError(rsp.StatusCode, data)
+	case contentRangeStart(rsp.Header.Get("Content-Range")) != start:
+		return fmt.Errorf("segment of %s: unexpected Content-Range %q", r.ID, rsp.Header.Get("Content-Range"))
+	}
+
+	length := end - start + 1
+	n, err := io.Copy(io.MultiWriter(io.NewOffsetWriter(file, start), progress), io.LimitReader(rsp.Body, length))
+	if err != nil {
+		return err
+	}
+	if n != length {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package pd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Download_Segments(t *testing.T) {
+	content := bytes.Repeat([]byte("segmented "), (2*pd.MinSegmentSize+pd.MinSegmentSize/2)/10)
+	var mu sync.Mutex
+	var ranges []string
+	ignoreRanges := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "S", "size": len(content)})
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		ignore := ignoreRanges
+		mu.Unlock()
+		if ignore {
+			_, _ = w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "big.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	target := filepath.Join(t.TempDir(), "big.bin")
+	rsp, err := c.Download(&pd.RequestDownload{ID: "S", PathToSave: target, Segments: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, rsp.Success)
+	data, _ := os.ReadFile(target)
+	assert.True(t, bytes.Equal(content, data))
+	// the segments are limited to MinSegmentSize
+	assert.ElementsMatch(t, []string{
+		"bytes=0-" + strconv.Itoa(len(content)/2-1),
+		"bytes=" + strconv.Itoa(len(content)/2) + "-" + strconv.Itoa(len(content)-1),
+	}, ranges)
+
+	// a server without ranges sends the whole file
+	mu.Lock()
+	ignoreRanges = true
+	mu.Unlock()
+	assert.NoError(t, os.Remove(target))
+	rsp, err = c.Download(&pd.RequestDownload{ID: "S", PathToSave: target, Segments: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, rsp.Success)
+	data, _ = os.ReadFile(target)
+	assert.True(t, bytes.Equal(content, data))
+	matches, _ := filepath.Glob(target + ".*.part")
+	assert.Empty(t, matches)
+}
@@ -0,0 +1,62 @@
+package pd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// state files in ClientOptions.SharedStateDir, shared by all clients and processes using the directory
+const (
+	sharedPauseFile      = "rate_limit_pause" // end of the pause after a 429
+	sharedPacerFile      = "request_pacer"    // next free slot of the requests per minute limit
+	sharedUploadRateFile = "upload_rate.json" // token bucket of the upload bandwidth limit
+
+	sharedUploadBandwidthFile   = "max_upload_rate.json"   // token bucket of MaxUploadBytesPerSec
+	sharedDownloadBandwidthFile = "max_download_rate.json" // token bucket of MaxDownloadBytesPerSec
+)
+
+// sharedPath returns the path of the state file or "" if the client shares no state
+func (pd *PixelDrainClient) sharedPath(name string) string {
+	if pd.sharedStateDir == "" {
+		return ""
+	}
+
+	return filepath.Join(pd.sharedStateDir, name)
+}
+
+// updateSharedTime passes the time stored in the file to update and stores the result, both under the lock of the file.
+// A missing or broken file is read as the zero time.
+func updateSharedTime(path string, update func(time.Time) time.Time) (time.Time, error) {
+	unlock, err := utils.LockFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer unlock()
+
+	var stored time.Time
+	if raw, err := os.ReadFile(path); err == nil {
+		stored, _ = time.Parse(time.RFC3339Nano, strings.TrimSpace(string(raw)))
+	}
+
+	updated := update(stored)
+	if !updated.Equal(stored) {
+		if err := os.WriteFile(path, []byte(updated.Format(time.RFC3339Nano)), 0644); err != nil {
+			return updated, err
+		}
+	}
+
+	return updated, nil
+}
+
+// stateStore returns the StateStore of the client or the files at the keys
+func (pd *PixelDrainClient) stateStore() utils.StateStore {
+	if pd.StateStore == nil {
+		return utils.FileStateStore{}
+	}
+
+	return pd.StateStore
+}
@@ -0,0 +1,186 @@
+package pd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// DefaultTombstoneKey is the StateStore key of the scheduled deletes if ClientOptions.TombstoneKey is empty
+const DefaultTombstoneKey = "pd_tombstones.json"
+
+// Tombstone is a delete which waits for the grace period of the client, it is undone by removing it
+type Tombstone struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	RequestedAt time.Time `json:"requested_at"`
+	DeleteAt    time.Time `json:"delete_at"`
+}
+
+// scheduleDelete records the intent to delete the file, the file is deleted by ExecuteDueDeletes after the grace
+func (pd *PixelDrainClient) scheduleDelete(r *RequestDelete) (*ResponseDelete, error) {
+	now := time.Now()
+	stone := Tombstone{ID: r.ID, URL: r.URL, RequestedAt: now, DeleteAt: now.Add(pd.DeleteGrace)}
+
+	err := pd.updateTombstones(func(stones map[string]Tombstone) {
+		// a repeated delete keeps the first deadline
+		if old, ok := stones[r.ID]; ok {
+			stone = old
+			return
+		}
+		stones[r.ID] = stone
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("File %s will be deleted at %s", r.ID, stone.DeleteAt.Format(time.RFC3339))
+
+	return &ResponseDelete{
+		DeleteAt: stone.DeleteAt,
+		ResponseDefault: ResponseDefault{
+			Success:    true,
+			StatusCode: http.StatusAccepted,
+			Value:      "delete_scheduled",
+			Message:    "The file will be deleted at " + stone.DeleteAt.Format(time.RFC3339) + ".",
+		},
+	}, nil
+}
+
+// UndoDelete removes the tombstone of the file, it returns false if no delete of the file is scheduled
+func (pd *PixelDrainClient) UndoDelete(id string) (bool, error) {
+	if id == "" {
+		return false, errors.New(ErrMissingFileID)
+	}
+
+	found := false
+	err := pd.updateTombstones(func(stones map[string]Tombstone) {
+		_, found = stones[id]
+		delete(stones, id)
+	})
+
+	return found, err
+}
+
+// Tombstones returns the scheduled deletes ordered by their deadline
+func (pd *PixelDrainClient) Tombstones() ([]Tombstone, error) {
+	pd.tombstoneMu.Lock()
+	defer pd.tombstoneMu.Unlock()
+
+	stones, err := pd.loadTombstones()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Tombstone, 0, len(stones))
+	for _, stone := range stones {
+		list = append(list, stone)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].DeleteAt.Before(list[j].DeleteAt)
+	})
+
+	return list, nil
+}
+
+// ExecuteDueDeletes deletes the files whose grace period is over with the credentials of auth.
+// A file which is already gone is dropped, failed deletes stay scheduled and are returned joined as error.
+func (pd *PixelDrainClient) ExecuteDueDeletes(auth Auth) ([]string, error) {
+	stones, err := pd.Tombstones()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	var errs []error
+	now := time.Now()
+	for _, stone := range stones {
+		if stone.DeleteAt.After(now) {
+			break
+		}
+
+		rsp, err := pd.deleteNow(&RequestDelete{ID: stone.ID, Auth: auth, URL: stone.URL})
+		if err == nil && !rsp.Success && rsp.StatusCode != http.StatusNotFound {
+			err = &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", stone.ID, err))
+			continue
+		}
+
+		// an undo during the request is too late, the file is gone
+		if err := pd.updateTombstones(func(stones map[string]Tombstone) { delete(stones, stone.ID) }); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, stone.ID)
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// RunDeleteScheduler executes the due deletes every interval until the context is done
+func (pd *PixelDrainClient) RunDeleteScheduler(ctx context.Context, interval time.Duration, auth Auth) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := pd.ExecuteDueDeletes(auth); err != nil {
+			log.Printf("Error executing scheduled deletes: %v", err)
+		} else if len(deleted) > 0 {
+			log.Printf("Deleted %d scheduled files", len(deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateTombstones loads the tombstones, passes them to update and stores them
+func (pd *PixelDrainClient) updateTombstones(update func(map[string]Tombstone)) error {
+	pd.tombstoneMu.Lock()
+	defer pd.tombstoneMu.Unlock()
+
+	stones, err := pd.loadTombstones()
+	if err != nil {
+		return err
+	}
+
+	update(stones)
+
+	data, err := json.Marshal(stones)
+	if err != nil {
+		return err
+	}
+
+	return pd.stateStore().Put(pd.tombstoneKey(), data)
+}
+
+func (pd *PixelDrainClient) loadTombstones() (map[string]Tombstone, error) {
+	stones := map[string]Tombstone{}
+	data, found, err := pd.stateStore().Get(pd.tombstoneKey())
+	if err != nil || !found {
+		return stones, err
+	}
+
+	if err := json.Unmarshal(data, &stones); err != nil {
+		return nil, errors.New("invalid tombstones " + pd.tombstoneKey() + ": " + err.Error())
+	}
+
+	return stones, nil
+}
+
+func (pd *PixelDrainClient) tombstoneKey() string {
+	if pd.TombstoneKey == "" {
+		return DefaultTombstoneKey
+	}
+
+	return pd.TombstoneKey
+}
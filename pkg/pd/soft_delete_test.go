@@ -0,0 +1,69 @@
+package pd_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Delete_Grace(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	store := utils.NewMemoryStateStore()
+	transport := &countingTransport{}
+	c := pd.New(&pd.ClientOptions{
+		API:         &spec,
+		StateStore:  store,
+		DeleteGrace: time.Hour,
+		HTTPClient:  &http.Client{Transport: transport},
+	}, nil)
+
+	rsp, err := c.Delete(&pd.RequestDelete{ID: "K1dA8U5W"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusAccepted, rsp.StatusCode)
+	assert.Equal(t, "delete_scheduled", rsp.Value)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rsp.DeleteAt, time.Minute)
+
+	// nothing is due within the grace period
+	deleted, err := c.ExecuteDueDeletes(pd.Auth{})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&transport.requests))
+
+	found, err := c.UndoDelete("K1dA8U5W")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	stones, err := c.Tombstones()
+	assert.NoError(t, err)
+	assert.Empty(t, stones)
+
+	// a due tombstone is executed once
+	c.DeleteGrace = time.Nanosecond
+	_, err = c.Delete(&pd.RequestDelete{ID: "K1dA8U5W"})
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	deleted, err = c.ExecuteDueDeletes(pd.Auth{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"K1dA8U5W"}, deleted)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.requests))
+
+	stones, err = c.Tombstones()
+	assert.NoError(t, err)
+	assert.Empty(t, stones)
+
+	// Immediately skips the grace
+	rsp, err = c.Delete(&pd.RequestDelete{ID: "K1dA8U5W", Immediately: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "file_deleted", rsp.Value)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&transport.requests))
+}
@@ -0,0 +1,48 @@
+package pd
+
+import (
+	"io"
+	"sync"
+)
+
+// replayableSource returns the Source of the request or a File which can seek, both can be read
+// again from any offset, so a failed upload is sent anew without buffering the file
+func replayableSource(r *RequestUpload) (io.ReaderAt, int64, bool) {
+	if r.Source != nil {
+		return r.Source, r.SourceSize, true
+	}
+
+	seeker, ok := r.File.(io.ReadSeeker)
+	if !ok {
+		return nil, 0, false
+	}
+
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	return &seekerAt{rs: seeker}, size, true
+}
+
+// seekerAt reads from an io.ReadSeeker at an offset
+type seekerAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+func (s *seekerAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.ReadFull(s.rs, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
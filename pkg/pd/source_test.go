@@ -0,0 +1,80 @@
+package pd_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_UploadPOST_SourceRetry(t *testing.T) {
+	attempts := 0
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// drop the connection in the middle of the upload
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+
+		file, _, err := r.FormFile("file")
+		if err == nil {
+			received, _ = io.ReadAll(file)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "replayed"}`))
+	}))
+	defer server.Close()
+
+	// uploads are retried only on request, a broken upload may have reached the server
+	content := bytes.Repeat([]byte("replayable "), 100)
+	policy := pd.DefaultRetryPolicy
+	policy.RetryNonIdempotent = true
+	c := pd.New(&pd.ClientOptions{RetryPolicy: &policy}, nil)
+	rsp, err := c.UploadPOST(&pd.RequestUpload{
+		Source:     bytes.NewReader(content),
+		SourceSize: int64(len(content)),
+		FileName:   "replay.txt",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+	}, filepath.Join(t.TempDir(), "hashes.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "replayed", rsp.ID)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, content, received)
+	assert.Equal(t, int64(len(content)), rsp.Size)
+}
+
+func TestPD_UploadPUT_SourceChunked(t *testing.T) {
+	var received bytes.Buffer
+	var ranges []string
+	server := chunkServer(&received, &ranges, func(string) bool { return false })
+	defer server.Close()
+
+	content := bytes.Repeat([]byte("x"), 25)
+	c := pd.New(&pd.ClientOptions{ChunkedUploads: true}, nil)
+	rsp, err := c.UploadPUT(&pd.RequestUpload{
+		Source:     bytes.NewReader(content),
+		SourceSize: int64(len(content)),
+		FileName:   "source.bin",
+		URL:        server.URL + "/file/source.bin",
+		ChunkSize:  10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "chunked-id", rsp.ID)
+	assert.Equal(t, []string{"bytes 0-9/25", "bytes 10-19/25", "bytes 20-24/25"}, ranges)
+	assert.Equal(t, content, received.Bytes())
+}
@@ -0,0 +1,94 @@
+package pd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// SpecialFileAction decides what a directory upload does with an empty, special or unreadable file
+type SpecialFileAction string
+
+const (
+	SpecialFileDefault SpecialFileAction = ""       // the default of the kind of file, see RequestUploadDirectory
+	SpecialFileSkip    SpecialFileAction = "skip"   // report the file as skipped and go on
+	SpecialFileUpload  SpecialFileAction = "upload" // upload the file like any other
+	SpecialFileError   SpecialFileAction = "error"  // report the file as failed, the upload stops unless ContinueOnError is set
+)
+
+// errors of the files a directory upload doesn't upload with SpecialFileError
+const (
+	ErrEmptyFile       = "file is empty"
+	ErrSpecialFile     = "file is no regular file"
+	ErrUnsupportedFile = "only named pipes of the special files can be uploaded"
+)
+
+// checkSpecialFile decides how the file of a directory upload is handled. The status and the cause are returned
+// for files which are skipped or failed, an empty status for files which are uploaded. pipe is set for a named
+// pipe which is uploaded, it can be read only once and is sent from memory.
+func checkSpecialFile(r *RequestUploadDirectory, entry utils.DirectoryEntry) (status BatchFileStatus, pipe bool, err error) {
+	// unreadable files can't be uploaded, they fail unless they are skipped
+	if entry.Err != nil {
+		if r.UnreadableFiles == SpecialFileSkip {
+			return BatchSkippedUnreadable, false, entry.Err
+		}
+		return BatchFailed, false, entry.Err
+	}
+
+	if !entry.Mode.IsRegular() {
+		err := fmt.Errorf("%s: %v", ErrSpecialFile, entry.Mode.Type())
+		switch {
+		case r.SpecialFiles == SpecialFileUpload && entry.Mode&os.ModeNamedPipe != 0:
+			return "", true, nil
+		case r.SpecialFiles == SpecialFileUpload:
+			return BatchFailed, false, errors.New(ErrUnsupportedFile)
+		case r.SpecialFiles == SpecialFileError:
+			return BatchFailed, false, err
+		default:
+			return BatchSkippedSpecial, false, err
+		}
+	}
+
+	file, err := os.Open(entry.Path)
+	if err != nil {
+		if r.UnreadableFiles == SpecialFileSkip {
+			return BatchSkippedUnreadable, false, err
+		}
+		return BatchFailed, false, err
+	}
+	_ = file.Close()
+
+	if entry.Size == 0 {
+		switch r.EmptyFiles {
+		case SpecialFileSkip:
+			return BatchSkippedEmpty, false, errors.New(ErrEmptyFile)
+		case SpecialFileError:
+			return BatchFailed, false, errors.New(ErrEmptyFile)
+		}
+	}
+
+	return "", false, nil
+}
+
+// readPipe reads a named pipe until its writer closes it, the content is uploaded from memory
+func readPipe(r *RequestUpload) error {
+	pipe, err := os.Open(r.PathToFile)
+	if err != nil {
+		return err
+	}
+	defer pipe.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, pipe); err != nil {
+		return err
+	}
+	r.File = io.NopCloser(&buf)
+	r.FileName = r.GetFileName()
+	r.PathToFile = ""
+
+	return nil
+}
@@ -0,0 +1,139 @@
+//go:build unix
+
+package pd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_UploadDirectory_SpecialFiles(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "empty.txt"), nil, 0644))
+	assert.NoError(t, syscall.Mkfifo(filepath.Join(dir, "pipe"), 0644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{
+		API:       &spec,
+		HashStore: utils.NewMemoryHashStore(),
+		UploadLog: utils.CSVUploadLog{Path: filepath.Join(t.TempDir(), "uploads.csv")},
+	}, nil)
+
+	statuses := func(rsp *pd.ResponseUploadDirectory) map[string]pd.BatchFileStatus {
+		result := map[string]pd.BatchFileStatus{}
+		for _, file := range rsp.Files {
+			result[filepath.Base(file.Path)] = file.Status
+		}
+		return result
+	}
+
+	// by default empty files are uploaded and the pipe is skipped instead of blocking the run
+	rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]pd.BatchFileStatus{
+		"a.txt":     pd.BatchCompleted,
+		"empty.txt": pd.BatchCompleted,
+		"pipe":      pd.BatchSkippedSpecial,
+	}, statuses(rsp))
+
+	rsp, err = c.UploadDirectory(&pd.RequestUploadDirectory{
+		Directory:       dir,
+		EmptyFiles:      pd.SpecialFileSkip,
+		SpecialFiles:    pd.SpecialFileError,
+		ContinueOnError: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pd.BatchSkippedEmpty, statuses(rsp)["empty.txt"])
+	assert.Equal(t, pd.BatchFailed, statuses(rsp)["pipe"])
+
+	// the content of an uploaded pipe is read once until the writer closes it
+	go func() {
+		pipe, err := os.OpenFile(filepath.Join(dir, "pipe"), os.O_WRONLY, 0)
+		if err == nil {
+			_, _ = pipe.Write([]byte("piped"))
+			_ = pipe.Close()
+		}
+	}()
+	rsp, err = c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir, SpecialFiles: pd.SpecialFileUpload})
+	assert.NoError(t, err)
+	assert.Equal(t, pd.BatchCompleted, statuses(rsp)["pipe"])
+
+	t.Run("unreadable", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can read all files")
+		}
+		assert.NoError(t, os.Remove(filepath.Join(dir, "pipe")))
+		locked := filepath.Join(dir, "locked")
+		assert.NoError(t, os.Mkdir(locked, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(locked, "b.txt"), []byte("b"), 0644))
+		assert.NoError(t, os.Chmod(locked, 0))
+		defer os.Chmod(locked, 0755)
+
+		rsp, err := c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir, UnreadableFiles: pd.SpecialFileSkip})
+		assert.NoError(t, err)
+		assert.Equal(t, pd.BatchSkippedUnreadable, statuses(rsp)["locked"])
+
+		_, err = c.UploadDirectory(&pd.RequestUploadDirectory{Directory: dir})
+		assert.Error(t, err)
+	})
+}
+
+// TestPD_Watch_SpecialFiles skips a named pipe instead of blocking the watch on it
+func TestPD_Watch_SpecialFiles(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	watched := t.TempDir()
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	uploaded := make(chan string, 10)
+	trigger := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, &pd.RequestWatch{
+			Directory: watched,
+			Anonymous: true,
+			Interval:  time.Hour,
+			Debounce:  time.Millisecond,
+			Trigger:   trigger,
+			OnUpload: func(filePath string, rsp *pd.ResponseUpload, err error) {
+				assert.NoError(t, err)
+				uploaded <- filepath.Base(filePath)
+			},
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, syscall.Mkfifo(filepath.Join(watched, "pipe"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "a.txt"), []byte("a"), 0644))
+	trigger <- struct{}{}
+	time.Sleep(10 * time.Millisecond)
+	trigger <- struct{}{}
+
+	select {
+	case name := <-uploaded:
+		assert.Equal(t, "a.txt", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the file was not uploaded")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, uploaded)
+}
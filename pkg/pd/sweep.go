@@ -0,0 +1,124 @@
+package pd
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// DefaultSweepConcurrency the number of parallel file info requests of SweepAccount if nothing else is configured
+const DefaultSweepConcurrency = 4
+
+// SweepAccount compares the files of the account with the upload log. The info of every remote file is
+// fetched in parallel, a file is unavailable if its info can't be fetched, altered if the size or SHA-256
+// differ from the log, untracked if the log has no upload of it and missing if only the log knows it.
+func (pd *PixelDrainClient) SweepAccount(r *RequestSweepAccount) (*ResponseSweepAccount, error) {
+	if r.LogPath == "" {
+		r.LogPath = CSVFilePath
+	}
+
+	workers := r.Concurrency
+	if workers <= 0 {
+		workers = DefaultSweepConcurrency
+	}
+
+	infos, err := utils.LoadUploadInfos(r.LogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last successful upload of an ID wins
+	catalog := map[string]utils.UploadInfo{}
+	for _, info := range infos {
+		if !strings.HasPrefix(info.UploadStatus, "2") {
+			continue
+		}
+		id := info.ID
+		if id == "" && info.URL != "" {
+			id = path.Base(info.URL)
+		}
+		if id == "" || id == "u" {
+			continue
+		}
+		catalog[id] = info
+	}
+
+	files, err := pd.GetUserFiles(&RequestGetUserFiles{Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	if !files.Success {
+		return nil, &APIError{StatusCode: files.StatusCode, Value: files.Value, Message: files.Message}
+	}
+
+	entries := make([]DriftEntry, len(files.Files))
+	Parallel(len(files.Files), workers, func(i int) {
+		file := files.Files[i]
+		entry := DriftEntry{ID: file.ID, Name: file.Name, RemoteSize: file.Size, RemoteHash: file.HashSha256}
+
+		info, err := pd.GetFileInfo(&RequestFileInfo{ID: file.ID, Auth: r.Auth})
+		switch {
+		case err != nil:
+			entry.Reason = err.Error()
+		case !info.Success:
+			entry.Reason = fmt.Sprintf("status %d %s", info.StatusCode, info.Value)
+		default:
+			entry.RemoteSize, entry.RemoteHash = info.Size, info.HashSha256
+		}
+		entries[i] = entry
+	})
+
+	report := &ResponseSweepAccount{Checked: len(entries)}
+	for _, entry := range entries {
+		local, tracked := catalog[entry.ID]
+		delete(catalog, entry.ID)
+		if tracked {
+			entry.Path, entry.Size, entry.Hash = local.DirectoryPath, local.FileSize, local.Hash
+		}
+
+		switch {
+		case entry.Reason != "":
+			report.Unavailable = append(report.Unavailable, entry)
+		case !tracked:
+			report.Untracked = append(report.Untracked, entry)
+			continue
+		case entry.Size > 0 && entry.RemoteSize != entry.Size:
+			entry.Reason = fmt.Sprintf("size %d, recorded %d", entry.RemoteSize, entry.Size)
+			report.Altered = append(report.Altered, entry)
+		case entry.Hash != "" && entry.RemoteHash != "" && entry.RemoteHash != entry.Hash:
+			entry.Reason = fmt.Sprintf("hash %s, recorded %s", entry.RemoteHash, entry.Hash)
+			report.Altered = append(report.Altered, entry)
+		default:
+			report.OK = append(report.OK, entry)
+			continue
+		}
+
+		log.Printf("File %s (%s): %s", entry.ID, entry.Name, entry.Reason)
+	}
+
+	// uploads of the log which are not in the account anymore, in the order of the log
+	for _, info := range infos {
+		id := info.ID
+		if id == "" && info.URL != "" {
+			id = path.Base(info.URL)
+		}
+		local, ok := catalog[id]
+		if !ok {
+			continue
+		}
+		delete(catalog, id)
+		report.Missing = append(report.Missing, DriftEntry{
+			ID:     id,
+			Name:   local.FileName,
+			Path:   local.DirectoryPath,
+			Size:   local.FileSize,
+			Hash:   local.Hash,
+			Reason: "not in the account",
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,83 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_SweepAccount(t *testing.T) {
+	remote := map[string]pd.FileGetUser{
+		"ok":        {ID: "ok", Name: "ok.txt", Size: 10, HashSha256: "aaa"},
+		"altered":   {ID: "altered", Name: "altered.txt", Size: 20, HashSha256: "bbb"},
+		"untracked": {ID: "untracked", Name: "untracked.txt", Size: 30},
+		"broken":    {ID: "broken", Name: "broken.txt", Size: 40},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user/files" {
+			files := []pd.FileGetUser{}
+			for _, id := range []string{"ok", "altered", "untracked", "broken"} {
+				files = append(files, remote[id])
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+			return
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/file/"), "/info")
+		if id == "broken" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success": false, "value": "not_found"}`))
+			return
+		}
+		file := remote[id]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true, "id": file.ID, "size": file.Size, "hash_sha256": file.HashSha256,
+		})
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "uploads.csv")
+	for _, info := range []utils.UploadInfo{
+		{FileName: "ok.txt", DirectoryPath: "/data/ok.txt", ID: "ok", FileSize: 10, Hash: "aaa", UploadStatus: "201"},
+		{FileName: "altered.txt", DirectoryPath: "/data/altered.txt", ID: "altered", FileSize: 20, Hash: "ccc", UploadStatus: "201"},
+		{FileName: "gone.txt", DirectoryPath: "/data/gone.txt", URL: "https://pixeldrain.com/u/gone", FileSize: 50, UploadStatus: "201"},
+		{FileName: "rejected.txt", UploadStatus: "413"},
+	} {
+		if err := utils.SaveUploadInfoToCSV(info, logPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	report, err := c.SweepAccount(&pd.RequestSweepAccount{LogPath: logPath, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driftIDs := func(entries []pd.DriftEntry) []string {
+		var result []string
+		for _, entry := range entries {
+			result = append(result, entry.ID)
+		}
+		return result
+	}
+
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, []string{"ok"}, driftIDs(report.OK))
+	assert.Equal(t, []string{"altered"}, driftIDs(report.Altered))
+	assert.Equal(t, "hash bbb, recorded ccc", report.Altered[0].Reason)
+	assert.Equal(t, []string{"broken"}, driftIDs(report.Unavailable))
+	assert.Equal(t, []string{"untracked"}, driftIDs(report.Untracked))
+	assert.Equal(t, []string{"gone"}, driftIDs(report.Missing))
+	assert.Equal(t, "/data/gone.txt", report.Missing[0].Path)
+}
@@ -0,0 +1,337 @@
+package pd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// SyncManifestName is the manifest of Sync inside of the synced directory if no ManifestPath is given
+const SyncManifestName = ".go-pd-sync.csv"
+
+// SyncManifestBatch and SyncManifestInterval bound how often Sync saves its manifest, after this many uploaded or
+// downloaded files or once this much time passed since the last save, and at the end of the run. An interrupted run
+// uploads at most these files again.
+const (
+	SyncManifestBatch    = 50
+	SyncManifestInterval = 30 * time.Second
+)
+
+// ErrMissingListIDOrDirectory is returned by Sync without a list or a directory
+const ErrMissingListIDOrDirectory = "list id and directory are required"
+
+// Sync keeps a local directory and a list in step. New and changed local files are uploaded and added to the
+// list, the old version of a changed file is removed from the list. The manifest keeps the state of the last run
+// by path relative to the directory, so only files which changed since then are hashed and uploaded.
+//
+// Files removed locally are deleted on pixeldrain with DeleteRemote, files removed from the list are deleted
+// locally with DeleteLocal and files added to the list by others are downloaded with DownloadNew. Without these
+// options the other side is left as it is. Deletions are saved in the manifest right away, uploads and downloads in
+// batches of SyncManifestBatch files.
+//
+// A DryRun hashes the changed files and reports what would be uploaded and deleted on pixeldrain without sending
+// anything, the manifest and the local files are left as they are. The list is not read either, so files removed
+// from it or added to it by others are not reported.
+func (pd *PixelDrainClient) Sync(r *RequestSync) (_ *ResponseSync, err error) {
+	if r.ListID == "" || r.Directory == "" {
+		return nil, errors.New(ErrMissingListIDOrDirectory)
+	}
+	if r.ManifestPath == "" {
+		r.ManifestPath = filepath.Join(r.Directory, SyncManifestName)
+	}
+
+	manifest, err := utils.LoadFileIndexFrom(pd.StateStore, r.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	// the files synced before a failure are kept in the manifest as well
+	batch := &manifestBatch{manifest: manifest, saved: time.Now()}
+	defer func() {
+		if ferr := batch.flush(); err == nil {
+			err = ferr
+		}
+	}()
+
+	// the list keeps its order, removed files are dropped and new files appended
+	list := &ResponseGetList{}
+	var listFiles []ListFile
+	inList := map[string]bool{}
+	if r.DryRun {
+		// the list is not read, it is assumed to hold the files of the manifest
+		for _, rel := range manifest.Paths() {
+			entry, _ := manifest.Get(rel)
+			inList[entry.ID] = true
+		}
+	} else {
+		list, err = pd.GetList(&RequestGetList{ID: r.ListID, Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		if list.StatusCode != http.StatusOK {
+			return nil, &APIError{StatusCode: list.StatusCode, Value: list.Value, Message: list.Message}
+		}
+	}
+	for _, file := range list.Files {
+		listFiles = append(listFiles, ListFile{ID: file.ID, Description: file.Description})
+		inList[file.ID] = true
+	}
+	removed := map[string]bool{}
+	listChanged := false
+
+	files, err := utils.GetFilesInDirectory(r.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestPath, _ := filepath.Abs(r.ManifestPath)
+	result := &ResponseSync{IDs: map[string]string{}}
+	seen := map[string]bool{}
+	// the files left to compare are the queue of the sync
+	defer r.Metrics.setQueueDepth(MetricsSourceSync, 0)
+	for i, filePath := range files {
+		r.Metrics.setQueueDepth(MetricsSourceSync, len(files)-i)
+		if absPath, _ := filepath.Abs(filePath); absPath == manifestPath || pd.isSidecar(filePath) {
+			continue
+		}
+
+		rel, err := filepath.Rel(r.Directory, filePath)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry, known := manifest.Get(rel); known && !inList[entry.ID] && entry.Unchanged(info) {
+			// removed from the list since the last run
+			if r.DeleteLocal {
+				log.Printf("Deleting %s, it was removed from the list", filePath)
+				if err := os.Remove(filePath); err != nil {
+					return nil, err
+				}
+				manifest.Delete(rel)
+				if err := batch.deleted(); err != nil {
+					return nil, err
+				}
+				result.DeletedLocal = append(result.DeletedLocal, filePath)
+			}
+			continue
+		}
+
+		change, err := pd.compareIndexed(manifest, rel, filePath, info, r.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if change.unchanged {
+			result.Unchanged = append(result.Unchanged, filePath)
+			continue
+		}
+		entry, known := change.entry, change.known
+
+		if r.DryRun {
+			if known && entry.ID != "" {
+				result.Replaced = append(result.Replaced, filePath)
+			} else {
+				result.Uploaded = append(result.Uploaded, filePath)
+			}
+			continue
+		}
+
+		log.Printf("Syncing file: %s", filePath)
+		rsp, err := pd.uploadFile(&RequestUpload{
+			PathToFile: filePath,
+			Anonymous:  r.Anonymous,
+			Auth:       r.Auth,
+		}, utils.GetHashFilePath())
+		if err == nil && rsp.ID == "" {
+			err = &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+		}
+		r.Metrics.observeUpload(MetricsSourceSync, rsp, err)
+		if err != nil {
+			return nil, err
+		}
+
+		if known && entry.ID != "" && entry.ID != rsp.ID {
+			removed[entry.ID] = true
+			if r.DeleteRemote {
+				if err := pd.syncDelete(entry.ID, r.Auth); err != nil {
+					return nil, err
+				}
+			}
+			result.Replaced = append(result.Replaced, filePath)
+		} else {
+			result.Uploaded = append(result.Uploaded, filePath)
+		}
+		result.IDs[filePath] = rsp.ID
+
+		if !inList[rsp.ID] {
+			listFiles = append(listFiles, ListFile{ID: rsp.ID, Description: pd.describe(filePath)})
+			inList[rsp.ID] = true
+		}
+		listChanged = true
+
+		manifest.Set(utils.FileIndexEntry{
+			Path:    rel,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Hash:    change.hash,
+			ID:      rsp.ID,
+		})
+		if err := batch.changed(); err != nil {
+			return nil, err
+		}
+	}
+
+	// files of the manifest which were removed locally
+	known := map[string]bool{}
+	for _, rel := range manifest.Paths() {
+		entry, _ := manifest.Get(rel)
+		known[entry.ID] = true
+		if seen[rel] || !r.DeleteRemote {
+			continue
+		}
+		if r.DryRun {
+			result.DeletedRemote = append(result.DeletedRemote, filepath.Join(r.Directory, filepath.FromSlash(rel)))
+			continue
+		}
+
+		if inList[entry.ID] {
+			removed[entry.ID] = true
+			listChanged = true
+		}
+		if err := pd.syncDelete(entry.ID, r.Auth); err != nil {
+			return nil, err
+		}
+		manifest.Delete(rel)
+		if err := batch.deleted(); err != nil {
+			return nil, err
+		}
+		result.DeletedRemote = append(result.DeletedRemote, filepath.Join(r.Directory, filepath.FromSlash(rel)))
+	}
+
+	// files added to the list by others
+	if r.DownloadNew && !r.DryRun {
+		used := map[string]string{}
+		for _, file := range list.Files {
+			if known[file.ID] {
+				continue
+			}
+
+			name := localName(remoteEntry{ID: file.ID, Name: file.Name}, nil, used)
+			target := filepath.Join(r.Directory, name)
+			if _, err := os.Stat(target); err == nil {
+				log.Printf("Not downloading %s, %s already exists", file.ID, target)
+				continue
+			}
+
+			log.Printf("Downloading new file %s to %s", file.ID, target)
+			rsp, err := pd.Download(&RequestDownload{ID: file.ID, PathToSave: target, Auth: r.Auth, Raw: true})
+			if err != nil {
+				return nil, err
+			}
+			if !rsp.Success {
+				return nil, &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+			}
+
+			info, err := os.Stat(target)
+			if err != nil {
+				return nil, err
+			}
+			hash, err := pd.calculateFileHash(target)
+			if err != nil {
+				return nil, err
+			}
+			manifest.Set(utils.FileIndexEntry{
+				Path:    filepath.ToSlash(name),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Hash:    hash,
+				ID:      file.ID,
+			})
+			if err := batch.changed(); err != nil {
+				return nil, err
+			}
+			result.Downloaded = append(result.Downloaded, target)
+			result.IDs[target] = file.ID
+		}
+	}
+
+	if !listChanged {
+		return result, nil
+	}
+
+	kept := listFiles[:0:0]
+	for _, file := range listFiles {
+		if !removed[file.ID] {
+			kept = append(kept, file)
+		}
+	}
+
+	// the title is sent as well, pixeldrain replaces the whole list
+	rspUpdate, err := pd.UpdateList(&RequestUpdateList{ID: r.ListID, Title: list.Title, Files: kept, Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	if !rspUpdate.Success {
+		return result, &APIError{StatusCode: rspUpdate.StatusCode, Value: rspUpdate.Value, Message: rspUpdate.Message}
+	}
+
+	return result, nil
+}
+
+// manifestBatch saves the manifest of Sync every SyncManifestBatch changes or SyncManifestInterval instead of after
+// every file
+type manifestBatch struct {
+	manifest *utils.FileIndex
+	pending  int       // changes since the last save
+	saved    time.Time // time of the last save
+}
+
+// changed counts a change and saves the manifest once the batch is full or the interval passed
+func (b *manifestBatch) changed() error {
+	b.pending++
+	if b.pending < SyncManifestBatch && time.Since(b.saved) < SyncManifestInterval {
+		return nil
+	}
+
+	return b.flush()
+}
+
+// deleted saves a deletion right away together with the pending changes, a deleted file is not synced again
+func (b *manifestBatch) deleted() error {
+	b.pending++
+	return b.flush()
+}
+
+// flush saves the manifest if it has changes
+func (b *manifestBatch) flush() error {
+	if b.pending == 0 {
+		return nil
+	}
+
+	b.pending = 0
+	b.saved = time.Now()
+	return b.manifest.Save()
+}
+
+// syncDelete deletes the remote file, a file which is already gone is no error
+func (pd *PixelDrainClient) syncDelete(id string, auth Auth) error {
+	rsp, err := pd.Delete(&RequestDelete{ID: id, Auth: auth})
+	if err != nil {
+		return err
+	}
+	if !rsp.Success && rsp.StatusCode != http.StatusNotFound {
+		return &APIError{StatusCode: rsp.StatusCode, Value: rsp.Value, Message: rsp.Message}
+	}
+
+	return nil
+}
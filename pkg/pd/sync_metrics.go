@@ -0,0 +1,116 @@
+package pd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultMetricsNamespace prefixes the names of the SyncMetrics, e.g. go_pd_uploads_total
+const DefaultMetricsNamespace = "go_pd"
+
+// sources of the SyncMetrics, the value of their "source" label
+const (
+	MetricsSourceWatch = "watch"
+	MetricsSourceSync  = "sync"
+)
+
+// SyncMetrics counts the uploads of Watch and Sync in the Prometheus text format, so a daemon running them can be
+// monitored. Pass it in RequestWatch.Metrics or RequestSync.Metrics and serve it, e.g. http.Handle("/metrics", m).
+// It is safe for concurrent use, one SyncMetrics can count several watches and syncs.
+type SyncMetrics struct {
+	namespace string
+
+	mu         sync.Mutex
+	uploads    map[string]int64
+	bytes      map[string]int64
+	failures   map[string]int64
+	dedupHits  map[string]int64
+	queueDepth map[string]int64
+}
+
+// NewSyncMetrics returns metrics with the names prefixed by the namespace, DefaultMetricsNamespace if empty
+func NewSyncMetrics(namespace string) *SyncMetrics {
+	if namespace == "" {
+		namespace = DefaultMetricsNamespace
+	}
+
+	return &SyncMetrics{
+		namespace:  namespace,
+		uploads:    map[string]int64{},
+		bytes:      map[string]int64{},
+		failures:   map[string]int64{},
+		dedupHits:  map[string]int64{},
+		queueDepth: map[string]int64{},
+	}
+}
+
+// observeUpload counts an upload, a failed one or one skipped by the duplicate check. Nil metrics count nothing.
+func (m *SyncMetrics) observeUpload(source string, rsp *ResponseUpload, err error) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err != nil || rsp == nil:
+		m.failures[source]++
+	case rsp.Duplicate != nil:
+		m.dedupHits[source]++
+	default:
+		m.uploads[source]++
+		m.bytes[source] += rsp.Size
+	}
+}
+
+// setQueueDepth sets the number of files waiting for their upload
+func (m *SyncMetrics) setQueueDepth(source string, depth int) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueDepth[source] = int64(depth)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *SyncMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	m.write(&b, "uploads_total", "counter", "Files uploaded.", m.uploads)
+	m.write(&b, "upload_bytes_total", "counter", "Bytes of the uploaded files.", m.bytes)
+	m.write(&b, "failures_total", "counter", "Uploads which failed.", m.failures)
+	m.write(&b, "dedupe_hits_total", "counter", "Uploads skipped by the duplicate check.", m.dedupHits)
+	m.write(&b, "queue_depth", "gauge", "Files waiting for their upload.", m.queueDepth)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics to the Prometheus scraper
+func (m *SyncMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// write writes a metric with a sample per source, sorted so the output is stable
+func (m *SyncMetrics) write(b *strings.Builder, name, kind, help string, values map[string]int64) {
+	name = m.namespace + "_" + name
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+
+	sources := make([]string, 0, len(values))
+	for source := range values {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		fmt.Fprintf(b, "%s{source=%q} %d\n", name, source, values[source])
+	}
+}
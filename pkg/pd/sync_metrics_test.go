@@ -0,0 +1,106 @@
+package pd_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// scrape returns the metrics as the Prometheus scraper gets them
+func scrape(t *testing.T, metrics *pd.SyncMetrics) string {
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+
+	return rec.Body.String()
+}
+
+// TestPD_SyncMetrics counts the uploads of a sync in the Prometheus format
+func TestPD_SyncMetrics(t *testing.T) {
+	fake := &syncServer{content: map[string]string{}, names: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	local := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "a.txt"), []byte("aaa"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "b.txt"), []byte("bb"), 0644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, HashStore: utils.NewMemoryHashStore(), DisableUploadLog: true}, nil)
+
+	metrics := pd.NewSyncMetrics("backup")
+	_, err := c.Sync(&pd.RequestSync{Directory: local, ListID: "L", Metrics: metrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := scrape(t, metrics)
+	assert.Contains(t, body, "# TYPE backup_uploads_total counter\n")
+	assert.Contains(t, body, `backup_uploads_total{source="sync"} 2`+"\n")
+	assert.Contains(t, body, `backup_upload_bytes_total{source="sync"} 5`+"\n")
+	assert.Contains(t, body, "# TYPE backup_queue_depth gauge\n")
+	assert.Contains(t, body, `backup_queue_depth{source="sync"} 0`+"\n")
+	assert.NotContains(t, body, `backup_failures_total{`)
+}
+
+// TestPD_SyncMetrics_Watch counts the files a watch skipped as duplicates
+func TestPD_SyncMetrics_Watch(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+
+	watched := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "a.txt"), []byte("aaa"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "copy-of-a.txt"), []byte("aaa"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "b.txt"), []byte("bb"), 0644))
+
+	c := server.Client(&pd.ClientOptions{HashStore: utils.NewMemoryHashStore(), DisableUploadLog: true})
+	metrics := pd.NewSyncMetrics("")
+	uploaded := make(chan string, 10)
+	trigger := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, &pd.RequestWatch{
+			Directory:      watched,
+			Anonymous:      true,
+			Interval:       time.Hour,
+			Debounce:       time.Millisecond,
+			UploadExisting: true,
+			Trigger:        trigger,
+			Metrics:        metrics,
+			OnUpload: func(filePath string, rsp *pd.ResponseUpload, err error) {
+				uploaded <- filepath.Base(filePath)
+			},
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	trigger <- struct{}{}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-uploaded:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the files were not uploaded")
+		}
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	body := scrape(t, metrics)
+	assert.Contains(t, body, `go_pd_uploads_total{source="watch"} 2`+"\n")
+	assert.Contains(t, body, `go_pd_upload_bytes_total{source="watch"} 5`+"\n")
+	assert.Contains(t, body, `go_pd_dedupe_hits_total{source="watch"} 1`+"\n")
+	assert.Contains(t, body, `go_pd_queue_depth{source="watch"} 0`+"\n")
+	assert.Len(t, server.Files(), 2)
+}
@@ -0,0 +1,193 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncServer keeps a list and the uploaded files in memory
+type syncServer struct {
+	mu      sync.Mutex
+	title   string
+	list    []pd.ListFile
+	content map[string]string
+	names   map[string]string
+	deleted []string
+	puts    int
+}
+
+func (s *syncServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/list/L":
+		var files []map[string]string
+		for _, file := range s.list {
+			files = append(files, map[string]string{"id": file.ID, "name": s.names[file.ID], "description": file.Description})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "id": "L", "title": s.title, "files": files})
+	case r.Method == http.MethodPut && r.URL.Path == "/list/L":
+		var body struct {
+			Title string        `json:"title"`
+			Files []pd.ListFile `json:"files"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.title, s.list = body.Title, body.Files
+		s.puts++
+		_, _ = w.Write([]byte(`{"success": true}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/file":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		id := fmt.Sprintf("f%d", len(s.content))
+		s.content[id], s.names[id] = string(data), header.Filename
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "` + id + `"}`))
+	case r.Method == http.MethodDelete:
+		s.deleted = append(s.deleted, strings.TrimPrefix(r.URL.Path, "/file/"))
+		_, _ = w.Write([]byte(`{"success": true}`))
+	case r.Method == http.MethodGet:
+		_, _ = w.Write([]byte(s.content[strings.TrimPrefix(r.URL.Path, "/file/")]))
+	}
+}
+
+func (s *syncServer) listIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []string
+	for _, file := range s.list {
+		result = append(result, file.ID)
+	}
+	return result
+}
+
+func TestPD_Sync(t *testing.T) {
+	fake := &syncServer{
+		title:   "Shared",
+		list:    []pd.ListFile{{ID: "x"}},
+		content: map[string]string{"x": "added remotely"},
+		names:   map[string]string{"x": "x.txt"},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local")
+	assert.NoError(t, os.MkdirAll(filepath.Join(local, "sub"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "a.txt"), []byte("a"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "sub", "b.txt"), []byte("b"), 0644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{
+		API:       &spec,
+		HashStore: utils.NewMemoryHashStore(),
+		UploadLog: utils.CSVUploadLog{Path: filepath.Join(dir, "uploads.csv")},
+	}, nil)
+	r := &pd.RequestSync{Directory: local, ListID: "L", DeleteRemote: true, DeleteLocal: true, DownloadNew: true}
+
+	rsp, err := c.Sync(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, rsp.Uploaded, 2)
+	assert.Equal(t, []string{filepath.Join(local, "x.txt")}, rsp.Downloaded)
+	assert.Equal(t, []string{"x", "f1", "f2"}, fake.listIDs())
+	assert.Equal(t, "Shared", fake.title, "the title of the list was dropped")
+
+	// nothing changed, the list is not written again
+	rsp, err = c.Sync(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, rsp.Unchanged, 3)
+	assert.Equal(t, 1, fake.puts)
+
+	// a changed file replaces its old version, a removed file is deleted remotely
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.WriteFile(filepath.Join(local, "a.txt"), []byte("a2"), 0644))
+	assert.NoError(t, os.Chtimes(filepath.Join(local, "a.txt"), later, later))
+	assert.NoError(t, os.Remove(filepath.Join(local, "sub", "b.txt")))
+	rsp, err = c.Sync(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{filepath.Join(local, "a.txt")}, rsp.Replaced)
+	assert.Equal(t, []string{filepath.Join(local, "sub", "b.txt")}, rsp.DeletedRemote)
+	assert.ElementsMatch(t, []string{"f1", "f2"}, fake.deleted)
+	assert.Equal(t, []string{"x", "f3"}, fake.listIDs())
+
+	// a file removed from the list is deleted locally
+	fake.mu.Lock()
+	fake.list = fake.list[1:]
+	fake.mu.Unlock()
+	rsp, err = c.Sync(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{filepath.Join(local, "x.txt")}, rsp.DeletedLocal)
+	assert.NoFileExists(t, filepath.Join(local, "x.txt"))
+
+	_, err = c.Sync(&pd.RequestSync{})
+	assert.EqualError(t, err, pd.ErrMissingListIDOrDirectory)
+}
+
+// countingStateStore counts the writes of a key
+type countingStateStore struct {
+	*utils.MemoryStateStore
+	mu   sync.Mutex
+	puts map[string]int
+}
+
+func (s *countingStateStore) Put(key string, data []byte) error {
+	s.mu.Lock()
+	s.puts[key]++
+	s.mu.Unlock()
+	return s.MemoryStateStore.Put(key, data)
+}
+
+// TestPD_Sync_ManifestBatch the manifest is saved in batches and at the end, not after every file
+func TestPD_Sync_ManifestBatch(t *testing.T) {
+	fake := &syncServer{content: map[string]string{}, names: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	local := t.TempDir()
+	files := pd.SyncManifestBatch + 5
+	for i := 0; i < files; i++ {
+		assert.NoError(t, os.WriteFile(filepath.Join(local, fmt.Sprintf("%03d.txt", i)), []byte(fmt.Sprint(i)), 0644))
+	}
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	store := &countingStateStore{MemoryStateStore: utils.NewMemoryStateStore(), puts: map[string]int{}}
+	c := pd.New(&pd.ClientOptions{API: &spec, StateStore: store, DisableUploadLog: true, DisableDedup: true}, nil)
+	rsp, err := c.Sync(&pd.RequestSync{Directory: local, ListID: "L", ManifestPath: "manifest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, rsp.Uploaded, files)
+	assert.Equal(t, 2, store.puts["manifest"])
+
+	manifest, err := utils.LoadFileIndexFrom(store, "manifest")
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Paths(), files)
+}
@@ -0,0 +1,115 @@
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// names of the metrics of the client
+const (
+	MetricUploadBytes     = "pd.upload.bytes"     // counter of the bytes uploaded
+	MetricDownloadBytes   = "pd.download.bytes"   // counter of the bytes downloaded
+	MetricRequestDuration = "pd.request.duration" // histogram of the duration of every attempt in seconds
+	MetricRequestRetries  = "pd.request.retries"  // counter of the retried attempts
+)
+
+// keys of the attributes of the spans and metrics, the HTTP ones follow the OpenTelemetry semantic conventions
+const (
+	AttrOperation  = "pd.operation" // method and route of the API call, e.g. "GET /file/{id}/info"
+	AttrMethod     = "http.request.method"
+	AttrBodySize   = "http.request.body.size"
+	AttrStatusCode = "http.response.status_code"
+)
+
+// Attribute a key value pair of a span or metric
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts the spans of the API calls, e.g. backed by an OpenTelemetry trace.Tracer
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span of an API call, e.g. backed by an OpenTelemetry trace.Span
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Meter receives the counters and histograms of the client, e.g. backed by OpenTelemetry instruments by name
+type Meter interface {
+	Add(name string, value int64, attrs ...Attribute)
+	Record(name string, value float64, attrs ...Attribute)
+}
+
+// instrument is the outermost Middleware if the client has a Tracer or Meter. Every attempt of a request gets a
+// span, the context of the request carries it for the middleware of the client, e.g. to propagate the trace.
+func (pd *PixelDrainClient) instrument(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		operation := pd.operation(req)
+		attrs := []Attribute{{AttrOperation, operation}, {AttrMethod, req.Method}}
+		if req.ContentLength > 0 {
+			attrs = append(attrs, Attribute{AttrBodySize, req.ContentLength})
+		}
+
+		var span Span
+		if pd.Tracer != nil {
+			var ctx context.Context
+			ctx, span = pd.Tracer.Start(req.Context(), operation, attrs...)
+			req = req.WithContext(ctx)
+		}
+
+		start := time.Now()
+		rsp, err := next.RoundTrip(req)
+		if rsp != nil {
+			attrs = append(attrs, Attribute{AttrStatusCode, rsp.StatusCode})
+		}
+		if pd.Meter != nil {
+			pd.Meter.Record(MetricRequestDuration, time.Since(start).Seconds(), attrs...)
+		}
+		if span != nil {
+			if rsp != nil {
+				span.SetAttributes(Attribute{AttrStatusCode, rsp.StatusCode})
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		}
+
+		return rsp, err
+	})
+}
+
+// operation the method and the route of the request with the IDs, names and paths as placeholders,
+// so the operations of all files are aggregated, e.g. "GET /file/{id}/info"
+func (pd *PixelDrainClient) operation(req *http.Request) string {
+	route := req.URL.Path
+	if base, err := url.Parse(pd.API.URL); err == nil && base.Path != "" {
+		route = strings.TrimPrefix(route, strings.TrimSuffix(base.Path, "/"))
+	}
+
+	// the segment after the endpoint of the API spec is the ID, name or path
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if len(segments) >= 2 {
+		switch "/" + segments[0] {
+		case pd.API.Filesystem:
+			segments = []string{segments[0], "{path}"}
+		case pd.API.File:
+			segments[1] = "{id}"
+			if req.Method == http.MethodPut {
+				segments[1] = "{name}"
+			}
+		case pd.API.List:
+			segments[1] = "{id}"
+		}
+	}
+
+	return req.Method + " /" + strings.Join(segments, "/")
+}
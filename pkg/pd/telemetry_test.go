@@ -0,0 +1,126 @@
+package pd_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...pd.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type spanKey struct{}
+
+// recorder is a Tracer and Meter which keeps everything in memory
+type recorder struct {
+	mu       sync.Mutex
+	spans    []*recordedSpan
+	counters map[string]int64
+	records  map[string]int
+}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs ...pd.Attribute) (context.Context, pd.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	span := &recordedSpan{name: name, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (r *recorder) Add(name string, value int64, attrs ...pd.Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += value
+}
+
+func (r *recorder) Record(name string, value float64, attrs ...pd.Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[name]++
+}
+
+// TestPD_Telemetry every attempt gets a span of its operation, the retries and bytes are counted
+func TestPD_Telemetry(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+	id := server.AddFile("cat.txt", []byte("meow"))
+	server.Inject(pdtest.Fault{Method: http.MethodGet, Status: http.StatusInternalServerError, Times: 1})
+
+	rec := &recorder{counters: map[string]int64{}, records: map[string]int{}}
+	var traced []string
+	policy := pd.DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	c := server.Client(&pd.ClientOptions{
+		DisableUploadLog: true,
+		DisableDedup:     true,
+		RetryPolicy:      &policy,
+		Tracer:           rec,
+		Meter:            rec,
+		Middleware: []pd.Middleware{pd.BeforeRequest(func(req *http.Request) error {
+			// the middleware of the client runs inside the span, e.g. to propagate the trace
+			if span, ok := req.Context().Value(spanKey{}).(*recordedSpan); ok {
+				traced = append(traced, span.name)
+			}
+			return nil
+		})},
+	})
+
+	_, err := c.GetFileInfo(&pd.RequestFileInfo{ID: id})
+	assert.NoError(t, err)
+	_, err = c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("woof"), FileName: "dog.txt", Anonymous: true})
+	assert.NoError(t, err)
+
+	if assert.Len(t, rec.spans, 3) {
+		assert.Equal(t, "GET /file/{id}/info", rec.spans[0].name)
+		assert.Equal(t, http.StatusInternalServerError, rec.spans[0].attrs[pd.AttrStatusCode])
+		assert.Equal(t, http.StatusOK, rec.spans[1].attrs[pd.AttrStatusCode])
+		assert.Equal(t, "PUT /file/{name}", rec.spans[2].name)
+		for _, span := range rec.spans {
+			assert.True(t, span.ended)
+		}
+	}
+	assert.Equal(t, []string{"GET /file/{id}/info", "GET /file/{id}/info", "PUT /file/{name}"}, traced)
+	assert.Equal(t, int64(1), rec.counters[pd.MetricRequestRetries])
+	assert.Equal(t, int64(4), rec.counters[pd.MetricUploadBytes])
+	assert.Equal(t, 3, rec.records[pd.MetricRequestDuration])
+}
+
+// TestPD_Telemetry_Error a failed request is recorded at its span
+func TestPD_Telemetry_Error(t *testing.T) {
+	rec := &recorder{counters: map[string]int64{}, records: map[string]int{}}
+	spec := pd.DefaultAPISpec
+	spec.URL = "http://127.0.0.1:1/api"
+	c := pd.New(&pd.ClientOptions{API: &spec, Tracer: rec, RetryPolicy: &pd.RetryPolicy{MaxAttempts: 1}, Middleware: []pd.Middleware{
+		pd.BeforeRequest(func(req *http.Request) error { return errors.New("offline") }),
+	}}, nil)
+
+	_, err := c.GetList(&pd.RequestGetList{ID: "abc"})
+	assert.Error(t, err)
+	if assert.Len(t, rec.spans, 1) {
+		assert.Equal(t, "GET /list/{id}", rec.spans[0].name)
+		assert.EqualError(t, rec.spans[0].err, "offline")
+	}
+}
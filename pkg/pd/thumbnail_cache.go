@@ -0,0 +1,194 @@
+package pd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultThumbnailCacheSize is the limit of a ThumbnailCache created with a size of 0
+const DefaultThumbnailCacheSize = 64 << 20
+
+// ThumbnailCache keeps thumbnails as files in a directory, keyed by file ID and size. The least recently
+// used thumbnails are removed when the cache grows beyond its limit.
+type ThumbnailCache struct {
+	Dir     string
+	MaxSize int64 // bytes of all cached thumbnails
+
+	mu sync.Mutex
+}
+
+// NewThumbnailCache creates the directory of the cache, maxSize 0 uses DefaultThumbnailCacheSize
+func NewThumbnailCache(dir string, maxSize int64) (*ThumbnailCache, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultThumbnailCacheSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &ThumbnailCache{Dir: dir, MaxSize: maxSize}, nil
+}
+
+func (c *ThumbnailCache) path(id string, width, height ThumbnailSize) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("%s_%dx%d", filepath.Base(id), width, height))
+}
+
+// Get returns the cached thumbnail and marks it as used
+func (c *ThumbnailCache) Get(id string, width, height ThumbnailSize) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(id, width, height)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return data, true
+}
+
+// Put stores the thumbnail and removes the least recently used ones beyond the size limit
+func (c *ThumbnailCache) Put(id string, width, height ThumbnailSize, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(id, width, height)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	return c.evict()
+}
+
+// Invalidate removes all cached sizes of the file, e.g. after it was deleted
+func (c *ThumbnailCache) Invalidate(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(c.Dir, filepath.Base(id)+"_*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear removes all cached thumbnails
+func (c *ThumbnailCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(c.Dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Size returns the bytes of all cached thumbnails
+func (c *ThumbnailCache) Size() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+
+	return size, nil
+}
+
+// files returns the cached thumbnails, the least recently used first
+func (c *ThumbnailCache) files() ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	return files, nil
+}
+
+// evict removes the least recently used thumbnails until the cache fits into MaxSize
+func (c *ThumbnailCache) evict() error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+	for _, file := range files {
+		if size <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.Dir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= file.Size()
+	}
+
+	return nil
+}
+
+// prefetchThumbnails caches the thumbnails of an uploaded image in the ThumbnailSizes of the client.
+// The upload succeeded already, so a failed prefetch is only logged.
+func (pd *PixelDrainClient) prefetchThumbnails(uploadRsp *ResponseUpload, auth Auth) {
+	if pd.ThumbnailCache == nil || len(pd.ThumbnailSizes) == 0 || uploadRsp.ID == "" {
+		return
+	}
+	if !strings.HasPrefix(uploadRsp.MimeType, "image/") {
+		return
+	}
+
+	for _, size := range pd.ThumbnailSizes {
+		_, err := pd.GetThumbnailBytes(&RequestThumbnail{ID: uploadRsp.ID, Width: size, Height: size, Auth: auth})
+		if err != nil {
+			log.Printf("Error prefetching the %dpx thumbnail of %s: %v", size, uploadRsp.ID, err)
+		}
+	}
+}
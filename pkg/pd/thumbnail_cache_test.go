@@ -0,0 +1,88 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_ThumbnailCache_Prefetch(t *testing.T) {
+	var thumbnails int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "img"}`))
+		case strings.HasSuffix(r.URL.Path, "/thumbnail"):
+			atomic.AddInt32(&thumbnails, 1)
+			_, _ = w.Write([]byte("thumbnail " + r.URL.Query().Get("width")))
+		case r.Method == http.MethodDelete:
+			_, _ = w.Write([]byte(`{"success": true}`))
+		}
+	}))
+	defer server.Close()
+
+	cache, err := pd.NewThumbnailCache(filepath.Join(t.TempDir(), "thumbnails"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, ThumbnailCache: cache, ThumbnailSizes: []pd.ThumbnailSize{32, 64}}, nil)
+
+	_, err = c.UploadPUT(&pd.RequestUpload{PathToFile: "testdata/cat.jpg", FileName: "cat.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&thumbnails))
+
+	// the prefetched thumbnail is served from the cache
+	rsp, err := c.GetThumbnailBytes(&pd.RequestThumbnail{ID: "img", Width: 64, Height: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "thumbnail 64", string(rsp.Data))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&thumbnails))
+
+	// deleting the file invalidates its thumbnails
+	_, err = c.Delete(&pd.RequestDelete{ID: "img"})
+	assert.NoError(t, err)
+	size, err := cache.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestPD_ThumbnailCache_Evict(t *testing.T) {
+	cache, err := pd.NewThumbnailCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, cache.Put("a", 16, 16, []byte("aaaa")))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, cache.Put("b", 16, 16, []byte("bbbb")))
+	time.Sleep(10 * time.Millisecond)
+	// a is used again, so b is the least recently used thumbnail
+	_, ok := cache.Get("a", 16, 16)
+	assert.True(t, ok)
+	assert.NoError(t, cache.Put("c", 16, 16, []byte("cccc")))
+
+	_, ok = cache.Get("b", 16, 16)
+	assert.False(t, ok)
+	_, ok = cache.Get("a", 16, 16)
+	assert.True(t, ok)
+	_, ok = cache.Get("c", 16, 16)
+	assert.True(t, ok)
+
+	assert.NoError(t, cache.Clear())
+	_, ok = cache.Get("a", 16, 16)
+	assert.False(t, ok)
+}
@@ -0,0 +1,133 @@
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings splits the duration of a transfer into its phases, so a slow transfer can be traced to local hashing,
+// the limits of the client, the network or pixeldrain. Phases of retried attempts add up.
+type Timings struct {
+	Hashing          time.Duration `json:"hashing"`           // SHA-256 and MIME type of the local file
+	QueueWait        time.Duration `json:"queue_wait"`        // bandwidth budget, request pacing, 429 pauses and retry backoff
+	Connect          time.Duration `json:"connect"`           // DNS, TCP and TLS of new connections, close to 0 for reused ones
+	Transfer         time.Duration `json:"transfer"`          // sending the request body or receiving the response body
+	ServerProcessing time.Duration `json:"server_processing"` // from the last byte sent to the first byte received
+	Total            time.Duration `json:"total"`
+}
+
+// TimingsFunc receives the timings of every upload and download, e.g. to export them as metrics.
+// Direction is utils.Upload or utils.Download, id is the remote file.
+type TimingsFunc func(direction, id string, t Timings)
+
+// timingsRecorder collects the phases of one transfer, it is passed to the requests in the context
+type timingsRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	t     Timings
+}
+
+type timingsKey struct{}
+
+// withTimings returns a context which records the phases of the requests sent with it
+func withTimings(ctx context.Context) (context.Context, *timingsRecorder) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rec := &timingsRecorder{start: time.Now()}
+	return context.WithValue(ctx, timingsKey{}, rec), rec
+}
+
+// timingsFrom returns the recorder of the context, nil if the phases are not recorded
+func timingsFrom(ctx context.Context) *timingsRecorder {
+	rec, _ := ctx.Value(timingsKey{}).(*timingsRecorder)
+	return rec
+}
+
+// add adds the time since start to the phase, a nil recorder records nothing
+func (rec *timingsRecorder) add(phase func(*Timings) *time.Duration, start time.Time) {
+	if rec == nil {
+		return
+	}
+
+	d := time.Since(start)
+	rec.mu.Lock()
+	*phase(&rec.t) += d
+	rec.mu.Unlock()
+}
+
+func hashing(t *Timings) *time.Duration          { return &t.Hashing }
+func queueWait(t *Timings) *time.Duration        { return &t.QueueWait }
+func connect(t *Timings) *time.Duration          { return &t.Connect }
+func transfer(t *Timings) *time.Duration         { return &t.Transfer }
+func serverProcessing(t *Timings) *time.Duration { return &t.ServerProcessing }
+
+// trace returns the context of an attempt with a trace which records connecting, sending and waiting for pixeldrain.
+// The hooks run on the goroutines of the transport, so the marks are kept under the lock of the recorder.
+func (rec *timingsRecorder) trace(ctx context.Context) context.Context {
+	var getConn, gotConn, wrote time.Time
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			rec.step(&getConn, nil, nil)
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			rec.step(&gotConn, connect, &getConn)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			rec.step(&wrote, transfer, &gotConn)
+		},
+		GotFirstResponseByte: func() {
+			rec.step(nil, serverProcessing, &wrote)
+		},
+	})
+}
+
+// step adds the time since the mark from to the phase and sets the mark to now
+func (rec *timingsRecorder) step(mark *time.Time, phase func(*Timings) *time.Duration, from *time.Time) {
+	now := time.Now()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if phase != nil && !from.IsZero() {
+		*phase(&rec.t) += now.Sub(*from)
+	}
+	if mark != nil {
+		*mark = now
+	}
+}
+
+// traced adds the trace of the recorder in the context of the request to it
+func traced(httpReq *http.Request) *http.Request {
+	rec := timingsFrom(httpReq.Context())
+	if rec == nil {
+		return httpReq
+	}
+
+	return httpReq.WithContext(rec.trace(httpReq.Context()))
+}
+
+// finishTimings returns the timings with the total duration and passes them to the TimingsFunc of the client
+func (pd *PixelDrainClient) finishTimings(rec *timingsRecorder, direction, id string) *Timings {
+	rec.mu.Lock()
+	t := rec.t
+	rec.mu.Unlock()
+	t.Total = time.Since(rec.start)
+
+	if pd.OnTimings != nil {
+		pd.OnTimings(direction, id, t)
+	}
+
+	return &t
+}
+
+// timings returns the recorder of the request of the response, e.g. to record reading the body
+func (rsp *httpResponse) timings() *timingsRecorder {
+	if rsp.Request == nil {
+		return nil
+	}
+
+	return timingsFrom(rsp.Request.Context())
+}
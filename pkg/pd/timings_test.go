@@ -0,0 +1,57 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Timings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "K1dA8U5W"}`))
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	reported := map[string]pd.Timings{}
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{
+		API:       &spec,
+		HashStore: utils.NewMemoryHashStore(),
+		UploadLog: utils.CSVUploadLog{Path: filepath.Join(t.TempDir(), "log.csv")},
+		OnTimings: func(direction, id string, timings pd.Timings) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported[direction+":"+id] = timings
+		},
+	}, nil)
+
+	uploadRsp, err := c.UploadPUT(&pd.RequestUpload{PathToFile: "testdata/cat.jpg", FileName: "cat.jpg"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, uploadRsp.Timings) {
+		assert.GreaterOrEqual(t, uploadRsp.Timings.ServerProcessing, 20*time.Millisecond)
+		assert.GreaterOrEqual(t, uploadRsp.Timings.Total, uploadRsp.Timings.ServerProcessing)
+		assert.Equal(t, *uploadRsp.Timings, reported[utils.Upload+":K1dA8U5W"])
+	}
+
+	downloadRsp, err := c.Download(&pd.RequestDownload{ID: "K1dA8U5W", PathToSave: filepath.Join(t.TempDir(), "cat.jpg")})
+	assert.NoError(t, err)
+	if assert.NotNil(t, downloadRsp.Timings) {
+		assert.GreaterOrEqual(t, downloadRsp.Timings.ServerProcessing, 20*time.Millisecond)
+		assert.Equal(t, *downloadRsp.Timings, reported[utils.Download+":K1dA8U5W"])
+	}
+}
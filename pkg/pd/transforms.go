@@ -0,0 +1,105 @@
+package pd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// uploadTransforms compresses and encrypts upload bodies as asked by the Compress and EncryptWith of the request
+type uploadTransforms struct {
+	names    []string // in the order they are applied
+	encoders []Encoder
+	key      string
+}
+
+// newUploadTransforms returns nil if the request has no transforms, the upload is sent as it is
+func newUploadTransforms(r *RequestUpload) (*uploadTransforms, error) {
+	var names []string
+	if r.Compress != "" {
+		names = append(names, string(r.Compress))
+	}
+	// compressed before it is encrypted, encrypted data doesn't compress
+	if r.EncryptWith != "" {
+		names = append(names, encryptTransform(r.EncryptWith))
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	t := &uploadTransforms{names: names, key: r.EncryptWith}
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	for _, name := range names {
+		encode, ok := encoders[name]
+		if !ok && name == string(r.Compress) {
+			return nil, fmt.Errorf("%w: %s compression is not built in, only %s is", ErrUnknownTransform, name, CompressGzip)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTransform, name)
+		}
+		t.encoders = append(t.encoders, encode)
+	}
+
+	return t, nil
+}
+
+// String returns the transforms as they are recorded in the upload log, e.g. "gzip+aes-gcm"
+func (t *uploadTransforms) String() string {
+	return strings.Join(t.names, "+")
+}
+
+// body returns the transformed src with an envelope in front, so Download finds the transforms to reverse
+func (t *uploadTransforms) body(src io.ReadCloser, name string, size int64) io.ReadCloser {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(t.write(pw, src, name, size))
+	}()
+
+	return &transformedBody{PipeReader: pr, src: src, done: done}
+}
+
+func (t *uploadTransforms) write(dst io.Writer, src io.Reader, name string, size int64) error {
+	if err := WriteEnvelope(dst, &Envelope{Transforms: t.names, Name: name, Size: size}); err != nil {
+		return err
+	}
+
+	// the first transform gets the content, its output goes through the following ones
+	writers := make([]io.WriteCloser, len(t.encoders))
+	w := dst
+	for i := len(t.encoders) - 1; i >= 0; i-- {
+		enc, err := t.encoders[i](w, t.key)
+		if err != nil {
+			return err
+		}
+		writers[i] = enc
+		w = enc
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+	for _, enc := range writers {
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformedBody closes the source after the transforming goroutine stopped reading it
+type transformedBody struct {
+	*io.PipeReader
+	src  io.Closer
+	done chan struct{}
+}
+
+func (b *transformedBody) Close() error {
+	_ = b.PipeReader.Close()
+	<-b.done
+
+	return b.src.Close()
+}
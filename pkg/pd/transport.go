@@ -0,0 +1,226 @@
+package pd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// newHTTPClient builds the *http.Client of the proxy, TLS, cookie and timeout options
+func newHTTPClient(opt *ClientOptions) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opt.EnableInsecureTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if opt.ProxyURL != "" {
+		if proxy, err := url.Parse(opt.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   opt.Timeout,
+	}
+	if opt.EnableCookies {
+		client.Jar, _ = cookiejar.New(nil)
+	}
+
+	return client
+}
+
+// newSourceClient builds the *http.Client of UploadFromURL, pixeldrain doesn't vouch for the certificates of other
+// servers so they are verified even with EnableInsecureTLS. It uses the proxy and timeout but no cookies.
+func newSourceClient(opt *ClientOptions) *http.Client {
+	sourceOpt := *opt
+	sourceOpt.EnableInsecureTLS = false
+	sourceOpt.EnableCookies = false
+
+	return newHTTPClient(&sourceOpt)
+}
+
+// httpResponse is the response of a request, the body is read on demand and kept for decoding and dumping it
+type httpResponse struct {
+	*http.Response
+	body      []byte
+	read      bool
+	err       error
+	rateLimit *RateLimitInfo // parsed from the headers, nil without rate limit headers
+}
+
+// readBody reads and closes the body once, later calls return the same bytes
+func (rsp *httpResponse) readBody() ([]byte, error) {
+	if !rsp.read {
+		rsp.read = true
+		rsp.body, rsp.err = io.ReadAll(rsp.Body)
+		_ = rsp.Body.Close()
+	}
+
+	return rsp.body, rsp.err
+}
+
+// decodeJSON reads the body and decodes it into v, unknown fields are kept in the RawExtra of responses
+func (rsp *httpResponse) decodeJSON(v interface{}) error {
+	data, err := rsp.readBody()
+	if err != nil {
+		return err
+	}
+
+	return rsp.decode(data, v)
+}
+
+// decode decodes the read body into v and adds the rate limit headers of the response
+func (rsp *httpResponse) decode(data []byte, v interface{}) error {
+	if err := decodeResponse(data, v); err != nil {
+		return err
+	}
+	if setter, ok := v.(rateLimitSetter); ok && rsp.rateLimit != nil {
+		setter.setRateLimit(rsp.rateLimit)
+	}
+
+	return nil
+}
+
+// saveTo writes the body into the file at path
+func (rsp *httpResponse) saveTo(path string) error {
+	defer rsp.Body.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, rsp.Body)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// dump returns the request and response headers for debugging, the body only if it was read already.
+// The API key of the Authorization header is not included.
+func (rsp *httpResponse) dump() string {
+	if rsp == nil || rsp.Response == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	if r := rsp.Request; r != nil {
+		header := r.Header.Clone()
+		if header.Get("Authorization") != "" {
+			header.Set("Authorization", "Basic ***")
+		}
+		fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL)
+		_ = header.Write(&b)
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "%s %s\n", rsp.Proto, rsp.Status)
+	_ = rsp.Header.Write(&b)
+	if rsp.read {
+		b.WriteString("\n")
+		b.Write(rsp.body)
+	}
+
+	return b.String()
+}
+
+// newRequest builds a request with the header, a nil body sends none
+func newRequest(ctx context.Context, method, url string, header http.Header, body io.Reader) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	// the client adds cookies to the header, every attempt gets its own copy
+	httpReq.Header = header.Clone()
+
+	return httpReq, nil
+}
+
+// withQuery appends the query parameters to the URL
+func withQuery(rawURL string, query url.Values) string {
+	if len(query) == 0 {
+		return rawURL
+	}
+
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+
+	return rawURL + separator + query.Encode()
+}
+
+// formBody encodes the fields as form body and sets its content type in the header
+func formBody(header http.Header, fields url.Values) []byte {
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return []byte(fields.Encode())
+}
+
+// jsonBody encodes v as JSON body and sets its content type in the header
+func jsonBody(header http.Header, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("Content-Type", "application/json")
+
+	return data, nil
+}
+
+// multipartBody streams the fields and the file as multipart form, the file is read while the request is sent.
+// It returns the body and its content type with the boundary.
+func multipartBody(fields map[string]string, fieldName, fileName string, file io.ReadCloser) (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		defer file.Close()
+
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := writer.WriteField(name, fields[name]); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		part, err := writer.CreateFormFile(fieldName, fileName)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, file); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(writer.Close())
+	}()
+
+	return pr, writer.FormDataContentType()
+}
+
+// bytesBody returns a reader of the body or nil if there is none, so no empty body is sent
+func bytesBody(body []byte) io.Reader {
+	if body == nil {
+		return nil
+	}
+
+	return bytes.NewReader(body)
+}
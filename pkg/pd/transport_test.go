@@ -0,0 +1,150 @@
+package pd_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTransport counts the requests which pass through it
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestPD_HTTPClient(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	transport := &countingTransport{}
+	c := pd.New(&pd.ClientOptions{API: &spec, HTTPClient: &http.Client{Transport: transport}}, nil)
+
+	rsp, err := c.GetFileInfo(&pd.RequestFileInfo{ID: "K1dA8U5W"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "K1dA8U5W", rsp.ID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.requests))
+}
+
+func TestPD_DoJSON_ContextCancel(t *testing.T) {
+	blocked := make(chan struct{})
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		close(blocked)
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+	c := pd.New(&pd.ClientOptions{HTTPClient: &http.Client{Transport: transport}, RetryPolicy: &pd.NoRetry}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pd.DoJSON[pd.ResponseFileInfo](ctx, c, pd.Auth{}, http.MethodGet, "/file/K1dA8U5W/info", nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request was not cancelled")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// discardServer reads and drops the uploads, so the memory of the client is measured
+func discardServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "discarded"}`))
+	}))
+}
+
+// sizedFile creates a file of the size without writing its content
+func sizedFile(tb testing.TB, size int64) string {
+	path := filepath.Join(tb.TempDir(), fmt.Sprintf("%d.bin", size))
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := file.Truncate(size); err != nil {
+		tb.Fatal(err)
+	}
+	_ = file.Close()
+
+	return path
+}
+
+// uploadAlloc returns the bytes allocated by the upload of the file
+func uploadAlloc(tb testing.TB, c *pd.PixelDrainClient, url, path string) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	if _, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, URL: url}, ""); err != nil {
+		tb.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// TestPD_UploadPOST_ConstantMemory streams the multipart body, a 32 times larger file doesn't take more memory
+func TestPD_UploadPOST_ConstantMemory(t *testing.T) {
+	server := discardServer()
+	defer server.Close()
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}, nil)
+
+	small := uploadAlloc(t, c, server.URL+"/file", sizedFile(t, 1<<20))
+	large := uploadAlloc(t, c, server.URL+"/file", sizedFile(t, 32<<20))
+	assert.Less(t, large, small+8<<20, "1 MiB: %d bytes allocated, 32 MiB: %d bytes", small, large)
+}
+
+// BenchmarkUploadPOST_Memory reports the bytes allocated per upload, they stay about the same for every file size
+func BenchmarkUploadPOST_Memory(b *testing.B) {
+	server := discardServer()
+	defer server.Close()
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true}, nil)
+
+	for _, size := range []int64{1 << 20, 16 << 20, 64 << 20} {
+		path := sizedFile(b, size)
+		b.Run(fmt.Sprintf("%dMiB", size>>20), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				_, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true, URL: server.URL + "/file"}, "")
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
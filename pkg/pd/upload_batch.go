@@ -0,0 +1,155 @@
+package pd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// BatchFileStatus the state of a single file of a batch
+type BatchFileStatus string
+
+const (
+	BatchCompleted  BatchFileStatus = "completed"   // uploaded, the ID is set
+	BatchFailed     BatchFileStatus = "failed"      // the upload returned an error
+	BatchAborted    BatchFileStatus = "aborted"     // the upload was running when the batch was cancelled
+	BatchNotStarted BatchFileStatus = "not_started" // the batch stopped before the file
+
+	BatchSkippedDuplicate BatchFileStatus = "skipped_duplicate" // not uploaded, the duplicate check found the file
+	BatchWouldUpload      BatchFileStatus = "would_upload"      // a dry run would upload the file
+
+	BatchSkippedEmpty      BatchFileStatus = "skipped_empty"      // not uploaded, the file has no content
+	BatchSkippedSpecial    BatchFileStatus = "skipped_special"    // not uploaded, the file is a pipe, socket or device
+	BatchSkippedUnreadable BatchFileStatus = "skipped_unreadable" // not uploaded, the file or its directory can't be read
+)
+
+// finished reports if the file needs no other attempt, it was uploaded or found by the duplicate check
+func (s BatchFileStatus) finished() bool {
+	return s == BatchCompleted || s == BatchSkippedDuplicate
+}
+
+// UploadBatch uploads the files one after another and records the state of every file. The files pass the
+// duplicate check and the UploadCache of the client like the other uploads, duplicates are BatchSkippedDuplicate.
+// If the context is cancelled the running upload is aborted and the result is returned together with the context error,
+// the state file then holds exactly the unfinished files and a later call with the same StatePath resumes them.
+func (pd *PixelDrainClient) UploadBatch(ctx context.Context, r *RequestUploadBatch) (*ResponseUploadBatch, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if r.URL == "" {
+		r.URL = pd.API.URL + pd.API.File
+	}
+	if r.HashFilePath == "" {
+		r.HashFilePath = utils.GetHashFilePath()
+	}
+
+	result, err := loadBatchState(pd.stateStore(), r.StatePath, r.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Files {
+		file := &result.Files[i]
+		if file.Status.finished() {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		rsp, err := pd.retryRateLimited(ctx, func() (*ResponseUpload, error) {
+			return pd.uploadPOSTContext(ctx, &RequestUpload{
+				PathToFile: file.Path,
+				Anonymous:  r.Anonymous,
+				Auth:       r.Auth,
+				URL:        r.URL,
+			}, r.HashFilePath)
+		})
+
+		switch {
+		case ctx.Err() != nil:
+			file.Status = BatchAborted
+		case err != nil:
+			file.Status, file.Error = BatchFailed, err.Error()
+			log.Printf("Error uploading file %s: %v", file.Path, err)
+		case rsp.Duplicate != nil:
+			file.Status, file.ID, file.Error = BatchSkippedDuplicate, rsp.ID, ""
+			file.Duplicate = rsp.Duplicate
+		default:
+			file.Status, file.ID, file.Error = BatchCompleted, rsp.ID, ""
+		}
+
+		if err := saveBatchState(pd.stateStore(), r.StatePath, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Cancelled = true
+		for i := range result.Files {
+			if result.Files[i].Status == "" {
+				result.Files[i].Status = BatchNotStarted
+			}
+		}
+		if serr := saveBatchState(pd.stateStore(), r.StatePath, result); serr != nil {
+			return nil, serr
+		}
+
+		return result, err
+	}
+
+	return result, nil
+}
+
+// loadBatchState returns the state of an earlier run or a new state for the paths
+func loadBatchState(store utils.StateStore, key string, paths []string) (*ResponseUploadBatch, error) {
+	result := &ResponseUploadBatch{}
+	if key != "" {
+		data, found, err := store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if err := json.Unmarshal(data, result); err != nil {
+				return nil, err
+			}
+			result.Cancelled = false
+		}
+	}
+
+	// paths which are not part of the stored state are added as new files
+	known := map[string]bool{}
+	for _, file := range result.Files {
+		known[file.Path] = true
+	}
+	for _, path := range paths {
+		if !known[path] {
+			known[path] = true
+			result.Files = append(result.Files, BatchFileResult{Path: path})
+		}
+	}
+
+	if len(result.Files) == 0 {
+		return nil, errors.New(ErrMissingPathToFile)
+	}
+
+	return result, nil
+}
+
+// saveBatchState stores the state, the file store writes a temporary file and renames it, so a crash never leaves a half written state
+func saveBatchState(store utils.StateStore, key string, result *ResponseUploadBatch) error {
+	if key == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return store.Put(key, data)
+}
@@ -0,0 +1,152 @@
+package pd_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_UploadBatch_CancelAndResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		uploads++
+		if uploads == 2 {
+			// cancel the batch while the second upload is running
+			cancel()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"success": true, "id": "id%d"}`, uploads)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		path := filepath.Join(dir, name)
+		_ = os.WriteFile(path, []byte("batch "+name), 0644)
+		paths = append(paths, path)
+	}
+	statePath := filepath.Join(dir, "batch.json")
+
+	hashFilePath := filepath.Join(dir, "hashes.csv")
+	c := pd.New(nil, nil)
+	rsp, err := c.UploadBatch(ctx, &pd.RequestUploadBatch{
+		Paths:        paths,
+		Anonymous:    true,
+		URL:          server.URL + "/file",
+		StatePath:    statePath,
+		HashFilePath: hashFilePath,
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.NotNil(t, rsp) {
+		assert.True(t, rsp.Cancelled)
+		assert.Equal(t, pd.BatchCompleted, rsp.Files[0].Status)
+		assert.Equal(t, pd.BatchAborted, rsp.Files[1].Status)
+		assert.Equal(t, pd.BatchNotStarted, rsp.Files[2].Status)
+		assert.Equal(t, paths[1:], rsp.Unfinished())
+	}
+
+	// resume from the state file, only the unfinished files are uploaded
+	rsp, err = c.UploadBatch(context.Background(), &pd.RequestUploadBatch{
+		Anonymous:    true,
+		URL:          server.URL + "/file",
+		StatePath:    statePath,
+		HashFilePath: hashFilePath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, rsp.Cancelled)
+	assert.Empty(t, rsp.Unfinished())
+	assert.Equal(t, "id1", rsp.Files[0].ID)
+	assert.Equal(t, 4, uploads)
+}
+
+func TestPD_UploadBatch_StateStore(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	_ = os.WriteFile(path, []byte("batch in a store"), 0644)
+
+	store := utils.NewMemoryStateStore()
+	c := pd.New(&pd.ClientOptions{StateStore: store, HashStore: utils.NewMemoryHashStore()}, nil)
+	_, err := c.UploadBatch(context.Background(), &pd.RequestUploadBatch{
+		Paths:     []string{path},
+		Anonymous: true,
+		URL:       server.URL + "/file",
+		StatePath: "batches/a",
+	})
+	assert.NoError(t, err)
+
+	// the state is kept in the store, no file is written
+	data, found, err := store.Get("batches/a")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Contains(t, string(data), `"mock-file-id"`)
+	assert.NoDirExists(t, "batches")
+}
+
+// TestPD_UploadBatch_Duplicates the files of a batch pass the duplicate check of the hash file of the request
+func TestPD_UploadBatch_Duplicates(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		uploads++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"success": true, "id": "id%d"}`, uploads)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	var paths []string
+	for _, file := range [][2]string{{"a.txt", "batch a"}, {"copy_of_a.txt", "batch a"}, {"b.txt", "batch b"}} {
+		path := filepath.Join(dir, file[0])
+		_ = os.WriteFile(path, []byte(file[1]), 0644)
+		paths = append(paths, path)
+	}
+	hashFilePath := filepath.Join(dir, "hashes.csv")
+
+	c := pd.New(&pd.ClientOptions{DisableUploadLog: true}, nil)
+	rsp, err := c.UploadBatch(context.Background(), &pd.RequestUploadBatch{
+		Paths:        paths,
+		Anonymous:    true,
+		URL:          server.URL + "/file",
+		HashFilePath: hashFilePath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, uploads)
+	assert.Equal(t, pd.BatchCompleted, rsp.Files[0].Status)
+	assert.Equal(t, pd.BatchSkippedDuplicate, rsp.Files[1].Status)
+	if assert.NotNil(t, rsp.Files[1].Duplicate) {
+		assert.Equal(t, paths[0], rsp.Files[1].Duplicate.OriginalPath)
+		assert.Equal(t, "id1", rsp.Files[1].ID)
+	}
+	assert.Equal(t, pd.BatchCompleted, rsp.Files[2].Status)
+	assert.Empty(t, rsp.Unfinished())
+	assert.FileExists(t, hashFilePath)
+}
@@ -0,0 +1,140 @@
+package pd
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// IndexFilePath default index of UploadChanged
+const IndexFilePath = "upload_index.csv"
+
+// UploadChanged uploads the files of the directory which are new or changed since the last run.
+// Size and modification time decide if a file has to be hashed, the hash decides if it is uploaded.
+// The remote file of a changed file is deleted after its new version was uploaded.
+// The index is saved after every file, so an interrupted run continues where it stopped.
+func (pd *PixelDrainClient) UploadChanged(r *RequestUploadChanged) (*ResponseUploadChanged, error) {
+	if r.IndexPath == "" {
+		r.IndexPath = IndexFilePath
+	}
+	if r.URL == "" {
+		r.URL = pd.API.URL
+	}
+
+	index, err := utils.LoadFileIndexFrom(pd.StateStore, r.IndexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := utils.GetFilesInDirectory(r.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	indexPath, _ := filepath.Abs(r.IndexPath)
+	result := &ResponseUploadChanged{IDs: map[string]string{}}
+	for _, filePath := range files {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if absPath == indexPath {
+			continue
+		}
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		change, err := pd.compareIndexed(index, absPath, filePath, info, false)
+		if err != nil {
+			return nil, err
+		}
+		if change.unchanged {
+			result.Unchanged = append(result.Unchanged, filePath)
+			continue
+		}
+		entry, known := change.entry, change.known
+
+		log.Printf("Uploading changed file: %s", filePath)
+		rsp, err := pd.uploadFile(&RequestUpload{
+			PathToFile: filePath,
+			Anonymous:  r.Anonymous,
+			Auth:       r.Auth,
+			URL:        r.URL + pd.API.File,
+		}, utils.GetHashFilePath())
+		if err != nil {
+			return nil, err
+		}
+
+		if known && entry.ID != "" && entry.ID != rsp.ID {
+			_, err := pd.Delete(&RequestDelete{
+				ID:   entry.ID,
+				Auth: r.Auth,
+				URL:  r.URL + pd.API.File + "/" + entry.ID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.Replaced = append(result.Replaced, filePath)
+		} else {
+			result.Uploaded = append(result.Uploaded, filePath)
+		}
+		result.IDs[filePath] = rsp.ID
+
+		index.Set(utils.FileIndexEntry{
+			Path:    absPath,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Hash:    change.hash,
+			ID:      rsp.ID,
+		})
+		if err := index.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// indexedChange is a local file compared with its entry of a FileIndex
+type indexedChange struct {
+	entry     utils.FileIndexEntry
+	known     bool   // the index has an entry for the file
+	unchanged bool   // the content is the one of the entry
+	hash      string // SHA-256 of a changed file
+}
+
+// compareIndexed compares the file with its entry of the index. Size and modification time decide if the file is
+// hashed, a file which was touched but has the hash of the entry only gets its new size and modification time
+// saved, unless dryRun is set.
+func (pd *PixelDrainClient) compareIndexed(index *utils.FileIndex, key string, filePath string, info os.FileInfo, dryRun bool) (indexedChange, error) {
+	entry, known := index.Get(key)
+	change := indexedChange{entry: entry, known: known}
+	if known && entry.Unchanged(info) {
+		change.unchanged = true
+		return change, nil
+	}
+
+	hash, err := pd.calculateFileHash(filePath)
+	if err != nil {
+		return change, err
+	}
+	change.hash = hash
+
+	// touched but the content is the same, only remember the new modification time
+	if known && entry.Hash == hash {
+		change.unchanged = true
+		if dryRun {
+			return change, nil
+		}
+		entry.Size, entry.ModTime = info.Size(), info.ModTime()
+		index.Set(entry)
+		return change, index.Save()
+	}
+
+	return change, nil
+}
@@ -0,0 +1,70 @@
+package pd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_UploadChanged(t *testing.T) {
+	uploads := 0
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"success": true, "id": "id%d"}`, uploads)
+		case http.MethodDelete:
+			deleted = append(deleted, filepath.Base(r.URL.Path))
+			_, _ = w.Write([]byte(`{"success": true, "value": "file_deleted"}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	files := filepath.Join(dir, "files")
+	_ = os.Mkdir(files, 0755)
+	a := filepath.Join(files, "a.txt")
+	_ = os.WriteFile(a, []byte("first version"), 0644)
+	_ = os.WriteFile(filepath.Join(files, "b.txt"), []byte("stays the same"), 0644)
+
+	c := pd.New(nil, nil)
+	run := func() *pd.ResponseUploadChanged {
+		rsp, err := c.UploadChanged(&pd.RequestUploadChanged{
+			Directory: files,
+			IndexPath: filepath.Join(dir, "index.csv"),
+			Anonymous: true,
+			URL:       server.URL,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rsp
+	}
+
+	rsp := run()
+	assert.Len(t, rsp.Uploaded, 2)
+	oldID := rsp.IDs[a]
+
+	rsp = run()
+	assert.Len(t, rsp.Unchanged, 2)
+	assert.Equal(t, 2, uploads)
+
+	_ = os.WriteFile(a, []byte("second version"), 0644)
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(a, later, later)
+
+	rsp = run()
+	assert.Equal(t, []string{a}, rsp.Replaced)
+	assert.Equal(t, []string{filepath.Join(files, "b.txt")}, rsp.Unchanged)
+	assert.Equal(t, []string{oldID}, deleted)
+	assert.Equal(t, 3, uploads)
+}
@@ -0,0 +1,200 @@
+package pd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// ListDuplicatePolicy decides what happens with a file whose content already exists when uploading into a list
+type ListDuplicatePolicy int
+
+const (
+	ListDuplicateUpload      ListDuplicatePolicy = iota // upload the bytes again
+	ListDuplicateSkip                                   // skip files which are already in the list or the account
+	ListDuplicateAddExisting                            // add the existing file of the account to the list instead of uploading it
+)
+
+// RequestUploadToList upload files into a new or an existing list
+type RequestUploadToList struct {
+	Paths      []string // files to upload
+	ListID     string   // existing list the files are added to
+	Title      string   // title of the new list if no ListID is given
+	Duplicates ListDuplicatePolicy
+	Anonymous  bool
+	Auth       Auth
+}
+
+// UploadToList uploads the files and adds them to a list. Files with the same SHA-256 as a file already in
+// the target list are never uploaded again, files known in the account are skipped or linked depending on the policy.
+func (pd *PixelDrainClient) UploadToList(r *RequestUploadToList) (*ResponseUploadToList, error) {
+	if len(r.Paths) == 0 {
+		return nil, errors.New(ErrMissingPathToFile)
+	}
+
+	rspStruct := &ResponseUploadToList{
+		ListID: r.ListID,
+	}
+
+	// hashes of the files already in the target list
+	var title string
+	listFiles := []ListFile{}
+	inList := map[string]DuplicateMatch{}
+	if r.ListID != "" {
+		list, err := pd.GetList(&RequestGetList{ID: r.ListID, Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		if list.StatusCode != http.StatusOK {
+			return nil, &APIError{StatusCode: list.StatusCode, Value: list.Value, Message: list.Message}
+		}
+
+		title = list.Title
+		var unhashed []string // files the listing has no hash of
+		for _, file := range list.Files {
+			listFiles = append(listFiles, ListFile{ID: file.ID, Description: file.Description})
+			if r.Duplicates == ListDuplicateUpload {
+				continue
+			}
+			if file.HashSha256 == "" {
+				unhashed = append(unhashed, file.ID)
+				continue
+			}
+
+			inList[file.HashSha256] = DuplicateMatch{
+				ID:         file.ID,
+				Name:       file.Name,
+				URL:        fileURL(file.ID),
+				UploadedAt: file.DateCreated,
+			}
+		}
+
+		// the hashes the listing lacks are fetched with batched info requests instead of one per file
+		if len(unhashed) > 0 {
+			infos, err := pd.GetFileInfoMany(&RequestFileInfoMany{IDs: unhashed, Auth: r.Auth})
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range unhashed {
+				info, ok := infos[id]
+				if !ok {
+					continue
+				}
+				inList[info.HashSha256] = DuplicateMatch{
+					ID:         id,
+					Name:       info.Name,
+					URL:        fileURL(id),
+					UploadedAt: info.DateUpload,
+				}
+			}
+		}
+	}
+
+	// hashes of the files in the account
+	inAccount := map[string]DuplicateMatch{}
+	if r.Duplicates == ListDuplicateAddExisting || r.Duplicates == ListDuplicateSkip {
+		files, err := pd.GetUserFiles(&RequestGetUserFiles{Auth: r.Auth})
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files.Files {
+			inAccount[file.HashSha256] = DuplicateMatch{
+				ID:         file.ID,
+				Name:       file.Name,
+				URL:        fileURL(file.ID),
+				UploadedAt: file.DateUpload,
+			}
+		}
+	}
+
+	newFiles := []ListFile{}
+	for _, path := range r.Paths {
+		var hash string
+		if r.Duplicates != ListDuplicateUpload {
+			var err error
+			hash, err = pd.calculateFileHash(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if match, ok := inList[hash]; ok {
+				log.Printf("File %s is already in the list as %s. Skipping upload.", path, match.ID)
+				match.Path = path
+				match.Hash = hash
+				rspStruct.Skipped = append(rspStruct.Skipped, match)
+				pd.recordDedup(DedupSkipped, match)
+				continue
+			}
+
+			if match, ok := inAccount[hash]; ok {
+				match.Path = path
+				match.Hash = hash
+				if r.Duplicates == ListDuplicateSkip {
+					log.Printf("File %s already exists as %s. Skipping upload.", path, match.ID)
+					rspStruct.Skipped = append(rspStruct.Skipped, match)
+					pd.recordDedup(DedupSkipped, match)
+					continue
+				}
+
+				log.Printf("File %s already exists as %s. Adding it to the list.", path, match.ID)
+				rspStruct.Linked = append(rspStruct.Linked, match)
+				pd.recordDedup(DedupLinked, match)
+				inList[hash] = match
+				newFiles = append(newFiles, ListFile{ID: match.ID, Description: pd.describe(path)})
+				continue
+			}
+		}
+
+		rspUpload, err := pd.uploadFile(&RequestUpload{
+			PathToFile: path,
+			Anonymous:  r.Anonymous,
+			Auth:       r.Auth,
+		}, utils.GetHashFilePath())
+		if err != nil {
+			return nil, err
+		}
+		if rspUpload.ID == "" {
+			return nil, &APIError{StatusCode: rspUpload.StatusCode, Value: rspUpload.Value, Message: rspUpload.Message}
+		}
+
+		rspStruct.Uploaded = append(rspStruct.Uploaded, rspUpload.ID)
+		newFiles = append(newFiles, ListFile{ID: rspUpload.ID, Description: pd.describe(path)})
+		// an identical file later in Paths is skipped instead of uploaded again
+		if hash != "" {
+			inList[hash] = DuplicateMatch{ID: rspUpload.ID, Name: filepath.Base(path), URL: fileURL(rspUpload.ID)}
+		}
+	}
+
+	if r.ListID == "" {
+		rspList, err := pd.CreateList(&RequestCreateList{
+			Title:     r.Title,
+			Anonymous: r.Anonymous,
+			Files:     newFiles,
+			Auth:      r.Auth,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rspStruct.ListID = rspList.ID
+		rspStruct.ResponseDefault = rspList.ResponseDefault
+		return rspStruct, nil
+	}
+
+	if len(newFiles) == 0 {
+		rspStruct.StatusCode = http.StatusOK
+		rspStruct.Success = true
+		return rspStruct, nil
+	}
+
+	// the title is sent as well, pixeldrain replaces the whole list
+	rspUpdate, err := pd.UpdateList(&RequestUpdateList{ID: r.ListID, Title: title, Files: listFiles, AddFiles: newFiles, Auth: r.Auth})
+	if err != nil {
+		return nil, err
+	}
+	rspStruct.ResponseDefault = rspUpdate.ResponseDefault
+
+	return rspStruct, nil
+}
@@ -0,0 +1,176 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_UploadToList_AddExisting links files already in the account instead of uploading them
+func TestPD_UploadToList_AddExisting(t *testing.T) {
+	SetupTestEnvironment()
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	rsp, err := c.UploadToList(&pd.RequestUploadToList{
+		Paths:      []string{"testdata/cat.jpg", "testdata/cat_unique1.jpg"},
+		Title:      "Cats",
+		Duplicates: pd.ListDuplicateAddExisting,
+		Auth:       pd.Auth{APIKey: "test-api-key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "123456", rsp.ListID)
+	assert.Equal(t, 1, len(rsp.Linked))
+	assert.Equal(t, "testdata/cat.jpg", rsp.Linked[0].Path)
+	assert.Equal(t, "tUxgDCoQ", rsp.Linked[0].ID)
+	assert.Equal(t, "https://pixeldrain.com/u/tUxgDCoQ", rsp.Linked[0].URL)
+	assert.Equal(t, 2022, rsp.Linked[0].UploadedAt.Year())
+	assert.Equal(t, []string{"mock-file-id"}, rsp.Uploaded)
+	assert.Empty(t, rsp.Skipped)
+}
+
+// TestPD_UploadToList_ExistingList appends uploaded files to an existing list
+func TestPD_UploadToList_ExistingList(t *testing.T) {
+	SetupTestEnvironment()
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	rsp, err := c.UploadToList(&pd.RequestUploadToList{
+		Paths:  []string{"testdata/cat_unique2.jpg"},
+		ListID: "123",
+		Auth:   pd.Auth{APIKey: "test-api-key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, rsp.Success)
+	assert.Equal(t, "123", rsp.ListID)
+	assert.Equal(t, []string{"mock-file-id"}, rsp.Uploaded)
+}
+
+// TestPD_UploadToList_KeepsTitle keeps the title of the existing list and uploads identical files once
+func TestPD_UploadToList_KeepsTitle(t *testing.T) {
+	var mu sync.Mutex
+	uploads := 0
+	var put struct {
+		Title string        `json:"title"`
+		Files []pd.ListFile `json:"files"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/list/abc":
+			_, _ = w.Write([]byte(`{"success": true, "id": "abc", "title": "Holiday", "files": [{"id": "old"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/file/old/info":
+			_, _ = w.Write([]byte(`{"success": true, "id": "old", "name": "old.jpg", "hash_sha256": "0000"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/user/files":
+			_, _ = w.Write([]byte(`{"files": []}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/file":
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "new"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/list/abc":
+			_ = json.NewDecoder(r.Body).Decode(&put)
+			_, _ = w.Write([]byte(`{"success": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	assert.NoError(t, os.WriteFile(a, []byte("same"), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte("same"), 0644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+	rsp, err := c.UploadToList(&pd.RequestUploadToList{
+		Paths:      []string{a, b},
+		ListID:     "abc",
+		Duplicates: pd.ListDuplicateSkip,
+		Auth:       pd.Auth{APIKey: "test-api-key"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, rsp.Success)
+	assert.Equal(t, []string{"new"}, rsp.Uploaded)
+	assert.Len(t, rsp.Skipped, 1)
+	assert.Equal(t, 1, uploads)
+
+	assert.Equal(t, "Holiday", put.Title)
+	assert.Equal(t, []pd.ListFile{{ID: "old"}, {ID: "new"}}, put.Files)
+}
+
+// TestPD_UploadToList_ListHashes the hashes come from the listing, the files without one are asked for at once
+func TestPD_UploadToList_ListHashes(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	assert.NoError(t, os.WriteFile(a, []byte("in the listing"), 0644))
+	assert.NoError(t, os.WriteFile(b, []byte("asked for"), 0644))
+	hashA, _ := utils.CalculateFileHash(a)
+	hashB, _ := utils.CalculateFileHash(b)
+
+	var mu sync.Mutex
+	var infoPaths []string
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/list/abc":
+			_, _ = w.Write([]byte(`{"success": true, "id": "abc", "files": [
+				{"id": "listed", "name": "a.txt", "hash_sha256": "` + hashA + `"}, {"id": "other"}, {"id": "b"}]}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/info"):
+			infoPaths = append(infoPaths, r.URL.Path)
+			_, _ = w.Write([]byte(`[{"id": "other", "hash_sha256": "0000"}, {"id": "b", "name": "b.txt", "hash_sha256": "` + hashB + `"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/file":
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"success": true, "id": "new"}`))
+		default:
+			_, _ = w.Write([]byte(`{"success": true}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+	rsp, err := c.UploadToList(&pd.RequestUploadToList{
+		Paths:      []string{a, b},
+		ListID:     "abc",
+		Duplicates: pd.ListDuplicateSkip,
+		Auth:       pd.Auth{APIKey: "test-api-key"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, rsp.Skipped, 2) {
+		assert.Equal(t, "listed", rsp.Skipped[0].ID)
+		assert.Equal(t, "b", rsp.Skipped[1].ID)
+	}
+	assert.Equal(t, 0, uploads)
+	assert.Equal(t, []string{"/file/other,b/info"}, infoPaths)
+}
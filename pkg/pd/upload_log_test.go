@@ -0,0 +1,37 @@
+package pd_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedLog []utils.UploadInfo
+
+func (l *recordedLog) Record(info utils.UploadInfo) error {
+	*l = append(*l, info)
+	return nil
+}
+
+func TestPD_UploadLog(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	uploads := &recordedLog{}
+	c := pd.New(&pd.ClientOptions{UploadLog: uploads, HashStore: utils.NewMemoryHashStore()}, nil)
+	_, err := c.UploadPOST(&pd.RequestUpload{
+		PathToFile: "testdata/cat.jpg",
+		Anonymous:  true,
+		URL:        server.URL + "/file",
+	}, filepath.Join(t.TempDir(), "hashes.csv"))
+	assert.NoError(t, err)
+
+	if assert.Len(t, *uploads, 1) {
+		assert.Equal(t, "mock-file-id", (*uploads)[0].ID)
+		assert.Equal(t, "testdata/cat.jpg", (*uploads)[0].DirectoryPath)
+		assert.NotEmpty(t, (*uploads)[0].Hash)
+	}
+}
@@ -0,0 +1,40 @@
+package pd
+
+import (
+	"errors"
+	"io"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// ErrMissingReader is returned by UploadStream without a reader
+const ErrMissingReader = "reader is required"
+
+// UploadStream uploads everything read from the reader until EOF, e.g. stdin of a pipe. The length doesn't have to be
+// known, the body is sent with chunked transfer encoding of a PUT request while it is read. Nothing is buffered, so a
+// failed upload is not retried. The reader is not closed, it belongs to the caller.
+func (pd *PixelDrainClient) UploadStream(r *RequestUploadStream) (*ResponseUpload, error) {
+	if r.Reader == nil {
+		return nil, errors.New(ErrMissingReader)
+	}
+	if r.FileName == "" {
+		return nil, errors.New(ErrMissingFilename)
+	}
+
+	reader := r.Reader
+	if r.Progress != nil {
+		total := r.Size
+		if total <= 0 {
+			total = -1
+		}
+		reader = utils.NewProgressReader(reader, total, r.Progress)
+	}
+
+	return pd.UploadPUT(&RequestUpload{
+		File:      io.NopCloser(reader),
+		FileName:  r.FileName,
+		Anonymous: r.Anonymous,
+		Auth:      r.Auth,
+		URL:       r.URL,
+	})
+}
@@ -0,0 +1,65 @@
+package pd_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// closeRecorder records whether the reader of the caller was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// TestPD_UploadStream sends a reader of unknown length with chunked transfer encoding
+func TestPD_UploadStream(t *testing.T) {
+	var received string
+	var chunked bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+		chunked = r.ContentLength == -1 && len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked"
+		assert.Equal(t, "/file/backup.tar", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "stream-id"}`))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(pw, "backup part ")
+		}
+		_ = pw.Close()
+	}()
+
+	stdin := &closeRecorder{Reader: pr}
+	rsp, err := c.UploadStream(&pd.RequestUploadStream{Reader: stdin, FileName: "backup.tar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "stream-id", rsp.ID)
+	assert.Equal(t, strings.Repeat("backup part ", 3), received)
+	assert.Equal(t, int64(len(received)), rsp.Size)
+	assert.True(t, chunked, "the body was not sent chunked")
+	assert.False(t, stdin.closed)
+
+	_, err = c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("x")})
+	assert.EqualError(t, err, pd.ErrMissingFilename)
+	_, err = c.UploadStream(&pd.RequestUploadStream{FileName: "x"})
+	assert.EqualError(t, err, pd.ErrMissingReader)
+}
@@ -0,0 +1,105 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+const (
+	ErrMissingSourceURL = "source url is required"
+	ErrSourceTooLarge   = "the source is larger than the MaxSize of the request"
+)
+
+// UploadFromURL streams a file from another server into a pixeldrain upload, nothing is written to disk. The source
+// is fetched with the SourceClient, which verifies TLS and doesn't send the credentials of the pixeldrain client, the
+// Header of the request is sent instead. A source larger than MaxSize is refused before the upload if it announces its size, otherwise the
+// upload is aborted once MaxSize is exceeded.
+func (pd *PixelDrainClient) UploadFromURL(r *RequestUploadFromURL) (*ResponseUpload, error) {
+	if r.SourceURL == "" {
+		return nil, errors.New(ErrMissingSourceURL)
+	}
+
+	srcReq, err := http.NewRequest(http.MethodGet, r.SourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range r.Header {
+		srcReq.Header[key] = values
+	}
+	if srcReq.Header.Get("User-Agent") == "" {
+		srcReq.Header.Set("User-Agent", pd.UserAgent())
+	}
+
+	src, err := pd.SourceClient.Do(srcReq)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Body.Close()
+
+	if src.StatusCode < 200 || src.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: %s", r.SourceURL, src.Status)
+	}
+	if r.MaxSize > 0 && src.ContentLength > r.MaxSize {
+		return nil, errors.New(ErrSourceTooLarge)
+	}
+
+	var body io.Reader = src.Body
+	if r.MaxSize > 0 {
+		body = &maxSizeReader{r: body, left: r.MaxSize}
+	}
+
+	fileName := r.FileName
+	if fileName == "" {
+		fileName = sourceFileName(src)
+	}
+
+	log.Printf("Uploading %s as %s", r.SourceURL, fileName)
+	return pd.UploadStream(&RequestUploadStream{
+		Reader:    body,
+		FileName:  fileName,
+		Size:      src.ContentLength,
+		Anonymous: r.Anonymous,
+		Auth:      r.Auth,
+		URL:       r.URL,
+		Progress:  r.Progress,
+	})
+}
+
+// sourceFileName is the file name of the Content-Disposition, the last element of the URL path or "download"
+func sourceFileName(rsp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(rsp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+
+	name := path.Base(rsp.Request.URL.Path)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	if name == "" || name == "/" || name == "." {
+		return "download"
+	}
+
+	return name
+}
+
+// maxSizeReader fails once more than left bytes were read
+type maxSizeReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	m.left -= int64(n)
+	if m.left < 0 {
+		return n, errors.New(ErrSourceTooLarge)
+	}
+
+	return n, err
+}
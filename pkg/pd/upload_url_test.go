@@ -0,0 +1,123 @@
+package pd_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// TestPD_UploadFromURL streams the file of another server into pixeldrain
+func TestPD_UploadFromURL(t *testing.T) {
+	content := bytes.Repeat([]byte("remote cat "), 1000)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer source-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		// without a Content-Length the size is only known at the end
+		if r.URL.Path != "/chunked" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+		_, _ = w.Write(content)
+	}))
+	defer source.Close()
+
+	var mu sync.Mutex
+	var uploadPath string
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		uploadPath = r.URL.Path
+		stored, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "remote-id"}`))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+	header := http.Header{"Authorization": {"Bearer source-token"}}
+
+	// the progress has its own lock, the server holds mu while the body is still being sent
+	var progressMu sync.Mutex
+	var last utils.Progress
+	rsp, err := c.UploadFromURL(&pd.RequestUploadFromURL{
+		SourceURL: source.URL + "/files/remote%20cat.txt",
+		Header:    header,
+		Anonymous: true,
+		Progress: func(p utils.Progress) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			last = p
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "remote-id", rsp.ID)
+	assert.Equal(t, int64(len(content)), rsp.Size)
+	mu.Lock()
+	assert.Equal(t, "/file/remote cat.txt", uploadPath)
+	assert.Equal(t, content, stored)
+	mu.Unlock()
+	progressMu.Lock()
+	assert.Equal(t, int64(len(content)), last.Total)
+	assert.Equal(t, int64(len(content)), last.Transferred)
+	progressMu.Unlock()
+
+	// refused by its Content-Length before anything is uploaded
+	_, err = c.UploadFromURL(&pd.RequestUploadFromURL{SourceURL: source.URL + "/large", Header: header, MaxSize: 100})
+	assert.EqualError(t, err, pd.ErrSourceTooLarge)
+
+	// aborted once it gets larger
+	_, err = c.UploadFromURL(&pd.RequestUploadFromURL{SourceURL: source.URL + "/chunked", Header: header, MaxSize: 100})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), pd.ErrSourceTooLarge)
+	}
+
+	_, err = c.UploadFromURL(&pd.RequestUploadFromURL{SourceURL: source.URL + "/private"})
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasSuffix(err.Error(), "403 Forbidden"), err.Error())
+	}
+
+	_, err = c.UploadFromURL(&pd.RequestUploadFromURL{})
+	assert.EqualError(t, err, pd.ErrMissingSourceURL)
+}
+
+// TestPD_UploadFromURL_VerifiesTLS the source is fetched with a verified certificate even with EnableInsecureTLS
+func TestPD_UploadFromURL_VerifiesTLS(t *testing.T) {
+	source := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("meow"))
+	}))
+	defer source.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "remote-id"}`))
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, EnableInsecureTLS: true, DisableUploadLog: true, DisableDedup: true}, nil)
+	_, err := c.UploadFromURL(&pd.RequestUploadFromURL{SourceURL: source.URL + "/cat.txt", Anonymous: true})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "certificate")
+	}
+
+	// a client which trusts the certificate of the source
+	c = pd.New(&pd.ClientOptions{API: &spec, SourceClient: source.Client(), DisableUploadLog: true, DisableDedup: true}, nil)
+	rsp, err := c.UploadFromURL(&pd.RequestUploadFromURL{SourceURL: source.URL + "/cat.txt", Anonymous: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "remote-id", rsp.ID)
+}
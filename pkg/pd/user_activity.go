@@ -0,0 +1,73 @@
+package pd
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetUserActivity GET /api/user/activity
+// the file events of the account, e.g. the files which were deleted because they expired
+// curl -i -H "Authorization: Basic <TOKEN>" https://pixeldrain.com/api/user/activity?page=0
+func (pd *PixelDrainClient) GetUserActivity(r *RequestGetUserActivity) (*ResponseGetUserActivity, error) {
+	if r.URL == "" {
+		query := url.Values{}
+		if r.Page > 0 {
+			query.Set("page", strconv.Itoa(r.Page))
+		}
+		r.URL = withQuery(pd.API.URL+pd.API.User+"/activity", query)
+	}
+
+	rspStruct := &ResponseGetUserActivity{}
+	if err := pd.getUserArray(r.URL, r.Auth, &rspStruct.Events, &rspStruct.ResponseDefault); err != nil {
+		return nil, err
+	}
+
+	return rspStruct, nil
+}
+
+// GetUserTransactions GET /api/user/transactions
+// the charges of the account per billing period, the storage and bandwidth used and what they cost
+// curl -i -H "Authorization: Basic <TOKEN>" https://pixeldrain.com/api/user/transactions
+func (pd *PixelDrainClient) GetUserTransactions(r *RequestGetUserTransactions) (*ResponseGetUserTransactions, error) {
+	if r.URL == "" {
+		r.URL = pd.API.URL + pd.API.User + "/transactions"
+	}
+
+	rspStruct := &ResponseGetUserTransactions{}
+	if err := pd.getUserArray(r.URL, r.Auth, &rspStruct.Transactions, &rspStruct.ResponseDefault); err != nil {
+		return nil, err
+	}
+
+	return rspStruct, nil
+}
+
+// getUserArray decodes the JSON array the user endpoint answers with into v
+func (pd *PixelDrainClient) getUserArray(url string, auth Auth, v interface{}, status *ResponseDefault) error {
+	rsp, err := pd.request(context.Background(), http.MethodGet, url, pd.header(auth), nil)
+	if pd.Debug {
+		log.Println(rsp.dump())
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := rsp.readBody()
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode >= 400 {
+		return newAPIError(rsp.StatusCode, data)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	status.StatusCode = rsp.StatusCode
+	status.Success = true
+
+	return nil
+}
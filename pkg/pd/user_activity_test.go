@@ -0,0 +1,60 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+)
+
+// TestPD_GetUserActivity decodes the events and the transactions of the account
+func TestPD_GetUserActivity(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/activity":
+			query = r.URL.RawQuery
+			_, _ = w.Write([]byte(`[{"time": "2024-03-01T10:00:00Z", "event": "file_deleted", "file_id": "abc", "file_name": "cat.jpg"}]`))
+		case "/user/transactions":
+			_, _ = w.Write([]byte(`[
+				{"time": "2024-02-01T00:00:00Z", "new_balance": 900000, "storage_charge": 60000, "storage_used": 1000, "bandwidth_charge": 40000, "bandwidth_used": 5000},
+				{"time": "2024-03-01T00:00:00Z", "new_balance": 850000, "storage_charge": 30000, "storage_used": 500, "bandwidth_charge": 20000, "bandwidth_used": 2000}]`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success": false, "value": "authentication_required", "message": "log in"}`))
+		}
+	}))
+	defer server.Close()
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec}, nil)
+	auth := pd.Auth{APIKey: "key"}
+
+	activity, err := c.GetUserActivity(&pd.RequestGetUserActivity{Page: 2, Auth: auth})
+	assert.NoError(t, err)
+	assert.True(t, activity.Success)
+	assert.Equal(t, "page=2", query)
+	if assert.Len(t, activity.Events, 1) {
+		assert.Equal(t, "file_deleted", activity.Events[0].Event)
+		assert.Equal(t, "cat.jpg", activity.Events[0].FileName)
+	}
+
+	transactions, err := c.GetUserTransactions(&pd.RequestGetUserTransactions{Auth: auth})
+	assert.NoError(t, err)
+	assert.Len(t, transactions.Transactions, 2)
+	assert.Equal(t, int64(100000), transactions.Transactions[0].Charged())
+	total := transactions.Total()
+	assert.Equal(t, int64(150000), total.Charged())
+	assert.Equal(t, int64(7000), total.BandwidthUsed)
+	assert.Equal(t, int64(850000), total.NewBalance)
+
+	_, err = c.GetUserTransactions(&pd.RequestGetUserTransactions{Auth: auth, URL: server.URL + "/unknown"})
+	var apiErr *pd.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "authentication_required", apiErr.Value)
+	}
+}
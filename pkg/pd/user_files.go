@@ -0,0 +1,138 @@
+package pd
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UserFilesSort is the order of the files of GetUserFiles
+type UserFilesSort string
+
+const (
+	SortByName       UserFilesSort = "name"
+	SortBySize       UserFilesSort = "size"
+	SortByDateUpload UserFilesSort = "date_upload"
+	SortByViews      UserFilesSort = "views"
+	SortByDownloads  UserFilesSort = "downloads"
+)
+
+// FileFilter selects files of the account on the client side, every condition that is set must match
+type FileFilter struct {
+	Name           string    // glob for the file name, e.g. "*.jpg"
+	MimeType       string    // glob for the MIME type, e.g. "image/*"
+	UploadedAfter  time.Time // zero means no lower limit
+	UploadedBefore time.Time // zero means no upper limit
+	MinSize        int64     // minimum size in bytes, 0 means no lower limit
+	MaxSize        int64     // maximum size in bytes, 0 means no upper limit
+}
+
+// Matches checks the file against the filter, a nil filter matches all files
+func (f *FileFilter) Matches(file FileGetUser) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Name != "" {
+		if ok, _ := path.Match(f.Name, file.Name); !ok {
+			return false
+		}
+	}
+
+	if f.MimeType != "" {
+		if ok, _ := path.Match(f.MimeType, file.MimeType); !ok {
+			return false
+		}
+	}
+
+	if !f.UploadedAfter.IsZero() && file.DateUpload.Before(f.UploadedAfter) {
+		return false
+	}
+
+	if !f.UploadedBefore.IsZero() && !file.DateUpload.Before(f.UploadedBefore) {
+		return false
+	}
+
+	if f.MinSize > 0 && file.Size < f.MinSize {
+		return false
+	}
+
+	if f.MaxSize > 0 && file.Size > f.MaxSize {
+		return false
+	}
+
+	return true
+}
+
+// query returns the sort parameters of the request. pixeldrain returns all files of the account in one response,
+// so Page and Limit are not sent, the page is cut out by page.
+func (r *RequestGetUserFiles) query() url.Values {
+	query := url.Values{}
+	if r.Sort != "" {
+		query.Set("sort", string(r.Sort))
+		if r.Descending {
+			query.Set("order", "desc")
+		}
+	}
+
+	return query
+}
+
+// page sorts and filters all files of the account and cuts out the page, so every page but the last one has Limit
+// files of the filter
+func (r *RequestGetUserFiles) page(rsp *ResponseGetUserFiles) {
+	if r.Sort != "" {
+		sortUserFiles(rsp.Files, r.Sort, r.Descending)
+	}
+
+	if r.Filter != nil {
+		files := rsp.Files[:0:0]
+		for _, file := range rsp.Files {
+			if r.Filter.Matches(file) {
+				files = append(files, file)
+			}
+		}
+		rsp.Files = files
+	}
+
+	if r.Limit > 0 {
+		start := r.Page * r.Limit
+		if start > len(rsp.Files) {
+			start = len(rsp.Files)
+		}
+		end := start + r.Limit
+		if end > len(rsp.Files) {
+			end = len(rsp.Files)
+		}
+		rsp.HasMore = end < len(rsp.Files)
+		rsp.Files = rsp.Files[start:end]
+	}
+}
+
+// sortUserFiles sorts the files stable by the field, names are compared case-insensitive
+func sortUserFiles(files []FileGetUser, by UserFilesSort, descending bool) {
+	less := func(a, b FileGetUser) bool {
+		switch by {
+		case SortByName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case SortBySize:
+			return a.Size < b.Size
+		case SortByDateUpload:
+			return a.DateUpload.Before(b.DateUpload)
+		case SortByViews:
+			return a.Views < b.Views
+		case SortByDownloads:
+			return a.Downloads < b.Downloads
+		}
+		return false
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if descending {
+			return less(files[j], files[i])
+		}
+		return less(files[i], files[j])
+	})
+}
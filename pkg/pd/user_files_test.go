@@ -0,0 +1,123 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/stretchr/testify/assert"
+)
+
+// userFilesServer returns the files of the account, pixeldrain ignores the pagination parameters
+func userFilesServer(files []pd.FileGetUser, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+	}))
+}
+
+func testUserFiles() []pd.FileGetUser {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var files []pd.FileGetUser
+	for i, name := range []string{"e.jpg", "b.png", "d.txt", "a.jpg", "c.mp4"} {
+		mimeType := map[string]string{"jpg": "image/jpeg", "png": "image/png", "txt": "text/plain", "mp4": "video/mp4"}[name[2:]]
+		files = append(files, pd.FileGetUser{
+			ID:         fmt.Sprintf("id-%s", name[:1]),
+			Name:       name,
+			Size:       int64(100 * (i + 1)),
+			MimeType:   mimeType,
+			DateUpload: day.AddDate(0, 0, i),
+		})
+	}
+
+	return files
+}
+
+func ids(files []pd.FileGetUser) []string {
+	var result []string
+	for _, file := range files {
+		result = append(result, file.ID)
+	}
+
+	return result
+}
+
+func TestPD_GetUserFiles_Pages(t *testing.T) {
+	var requests int32
+	server := userFilesServer(testUserFiles(), &requests)
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	var pages [][]string
+	for page := 0; ; page++ {
+		rsp, err := c.GetUserFiles(&pd.RequestGetUserFiles{URL: server.URL, Page: page, Limit: 2, Sort: pd.SortByName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(rsp.Files))
+		if !rsp.HasMore {
+			break
+		}
+	}
+
+	assert.Equal(t, [][]string{{"id-a", "id-b"}, {"id-c", "id-d"}, {"id-e"}}, pages)
+}
+
+// TestPD_GetUserFiles_FilterPages filters before the page is cut out, so the pages are full
+func TestPD_GetUserFiles_FilterPages(t *testing.T) {
+	var requests int32
+	server := userFilesServer(testUserFiles(), &requests)
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	filter := &pd.FileFilter{MimeType: "image/*"}
+	var pages [][]string
+	for page := 0; ; page++ {
+		rsp, err := c.GetUserFiles(&pd.RequestGetUserFiles{URL: server.URL, Page: page, Limit: 2, Sort: pd.SortByName, Filter: filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(rsp.Files))
+		if !rsp.HasMore {
+			break
+		}
+	}
+
+	assert.Equal(t, [][]string{{"id-a", "id-b"}, {"id-e"}}, pages)
+}
+
+func TestPD_GetUserFiles_Filter(t *testing.T) {
+	var requests int32
+	server := userFilesServer(testUserFiles(), &requests)
+	defer server.Close()
+
+	c := pd.New(nil, nil)
+	tests := []struct {
+		filter *pd.FileFilter
+		want   []string
+	}{
+		{&pd.FileFilter{Name: "*.jpg"}, []string{"id-e", "id-a"}},
+		{&pd.FileFilter{MimeType: "image/*", MinSize: 200}, []string{"id-b", "id-a"}},
+		{&pd.FileFilter{UploadedAfter: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), UploadedBefore: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)}, []string{"id-b", "id-d"}},
+		{&pd.FileFilter{MaxSize: 200}, []string{"id-e", "id-b"}},
+	}
+
+	for _, tt := range tests {
+		rsp, err := c.GetUserFiles(&pd.RequestGetUserFiles{URL: server.URL, Filter: tt.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tt.want, ids(rsp.Files))
+	}
+
+	rsp, err := c.GetUserFiles(&pd.RequestGetUserFiles{URL: server.URL, Sort: pd.SortBySize, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"id-c", "id-a", "id-d", "id-b", "id-e"}, ids(rsp.Files))
+}
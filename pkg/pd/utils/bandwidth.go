@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Transfer directions of the BandwidthLedger
+const (
+	Upload   = "upload"
+	Download = "download"
+)
+
+// BandwidthUsage the bytes transferred in the current day and month.
+type BandwidthUsage struct {
+	DayUpload     int64 `json:"day_upload"`
+	DayDownload   int64 `json:"day_download"`
+	MonthUpload   int64 `json:"month_upload"`
+	MonthDownload int64 `json:"month_download"`
+}
+
+type bandwidthCounter struct {
+	Upload   int64 `json:"upload"`
+	Download int64 `json:"download"`
+}
+
+type bandwidthFile struct {
+	Days   map[string]bandwidthCounter `json:"days"`   // by "2006-01-02"
+	Months map[string]bandwidthCounter `json:"months"` // by "2006-01"
+}
+
+// BandwidthLedger counts the transferred bytes per day and month in a JSON file, so the counters survive restarts.
+// Processes sharing the file count into the same ledger, every update holds the lock of the file.
+type BandwidthLedger struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewBandwidthLedger returns a ledger stored at the given path.
+func NewBandwidthLedger(path string) *BandwidthLedger {
+	return &BandwidthLedger{Path: path}
+}
+
+// Add counts n bytes in the given direction at time t.
+func (l *BandwidthLedger) Add(direction string, n int64, t time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	unlock, err := LockFile(l.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := l.load()
+	if err != nil {
+		return err
+	}
+
+	day, month := t.Format("2006-01-02"), t.Format("2006-01")
+	dayCounter, monthCounter := data.Days[day], data.Months[month]
+	switch direction {
+	case Upload:
+		dayCounter.Upload += n
+		monthCounter.Upload += n
+	case Download:
+		dayCounter.Download += n
+		monthCounter.Download += n
+	}
+	data.Days[day], data.Months[month] = dayCounter, monthCounter
+
+	// only the current month is needed, older days are dropped
+	for key := range data.Days {
+		if key[:7] != month {
+			delete(data.Days, key)
+		}
+	}
+
+	return l.save(data)
+}
+
+// Usage returns the counters of the day and month of t.
+func (l *BandwidthLedger) Usage(t time.Time) (BandwidthUsage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	unlock, err := LockFile(l.Path)
+	if err != nil {
+		return BandwidthUsage{}, err
+	}
+	defer unlock()
+
+	data, err := l.load()
+	if err != nil {
+		return BandwidthUsage{}, err
+	}
+
+	day, month := data.Days[t.Format("2006-01-02")], data.Months[t.Format("2006-01")]
+	return BandwidthUsage{
+		DayUpload:     day.Upload,
+		DayDownload:   day.Download,
+		MonthUpload:   month.Upload,
+		MonthDownload: month.Download,
+	}, nil
+}
+
+func (l *BandwidthLedger) load() (*bandwidthFile, error) {
+	data := &bandwidthFile{}
+	raw, err := os.ReadFile(l.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, data); err != nil {
+			return nil, err
+		}
+	}
+	if data.Days == nil {
+		data.Days = map[string]bandwidthCounter{}
+	}
+	if data.Months == nil {
+		data.Months = map[string]bandwidthCounter{}
+	}
+
+	return data, nil
+}
+
+// save writes to a temporary file and renames it, so a crash never leaves a half written ledger
+func (l *BandwidthLedger) save(data *bandwidthFile) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(l.Path), "."+filepath.Base(l.Path)+".tmp")
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, l.Path)
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLedger(t *testing.T) {
+	ledger := NewBandwidthLedger(filepath.Join(t.TempDir(), "bandwidth.json"))
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	_ = ledger.Add(Upload, 100, day)
+	_ = ledger.Add(Download, 40, day)
+	_ = ledger.Add(Upload, 50, day.AddDate(0, 0, 1))
+
+	usage, err := NewBandwidthLedger(ledger.Path).Usage(day)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := BandwidthUsage{DayUpload: 100, DayDownload: 40, MonthUpload: 150, MonthDownload: 40}
+	if usage != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, usage)
+	}
+}
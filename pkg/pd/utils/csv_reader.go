@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ErrStopIteration returned by the callback of EachUploadInfo ends the iteration without an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// LoadUploadInfos reads the upload log written by SaveUploadInfoToCSV.
+// Rows of older versions without the error, hash, exact size and ID columns are read with these fields empty.
+func LoadUploadInfos(filePath string) ([]UploadInfo, error) {
+	var infos []UploadInfo
+	err := EachUploadInfo(filePath, func(info UploadInfo) error {
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// EachUploadInfo calls fn for every row of the upload log, one row is read at a time so large logs aren't loaded
+// into memory. An error of fn ends the iteration and is returned, except ErrStopIteration.
+func EachUploadInfo(filePath string, fn func(UploadInfo) error) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(parseUploadInfo(record)); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func parseUploadInfo(record []string) UploadInfo {
+	field := func(i int) string {
+		if i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	info := UploadInfo{
+		FileName:       field(0),
+		DirectoryPath:  field(1),
+		URL:            field(2),
+		UploadDateTime: field(3),
+		FormattedSize:  field(4),
+		MIMEType:       field(5),
+		Uploader:       field(6),
+		UploadStatus:   field(7),
+		ErrorValue:     field(8),
+		ErrorMessage:   field(9),
+		Hash:           field(10),
+		ID:             field(12),
+		Transforms:     field(13),
+	}
+	info.FileSize, _ = strconv.ParseInt(field(11), 10, 64)
+	info.StoredSize, _ = strconv.ParseInt(field(14), 10, 64)
+
+	return info
+}
@@ -3,23 +3,39 @@ package utils
 import (
 	"encoding/csv"
 	"os"
+	"strconv"
 )
 
 // UploadInfo holds the information about the uploaded file.
 type UploadInfo struct {
-	FileName       string `csv:"file_name"`
-	DirectoryPath  string `csv:"directory_path"`
-	URL            string `csv:"url"`
-	UploadDateTime string `csv:"upload_date_time"`
-	FileSize       int64  `csv:"file_size"`
-	FormattedSize  string `csv:"formatted_size"`
-	MIMEType       string `csv:"mime_type"`
-	Uploader       string `csv:"uploader"`
-	UploadStatus   string `csv:"upload_status"`
+	FileName       string `csv:"file_name" json:"file_name"`
+	DirectoryPath  string `csv:"directory_path" json:"directory_path"`
+	URL            string `csv:"url" json:"url"`
+	UploadDateTime string `csv:"upload_date_time" json:"upload_date_time"`
+	FileSize       int64  `csv:"file_size" json:"file_size"`
+	FormattedSize  string `csv:"formatted_size" json:"formatted_size"`
+	MIMEType       string `csv:"mime_type" json:"mime_type"`
+	Uploader       string `csv:"uploader" json:"uploader"`
+	UploadStatus   string `csv:"upload_status" json:"upload_status"`
+	ErrorValue     string `csv:"error_value" json:"error_value,omitempty"`     // error value of a rejected upload, e.g. "file_too_large"
+	ErrorMessage   string `csv:"error_message" json:"error_message,omitempty"` // error message of a rejected upload as sent by pixeldrain
+	Hash           string `csv:"hash" json:"hash"`                             // SHA-256 of the uploaded file
+	ID             string `csv:"id" json:"id"`                                 // pixeldrain ID of the uploaded file
+	// Transforms applied before the upload, e.g. "gzip+aes-gcm". FileName, FileSize and Hash are of the original
+	// file, StoredSize is the size of the transformed file on pixeldrain.
+	Transforms string `csv:"transforms" json:"transforms,omitempty"`
+	StoredSize int64  `csv:"stored_size" json:"stored_size,omitempty"`
 }
 
 // SaveUploadInfoToCSV saves the upload information to a CSV file.
+// The row is appended under the lock of the file, so rows of concurrent processes are not interleaved.
 func SaveUploadInfoToCSV(info UploadInfo, filePath string) error {
+	unlock, err := LockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
@@ -38,6 +54,13 @@ func SaveUploadInfoToCSV(info UploadInfo, filePath string) error {
 		info.MIMEType,
 		info.Uploader,
 		info.UploadStatus,
+		info.ErrorValue,
+		info.ErrorMessage,
+		info.Hash,
+		strconv.FormatInt(info.FileSize, 10), // the exact size, the size column above is formatted
+		info.ID,
+		info.Transforms,
+		strconv.FormatInt(info.StoredSize, 10),
 	}
 
 	return writer.Write(record)
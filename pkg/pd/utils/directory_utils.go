@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -25,3 +26,51 @@ func GetFilesInDirectory(dirPath string) ([]string, error) {
 	}
 	return files, nil
 }
+
+// DirectoryEntry is a file found by ScanDirectory, symbolic links to files are followed
+type DirectoryEntry struct {
+	Path string
+	Mode os.FileMode
+	Size int64
+	Err  error // the file, or the directory at Path and all below it, could not be read
+}
+
+// ScanDirectory recursively collects the files of a directory like GetFilesInDirectory, but keeps going past
+// entries which can't be read and returns them with their error, so the caller decides what to do with them.
+func ScanDirectory(dirPath string) ([]DirectoryEntry, error) {
+	var entries []DirectoryEntry
+
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dirPath {
+				return err
+			}
+			entries = append(entries, DirectoryEntry{Path: path, Err: err})
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			entries = append(entries, DirectoryEntry{Path: path, Err: err})
+			return nil
+		}
+		// links to directories are not walked, they could form a loop
+		if info.IsDir() {
+			return nil
+		}
+		log.Printf("Found file: %s", path)
+		entries = append(entries, DirectoryEntry{Path: path, Mode: info.Mode(), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// FileIndexEntry is the state of a local file at its last upload.
+type FileIndexEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Hash    string
+	ID      string // remote ID of the upload
+}
+
+// Unchanged checks size and modification time, which is enough to skip hashing an untouched file.
+func (e FileIndexEntry) Unchanged(info os.FileInfo) bool {
+	return e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// FileIndex maps local paths to their last uploaded state, stored as CSV with
+// "path,size,mtime,hash,id" rows.
+type FileIndex struct {
+	Path    string     // key of the index in the store
+	Store   StateStore // a file at Path if nil
+	entries map[string]FileIndexEntry
+}
+
+// LoadFileIndex reads the index at the given path, a missing file is an empty index.
+func LoadFileIndex(path string) (*FileIndex, error) {
+	return LoadFileIndexFrom(nil, path)
+}
+
+// LoadFileIndexFrom reads the index stored with the key, a missing key is an empty index.
+func LoadFileIndexFrom(store StateStore, key string) (*FileIndex, error) {
+	index := &FileIndex{Path: key, Store: store, entries: map[string]FileIndexEntry{}}
+
+	data, found, err := index.store().Get(key)
+	if err != nil || !found {
+		return index, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = 5
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		size, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		modTime, err := time.Parse(time.RFC3339Nano, record[2])
+		if err != nil {
+			return nil, err
+		}
+
+		index.entries[record[0]] = FileIndexEntry{
+			Path:    record[0],
+			Size:    size,
+			ModTime: modTime,
+			Hash:    record[3],
+			ID:      record[4],
+		}
+	}
+
+	return index, nil
+}
+
+func (i *FileIndex) store() StateStore {
+	if i.Store == nil {
+		return FileStateStore{}
+	}
+
+	return i.Store
+}
+
+// Get returns the entry of the local path.
+func (i *FileIndex) Get(path string) (FileIndexEntry, bool) {
+	entry, ok := i.entries[path]
+	return entry, ok
+}
+
+// Set adds or replaces the entry of its path.
+func (i *FileIndex) Set(entry FileIndexEntry) {
+	i.entries[entry.Path] = entry
+}
+
+// Delete removes the entry of the path.
+func (i *FileIndex) Delete(path string) {
+	delete(i.entries, path)
+}
+
+// Paths returns the paths of all entries in sorted order.
+func (i *FileIndex) Paths() []string {
+	paths := make([]string, 0, len(i.entries))
+	for path := range i.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// Save writes the index, a file is written to a temporary file and renamed, so a crash never leaves a half written index.
+func (i *FileIndex) Save() error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	for _, path := range i.Paths() {
+		entry := i.entries[path]
+		record := []string{
+			entry.Path,
+			strconv.FormatInt(entry.Size, 10),
+			entry.ModTime.Format(time.RFC3339Nano),
+			entry.Hash,
+			entry.ID,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	return i.store().Put(i.Path, buf.Bytes())
+}
@@ -0,0 +1,22 @@
+package utils
+
+// LockSuffix is appended to the path of a shared file to name its lock file.
+const LockSuffix = ".lock"
+
+// LockFile takes the exclusive lock of the shared file at path, which serializes all goroutines and processes
+// using the file, e.g. a CLI upload and a daemon writing the same hash store. It blocks until the lock is free
+// and returns the function releasing it.
+func LockFile(path string) (unlock func(), err error) {
+	return lockFile(path + LockSuffix)
+}
+
+// WithFileLock calls fn while holding the lock of the shared file at path.
+func WithFileLock(path string, fn func() error) error {
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return fn()
+}
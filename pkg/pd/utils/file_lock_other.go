@@ -0,0 +1,34 @@
+//go:build !unix
+
+package utils
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// staleLockAge a lock file older than this was left behind by a crashed process
+	staleLockAge  = time.Minute
+	lockPollDelay = 10 * time.Millisecond
+)
+
+// lockFile creates the lock file exclusively, flock is not available on these systems
+func lockFile(path string) (func(), error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(lockPollDelay)
+	}
+}
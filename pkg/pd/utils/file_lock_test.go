@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSaveFileHash_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.csv")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every entry is saved twice, the locked check keeps it once
+			if err := SaveFileHash(path, fmt.Sprintf("file%d", i%10), fmt.Sprintf("hash%d", i%10)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	rows := 0
+	if err := eachFileHash(path, func(string, string, string) { rows++ }); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 10 {
+		t.Fatalf("expected 10 stored hashes, got %d", rows)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload_rate.json")
+	a := NewSharedRateLimiter(1000, path)
+	b := NewSharedRateLimiter(1000, path)
+
+	// both limiters take from one bucket of 1000 bytes, the second half waits for the refill
+	start := time.Now()
+	a.Wait(1000)
+	b.Wait(500)
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("expected the shared bucket to throttle, took %v", elapsed)
+	}
+}
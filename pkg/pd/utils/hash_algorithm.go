@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"lukechampine.com/blake3"
+)
+
+// HashAlgorithm names the hash of the duplicate check. Hashes of other algorithms than SHA-256 are stored with
+// the algorithm as prefix, e.g. "md5:9e107d9d372bb6826bd81d3542a419d6", so every row of a store records its
+// algorithm and the bare SHA-256 rows of older stores keep matching.
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256" // the default, the hash pixeldrain stores
+	HashBLAKE3 HashAlgorithm = "blake3" // 256 bit BLAKE3, faster than SHA-256 on large files
+	HashMD5    HashAlgorithm = "md5"    // to match the hashes of legacy tools
+)
+
+var (
+	hashAlgorithmsMu sync.RWMutex
+	hashAlgorithms   = map[HashAlgorithm]func() hash.Hash{
+		HashSHA256: sha256.New,
+		HashBLAKE3: func() hash.Hash { return blake3.New(32, nil) },
+		HashMD5:    md5.New,
+	}
+)
+
+// RegisterHashAlgorithm makes another algorithm available or replaces the implementation of a built-in one
+func RegisterHashAlgorithm(algorithm HashAlgorithm, newHash func() hash.Hash) {
+	hashAlgorithmsMu.Lock()
+	defer hashAlgorithmsMu.Unlock()
+	hashAlgorithms[algorithm] = newHash
+}
+
+// orDefault returns SHA-256 for the empty algorithm
+func (a HashAlgorithm) orDefault() HashAlgorithm {
+	if a == "" {
+		return HashSHA256
+	}
+
+	return a
+}
+
+// New returns a hash of the algorithm, an algorithm which is not registered fails
+func (a HashAlgorithm) New() (hash.Hash, error) {
+	hashAlgorithmsMu.RLock()
+	newHash, ok := hashAlgorithms[a.orDefault()]
+	hashAlgorithmsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("hash algorithm %s is not registered", a)
+	}
+
+	return newHash(), nil
+}
+
+// Label returns the hex sum as it is stored, with the algorithm as prefix unless it is SHA-256
+func (a HashAlgorithm) Label(sum string) string {
+	if a.orDefault() == HashSHA256 {
+		return sum
+	}
+
+	return string(a) + ":" + sum
+}
+
+// ParseHash splits a stored hash into its algorithm and hex sum, a hash without prefix is SHA-256
+func ParseHash(hash string) (HashAlgorithm, string) {
+	if algorithm, sum, ok := strings.Cut(hash, ":"); ok {
+		return HashAlgorithm(algorithm), sum
+	}
+
+	return HashSHA256, hash
+}
+
+// CalculateFileHashWith hashes the file with the algorithm and returns the labeled hash
+func CalculateFileHashWith(filePath string, algorithm HashAlgorithm) (string, error) {
+	h, err := algorithm.New()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return algorithm.Label(hex.EncodeToString(h.Sum(nil))), nil
+}
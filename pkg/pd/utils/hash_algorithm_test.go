@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCalculateFileHashWith(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fox.txt")
+	if err := os.WriteFile(path, []byte("The quick brown fox jumps over the lazy dog"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		algorithm HashAlgorithm
+		hash      string
+	}{
+		{"", "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592"},
+		{HashSHA256, "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592"},
+		{HashMD5, "md5:9e107d9d372bb6826bd81d3542a419d6"},
+		{HashBLAKE3, "blake3:2f1514181aadccd913abd94cfa592701a5686ab23f8df1dff1b74710febc6d4a"},
+	}
+	for _, tt := range tests {
+		hash, err := CalculateFileHashWith(path, tt.algorithm)
+		if err != nil || hash != tt.hash {
+			t.Errorf("%q: expected %s, got %s (%v)", tt.algorithm, tt.hash, hash, err)
+		}
+		if algorithm, _ := ParseHash(hash); algorithm != tt.algorithm.orDefault() {
+			t.Errorf("%q: parsed the algorithm %s", tt.algorithm, algorithm)
+		}
+	}
+
+	// other algorithms are available once registered
+	if _, err := CalculateFileHashWith(path, "sha1"); err == nil {
+		t.Error("Expected an error for the unregistered SHA-1")
+	}
+	RegisterHashAlgorithm("sha1", sha1.New)
+	hash, err := CalculateFileHashWith(path, "sha1")
+	if err != nil || hash != "sha1:2fd4e1c67a2d28fced849ee1bb76e7391b93eb12" {
+		t.Errorf("Expected the SHA-1 of the registered algorithm, got %s (%v)", hash, err)
+	}
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HashCache remembers the hash of a file with its size and modification time, so the duplicate check of an
+// unchanged file doesn't read it again. A file whose size or modification time changed is hashed again.
+type HashCache interface {
+	Lookup(path string, size int64, modTime time.Time) (hash string, found bool, err error)
+	Record(path string, size int64, modTime time.Time, hash string) error
+}
+
+type hashCacheEntry struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+func (e hashCacheEntry) matches(size int64, modTime time.Time) bool {
+	return e.size == size && e.modTime.Equal(modTime)
+}
+
+// MemoryHashCache is a HashCache for the lifetime of the process
+type MemoryHashCache struct {
+	mu      sync.Mutex
+	entries map[string]hashCacheEntry
+}
+
+// NewMemoryHashCache returns an empty cache
+func NewMemoryHashCache() *MemoryHashCache {
+	return &MemoryHashCache{entries: map[string]hashCacheEntry{}}
+}
+
+// Lookup returns the hash of the path if its size and modification time are unchanged
+func (c *MemoryHashCache) Lookup(path string, size int64, modTime time.Time) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || !entry.matches(size, modTime) {
+		return "", false, nil
+	}
+
+	return entry.hash, true, nil
+}
+
+// Record stores the hash of the path
+func (c *MemoryHashCache) Record(path string, size int64, modTime time.Time, hash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[path] = hashCacheEntry{size: size, modTime: modTime, hash: hash}
+	return nil
+}
+
+// FileHashCache is a HashCache backed by an append-only file with "size mtime hash path" lines, the path is
+// quoted. The file is read once, every record is appended, the latest line of a path wins.
+type FileHashCache struct {
+	Path    string
+	mu      sync.Mutex
+	entries map[string]hashCacheEntry
+}
+
+// NewFileHashCache returns a HashCache using the file at the given path
+func NewFileHashCache(path string) *FileHashCache {
+	return &FileHashCache{Path: path}
+}
+
+// load reads the file on the first use, a torn or unreadable line is ignored
+func (c *FileHashCache) load() error {
+	if c.entries != nil {
+		return nil
+	}
+
+	entries := map[string]hashCacheEntry{}
+	file, err := os.Open(c.Path)
+	if os.IsNotExist(err) {
+		c.entries = entries
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		size, errSize := strconv.ParseInt(fields[0], 10, 64)
+		nanos, errTime := strconv.ParseInt(fields[1], 10, 64)
+		path, errPath := strconv.Unquote(fields[3])
+		if errSize != nil || errTime != nil || errPath != nil {
+			continue
+		}
+		entries[path] = hashCacheEntry{size: size, modTime: time.Unix(0, nanos), hash: fields[2]}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return err
+	}
+
+	c.entries = entries
+	return nil
+}
+
+// Lookup returns the hash of the path if its size and modification time are unchanged
+func (c *FileHashCache) Lookup(path string, size int64, modTime time.Time) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return "", false, err
+	}
+
+	entry, ok := c.entries[path]
+	if !ok || !entry.matches(size, modTime) {
+		return "", false, nil
+	}
+
+	return entry.hash, true, nil
+}
+
+// Record appends the hash of the path, an unchanged entry is not written again
+func (c *FileHashCache) Record(path string, size int64, modTime time.Time, hash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return err
+	}
+	entry := hashCacheEntry{size: size, modTime: modTime, hash: hash}
+	if old, ok := c.entries[path]; ok && old.matches(size, modTime) && old.hash == hash {
+		return nil
+	}
+
+	unlock, err := LockFile(c.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(c.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%d %d %s %s\n", size, modTime.UnixNano(), hash, strconv.Quote(path))
+	if _, err := file.WriteString(line); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	c.entries[path] = entry
+	return nil
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileHashCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.cache")
+	cache := NewFileHashCache(path)
+	modTime := time.Date(2024, 3, 1, 10, 0, 0, 123, time.UTC)
+	file := "/data/my cat.jpg"
+
+	if _, found, err := cache.Lookup(file, 10, modTime); err != nil || found {
+		t.Fatalf("Expected an empty cache, got found=%v err=%v", found, err)
+	}
+	if err := cache.Record(file, 10, modTime, "abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cache.Record(file, 12, modTime, "def"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// a new cache reads the file, the latest record of the path wins
+	reloaded := NewFileHashCache(path)
+	if hash, found, err := reloaded.Lookup(file, 12, modTime); err != nil || !found || hash != "def" {
+		t.Fatalf("Expected def, got %q found=%v err=%v", hash, found, err)
+	}
+	if _, found, _ := reloaded.Lookup(file, 10, modTime); found {
+		t.Fatal("Expected no hash for the old size")
+	}
+	if _, found, _ := reloaded.Lookup(file, 12, modTime.Add(time.Second)); found {
+		t.Fatal("Expected no hash for another modification time")
+	}
+}
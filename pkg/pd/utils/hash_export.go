@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// HashEntry is one entry of an exported hash store.
+type HashEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+// ExportHashesJSON writes all entries of the store as one indented JSON array, sorted by path.
+func ExportHashesJSON(w io.Writer, from HashLoader) error {
+	entries, err := hashEntries(from)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+
+	return err
+}
+
+// ExportHashesNDJSON writes all entries of the store as newline delimited JSON, one object per line sorted by
+// path, so exports of several hosts can be concatenated and imported at once.
+func ExportHashesNDJSON(w io.Writer, from HashLoader) error {
+	entries, err := hashEntries(from)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
+
+// ImportHashes saves the entries of a JSON array or NDJSON export into the store and returns how many were read.
+// Entries are merged with the ones of the store, a hash it knows already keeps its path.
+func ImportHashes(r io.Reader, to HashStore) (int, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	// an array starts with '[', NDJSON with the first object
+	peek, err := reader.Peek(1)
+	for err == nil && bytes.ContainsAny(peek, " \t\r\n") {
+		_, _ = reader.Discard(1)
+		peek, err = reader.Peek(1)
+	}
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if peek[0] == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for decoder.More() {
+		var entry HashEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return count, err
+		}
+		if entry.Hash == "" {
+			continue
+		}
+		if err := to.Save(entry.Path, entry.Hash); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// hashEntries returns the entries of the store sorted by path
+func hashEntries(from HashLoader) ([]HashEntry, error) {
+	hashes, err := from.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]HashEntry, 0, len(hashes))
+	for filePath, hash := range hashes {
+		entries = append(entries, HashEntry{Path: filePath, Hash: hash})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,614 @@
+package utils
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// HashStore keeps track of the hashes of already uploaded files for the duplicate check.
+type HashStore interface {
+	Exists(hash string) (bool, error)
+	Save(filePath, hash string) error
+}
+
+// HashFinder is implemented by stores which can return the file path recorded for a hash.
+type HashFinder interface {
+	Find(hash string) (filePath string, found bool, err error)
+}
+
+// HashLoader is implemented by stores which can return all entries at once, as file path to hash.
+type HashLoader interface {
+	Load() (map[string]string, error)
+}
+
+// HashPathsFinder is implemented by stores which keep every path saved with a hash, e.g. the copies of a file
+// in several directories.
+type HashPathsFinder interface {
+	Paths(hash string) ([]string, error)
+}
+
+// HashIDStore is implemented by stores which keep the pixeldrain ID of the upload next to the hash, so a duplicate
+// is answered with the existing remote file even without an upload log.
+type HashIDStore interface {
+	SaveID(filePath, hash, id string) error
+	FindID(hash string) (id string, found bool, err error)
+}
+
+// hashIndex keeps the paths of every hash in the order they were saved and the ID of the last upload
+type hashIndex map[string]*hashEntry
+
+type hashEntry struct {
+	paths []string
+	id    string
+}
+
+// add records the path and the ID of the hash, it returns false if both were known already
+func (idx hashIndex) add(filePath, hash, id string) bool {
+	entry := idx[hash]
+	if entry == nil {
+		entry = &hashEntry{}
+		idx[hash] = entry
+	}
+
+	changed := false
+	if !containsPath(entry.paths, filePath) {
+		entry.paths = append(entry.paths, filePath)
+		changed = true
+	}
+	if id != "" && id != entry.id {
+		entry.id = id
+		changed = true
+	}
+
+	return changed
+}
+
+func (idx hashIndex) find(hash string) (string, bool) {
+	if entry := idx[hash]; entry != nil && len(entry.paths) > 0 {
+		return entry.paths[0], true
+	}
+
+	return "", false
+}
+
+func (idx hashIndex) pathsOf(hash string) []string {
+	if entry := idx[hash]; entry != nil {
+		return append([]string(nil), entry.paths...)
+	}
+
+	return nil
+}
+
+func (idx hashIndex) findID(hash string) (string, bool) {
+	if entry := idx[hash]; entry != nil && entry.id != "" {
+		return entry.id, true
+	}
+
+	return "", false
+}
+
+func containsPath(paths []string, filePath string) bool {
+	for _, p := range paths {
+		if p == filePath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CSVHashStore is a HashStore backed by a CSV file with "path,hash" rows, rows saved with SaveID have the
+// pixeldrain ID as third column. The rows are indexed by hash in memory and parsed again only if the file was
+// changed, e.g. by another process.
+type CSVHashStore struct {
+	Path string
+
+	mu      sync.Mutex
+	index   hashIndex
+	modTime time.Time
+	size    int64
+}
+
+// NewCSVHashStore returns a HashStore using the CSV file at the given path.
+func NewCSVHashStore(path string) *CSVHashStore {
+	return &CSVHashStore{Path: path}
+}
+
+// refresh parses the file again if it was changed since the last parse, the caller holds the file lock
+func (s *CSVHashStore) refresh() error {
+	info, err := os.Stat(s.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && s.index != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	index := hashIndex{}
+	err = eachFileHash(s.Path, func(filePath, hash, id string) {
+		index.add(filePath, hash, id)
+	})
+	if err != nil {
+		return err
+	}
+	s.index = index
+	s.remember()
+
+	return nil
+}
+
+// remember keeps the state of the file the cache was built from
+func (s *CSVHashStore) remember() {
+	if info, err := os.Stat(s.Path); err == nil {
+		s.modTime, s.size = info.ModTime(), info.Size()
+	}
+}
+
+// locked refreshes the index and calls fn while holding the mutex and the lock of the file
+func (s *CSVHashStore) locked(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return WithFileLock(s.Path, func() error {
+		if err := s.refresh(); err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
+// Exists checks if the hash is stored in the CSV file.
+func (s *CSVHashStore) Exists(hash string) (bool, error) {
+	_, found, err := s.Find(hash)
+	return found, err
+}
+
+// Save appends the file path and hash to the CSV file unless the path is stored with the hash already.
+func (s *CSVHashStore) Save(filePath, hash string) error {
+	return s.SaveID(filePath, hash, "")
+}
+
+// SaveID appends the file path, hash and pixeldrain ID unless all of them are stored already.
+func (s *CSVHashStore) SaveID(filePath, hash, id string) error {
+	return s.locked(func() error {
+		if !s.index.add(filePath, hash, id) {
+			return nil
+		}
+
+		if err := appendFileHash(s.Path, filePath, hash, id); err != nil {
+			s.index = nil // parsed again by the next call
+			return err
+		}
+		s.remember()
+
+		return nil
+	})
+}
+
+// Find returns the path of the first file stored with the hash.
+func (s *CSVHashStore) Find(hash string) (string, bool, error) {
+	var filePath string
+	var found bool
+	err := s.locked(func() error {
+		filePath, found = s.index.find(hash)
+		return nil
+	})
+
+	return filePath, found, err
+}
+
+// Paths returns all paths stored with the hash in the order they were saved.
+func (s *CSVHashStore) Paths(hash string) ([]string, error) {
+	var paths []string
+	err := s.locked(func() error {
+		paths = s.index.pathsOf(hash)
+		return nil
+	})
+
+	return paths, err
+}
+
+// FindID returns the pixeldrain ID last stored with the hash.
+func (s *CSVHashStore) FindID(hash string) (string, bool, error) {
+	var id string
+	var found bool
+	err := s.locked(func() error {
+		id, found = s.index.findID(hash)
+		return nil
+	})
+
+	return id, found, err
+}
+
+// Load returns all rows of the CSV file.
+func (s *CSVHashStore) Load() (map[string]string, error) {
+	return LoadFileHashes(s.Path)
+}
+
+// MemoryHashStore is a HashStore kept in memory only, e.g. for tests or a process which checks against a loaded snapshot.
+type MemoryHashStore struct {
+	mu    sync.RWMutex
+	index hashIndex
+}
+
+// NewMemoryHashStore returns an empty MemoryHashStore.
+func NewMemoryHashStore() *MemoryHashStore {
+	return &MemoryHashStore{index: hashIndex{}}
+}
+
+// Exists checks if the hash is stored.
+func (s *MemoryHashStore) Exists(hash string) (bool, error) {
+	_, found, err := s.Find(hash)
+	return found, err
+}
+
+// Save stores the file path with the hash, Find returns the first path of a hash.
+func (s *MemoryHashStore) Save(filePath, hash string) error {
+	return s.SaveID(filePath, hash, "")
+}
+
+// SaveID stores the file path and the pixeldrain ID with the hash.
+func (s *MemoryHashStore) SaveID(filePath, hash, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index == nil {
+		s.index = hashIndex{}
+	}
+	s.index.add(filePath, hash, id)
+
+	return nil
+}
+
+// Find returns the first path stored with the hash.
+func (s *MemoryHashStore) Find(hash string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filePath, found := s.index.find(hash)
+	return filePath, found, nil
+}
+
+// Paths returns all paths stored with the hash in the order they were saved.
+func (s *MemoryHashStore) Paths(hash string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.index.pathsOf(hash), nil
+}
+
+// FindID returns the pixeldrain ID last stored with the hash.
+func (s *MemoryHashStore) FindID(hash string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, found := s.index.findID(hash)
+	return id, found, nil
+}
+
+// Load returns all entries as file path to hash.
+func (s *MemoryHashStore) Load() (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hashes := make(map[string]string, len(s.index))
+	for hash, entry := range s.index {
+		for _, filePath := range entry.paths {
+			hashes[filePath] = hash
+		}
+	}
+
+	return hashes, nil
+}
+
+// NopHashStore knows no hashes and forgets the saved ones, it turns the duplicate check off.
+type NopHashStore struct{}
+
+// Exists always returns false.
+func (NopHashStore) Exists(string) (bool, error) {
+	return false, nil
+}
+
+// Save discards the entry.
+func (NopHashStore) Save(string, string) error {
+	return nil
+}
+
+// LayeredHashStore consults several HashStores in order and writes new entries to the primary store only,
+// e.g. a per-project store checked first and a machine-global store shared by all projects.
+type LayeredHashStore struct {
+	Primary HashStore   // new entries are written here
+	Stores  []HashStore // consulted in order for the duplicate check
+}
+
+// NewLayeredHashStore returns a LayeredHashStore which checks the primary store first and the fallbacks after it.
+func NewLayeredHashStore(primary HashStore, fallbacks ...HashStore) *LayeredHashStore {
+	return &LayeredHashStore{
+		Primary: primary,
+		Stores:  append([]HashStore{primary}, fallbacks...),
+	}
+}
+
+// Exists returns true as soon as one of the stores knows the hash.
+func (s *LayeredHashStore) Exists(hash string) (bool, error) {
+	for _, store := range s.Stores {
+		exists, err := store.Exists(hash)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Save writes the entry to the primary store.
+func (s *LayeredHashStore) Save(filePath, hash string) error {
+	return s.Primary.Save(filePath, hash)
+}
+
+// Find returns the path from the first store which knows the hash and can look it up.
+func (s *LayeredHashStore) Find(hash string) (string, bool, error) {
+	for _, store := range s.Stores {
+		finder, ok := store.(HashFinder)
+		if !ok {
+			continue
+		}
+		filePath, found, err := finder.Find(hash)
+		if err != nil || found {
+			return filePath, found, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// Paths returns the paths from the first store which knows the hash and keeps its paths.
+func (s *LayeredHashStore) Paths(hash string) ([]string, error) {
+	for _, store := range s.Stores {
+		finder, ok := store.(HashPathsFinder)
+		if !ok {
+			continue
+		}
+		paths, err := finder.Paths(hash)
+		if err != nil || len(paths) > 0 {
+			return paths, err
+		}
+	}
+
+	return nil, nil
+}
+
+// SaveID writes the entry with the ID to the primary store.
+func (s *LayeredHashStore) SaveID(filePath, hash, id string) error {
+	return saveHashID(s.Primary, filePath, hash, id)
+}
+
+// FindID returns the ID from the first store which knows one for the hash.
+func (s *LayeredHashStore) FindID(hash string) (string, bool, error) {
+	for _, store := range s.Stores {
+		idStore, ok := store.(HashIDStore)
+		if !ok {
+			continue
+		}
+		id, found, err := idStore.FindID(hash)
+		if err != nil || found {
+			return id, found, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// NamespacedHashStore keeps the entries of one namespace, e.g. an account, apart from the others,
+// so the same file uploaded to a second account isn't detected as a duplicate.
+type NamespacedHashStore struct {
+	Store     HashStore
+	Namespace string
+	// Fallback are namespaces the hashes were saved in before, e.g. "" for the plain hashes of a store from before
+	// it was namespaced. A hash found in one of them counts as stored, new hashes are saved in the Namespace only.
+	Fallback []string
+}
+
+// NewNamespacedHashStore wraps the store, an empty namespace uses the plain hashes.
+func NewNamespacedHashStore(store HashStore, namespace string) *NamespacedHashStore {
+	return &NamespacedHashStore{Store: store, Namespace: namespace}
+}
+
+func namespacedHash(namespace, hash string) string {
+	if namespace == "" {
+		return hash
+	}
+
+	return namespace + ":" + hash
+}
+
+func (s *NamespacedHashStore) key(hash string) string {
+	return namespacedHash(s.Namespace, hash)
+}
+
+// keys returns the key of the hash in the namespace followed by its keys in the fallback namespaces
+func (s *NamespacedHashStore) keys(hash string) []string {
+	keys := []string{s.key(hash)}
+	for _, namespace := range s.Fallback {
+		if namespace != s.Namespace {
+			keys = append(keys, namespacedHash(namespace, hash))
+		}
+	}
+
+	return keys
+}
+
+// Exists checks if the hash is stored in the namespace or a fallback namespace.
+func (s *NamespacedHashStore) Exists(hash string) (bool, error) {
+	for _, key := range s.keys(hash) {
+		exists, err := s.Store.Exists(key)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+
+	return false, nil
+}
+
+// Save stores the hash in the namespace.
+func (s *NamespacedHashStore) Save(filePath, hash string) error {
+	return s.Store.Save(filePath, s.key(hash))
+}
+
+// Find returns the path stored for the hash in the namespace or a fallback namespace.
+func (s *NamespacedHashStore) Find(hash string) (string, bool, error) {
+	finder, ok := s.Store.(HashFinder)
+	if !ok {
+		return "", false, nil
+	}
+
+	for _, key := range s.keys(hash) {
+		path, found, err := finder.Find(key)
+		if err != nil || found {
+			return path, found, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// Paths returns the paths stored for the hash in the namespace, or in the first fallback namespace which has any.
+func (s *NamespacedHashStore) Paths(hash string) ([]string, error) {
+	finder, ok := s.Store.(HashPathsFinder)
+	if !ok {
+		return nil, nil
+	}
+
+	for _, key := range s.keys(hash) {
+		paths, err := finder.Paths(key)
+		if err != nil || len(paths) > 0 {
+			return paths, err
+		}
+	}
+
+	return nil, nil
+}
+
+// SaveID stores the hash and the ID in the namespace.
+func (s *NamespacedHashStore) SaveID(filePath, hash, id string) error {
+	return saveHashID(s.Store, filePath, s.key(hash), id)
+}
+
+// FindID returns the ID stored for the hash in the namespace or a fallback namespace.
+func (s *NamespacedHashStore) FindID(hash string) (string, bool, error) {
+	idStore, ok := s.Store.(HashIDStore)
+	if !ok {
+		return "", false, nil
+	}
+
+	for _, key := range s.keys(hash) {
+		id, found, err := idStore.FindID(key)
+		if err != nil || found {
+			return id, found, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// SyncHashStore serializes the calls to a HashStore which is not safe for concurrent use, e.g. a store of the caller
+// which keeps a plain map. The stores of this package are safe for concurrent use already.
+type SyncHashStore struct {
+	Store HashStore
+
+	mu sync.Mutex
+}
+
+// NewSyncHashStore wraps the store with a mutex.
+func NewSyncHashStore(store HashStore) *SyncHashStore {
+	return &SyncHashStore{Store: store}
+}
+
+// Exists checks if the hash is stored.
+func (s *SyncHashStore) Exists(hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Store.Exists(hash)
+}
+
+// Save stores the file path and hash.
+func (s *SyncHashStore) Save(filePath, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Store.Save(filePath, hash)
+}
+
+// Find returns the path stored for the hash if the wrapped store can look it up.
+func (s *SyncHashStore) Find(hash string) (string, bool, error) {
+	finder, ok := s.Store.(HashFinder)
+	if !ok {
+		return "", false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return finder.Find(hash)
+}
+
+// Paths returns the paths stored for the hash if the wrapped store keeps them.
+func (s *SyncHashStore) Paths(hash string) ([]string, error) {
+	finder, ok := s.Store.(HashPathsFinder)
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return finder.Paths(hash)
+}
+
+// SaveID stores the file path, hash and ID.
+func (s *SyncHashStore) SaveID(filePath, hash, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return saveHashID(s.Store, filePath, hash, id)
+}
+
+// FindID returns the ID stored for the hash if the wrapped store keeps IDs.
+func (s *SyncHashStore) FindID(hash string) (string, bool, error) {
+	idStore, ok := s.Store.(HashIDStore)
+	if !ok {
+		return "", false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return idStore.FindID(hash)
+}
+
+// Load returns all entries if the wrapped store can load them.
+func (s *SyncHashStore) Load() (map[string]string, error) {
+	loader, ok := s.Store.(HashLoader)
+	if !ok {
+		return map[string]string{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return loader.Load()
+}
+
+// saveHashID saves the ID with the entry if the store keeps IDs, other stores save the entry only
+func saveHashID(store HashStore, filePath, hash, id string) error {
+	if idStore, ok := store.(HashIDStore); ok && id != "" {
+		return idStore.SaveID(filePath, hash, id)
+	}
+
+	return store.Save(filePath, hash)
+}
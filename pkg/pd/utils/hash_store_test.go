@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLayeredHashStore(t *testing.T) {
+	dir := t.TempDir()
+	local := NewCSVHashStore(filepath.Join(dir, "local_hashes.csv"))
+	global := NewCSVHashStore(filepath.Join(dir, "global_hashes.csv"))
+
+	if err := global.Save("/other/project/cat.jpg", "global-hash"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	store := NewLayeredHashStore(local, global)
+	if err := store.Save("cat.jpg", "local-hash"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, hash := range []string{"local-hash", "global-hash"} {
+		exists, err := store.Exists(hash)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !exists {
+			t.Fatalf("Expected hash %s to exist", hash)
+		}
+	}
+
+	// new entries must only be written to the primary store
+	exists, err := global.Exists("local-hash")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exists {
+		t.Fatalf("Expected local-hash not to be written to the global store")
+	}
+}
+
+func TestHashStores(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "hashes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlStore, err := NewSQLHashStore(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]HashStore{
+		"csv":    NewCSVHashStore(filepath.Join(dir, "hashes.csv")),
+		"memory": NewMemoryHashStore(),
+		"sql":    sqlStore,
+		"sync":   NewSyncHashStore(NewMemoryHashStore()),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save("cat.jpg", "cat-hash"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Save("copy_of_cat.jpg", "cat-hash"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			exists, err := store.Exists("cat-hash")
+			if err != nil || !exists {
+				t.Fatalf("Expected cat-hash to exist, got %v %v", exists, err)
+			}
+			exists, err = store.Exists("dog-hash")
+			if err != nil || exists {
+				t.Fatalf("Expected dog-hash not to exist, got %v %v", exists, err)
+			}
+
+			filePath, found, err := store.(HashFinder).Find("cat-hash")
+			if err != nil || !found || filePath != "cat.jpg" {
+				t.Fatalf("Expected the first path cat.jpg, got %q %v %v", filePath, found, err)
+			}
+
+			hashes, err := store.(HashLoader).Load()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if hashes["cat.jpg"] != "cat-hash" {
+				t.Fatalf("Expected the cat entry, got %v", hashes)
+			}
+
+			// the SQL table keeps the first path of a hash only
+			if finder, ok := store.(HashPathsFinder); ok {
+				paths, err := finder.Paths("cat-hash")
+				if err != nil || fmt.Sprint(paths) != "[cat.jpg copy_of_cat.jpg]" {
+					t.Fatalf("Expected both paths of the cat, got %v %v", paths, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHashStores_SaveID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.csv")
+	csvStore := NewCSVHashStore(path)
+	stores := map[string]HashStore{
+		"csv":        csvStore,
+		"memory":     NewMemoryHashStore(),
+		"namespaced": NewNamespacedHashStore(NewMemoryHashStore(), "account"),
+		"layered":    NewLayeredHashStore(NewMemoryHashStore(), NewMemoryHashStore()),
+		"sync":       NewSyncHashStore(NewMemoryHashStore()),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			idStore := store.(HashIDStore)
+			if _, found, _ := idStore.FindID("cat-hash"); found {
+				t.Fatal("Expected no ID before the upload")
+			}
+
+			if err := idStore.SaveID("cat.jpg", "cat-hash", "aaa"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save("renamed_cat.jpg", "cat-hash"); err != nil {
+				t.Fatal(err)
+			}
+
+			id, found, err := idStore.FindID("cat-hash")
+			if err != nil || !found || id != "aaa" {
+				t.Fatalf("Expected the ID aaa, got %q %v %v", id, found, err)
+			}
+			paths, err := store.(HashPathsFinder).Paths("cat-hash")
+			if err != nil || fmt.Sprint(paths) != "[cat.jpg renamed_cat.jpg]" {
+				t.Fatalf("Expected both paths, got %v %v", paths, err)
+			}
+		})
+	}
+
+	// another process reads the ID from the file
+	if id, found, err := NewCSVHashStore(path).FindID("cat-hash"); err != nil || !found || id != "aaa" {
+		t.Fatalf("Expected the ID in the file, got %q %v %v", id, found, err)
+	}
+	if exists, err := HashExists(path, "cat-hash"); err != nil || !exists {
+		t.Fatalf("Expected the hash in the file, got %v %v", exists, err)
+	}
+}
+
+func TestCSVHashStore_ChangedByOtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.csv")
+	store := NewCSVHashStore(path)
+	if exists, _ := store.Exists("cat-hash"); exists {
+		t.Fatal("Expected an empty store")
+	}
+
+	// written by another store instance, as another process would
+	if err := SaveFileHash(path, "cat.jpg", "cat-hash"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := store.Exists("cat-hash"); !exists {
+		t.Fatal("Expected the store to read the changed file")
+	}
+}
+
+func TestSQLHashStore_Import(t *testing.T) {
+	dir := t.TempDir()
+	csvStore := NewCSVHashStore(filepath.Join(dir, "hashes.csv"))
+	_ = csvStore.Save("cat.jpg", "cat-hash")
+	_ = csvStore.Save("dog.jpg", "dog-hash")
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "imported.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := NewSQLHashStore(db, "imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Import(csvStore); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, hash := range []string{"cat-hash", "dog-hash"} {
+		if exists, err := store.Exists(hash); err != nil || !exists {
+			t.Fatalf("Expected %s to be imported, got %v %v", hash, exists, err)
+		}
+	}
+}
+
+func TestCSVHashStore_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.csv")
+	store := NewCSVHashStore(path)
+
+	// half of the goroutines use another store instance, as a second client would
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			file, hash := fmt.Sprintf("file%d.txt", i), fmt.Sprintf("hash%d", i)
+			var err error
+			if i%2 == 0 {
+				err = store.Save(file, hash)
+			} else {
+				err = SaveFileHash(path, file, hash)
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	hashes, err := LoadFileHashes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 50 {
+		t.Fatalf("Expected 50 entries, got %d", len(hashes))
+	}
+}
+
+func TestExportImportHashes(t *testing.T) {
+	from := NewMemoryHashStore()
+	_ = from.Save("dog.jpg", "dog-hash")
+	_ = from.Save("cat.jpg", "cat-hash")
+
+	var array, lines bytes.Buffer
+	if err := ExportHashesJSON(&array, from); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportHashesNDJSON(&lines, from); err != nil {
+		t.Fatal(err)
+	}
+	if got := lines.String(); got != "{\"path\":\"cat.jpg\",\"hash\":\"cat-hash\"}\n{\"path\":\"dog.jpg\",\"hash\":\"dog-hash\"}\n" {
+		t.Fatalf("Expected one sorted entry per line, got %q", got)
+	}
+
+	// the NDJSON export of a second host is appended
+	bird := `{"path": "bird.jpg", "hash": "bird-hash"}`
+	for name, export := range map[string]string{"json": array.String(), "ndjson": lines.String() + bird + "\n"} {
+		to := NewCSVHashStore(filepath.Join(t.TempDir(), "hashes.csv"))
+		_ = to.Save("local/cat.jpg", "cat-hash")
+
+		count, err := ImportHashes(strings.NewReader(export), to)
+		if err != nil || count < 2 {
+			t.Fatalf("%s: expected the entries to be imported, got %d %v", name, count, err)
+		}
+
+		for _, hash := range []string{"cat-hash", "dog-hash"} {
+			if exists, err := to.Exists(hash); err != nil || !exists {
+				t.Fatalf("%s: expected %s to be imported, got %v %v", name, hash, exists, err)
+			}
+		}
+		if path, _, _ := to.Find("cat-hash"); path != "local/cat.jpg" {
+			t.Fatalf("%s: expected the known hash to keep its path, got %s", name, path)
+		}
+	}
+
+	if count, err := ImportHashes(strings.NewReader("  \n"), NewMemoryHashStore()); err != nil || count != 0 {
+		t.Fatalf("Expected an empty import, got %d %v", count, err)
+	}
+}
@@ -52,21 +52,14 @@ func InitializeHashFile(hashFilePath string) error {
 	return nil
 }
 
-// SaveFileHash saves the file path and its hash to a CSV file if it doesn't already exist.
+// SaveFileHash saves the file path and its hash to a CSV file unless the path is stored with the hash already.
+// The check and the write hold the lock of the hash file, so concurrent processes don't write a row twice.
 func SaveFileHash(hashFilePath, filePath, hash string) error {
-	if err := InitializeHashFile(hashFilePath); err != nil {
-		return err
-	}
-
-	// Check if the file is a duplicate before saving
-	isDuplicate, err := IsDuplicate(hashFilePath, filePath)
-	if err != nil {
-		return err
-	}
-	if isDuplicate {
-		return nil // Do not save if the file is a duplicate
-	}
+	return NewCSVHashStore(hashFilePath).Save(filePath, hash)
+}
 
+// appendFileHash appends the row without the duplicate check, the caller holds the file lock
+func appendFileHash(hashFilePath, filePath, hash, id string) error {
 	file, err := os.OpenFile(hashFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
@@ -80,18 +73,48 @@ func SaveFileHash(hashFilePath, filePath, hash string) error {
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	return writer.Write([]string{filePath, hash})
+	record := []string{filePath, hash}
+	if id != "" {
+		record = append(record, id)
+	}
+
+	return writer.Write(record)
 }
 
 // LoadFileHashes loads the file hashes from a CSV file into a map.
 func LoadFileHashes(hashFilePath string) (map[string]string, error) {
-	if err := InitializeHashFile(hashFilePath); err != nil {
+	var hashes map[string]string
+	err := WithFileLock(hashFilePath, func() error {
+		var err error
+		hashes, err = loadFileHashes(hashFilePath)
+		return err
+	})
+
+	return hashes, err
+}
+
+func loadFileHashes(hashFilePath string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := eachFileHash(hashFilePath, func(filePath, hash, id string) {
+		hashes[filePath] = hash
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	return hashes, nil
+}
+
+// eachFileHash calls fn for every row of the hash file in the order they were written, the ID is empty in rows
+// without the third column
+func eachFileHash(hashFilePath string, fn func(filePath, hash, id string)) error {
+	if err := InitializeHashFile(hashFilePath); err != nil {
+		return err
+	}
+
 	file, err := os.Open(hashFilePath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer func() {
 		if cerr := file.Close(); cerr != nil {
@@ -100,17 +123,24 @@ func LoadFileHashes(hashFilePath string) (map[string]string, error) {
 	}()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	hashes := make(map[string]string)
 	for _, record := range records {
-		hashes[record[0]] = record[1]
+		if len(record) < 2 {
+			continue
+		}
+		id := ""
+		if len(record) > 2 {
+			id = record[2]
+		}
+		fn(record[0], record[1], id)
 	}
 
-	return hashes, nil
+	return nil
 }
 
 // IsDuplicate checks if the file is a duplicate by comparing its hash with stored hashes.
@@ -120,18 +150,12 @@ func IsDuplicate(hashFilePath, filePath string) (bool, error) {
 		return false, err
 	}
 
-	hashes, err := LoadFileHashes(hashFilePath)
-	if err != nil {
-		return false, err
-	}
-
-	for _, hash := range hashes {
-		if hash == newHash {
-			return true, nil
-		}
-	}
+	return HashExists(hashFilePath, newHash)
+}
 
-	return false, nil
+// HashExists checks if the given hash is already stored in the hash file.
+func HashExists(hashFilePath, hash string) (bool, error) {
+	return NewCSVHashStore(hashFilePath).Exists(hash)
 }
 
 // PrintFileHash prints the SHA-256 hash of a given file.
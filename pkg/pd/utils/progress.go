@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval how often progress callbacks are called during a transfer
+const DefaultProgressInterval = 200 * time.Millisecond
+
+// Progress is a snapshot of a running transfer.
+type Progress struct {
+	Transferred int64         // bytes transferred so far
+	Total       int64         // total bytes, -1 if unknown
+	BytesPerSec float64       // average rate since the start
+	Elapsed     time.Duration // time since the first byte
+	Done        bool          // true for the last call after EOF
+}
+
+// Percent returns the transferred share in percent or -1 if the total is unknown.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+
+	return float64(p.Transferred) / float64(p.Total) * 100
+}
+
+// ProgressFunc receives progress snapshots.
+type ProgressFunc func(Progress)
+
+// progressCounter counts bytes and calls the callback at most once per interval.
+type progressCounter struct {
+	mu       sync.Mutex
+	total    int64
+	fn       ProgressFunc
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	n        int64
+	done     bool
+}
+
+func (c *progressCounter) add(n int64, eof bool) {
+	if c.fn == nil {
+		return
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if c.start.IsZero() {
+		c.start = now
+	}
+	c.n += n
+	if c.done || (!eof && now.Sub(c.last) < c.interval) {
+		c.mu.Unlock()
+		return
+	}
+	c.last = now
+	c.done = eof
+
+	elapsed := now.Sub(c.start)
+	p := Progress{
+		Transferred: c.n,
+		Total:       c.total,
+		Elapsed:     elapsed,
+		Done:        eof,
+	}
+	if elapsed > 0 {
+		p.BytesPerSec = float64(c.n) / elapsed.Seconds()
+	}
+	c.mu.Unlock()
+
+	c.fn(p)
+}
+
+// ProgressReader reports the bytes read from the wrapped reader.
+type ProgressReader struct {
+	r io.Reader
+	c *progressCounter
+}
+
+// NewProgressReader wraps the reader, total is the expected size or -1 if unknown.
+func NewProgressReader(r io.Reader, total int64, fn ProgressFunc) *ProgressReader {
+	return &ProgressReader{
+		r: r,
+		c: &progressCounter{total: total, fn: fn, interval: DefaultProgressInterval},
+	}
+}
+
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.c.add(int64(n), err == io.EOF)
+	return n, err
+}
+
+// Close closes the wrapped reader if it is an io.Closer.
+func (p *ProgressReader) Close() error {
+	if closer, ok := p.r.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// ProgressWriter reports the bytes written to the wrapped writer.
+type ProgressWriter struct {
+	w io.Writer
+	c *progressCounter
+}
+
+// NewProgressWriter wraps the writer, total is the expected size or -1 if unknown.
+func NewProgressWriter(w io.Writer, total int64, fn ProgressFunc) *ProgressWriter {
+	return &ProgressWriter{
+		w: w,
+		c: &progressCounter{total: total, fn: fn, interval: DefaultProgressInterval},
+	}
+}
+
+func (p *ProgressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.c.add(int64(n), false)
+	return n, err
+}
+
+// Finish sends the final progress snapshot, writers don't see an EOF.
+func (p *ProgressWriter) Finish() {
+	p.c.add(0, true)
+}
+
+// ProgressCounter reports bytes counted by the caller, e.g. for transfers split into several requests.
+type ProgressCounter struct {
+	c *progressCounter
+}
+
+// NewProgressCounter creates a counter, total is the expected size or -1 if unknown.
+func NewProgressCounter(total int64, fn ProgressFunc) *ProgressCounter {
+	return &ProgressCounter{c: &progressCounter{total: total, fn: fn, interval: DefaultProgressInterval}}
+}
+
+// Add counts n transferred bytes.
+func (p *ProgressCounter) Add(n int64) {
+	p.c.add(n, false)
+}
+
+// Finish sends the final progress snapshot.
+func (p *ProgressCounter) Finish() {
+	p.c.add(0, true)
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReader(t *testing.T) {
+	var last Progress
+	r := NewProgressReader(strings.NewReader("hello pixeldrain"), 16, func(p Progress) {
+		last = p
+	})
+
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !last.Done || last.Transferred != 16 || last.Percent() != 100 {
+		t.Fatalf("Expected a final progress of 16 bytes, got %+v", last)
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var last Progress
+	var buf bytes.Buffer
+	w := NewProgressWriter(&buf, -1, func(p Progress) {
+		last = p
+	})
+
+	_, _ = w.Write([]byte("hello"))
+	w.Finish()
+
+	if !last.Done || last.Transferred != 5 || last.Percent() != -1 {
+		t.Fatalf("Expected a final progress of 5 bytes, got %+v", last)
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	limiter := NewRateLimiter(1000)
+	r := NewRateLimitedReader(bytes.NewReader(make([]byte, 1500)), limiter)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, r)
+	if err != nil || n != 1500 {
+		t.Fatalf("Expected 1500 bytes, got %d (%v)", n, err)
+	}
+
+	// the first 1000 bytes are the burst, the remaining 500 need about half a second
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("Expected the read to be throttled, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Fatalf("Expected a nil limiter for unlimited rates")
+	}
+
+	w := NewRateLimitedWriter(io.Discard, nil)
+	if n, err := w.Write(make([]byte, 1<<20)); err != nil || n != 1<<20 {
+		t.Fatalf("Expected an unlimited write, got %d (%v)", n, err)
+	}
+}
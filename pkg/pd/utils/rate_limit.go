@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket for bytes per second, it can be shared by several readers and writers.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	path   string // state file of a bucket shared by several processes
+}
+
+// rateLimiterState is the bucket stored in the state file of a shared RateLimiter
+type rateLimiterState struct {
+	Tokens float64   `json:"tokens"`
+	Last   time.Time `json:"last"`
+}
+
+// NewRateLimiter returns a limiter for the given bytes per second, 0 or less means unlimited and returns nil.
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	return &RateLimiter{
+		rate:   float64(bytesPerSec),
+		burst:  float64(bytesPerSec),
+		tokens: float64(bytesPerSec),
+		last:   time.Now(),
+	}
+}
+
+// NewSharedRateLimiter returns a limiter whose bucket is kept in the state file at path, all processes using the
+// same file share the bytes per second. An empty path returns a limiter of this process only.
+func NewSharedRateLimiter(bytesPerSec int64, path string) *RateLimiter {
+	l := NewRateLimiter(bytesPerSec)
+	if l != nil {
+		l.path = path
+	}
+
+	return l
+}
+
+// chunk returns the largest single read or write which fits into the bucket.
+func (l *RateLimiter) chunk(n int) int {
+	if l == nil || n <= int(l.burst) {
+		return n
+	}
+
+	return int(l.burst)
+}
+
+// Wait blocks until n bytes may be transferred, a nil limiter never blocks.
+func (l *RateLimiter) Wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	wait := l.reserve(n)
+	l.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait for them
+func (l *RateLimiter) reserve(n int) time.Duration {
+	if l.path != "" {
+		// a missing or broken state file falls back to the bucket of this process
+		if unlock, err := LockFile(l.path); err == nil {
+			defer unlock()
+			l.loadState()
+			defer l.saveState()
+		}
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+
+	if l.tokens < 0 {
+		return time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+
+	return 0
+}
+
+func (l *RateLimiter) loadState() {
+	raw, err := os.ReadFile(l.path)
+	if err != nil {
+		return
+	}
+
+	state := rateLimiterState{}
+	if err := json.Unmarshal(raw, &state); err == nil && !state.Last.IsZero() {
+		l.tokens, l.last = state.Tokens, state.Last
+	}
+}
+
+func (l *RateLimiter) saveState() {
+	raw, err := json.Marshal(rateLimiterState{Tokens: l.tokens, Last: l.last})
+	if err == nil {
+		_ = os.WriteFile(l.path, raw, 0644)
+	}
+}
+
+// RateLimitedReader limits the read rate of the wrapped reader.
+type RateLimitedReader struct {
+	r io.Reader
+	l *RateLimiter
+}
+
+// NewRateLimitedReader wraps the reader with the limiter.
+func NewRateLimitedReader(r io.Reader, l *RateLimiter) *RateLimitedReader {
+	return &RateLimitedReader{r: r, l: l}
+}
+
+func (r *RateLimitedReader) Read(b []byte) (int, error) {
+	b = b[:r.l.chunk(len(b))]
+	n, err := r.r.Read(b)
+	r.l.Wait(n)
+	return n, err
+}
+
+// Close closes the wrapped reader if it is an io.Closer.
+func (r *RateLimitedReader) Close() error {
+	if closer, ok := r.r.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// RateLimitedWriter limits the write rate of the wrapped writer.
+type RateLimitedWriter struct {
+	w io.Writer
+	l *RateLimiter
+}
+
+// NewRateLimitedWriter wraps the writer with the limiter.
+func NewRateLimitedWriter(w io.Writer, l *RateLimiter) *RateLimitedWriter {
+	return &RateLimitedWriter{w: w, l: l}
+}
+
+func (w *RateLimitedWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		size := w.l.chunk(len(b))
+		w.l.Wait(size)
+		n, err := w.w.Write(b[:size])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[size:]
+	}
+
+	return written, nil
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// SQLHashStore is a HashStore in a SQLite table, looked up by index instead of parsing a file,
+// for libraries of several 100k files. The caller opens the database with the driver of its choice,
+// e.g. github.com/mattn/go-sqlite3 or modernc.org/sqlite, so this package doesn't depend on one.
+type SQLHashStore struct {
+	DB    *sql.DB
+	Table string
+}
+
+// DefaultHashTable is the table of a SQLHashStore created with an empty table name.
+const DefaultHashTable = "file_hashes"
+
+// NewSQLHashStore creates the table if it doesn't exist and returns the store.
+func NewSQLHashStore(db *sql.DB, table string) (*SQLHashStore, error) {
+	if table == "" {
+		table = DefaultHashTable
+	}
+
+	s := &SQLHashStore{DB: db, Table: table}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS "` + table + `" (hash TEXT PRIMARY KEY, path TEXT NOT NULL)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Exists checks if the hash is stored in the table.
+func (s *SQLHashStore) Exists(hash string) (bool, error) {
+	_, found, err := s.Find(hash)
+	return found, err
+}
+
+// Save inserts the file path and hash, the first path of a hash is kept.
+func (s *SQLHashStore) Save(filePath, hash string) error {
+	_, err := s.DB.Exec(`INSERT OR IGNORE INTO "`+s.Table+`" (hash, path) VALUES (?, ?)`, hash, filePath)
+	return err
+}
+
+// Find returns the path stored with the hash.
+func (s *SQLHashStore) Find(hash string) (string, bool, error) {
+	var filePath string
+	err := s.DB.QueryRow(`SELECT path FROM "`+s.Table+`" WHERE hash = ?`, hash).Scan(&filePath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return filePath, true, nil
+}
+
+// Load returns all rows as file path to hash.
+func (s *SQLHashStore) Load() (map[string]string, error) {
+	rows, err := s.DB.Query(`SELECT path, hash FROM "` + s.Table + `"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var filePath, hash string
+		if err := rows.Scan(&filePath, &hash); err != nil {
+			return nil, err
+		}
+		hashes[filePath] = hash
+	}
+
+	return hashes, rows.Err()
+}
+
+// Import copies all entries of another store, e.g. to move an existing CSV file into the table.
+func (s *SQLHashStore) Import(from HashLoader) error {
+	hashes, err := from.Load()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO "` + s.Table + `" (hash, path) VALUES (?, ?)`)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for filePath, hash := range hashes {
+		if _, err := stmt.Exec(hash, filePath); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
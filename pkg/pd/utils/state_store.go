@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateStore keeps small state documents by key, e.g. the progress of a batch, a chunked upload or the index of
+// uploaded files. Embedders implement it to keep the state wherever their application persists data.
+type StateStore interface {
+	Get(key string) (data []byte, found bool, err error)
+	Put(key string, data []byte) error
+	Delete(key string) error
+}
+
+// FileStateStore keeps every key in a file of the directory, an empty Dir uses the keys as paths.
+type FileStateStore struct {
+	Dir string
+}
+
+func (s FileStateStore) path(key string) string {
+	if s.Dir == "" {
+		return key
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(key))
+}
+
+// Get reads the file of the key, a missing file is not found.
+func (s FileStateStore) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// Put writes to a temporary file and renames it, so a crash never leaves a half written state.
+func (s FileStateStore) Put(key string, data []byte) error {
+	path := s.path(key)
+	if s.Dir != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the file of the key, a missing file is no error.
+func (s FileStateStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// MemoryStateStore keeps the state in memory only, e.g. for tests.
+type MemoryStateStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemoryStateStore returns an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{data: map[string][]byte{}}
+}
+
+// Get returns a copy of the stored data.
+func (s *MemoryStateStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, found := s.data[key]
+	return append([]byte(nil), data...), found, nil
+}
+
+// Put stores a copy of the data.
+func (s *MemoryStateStore) Put(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		s.data = map[string][]byte{}
+	}
+	s.data[key] = append([]byte(nil), data...)
+
+	return nil
+}
+
+// Delete removes the key.
+func (s *MemoryStateStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+// SQLStateStore keeps the state in a SQLite table, the caller opens the database with the driver of its choice.
+type SQLStateStore struct {
+	DB    *sql.DB
+	Table string
+}
+
+// DefaultStateTable is the table of a SQLStateStore created with an empty table name.
+const DefaultStateTable = "state"
+
+// NewSQLStateStore creates the table if it doesn't exist and returns the store.
+func NewSQLStateStore(db *sql.DB, table string) (*SQLStateStore, error) {
+	if table == "" {
+		table = DefaultStateTable
+	}
+
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS "` + table + `" (key TEXT PRIMARY KEY, data BLOB NOT NULL)`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStateStore{DB: db, Table: table}, nil
+}
+
+// Get returns the data of the key.
+func (s *SQLStateStore) Get(key string) ([]byte, bool, error) {
+	var data []byte
+	err := s.DB.QueryRow(`SELECT data FROM "`+s.Table+`" WHERE key = ?`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// Put inserts or replaces the data of the key.
+func (s *SQLStateStore) Put(key string, data []byte) error {
+	_, err := s.DB.Exec(`INSERT OR REPLACE INTO "`+s.Table+`" (key, data) VALUES (?, ?)`, key, data)
+	return err
+}
+
+// Delete removes the key.
+func (s *SQLStateStore) Delete(key string) error {
+	_, err := s.DB.Exec(`DELETE FROM "`+s.Table+`" WHERE key = ?`, key)
+	return err
+}
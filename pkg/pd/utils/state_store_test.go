@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateStores(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlStore, err := NewSQLStateStore(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]StateStore{
+		"file":   FileStateStore{Dir: filepath.Join(dir, "state")},
+		"memory": NewMemoryStateStore(),
+		"sql":    sqlStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, found, err := store.Get("batch.json"); err != nil || found {
+				t.Fatalf("Expected a missing key, got %v %v", found, err)
+			}
+
+			if err := store.Put("batch.json", []byte("first")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Put("batch.json", []byte("second")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			data, found, err := store.Get("batch.json")
+			if err != nil || !found || string(data) != "second" {
+				t.Fatalf("Expected the replaced data, got %q %v %v", data, found, err)
+			}
+
+			if err := store.Delete("batch.json"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, found, _ := store.Get("batch.json"); found {
+				t.Fatal("Expected the key to be deleted")
+			}
+		})
+	}
+}
+
+func TestFileIndex_StateStore(t *testing.T) {
+	store := NewMemoryStateStore()
+	index, err := LoadFileIndexFrom(store, "index.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	index.Set(FileIndexEntry{Path: "/data/cat.jpg", Size: 42, ModTime: modTime, Hash: "cat-hash", ID: "abc"})
+	if err := index.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFileIndexFrom(store, "index.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := loaded.Get("/data/cat.jpg")
+	if !ok || entry.ID != "abc" || !entry.ModTime.Equal(modTime) {
+		t.Fatalf("Expected the saved entry, got %+v %v", entry, ok)
+	}
+}
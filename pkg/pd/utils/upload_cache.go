@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// UploadCache remembers the remote ID of every completed upload by file hash,
+// so a re-run of an interrupted batch can skip files which already reached the server.
+type UploadCache interface {
+	Lookup(hash string) (id string, found bool, err error)
+	Record(hash, id string) error
+}
+
+// FileUploadCache is an UploadCache backed by an append-only file with "hash id" lines.
+// Every record is written with a single synced append, a torn last line of a crashed process is ignored.
+type FileUploadCache struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileUploadCache returns an UploadCache using the file at the given path.
+func NewFileUploadCache(path string) *FileUploadCache {
+	return &FileUploadCache{Path: path}
+}
+
+// Lookup returns the remote ID recorded for the hash, the latest record wins.
+func (c *FileUploadCache) Lookup(hash string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	// drop a partial line without the trailing newline
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	} else {
+		data = nil
+	}
+
+	var id string
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == hash {
+			id, found = fields[1], true
+		}
+	}
+
+	return id, found, scanner.Err()
+}
+
+// Record appends the hash and remote ID and syncs the file before returning.
+func (c *FileUploadCache) Record(hash, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	unlock, err := LockFile(c.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := os.OpenFile(c.Path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+
+	// terminate a torn line first, else the new record would be glued to it
+	line := fmt.Sprintf("%s %s\n", hash, id)
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = "\n" + line
+		}
+	}
+
+	if _, err := file.WriteString(line); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
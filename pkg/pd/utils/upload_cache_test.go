@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileUploadCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uploads.cache")
+	cache := NewFileUploadCache(path)
+
+	if _, found, err := cache.Lookup("abc"); err != nil || found {
+		t.Fatalf("Expected an empty cache, got found=%v err=%v", found, err)
+	}
+
+	if err := cache.Record("abc", "id1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cache.Record("abc", "id2"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// a torn write of a crashed process must not be read as a record
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = file.WriteString("def id")
+	file.Close()
+
+	id, found, err := NewFileUploadCache(path).Lookup("abc")
+	if err != nil || !found || id != "id2" {
+		t.Fatalf("Expected id2, got %q found=%v err=%v", id, found, err)
+	}
+
+	if _, found, _ := cache.Lookup("def"); found {
+		t.Fatalf("Expected the partial line to be ignored")
+	}
+
+	if err := cache.Record("ghi", "id3"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if id, found, _ := cache.Lookup("ghi"); !found || id != "id3" {
+		t.Fatalf("Expected a record after a torn line, got %q found=%v", id, found)
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UploadDB keeps the upload log and the duplicate check in a SQLite database, indexed by hash, path and
+// pixeldrain ID, so lookups don't scan upload_logs.csv and hashes.csv. It is an UploadLog and a HashStore.
+// The caller opens the database with the driver of its choice, e.g. github.com/mattn/go-sqlite3.
+type UploadDB struct {
+	*SQLHashStore
+	DB *sql.DB
+}
+
+const uploadColumns = "file_name, path, url, uploaded_at, size, mime_type, uploader, status, error_value, error_message, hash, id"
+
+// NewUploadDB creates the tables and indexes if they don't exist and returns the database.
+func NewUploadDB(db *sql.DB) (*UploadDB, error) {
+	hashes, err := NewSQLHashStore(db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS uploads (
+			file_name TEXT NOT NULL, path TEXT NOT NULL, url TEXT NOT NULL, uploaded_at INTEGER NOT NULL,
+			size INTEGER NOT NULL, mime_type TEXT NOT NULL, uploader TEXT NOT NULL, status TEXT NOT NULL,
+			error_value TEXT NOT NULL, error_message TEXT NOT NULL, hash TEXT NOT NULL, id TEXT NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS uploads_hash ON uploads (hash)`,
+		`CREATE INDEX IF NOT EXISTS uploads_path ON uploads (path)`,
+		`CREATE INDEX IF NOT EXISTS uploads_id ON uploads (id)`,
+		`CREATE INDEX IF NOT EXISTS uploads_uploaded_at ON uploads (uploaded_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, err
+		}
+	}
+
+	return &UploadDB{SQLHashStore: hashes, DB: db}, nil
+}
+
+// Record inserts the upload, the uploader column keeps the API key as the CSV log does.
+func (d *UploadDB) Record(info UploadInfo) error {
+	return insertUpload(d.DB, info)
+}
+
+// insertUpload inserts the row with the database or a transaction
+func insertUpload(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, info UploadInfo) error {
+	uploadedAt, err := time.Parse(time.RFC3339, info.UploadDateTime)
+	if err != nil {
+		uploadedAt = time.Now()
+	}
+
+	_, err = db.Exec(`INSERT INTO uploads (`+uploadColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		info.FileName, info.DirectoryPath, info.URL, uploadedAt.Unix(), info.FileSize, info.MIMEType, info.Uploader,
+		info.UploadStatus, info.ErrorValue, info.ErrorMessage, info.Hash, info.ID)
+	return err
+}
+
+// FindByHash returns the uploads of files with the hash, oldest first.
+func (d *UploadDB) FindByHash(hash string) ([]UploadInfo, error) {
+	return d.query(`WHERE hash = ?`, hash)
+}
+
+// FindByPath returns the uploads of the local path, oldest first.
+func (d *UploadDB) FindByPath(path string) ([]UploadInfo, error) {
+	return d.query(`WHERE path = ?`, path)
+}
+
+// FindByID returns the uploads which created the pixeldrain file.
+func (d *UploadDB) FindByID(id string) ([]UploadInfo, error) {
+	return d.query(`WHERE id = ?`, id)
+}
+
+// ListUploadsSince returns the uploads at or after t, oldest first.
+func (d *UploadDB) ListUploadsSince(t time.Time) ([]UploadInfo, error) {
+	return d.query(`WHERE uploaded_at >= ?`, t.Unix())
+}
+
+// ImportUploadLog copies the rows of a CSV upload log into the database.
+func (d *UploadDB) ImportUploadLog(path string) error {
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = EachUploadInfo(path, func(info UploadInfo) error {
+		return insertUpload(tx, info)
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *UploadDB) query(where string, args ...interface{}) ([]UploadInfo, error) {
+	rows, err := d.DB.Query(`SELECT `+uploadColumns+` FROM uploads `+where+` ORDER BY uploaded_at, rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []UploadInfo
+	for rows.Next() {
+		var info UploadInfo
+		var uploadedAt int64
+		err := rows.Scan(&info.FileName, &info.DirectoryPath, &info.URL, &uploadedAt, &info.FileSize, &info.MIMEType,
+			&info.Uploader, &info.UploadStatus, &info.ErrorValue, &info.ErrorMessage, &info.Hash, &info.ID)
+		if err != nil {
+			return nil, err
+		}
+		info.UploadDateTime = time.Unix(uploadedAt, 0).Format(time.RFC3339)
+		info.FormattedSize = FormatFileSize(info.FileSize)
+		infos = append(infos, info)
+	}
+
+	return infos, rows.Err()
+}
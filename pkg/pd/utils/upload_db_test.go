@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUploadDB(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "uploads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	uploads, err := NewUploadDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := []UploadInfo{
+		{FileName: "cat.jpg", DirectoryPath: "/data/cat.jpg", UploadDateTime: day.Format(time.RFC3339), FileSize: 10, Hash: "cat-hash", ID: "aaa"},
+		{FileName: "dog.jpg", DirectoryPath: "/data/dog.jpg", UploadDateTime: day.Add(time.Hour).Format(time.RFC3339), FileSize: 20, Hash: "dog-hash", ID: "bbb"},
+		{FileName: "cat.jpg", DirectoryPath: "/data/cat.jpg", UploadDateTime: day.Add(48 * time.Hour).Format(time.RFC3339), FileSize: 10, Hash: "cat-hash", ID: "ccc"},
+	}
+	for _, info := range records {
+		if err := uploads.Record(info); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := uploads.FindByHash("cat-hash")
+	if err != nil || len(found) != 2 || found[0].ID != "aaa" || found[1].ID != "ccc" {
+		t.Fatalf("Expected both uploads of the cat, got %+v %v", found, err)
+	}
+	found, err = uploads.FindByPath("/data/dog.jpg")
+	if err != nil || len(found) != 1 || found[0].FileSize != 20 {
+		t.Fatalf("Expected the dog upload, got %+v %v", found, err)
+	}
+	found, err = uploads.FindByID("ccc")
+	if err != nil || len(found) != 1 || found[0].UploadDateTime != records[2].UploadDateTime {
+		t.Fatalf("Expected the last upload, got %+v %v", found, err)
+	}
+	found, err = uploads.ListUploadsSince(day.Add(time.Hour))
+	if err != nil || len(found) != 2 || found[0].ID != "bbb" {
+		t.Fatalf("Expected the uploads since the dog, got %+v %v", found, err)
+	}
+
+	// the database is the duplicate check too
+	if err := uploads.Save("/data/cat.jpg", "cat-hash"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := uploads.Exists("cat-hash"); err != nil || !exists {
+		t.Fatalf("Expected cat-hash to exist, got %v %v", exists, err)
+	}
+}
+
+func TestUploadDB_ImportUploadLog(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "upload_logs.csv")
+	info := UploadInfo{FileName: "cat.jpg", DirectoryPath: "/data/cat.jpg", UploadDateTime: time.Now().Format(time.RFC3339), Hash: "cat-hash", ID: "aaa"}
+	if err := (CSVUploadLog{Path: logPath}).Record(info); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "uploads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	uploads, err := NewUploadDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uploads.ImportUploadLog(logPath); err != nil {
+		t.Fatal(err)
+	}
+	found, err := uploads.FindByID("aaa")
+	if err != nil || len(found) != 1 || found[0].Hash != "cat-hash" {
+		t.Fatalf("Expected the imported upload, got %+v %v", found, err)
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// UploadLog records the uploads, successful and rejected ones.
+type UploadLog interface {
+	Record(info UploadInfo) error
+}
+
+// UploadLogFormat selects the file format of the upload log.
+type UploadLogFormat string
+
+const (
+	UploadLogCSV   UploadLogFormat = "csv"   // one row per upload, the default
+	UploadLogJSONL UploadLogFormat = "jsonl" // JSON Lines, one object per upload, e.g. for jq or log shippers
+	UploadLogJSON  UploadLogFormat = "json"  // one indented array of all uploads, rewritten on every upload
+)
+
+// ErrUnknownUploadLogFormat is returned by NewUploadLog for a format it doesn't know.
+var ErrUnknownUploadLogFormat = errors.New("unknown upload log format")
+
+// NewUploadLog returns the upload log writing the file at path in the format, an empty format is CSV.
+func NewUploadLog(format UploadLogFormat, path string) (UploadLog, error) {
+	switch format {
+	case "", UploadLogCSV:
+		return CSVUploadLog{Path: path}, nil
+	case UploadLogJSONL:
+		return JSONLUploadLog{Path: path}, nil
+	case UploadLogJSON:
+		return JSONUploadLog{Path: path}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownUploadLogFormat, format)
+}
+
+// CSVUploadLog appends the uploads to the CSV file written by SaveUploadInfoToCSV.
+type CSVUploadLog struct {
+	Path string
+}
+
+// Record appends a row to the CSV file.
+func (l CSVUploadLog) Record(info UploadInfo) error {
+	return SaveUploadInfoToCSV(info, l.Path)
+}
+
+// NopUploadLog discards the uploads, e.g. for a client which must not write files.
+type NopUploadLog struct{}
+
+// Record discards the upload.
+func (NopUploadLog) Record(UploadInfo) error {
+	return nil
+}
+
+// UploadFinder is implemented by logs which can look up the uploads of a content hash,
+// e.g. to find the remote file of a duplicate.
+type UploadFinder interface {
+	FindByHash(hash string) ([]UploadInfo, error)
+}
+
+// FindByHash returns the rows with the hash in the order they were written, a missing file has no rows.
+func (l CSVUploadLog) FindByHash(hash string) ([]UploadInfo, error) {
+	var uploads []UploadInfo
+	err := WithFileLock(l.Path, func() error {
+		return EachUploadInfo(l.Path, func(info UploadInfo) error {
+			if info.Hash == hash {
+				uploads = append(uploads, info)
+			}
+			return nil
+		})
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return uploads, err
+}
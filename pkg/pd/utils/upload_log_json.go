@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// JSONLUploadLog appends the uploads to a JSON Lines file, one object per line.
+type JSONLUploadLog struct {
+	Path string
+}
+
+// Record appends a line to the file under its lock, so lines of concurrent processes are not interleaved.
+func (l JSONLUploadLog) Record(info UploadInfo) error {
+	data, err := json.Marshal(withFormattedSize(info))
+	if err != nil {
+		return err
+	}
+
+	return WithFileLock(l.Path, func() error {
+		file, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			file.Close()
+			return err
+		}
+
+		return file.Close()
+	})
+}
+
+// FindByHash returns the lines with the hash in the order they were written, a missing file has no lines.
+func (l JSONLUploadLog) FindByHash(hash string) ([]UploadInfo, error) {
+	var uploads []UploadInfo
+	err := WithFileLock(l.Path, func() error {
+		file, err := os.Open(l.Path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+
+			var info UploadInfo
+			if err := json.Unmarshal(scanner.Bytes(), &info); err != nil {
+				return err
+			}
+			if info.Hash == hash {
+				uploads = append(uploads, info)
+			}
+		}
+
+		return scanner.Err()
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return uploads, err
+}
+
+// JSONUploadLog keeps the uploads as one indented JSON array. Every upload reads and rewrites the whole file,
+// so it suits logs which are read by people, JSONLUploadLog suits large logs.
+type JSONUploadLog struct {
+	Path string
+}
+
+// Record adds the upload to the array, the file is replaced by a rename so a crash never leaves half an array.
+func (l JSONUploadLog) Record(info UploadInfo) error {
+	return WithFileLock(l.Path, func() error {
+		uploads, err := l.load()
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(append(uploads, withFormattedSize(info)), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return FileStateStore{}.Put(l.Path, append(data, '\n'))
+	})
+}
+
+// FindByHash returns the uploads with the hash in the order they were written, a missing file has no uploads.
+func (l JSONUploadLog) FindByHash(hash string) ([]UploadInfo, error) {
+	var uploads []UploadInfo
+	err := WithFileLock(l.Path, func() error {
+		all, err := l.load()
+		for _, info := range all {
+			if info.Hash == hash {
+				uploads = append(uploads, info)
+			}
+		}
+		return err
+	})
+
+	return uploads, err
+}
+
+// load reads the array, the caller holds the file lock
+func (l JSONUploadLog) load() ([]UploadInfo, error) {
+	data, err := os.ReadFile(l.Path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var uploads []UploadInfo
+	if err := json.Unmarshal(data, &uploads); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// withFormattedSize fills in the formatted size like the size column of the CSV log
+func withFormattedSize(info UploadInfo) UploadInfo {
+	if info.FormattedSize == "" {
+		info.FormattedSize = FormatFileSize(info.FileSize)
+	}
+
+	return info
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewUploadLog(t *testing.T) {
+	dir := t.TempDir()
+	records := []UploadInfo{
+		{FileName: "cat.jpg", FileSize: 2048, Hash: "cat-hash", ID: "aaa"},
+		{FileName: "dog.jpg", FileSize: 10, Hash: "dog-hash", ID: "bbb"},
+		{FileName: "cat.jpg", FileSize: 2048, Hash: "cat-hash", ID: "ccc"},
+	}
+
+	for _, format := range []UploadLogFormat{UploadLogCSV, UploadLogJSONL, UploadLogJSON} {
+		path := filepath.Join(dir, "uploads."+string(format))
+		uploadLog, err := NewUploadLog(format, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, info := range records {
+			if err := uploadLog.Record(info); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		found, err := uploadLog.(UploadFinder).FindByHash("cat-hash")
+		if err != nil || len(found) != 2 || found[0].ID != "aaa" || found[1].ID != "ccc" {
+			t.Fatalf("%s: expected both uploads of the cat, got %+v %v", format, found, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "uploads.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var first UploadInfo
+	if len(lines) != 3 || json.Unmarshal([]byte(lines[0]), &first) != nil || first.FormattedSize != "2.00 KB" {
+		t.Fatalf("Expected 3 JSON lines with the formatted size, got %q", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "uploads.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []UploadInfo
+	if err := json.Unmarshal(data, &all); err != nil || len(all) != 3 || !strings.Contains(string(data), "\n  {") {
+		t.Fatalf("Expected an indented array of 3 uploads, got %q %v", data, err)
+	}
+
+	if _, err := NewUploadLog("xml", filepath.Join(dir, "uploads.xml")); err == nil {
+		t.Fatal("Expected an error for an unknown format")
+	}
+}
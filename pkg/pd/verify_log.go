@@ -0,0 +1,76 @@
+package pd
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+// VerifyLog checks every successful upload of the upload log against pixeldrain.
+// A file is missing if its ID no longer exists, e.g. after expiry or deletion, and altered
+// if the remote size or SHA-256 differ from the recorded values. Old log rows without
+// hash and exact size are only checked for existence.
+func (pd *PixelDrainClient) VerifyLog(r *RequestVerifyLog) (*ResponseVerifyLog, error) {
+	if r.LogPath == "" {
+		r.LogPath = CSVFilePath
+	}
+	if r.URL == "" {
+		r.URL = pd.API.URL
+	}
+
+	infos, err := utils.LoadUploadInfos(r.LogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ResponseVerifyLog{}
+	for _, info := range infos {
+		if !strings.HasPrefix(info.UploadStatus, "2") || info.URL == "" {
+			continue
+		}
+
+		id := path.Base(info.URL)
+		if id == "" || id == "u" {
+			continue
+		}
+
+		entry := VerifiedUpload{
+			ID:   id,
+			Path: info.DirectoryPath,
+			Hash: info.Hash,
+			Size: info.FileSize,
+		}
+		report.Checked++
+
+		fileInfo, err := pd.GetFileInfo(&RequestFileInfo{
+			ID:   id,
+			Auth: r.Auth,
+			URL:  r.URL + pd.API.File + "/" + id + "/info",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !fileInfo.Success:
+			entry.Reason = fmt.Sprintf("status %d %s", fileInfo.StatusCode, fileInfo.Value)
+			report.Missing = append(report.Missing, entry)
+		case info.FileSize > 0 && fileInfo.Size != info.FileSize:
+			entry.Reason = fmt.Sprintf("size %d, recorded %d", fileInfo.Size, info.FileSize)
+			report.Altered = append(report.Altered, entry)
+		case info.Hash != "" && fileInfo.HashSha256 != "" && fileInfo.HashSha256 != info.Hash:
+			entry.Reason = fmt.Sprintf("hash %s, recorded %s", fileInfo.HashSha256, info.Hash)
+			report.Altered = append(report.Altered, entry)
+		default:
+			report.OK = append(report.OK, entry)
+			continue
+		}
+
+		log.Printf("Upload %s of %s: %s", id, info.DirectoryPath, entry.Reason)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,52 @@
+package pd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_VerifyLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file/kept/info":
+			_, _ = w.Write([]byte(`{"id": "kept", "size": 10, "hash_sha256": "aaa"}`))
+		case "/file/changed/info":
+			_, _ = w.Write([]byte(`{"id": "changed", "size": 10, "hash_sha256": "ccc"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"success": false, "value": "not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "upload_logs.csv")
+	for _, info := range []utils.UploadInfo{
+		{DirectoryPath: "kept.txt", URL: "https://pixeldrain.com/u/kept", UploadStatus: "201", Hash: "aaa", FileSize: 10},
+		{DirectoryPath: "gone.txt", URL: "https://pixeldrain.com/u/gone", UploadStatus: "201", Hash: "bbb", FileSize: 10},
+		{DirectoryPath: "changed.txt", URL: "https://pixeldrain.com/u/changed", UploadStatus: "201", Hash: "bbb", FileSize: 10},
+		{DirectoryPath: "failed.txt", UploadStatus: "413", ErrorValue: "file_too_large"},
+	} {
+		if err := utils.SaveUploadInfoToCSV(info, logPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := pd.New(nil, nil)
+	report, err := c.VerifyLog(&pd.RequestVerifyLog{LogPath: logPath, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, report.Checked)
+	if assert.Len(t, report.OK, 1) && assert.Len(t, report.Missing, 1) && assert.Len(t, report.Altered, 1) {
+		assert.Equal(t, "kept", report.OK[0].ID)
+		assert.Equal(t, "gone.txt", report.Missing[0].Path)
+		assert.Equal(t, "changed", report.Altered[0].ID)
+	}
+}
@@ -0,0 +1,326 @@
+package pd
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+)
+
+const (
+	// DefaultWatchInterval is the time between two scans of a watched directory without file system notifications
+	DefaultWatchInterval = 2 * time.Second
+	// DefaultWatchDebounce is how long a file must stay unchanged before it is uploaded
+	DefaultWatchDebounce = time.Second
+	// watchEventDelay collects the file system notifications of a burst into one scan
+	watchEventDelay = 100 * time.Millisecond
+	// maxWatchBackoff is the longest pause before a failed upload of a watched file is tried again
+	maxWatchBackoff = 5 * time.Minute
+)
+
+// watchedFile is the state of a file of the watched directory
+type watchedFile struct {
+	mode     os.FileMode
+	size     int64
+	modTime  time.Time
+	since    time.Time // first scan which saw this size and modification time
+	uploaded bool      // this size and modification time was uploaded, skipped or existed when the watch started
+	failures int       // failed uploads of this size and modification time
+	retryAt  time.Time // the next upload after a failure
+}
+
+// Watch uploads new and modified files of the directory until the context is done. A file is uploaded once its
+// size and modification time stayed the same for Debounce, so files which are still written are not sent half.
+// Uploads go through UploadPOST, so the duplicate check skips content which was uploaded before. Files matching an
+// Ignore pattern, sidecars of the Describer and special files like named pipes are skipped. A failed upload is tried
+// again, the pause doubles from Interval on.
+//
+// The directory is scanned on the file system notifications of fsnotify and while files wait for their debounce or
+// retry. Without notifications, because Poll is set or the watcher can't be created, it is scanned every Interval.
+func (pd *PixelDrainClient) Watch(ctx context.Context, r *RequestWatch) error {
+	if r.Directory == "" {
+		return errors.New(ErrMissingDirectory)
+	}
+	if r.Interval <= 0 {
+		r.Interval = DefaultWatchInterval
+	}
+	if r.Debounce <= 0 {
+		r.Debounce = DefaultWatchDebounce
+	}
+	if r.HashFilePath == "" {
+		r.HashFilePath = utils.GetHashFilePath()
+	}
+
+	var watcher *fsnotify.Watcher
+	if !r.Poll {
+		var err error
+		if watcher, err = fsnotify.NewWatcher(); err != nil {
+			log.Printf("Watching %s by polling every %s, no file system notifications: %v", r.Directory, r.Interval, err)
+		} else {
+			defer watcher.Close()
+		}
+	}
+
+	files := map[string]*watchedFile{}
+	if err := pd.scanWatched(ctx, r, watcher, files, !r.UploadExisting); err != nil {
+		return err
+	}
+
+	timer := newWatchTimer()
+	defer timer.Stop()
+	for {
+		if watcher == nil {
+			timer.schedule(r.Interval)
+		} else if at, pending := nextWatched(r, files); pending {
+			timer.schedule(time.Until(at))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.fired()
+		case <-r.Trigger:
+		case _, ok := <-watcherEvents(watcher):
+			if !ok {
+				log.Printf("File system notifications of %s stopped, polling every %s", r.Directory, r.Interval)
+				watcher = nil
+				continue
+			}
+			// a burst of events, e.g. of a file which is written, is one scan
+			timer.schedule(watchEventDelay)
+			continue
+		case err := <-watcherErrors(watcher):
+			// e.g. an overflow of the event queue, the scan finds what was missed
+			log.Printf("Error of the file system notifications of %s: %v", r.Directory, err)
+		}
+
+		if err := pd.scanWatched(ctx, r, watcher, files, false); err != nil {
+			return err
+		}
+	}
+}
+
+// watchTimer wakes the watch at the earliest scheduled time
+type watchTimer struct {
+	*time.Timer
+	at time.Time // zero if nothing is scheduled
+}
+
+func newWatchTimer() *watchTimer {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	return &watchTimer{Timer: timer}
+}
+
+// schedule wakes the watch after d unless it is woken earlier already
+func (t *watchTimer) schedule(d time.Duration) {
+	at := time.Now().Add(d)
+	if !t.at.IsZero() && !at.Before(t.at) {
+		return
+	}
+
+	if !t.Stop() && !t.at.IsZero() {
+		<-t.C
+	}
+	t.at = at
+	t.Reset(d)
+}
+
+// fired marks the timer as expired after its channel was read
+func (t *watchTimer) fired() {
+	t.at = time.Time{}
+}
+
+// nextWatched returns when the next file settles or may be retried, pending is false if no file waits
+func nextWatched(r *RequestWatch, files map[string]*watchedFile) (at time.Time, pending bool) {
+	for _, file := range files {
+		if file.uploaded {
+			continue
+		}
+
+		due := file.since.Add(r.Debounce)
+		if file.retryAt.After(due) {
+			due = file.retryAt
+		}
+		if !pending || due.Before(at) {
+			at, pending = due, true
+		}
+	}
+
+	return at, pending
+}
+
+// watcherEvents returns the events of the watcher, nil blocks forever without a watcher
+func watcherEvents(watcher *fsnotify.Watcher) <-chan fsnotify.Event {
+	if watcher == nil {
+		return nil
+	}
+
+	return watcher.Events
+}
+
+// watcherErrors returns the errors of the watcher, nil blocks forever without a watcher
+func watcherErrors(watcher *fsnotify.Watcher) <-chan error {
+	if watcher == nil {
+		return nil
+	}
+
+	return watcher.Errors
+}
+
+// scanWatched compares the directory with the known files and uploads the files which settled.
+// On the first scan of a watch the existing files are only recorded if baseline is set.
+// The directories are added to the watcher if there is one, so new subdirectories are watched as well.
+func (pd *PixelDrainClient) scanWatched(ctx context.Context, r *RequestWatch, watcher *fsnotify.Watcher, files map[string]*watchedFile, baseline bool) error {
+	now := time.Now()
+	seen := map[string]bool{}
+
+	err := filepath.WalkDir(r.Directory, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// a file removed during the walk is picked up by the next scan
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if watcher != nil {
+				if err := watcher.Add(filePath); err != nil {
+					log.Printf("Error watching %s, its files are found by the scans of other changes: %v", filePath, err)
+				}
+			}
+			return nil
+		}
+		if watchIgnored(r, filePath) || pd.isSidecar(filePath) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[filePath] = true
+
+		file, known := files[filePath]
+		if !known || file.size != info.Size() || !file.modTime.Equal(info.ModTime()) {
+			files[filePath] = &watchedFile{mode: info.Mode(), size: info.Size(), modTime: info.ModTime(), since: now, uploaded: baseline}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for filePath, file := range files {
+		if !seen[filePath] {
+			delete(files, filePath)
+			continue
+		}
+		if file.uploaded || now.Sub(file.since) < r.Debounce || now.Before(file.retryAt) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// special files are skipped like by UploadDirectory with the default settings, a named pipe would block
+		var rsp *ResponseUpload
+		status, _, err := checkSpecialFile(&RequestUploadDirectory{}, utils.DirectoryEntry{Path: filePath, Mode: file.mode, Size: file.size})
+		switch status {
+		case "":
+			rsp, err = pd.uploadWatched(ctx, r, filePath)
+		case BatchFailed:
+		default:
+			log.Printf("Not uploading watched file %s: %v", filePath, err)
+			file.uploaded = true
+			continue
+		}
+
+		if err != nil {
+			// the file stays pending and is tried again after the backoff, or right after it changed
+			file.failures++
+			file.retryAt = now.Add(watchBackoff(r.Interval, file.failures))
+			log.Printf("Error uploading watched file %s, retrying at %s: %v", filePath, file.retryAt.Format(time.RFC3339), err)
+		} else {
+			file.uploaded = true
+		}
+		r.Metrics.observeUpload(MetricsSourceWatch, rsp, err)
+		if r.OnUpload != nil {
+			r.OnUpload(filePath, rsp, err)
+		}
+	}
+	r.Metrics.setQueueDepth(MetricsSourceWatch, pendingWatched(files))
+
+	return nil
+}
+
+// watchBackoff is the pause after the given number of failed uploads, doubled from the scan interval on
+func watchBackoff(interval time.Duration, failures int) time.Duration {
+	backoff := interval
+	for i := 1; i < failures && backoff < maxWatchBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxWatchBackoff {
+		backoff = maxWatchBackoff
+	}
+
+	return backoff
+}
+
+// pendingWatched the number of files which are not uploaded yet, e.g. because they are still written
+func pendingWatched(files map[string]*watchedFile) int {
+	pending := 0
+	for _, file := range files {
+		if !file.uploaded {
+			pending++
+		}
+	}
+
+	return pending
+}
+
+// uploadWatched uploads the file with the UploadRules of the client like UploadDirectory
+func (pd *PixelDrainClient) uploadWatched(ctx context.Context, r *RequestWatch, filePath string) (*ResponseUpload, error) {
+	reqUpload := &RequestUpload{
+		PathToFile: filePath,
+		Anonymous:  r.Anonymous,
+		Auth:       r.Auth,
+	}
+	pd.matchUploadRule(r.Directory, filePath).apply(reqUpload)
+
+	log.Printf("Uploading watched file: %s", filePath)
+	return pd.retryRateLimited(ctx, func() (*ResponseUpload, error) {
+		return pd.UploadPOST(reqUpload, r.HashFilePath)
+	})
+}
+
+// watchIgnored checks the path relative to the directory and the file name against the Ignore patterns
+func watchIgnored(r *RequestWatch, filePath string) bool {
+	rel, err := filepath.Rel(r.Directory, filePath)
+	if err != nil {
+		rel = filePath
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range r.Ignore {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,226 @@
+package pd_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPD_Watch(t *testing.T) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "watched")
+	assert.NoError(t, os.MkdirAll(watched, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "existing.txt"), []byte("existing"), 0644))
+
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{
+		API:       &spec,
+		HashStore: utils.NewMemoryHashStore(),
+		UploadLog: utils.CSVUploadLog{Path: filepath.Join(dir, "uploads.csv")},
+	}, nil)
+
+	uploaded := make(chan string, 10)
+	trigger := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, &pd.RequestWatch{
+			Directory: watched,
+			Anonymous: true,
+			Interval:  time.Hour,
+			Debounce:  time.Millisecond,
+			Ignore:    []string{"*.tmp"},
+			Trigger:   trigger,
+			OnUpload: func(filePath string, rsp *pd.ResponseUpload, err error) {
+				assert.NoError(t, err)
+				uploaded <- filepath.Base(filePath)
+			},
+		})
+	}()
+
+	// the first trigger sees the new files, the second one after the debounce uploads them
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "new.txt"), []byte("new"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "partial.tmp"), []byte("partial"), 0644))
+	trigger <- struct{}{}
+	time.Sleep(10 * time.Millisecond)
+	trigger <- struct{}{}
+
+	select {
+	case name := <-uploaded:
+		assert.Equal(t, "new.txt", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new file was not uploaded")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, uploaded)
+}
+
+// TestPD_Watch_EncryptWithRule encrypts the files matching an UploadRule like UploadDirectory
+func TestPD_Watch_EncryptWithRule(t *testing.T) {
+	server, stored := storingServer()
+	defer server.Close()
+
+	watched := t.TempDir()
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{
+		API:              &spec,
+		DisableUploadLog: true,
+		DisableDedup:     true,
+		UploadRules:      pd.UploadRules{{Pattern: "*.secret", Anonymous: true, EncryptWith: "hunter2"}},
+	}, nil)
+
+	uploaded := make(chan *pd.ResponseUpload, 10)
+	trigger := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, &pd.RequestWatch{
+			Directory: watched,
+			Interval:  time.Hour,
+			Debounce:  time.Millisecond,
+			Trigger:   trigger,
+			OnUpload: func(filePath string, rsp *pd.ResponseUpload, err error) {
+				assert.NoError(t, err)
+				uploaded <- rsp
+			},
+		})
+	}()
+
+	plain := []byte("the secret cat")
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "cat.secret"), plain, 0644))
+	trigger <- struct{}{}
+	time.Sleep(10 * time.Millisecond)
+	trigger <- struct{}{}
+
+	select {
+	case rsp := <-uploaded:
+		assert.Equal(t, "encrypted-id", rsp.ID)
+		assert.False(t, bytes.Contains(stored(), plain), "plaintext was sent")
+	case <-time.After(30 * time.Second):
+		t.Fatal("the file was not uploaded")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+// TestPD_Watch_RetryFailed uploads a file again after a failed upload although it didn't change
+func TestPD_Watch_RetryFailed(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+		if attempt == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"success": true, "id": "watched-id"}`))
+	}))
+	defer server.Close()
+
+	watched := t.TempDir()
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true, RetryPolicy: &pd.NoRetry}, nil)
+
+	results := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, &pd.RequestWatch{
+			Directory: watched,
+			Anonymous: true,
+			Interval:  5 * time.Millisecond,
+			Debounce:  time.Millisecond,
+			OnUpload: func(filePath string, rsp *pd.ResponseUpload, err error) {
+				results <- err
+			},
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(filepath.Join(watched, "flaky.txt"), []byte("flaky"), 0644))
+
+	for i, wantErr := range []bool{true, false} {
+		select {
+		case err := <-results:
+			assert.Equal(t, wantErr, err != nil, "upload %d: %v", i, err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("upload %d did not happen", i)
+		}
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, results)
+}
+
+// TestPD_Watch_Notify a new file in a new subdirectory is uploaded on the file system notifications, with Poll on
+// the scans of the Interval
+func TestPD_Watch_Notify(t *testing.T) {
+	t.Run("notify", func(t *testing.T) { testWatchNewFile(t, &pd.RequestWatch{Interval: time.Hour}) })
+	t.Run("poll", func(t *testing.T) { testWatchNewFile(t, &pd.RequestWatch{Interval: 5 * time.Millisecond, Poll: true}) })
+}
+
+func testWatchNewFile(t *testing.T, r *pd.RequestWatch) {
+	server := pd.MockFileUploadServer()
+	defer server.Close()
+
+	watched := t.TempDir()
+	spec := pd.DefaultAPISpec
+	spec.URL = server.URL
+	c := pd.New(&pd.ClientOptions{API: &spec, DisableUploadLog: true, DisableDedup: true}, nil)
+
+	uploaded := make(chan string, 10)
+	r.Directory = watched
+	r.Anonymous = true
+	r.Debounce = 10 * time.Millisecond
+	r.OnUpload = func(filePath string, rsp *pd.ResponseUpload, err error) {
+		assert.NoError(t, err)
+		uploaded <- filepath.Base(filePath)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, r)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	sub := filepath.Join(watched, "sub")
+	assert.NoError(t, os.MkdirAll(sub, 0755))
+	time.Sleep(300 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(filepath.Join(sub, "new.txt"), []byte("new"), 0644))
+
+	select {
+	case name := <-uploaded:
+		assert.Equal(t, "new.txt", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new file was not uploaded")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
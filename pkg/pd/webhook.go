@@ -0,0 +1,188 @@
+package pd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWebhookAttempts is the number of attempts to deliver a webhook if Webhook.MaxAttempts is 0
+	DefaultWebhookAttempts = 3
+	// DefaultWebhookBackoff is the pause before the second attempt if Webhook.Backoff is 0, doubled after every attempt
+	DefaultWebhookBackoff = time.Second
+	// DefaultWebhookTimeout bounds every attempt to deliver a webhook if Webhook.Timeout is 0
+	DefaultWebhookTimeout = 10 * time.Second
+	// DefaultWebhookWorkers is the number of deliveries at the same time if Webhook.Workers is 0
+	DefaultWebhookWorkers = 4
+	// DefaultWebhookQueueSize is the number of deliveries waiting for a worker if Webhook.QueueSize is 0
+	DefaultWebhookQueueSize = 256
+)
+
+// WebhookEventUpload is the event of the payload after a successful upload
+const WebhookEventUpload = "upload"
+
+// Webhook receives a JSON WebhookPayload after every successful upload, e.g. to trigger a Discord, Slack or n8n
+// automation. The webhook is delivered in the background by a fixed number of workers with their own HTTP client,
+// so a slow receiver doesn't hold up the uploads until the queue is full. Wait waits for the queued deliveries
+// before the program exits. A failed delivery is retried on network errors and 5xx or 429 answers and only logged
+// in the end, it doesn't fail the upload. A Webhook must not be copied after its first delivery.
+type Webhook struct {
+	URL         string
+	Header      http.Header   // sent with every delivery, e.g. the Authorization of the receiver
+	MaxAttempts int           // attempts including the first one, DefaultWebhookAttempts if 0
+	Backoff     time.Duration // pause before the second attempt, DefaultWebhookBackoff if 0
+	Timeout     time.Duration // limit of every attempt, DefaultWebhookTimeout if 0
+	Workers     int           // deliveries at the same time, DefaultWebhookWorkers if 0
+	QueueSize   int           // deliveries waiting for a worker, DefaultWebhookQueueSize if 0, a full queue holds up the next upload
+
+	start   sync.Once
+	queue   chan webhookDelivery
+	pending sync.WaitGroup
+}
+
+// webhookDelivery is a payload waiting in the queue of the webhook
+type webhookDelivery struct {
+	fileName string
+	payload  WebhookPayload
+}
+
+// WebhookPayload is the JSON body of a webhook. Text is a message for people, it is also sent as "content", so a
+// Discord or Slack webhook URL shows it as it is.
+type WebhookPayload struct {
+	Event    string    `json:"event"`
+	FileName string    `json:"file_name"`
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`
+	Size     int64     `json:"size"`
+	Hash     string    `json:"hash"`
+	MimeType string    `json:"mime_type,omitempty"`
+	Time     time.Time `json:"time"`
+	Text     string    `json:"text"`
+	Content  string    `json:"content"`
+}
+
+// newUploadPayload the payload of an upload of the file
+func newUploadPayload(fileName string, rsp *ResponseUpload) WebhookPayload {
+	text := fmt.Sprintf("Uploaded %s: %s", fileName, rsp.GetFileURL())
+
+	return WebhookPayload{
+		Event:    WebhookEventUpload,
+		FileName: fileName,
+		ID:       rsp.ID,
+		URL:      rsp.GetFileURL(),
+		Size:     rsp.Size,
+		Hash:     rsp.Hash,
+		MimeType: rsp.MimeType,
+		Time:     time.Now().UTC(),
+		Text:     text,
+		Content:  text,
+	}
+}
+
+// notifyUpload delivers the webhook of a successful upload, if the client has one
+func (pd *PixelDrainClient) notifyUpload(fileName string, rsp *ResponseUpload) {
+	if pd.Webhook == nil || pd.Webhook.URL == "" || rsp == nil || rsp.ID == "" {
+		return
+	}
+
+	w := pd.Webhook
+	w.start.Do(w.startWorkers)
+	w.pending.Add(1)
+	w.queue <- webhookDelivery{fileName: fileName, payload: newUploadPayload(fileName, rsp)}
+}
+
+// startWorkers creates the queue and starts the workers which deliver from it, they wait for deliveries for the
+// lifetime of the webhook
+func (w *Webhook) startWorkers() {
+	workers, size := w.Workers, w.QueueSize
+	if workers <= 0 {
+		workers = DefaultWebhookWorkers
+	}
+	if size <= 0 {
+		size = DefaultWebhookQueueSize
+	}
+
+	w.queue = make(chan webhookDelivery, size)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for d := range w.queue {
+				if err := w.deliver(d.payload); err != nil {
+					log.Printf("Error delivering the webhook of %s: %v", d.fileName, err)
+				}
+				w.pending.Done()
+			}
+		}()
+	}
+}
+
+// Wait waits until the queued webhooks of the finished uploads are delivered or given up
+func (w *Webhook) Wait() {
+	w.pending.Wait()
+}
+
+// deliver posts the payload until the receiver accepts it or the attempts are used up
+func (w *Webhook) deliver(payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultWebhookAttempts
+	}
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = DefaultWebhookBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, err := w.post(client, body)
+		if err == nil || !retryable || attempt >= attempts {
+			return err
+		}
+
+		log.Printf("Retrying webhook after %v: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends the body once, the error of a failed delivery tells if another attempt may succeed
+func (w *Webhook) post(client *http.Client, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+
+	if rsp.StatusCode >= 300 {
+		retryable := rsp.StatusCode >= 500 || rsp.StatusCode == http.StatusTooManyRequests
+		return retryable, fmt.Errorf("webhook answered with status %d", rsp.StatusCode)
+	}
+
+	return false, nil
+}
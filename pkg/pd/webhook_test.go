@@ -0,0 +1,135 @@
+package pd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/itsDarianNgo/go-pd/pkg/pd"
+	"github.com/itsDarianNgo/go-pd/pkg/pd/pdtest"
+)
+
+// TestPD_Webhook the receiver gets the payload of the upload, a failed delivery is retried
+func TestPD_Webhook(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var payloads []pd.WebhookPayload
+	var tokens []string
+	status := []int{http.StatusServiceUnavailable, http.StatusNoContent}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var payload pd.WebhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.WriteHeader(status[0])
+		if len(status) > 1 {
+			status = status[1:]
+		}
+	}))
+	defer receiver.Close()
+
+	webhook := &pd.Webhook{
+		URL:     receiver.URL,
+		Header:  http.Header{"Authorization": {"Bearer secret"}},
+		Backoff: time.Millisecond,
+	}
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, Webhook: webhook})
+
+	path := filepath.Join(t.TempDir(), "cat.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("meow"), 0644))
+	rsp, err := c.UploadPOST(&pd.RequestUpload{PathToFile: path, Anonymous: true}, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	webhook.Wait()
+	if assert.Len(t, payloads, 2) {
+		payload := payloads[1]
+		assert.Equal(t, pd.WebhookEventUpload, payload.Event)
+		assert.Equal(t, "cat.txt", payload.FileName)
+		assert.Equal(t, rsp.ID, payload.ID)
+		assert.Equal(t, rsp.GetFileURL(), payload.URL)
+		assert.Equal(t, int64(4), payload.Size)
+		assert.Equal(t, rsp.Hash, payload.Hash)
+		assert.Contains(t, payload.Content, payload.URL)
+	}
+	assert.Equal(t, []string{"Bearer secret", "Bearer secret"}, tokens)
+
+	// a rejected payload is not sent again and doesn't fail the upload
+	mu.Lock()
+	status = []int{http.StatusBadRequest}
+	mu.Unlock()
+	_, err = c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("woof"), FileName: "dog.txt", Anonymous: true})
+	assert.NoError(t, err)
+	webhook.Wait()
+	assert.Len(t, payloads, 3)
+}
+
+// TestPD_Webhook_Slow a slow receiver doesn't hold up the upload, a delivery is given up after the Timeout
+func TestPD_Webhook_Slow(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	webhook := &pd.Webhook{URL: receiver.URL, MaxAttempts: 1, Timeout: time.Second}
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, Webhook: webhook})
+
+	start := time.Now()
+	_, err := c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("meow"), FileName: "cat.txt", Anonymous: true})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second, "the upload waited for the webhook")
+
+	webhook.Wait()
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestPD_Webhook_Workers a directory of uploads is delivered by the workers of the webhook, not a goroutine per upload
+func TestPD_Webhook_Workers(t *testing.T) {
+	server := pdtest.NewServer()
+	defer server.Close()
+
+	var running, maxRunning, delivered int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer receiver.Close()
+
+	webhook := &pd.Webhook{URL: receiver.URL, Workers: 2, QueueSize: 3}
+	c := server.Client(&pd.ClientOptions{DisableUploadLog: true, DisableDedup: true, Webhook: webhook})
+	for i := 0; i < 10; i++ {
+		_, err := c.UploadStream(&pd.RequestUploadStream{Reader: strings.NewReader("meow"), FileName: "cat.txt", Anonymous: true})
+		assert.NoError(t, err)
+	}
+
+	webhook.Wait()
+	assert.Equal(t, int32(10), atomic.LoadInt32(&delivered))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+}